@@ -3,6 +3,7 @@ package integration_test
 import (
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync/atomic"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/kotaroyamazaki/playcheck/internal/datasafety"
 	"github.com/kotaroyamazaki/playcheck/internal/manifest"
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/playcheck"
 )
 
 func projectRoot() string {
@@ -74,6 +76,17 @@ func TestIntegration_ViolatingApp(t *testing.T) {
 		t.Error("expected MV004 (cleartext traffic) finding")
 	}
 
+	// Manifest findings: permissive base-config in network security config
+	hasBaseConfig := false
+	for _, f := range result.Findings {
+		if f.CheckID == "MV004" && filepath.Base(f.Location.File) == "network_security_config.xml" && f.Severity == preflight.SeverityError {
+			hasBaseConfig = true
+		}
+	}
+	if !hasBaseConfig {
+		t.Error("expected MV004 error for cleartext base-config in network_security_config.xml")
+	}
+
 	// Code scan findings: HTTP usage (CS001)
 	if !checkIDs["CS001"] {
 		t.Error("expected CS001 (HTTP usage) finding")
@@ -215,7 +228,7 @@ func TestIntegration_ProgressCallback(t *testing.T) {
 
 	runner := newFullRunner()
 	callCount := &atomic.Int32{}
-	runner.Run(appDir, func() {
+	runner.Run(appDir, func(*preflight.CheckResult) {
 		callCount.Add(1)
 	})
 
@@ -224,3 +237,60 @@ func TestIntegration_ProgressCallback(t *testing.T) {
 		t.Errorf("expected 3 progress callbacks, got %d", callCount.Load())
 	}
 }
+
+func TestIntegration_ScanCoverage(t *testing.T) {
+	root := projectRoot()
+	appDir := filepath.Join(root, "testdata", "sample-apps", "violating-app")
+
+	result := newFullRunner().Run(appDir, nil)
+
+	// Code scan reads the Kotlin and Java sources plus the manifest and two
+	// resource XML files; data safety reads the sources, the manifest,
+	// strings.xml, and build.gradle.
+	if got := result.ByScanner["code-scan"].FilesScanned; got != 5 {
+		t.Errorf("expected code scan to cover 5 files, got %d", got)
+	}
+	if got := result.ByScanner["DATA_SAFETY"].FilesScanned; got != 5 {
+		t.Errorf("expected data safety to cover 5 files, got %d", got)
+	}
+	if result.ScanMeta.FilesScanned != 10 {
+		t.Errorf("expected 10 files scanned in total, got %d", result.ScanMeta.FilesScanned)
+	}
+	if result.ScanMeta.BytesScanned == 0 {
+		t.Error("expected non-zero bytes scanned")
+	}
+}
+
+func TestIntegration_AppBundle(t *testing.T) {
+	root := projectRoot()
+	bundlePath := filepath.Join(root, "testdata", "sample-apps", "bundle-app.aab")
+
+	result, err := playcheck.Scan(bundlePath, playcheck.Options{})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	// Bundles carry no sources, so only the manifest scanner runs.
+	if len(result.ScanMeta.ScannerIDs) != 1 || result.ScanMeta.ScannerIDs[0] != playcheck.ScannerManifest {
+		t.Errorf("expected only the manifest scanner, got %v", result.ScanMeta.ScannerIDs)
+	}
+
+	var camera, featureLauncher bool
+	for _, f := range result.Findings {
+		t.Logf("  [%s] %s: %s (%s)", f.Severity, f.CheckID, f.Title, f.Location)
+		switch {
+		case f.CheckID == "DP003":
+			camera = true
+		case f.CheckID == "MV002" && strings.HasSuffix(f.Location.File, "!/camera/manifest/AndroidManifest.xml"):
+			featureLauncher = f.Severity == preflight.SeverityError
+		case f.CheckID == "MV002":
+			t.Errorf("unexpected launcher finding for the base module: %s", f.Title)
+		}
+	}
+	if !camera {
+		t.Error("expected DP003 (camera permission) from the base manifest")
+	}
+	if !featureLauncher {
+		t.Error("expected MV002 error for the launcher in the camera feature module")
+	}
+}
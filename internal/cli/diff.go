@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/spf13/cobra"
+)
+
+// NewDiffCmd creates the diff subcommand.
+func NewDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <old.json> <new.json>",
+		Short: "Compare two JSON scan reports",
+		Long: "Loads two reports written by \"playcheck scan --format json\" and lists findings that were introduced, resolved, or unchanged.\n" +
+			"Exits non-zero when new critical or error-level findings appeared.",
+		Args: usageArgs(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(args[0], args[1], cmd.OutOrStdout())
+		},
+	}
+}
+
+func runDiff(oldPath, newPath string, out io.Writer) error {
+	oldReport, err := loadJSONReport(oldPath)
+	if err != nil {
+		return err
+	}
+	newReport, err := loadJSONReport(newPath)
+	if err != nil {
+		return err
+	}
+
+	diff := preflight.DiffReports(oldReport, newReport)
+	fmt.Fprint(out, diff.RenderTerminal())
+
+	if diff.HasNewCritical() {
+		return findingsError("new critical issues detected")
+	}
+	return nil
+}
+
+// loadJSONReport reads a report written by the scan command's JSON format.
+func loadJSONReport(path string) (preflight.JSONReport, error) {
+	var report preflight.JSONReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, fmt.Errorf("cannot read report: %w", err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("invalid JSON report %s: %w", path, err)
+	}
+	return report, nil
+}
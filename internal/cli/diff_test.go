@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+func writeReport(t *testing.T, dir, name string, findings ...preflight.JSONFinding) string {
+	t.Helper()
+	data, err := json.Marshal(preflight.JSONReport{ProjectPath: "/app", Findings: findings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunDiff_AddedCritical(t *testing.T) {
+	dir := t.TempDir()
+	http := preflight.JSONFinding{CheckID: "CS001", Severity: "ERROR", Title: "HTTP URL", Location: "Api.kt:10"}
+	sms := preflight.JSONFinding{CheckID: "DP001", Severity: "CRITICAL", Title: "SMS permission", Location: "AndroidManifest.xml:4"}
+	oldPath := writeReport(t, dir, "old.json", http)
+	newPath := writeReport(t, dir, "new.json", http, sms)
+
+	var out bytes.Buffer
+	err := runDiff(oldPath, newPath, &out)
+	if err == nil || !strings.Contains(err.Error(), "new critical issues") {
+		t.Errorf("expected new critical issues error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "DP001: SMS permission") {
+		t.Errorf("expected added finding in output, got:\n%s", out.String())
+	}
+}
+
+func TestRunDiff_Resolved(t *testing.T) {
+	dir := t.TempDir()
+	http := preflight.JSONFinding{CheckID: "CS001", Severity: "ERROR", Title: "HTTP URL", Location: "Api.kt:10"}
+	oldPath := writeReport(t, dir, "old.json", http)
+	newPath := writeReport(t, dir, "new.json")
+
+	var out bytes.Buffer
+	if err := runDiff(oldPath, newPath, &out); err != nil {
+		t.Errorf("expected no error when findings are only resolved, got %v", err)
+	}
+	if !strings.Contains(out.String(), "RESOLVED (1)") {
+		t.Errorf("expected resolved section in output, got:\n%s", out.String())
+	}
+}
+
+func TestRunDiff_InvalidReport(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runDiff(bad, bad, &bytes.Buffer{}); err == nil {
+		t.Error("expected error for invalid JSON report")
+	}
+}
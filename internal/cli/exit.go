@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the playcheck command, so CI can tell failing findings from
+// a broken invocation or a scan that could not run.
+const (
+	// ExitOK means no critical or error-level findings were reported.
+	ExitOK = 0
+	// ExitFindings means critical or error-level findings were reported.
+	ExitFindings = 1
+	// ExitUsage means the flags, arguments, or config file are invalid.
+	ExitUsage = 2
+	// ExitError means the scan could not run or its report could not be
+	// written.
+	ExitError = 3
+)
+
+// ExitCoder is implemented by errors that choose the exit code of the
+// playcheck command.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// ExitCode returns the exit code for an error returned by the root command:
+// ExitOK for nil, the code of the first ExitCoder in err's chain, and
+// ExitError for any other error.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var ec ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	return ExitError
+}
+
+// exitError attaches an exit code to an error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+func (e *exitError) ExitCode() int { return e.code }
+
+// usageError marks err as an invalid invocation. A nil err stays nil.
+func usageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: ExitUsage, err: err}
+}
+
+// findingsError reports that findings at or above the failure threshold
+// were reported.
+func findingsError(msg string) error {
+	return &exitError{code: ExitFindings, err: errors.New(msg)}
+}
+
+// usageArgs wraps a cobra argument validator so its errors are usage errors.
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		return usageError(validate(cmd, args))
+	}
+}
+
+// flagError makes cobra's flag parsing errors usage errors.
+func flagError(_ *cobra.Command, err error) error {
+	return usageError(err)
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	violating := filepath.Join("..", "..", "testdata", "sample-apps", "violating-app")
+	clean := filepath.Join("..", "..", "testdata", "sample-apps", "clean-app")
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "clean scan", args: []string{"scan", "-q", "-f", "oneline", clean}, want: ExitOK},
+		{name: "findings above threshold", args: []string{"scan", "-q", "-f", "oneline", violating}, want: ExitFindings},
+		{name: "write without fix", args: []string{"scan", "--write", clean}, want: ExitUsage},
+		{name: "unknown format", args: []string{"scan", "-f", "xml", clean}, want: ExitUsage},
+		{name: "unknown format on a quiet clean scan", args: []string{"scan", "-q", "-f", "xml", clean}, want: ExitUsage},
+		{name: "output is a directory", args: []string{"scan", "-q", "-f", "json", "-o", t.TempDir(), clean}, want: ExitUsage},
+		{name: "unknown flag", args: []string{"scan", "--bogus", clean}, want: ExitUsage},
+		{name: "missing path", args: []string{"scan"}, want: ExitUsage},
+		{name: "nonexistent path", args: []string{"scan", filepath.Join(t.TempDir(), "missing")}, want: ExitUsage},
+		{name: "unknown command", args: []string{"frobnicate"}, want: ExitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewRootCmd()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			var err error
+			captureStdout(t, func() { err = cmd.Execute() })
+			if got := ExitCode(err); got != tt.want {
+				t.Errorf("got exit code %d, want %d (err: %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestExitCode_Errors(t *testing.T) {
+	if got := ExitCode(errors.New("disk full")); got != ExitError {
+		t.Errorf("untyped error: got %d, want %d", got, ExitError)
+	}
+	wrapped := fmt.Errorf("scanning: %w", usageError(errors.New("bad flag")))
+	if got := ExitCode(wrapped); got != ExitUsage {
+		t.Errorf("wrapped usage error: got %d, want %d", got, ExitUsage)
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/fix"
+	"github.com/kotaroyamazaki/playcheck/internal/manifest"
+)
+
+// runFix collects patches for findings with a deterministic fix and either
+// prints them as a unified diff or, with --write, applies them.
+func runFix(absPaths []string, opts *scanOptions) error {
+	var patches []*fix.FilePatch
+	for _, absPath := range absPaths {
+		m, err := manifest.FindAndParse(absPath)
+		if err != nil {
+			continue
+		}
+		if p := m.FixExportedComponents(); p != nil {
+			patches = append(patches, p)
+		}
+	}
+
+	if len(patches) == 0 {
+		fmt.Fprintln(os.Stderr, "No automatic fixes available.")
+		return nil
+	}
+
+	if opts.write {
+		for _, p := range patches {
+			if err := p.Write(); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Fixed %d issue(s) in %s\n", len(p.Edits), diffName(p.Path))
+		}
+		return nil
+	}
+
+	var b strings.Builder
+	for _, p := range patches {
+		b.WriteString(p.Diff(diffName(p.Path)))
+	}
+
+	if opts.output != "" {
+		if err := os.WriteFile(opts.output, []byte(b.String()), 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Patch written to %s\n", opts.output)
+		return nil
+	}
+	fmt.Print(b.String())
+	return nil
+}
+
+// diffName returns path relative to the working directory, so the patch can
+// be applied with `git apply` or `patch -p1` from there.
+func diffName(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// cloneTimeout bounds how long cloning a remote repository may take.
+	cloneTimeout = 2 * time.Minute
+
+	// maxCloneSize is the largest checkout, including .git, that is scanned.
+	maxCloneSize = 500 << 20
+
+	// cloneSizeInterval is how often the size of a running clone is checked.
+	cloneSizeInterval = 500 * time.Millisecond
+)
+
+// gitURLRe matches URLs and scp-style addresses accepted by git clone, e.g.
+// https://github.com/org/app.git or git@github.com:org/app.git.
+var gitURLRe = regexp.MustCompile(`^(?:https?|ssh|git|file)://|^[\w.-]+@[\w.-]+:`)
+
+// isGitURL reports whether a scan argument names a remote repository rather
+// than a local path. A local directory ending in .git is scanned in place.
+func isGitURL(s string) bool {
+	if gitURLRe.MatchString(s) {
+		return true
+	}
+	if strings.HasSuffix(s, ".git") {
+		_, err := os.Stat(s)
+		return errors.Is(err, fs.ErrNotExist)
+	}
+	return false
+}
+
+// gitClone shallow-clones url into dir with the system git binary. It is a
+// variable so tests can replace the clone step.
+var gitClone = func(ctx context.Context, url, dir string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git not found in PATH; clone %s manually and scan the checkout", url)
+	}
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--single-branch", "--quiet", "--", url, dir)
+	// Fail instead of waiting for credentials on private repositories.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("cloning %s: timed out after %s", url, cloneTimeout)
+		}
+		return fmt.Errorf("cloning %s: %w: %s", url, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// cloneRemote clones the repository at url into a temporary directory and
+// returns the checkout path and a function that removes it. A clone that
+// grows past maxCloneSize is aborted, removed, and reported as an error.
+func cloneRemote(url string) (string, func(), error) {
+	tmp, err := os.MkdirTemp("", "playcheck-clone-")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating clone directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+
+	ctx, cancel := context.WithTimeout(context.Background(), cloneTimeout)
+	defer cancel()
+
+	dir := filepath.Join(tmp, "repo")
+	stop := make(chan struct{})
+	exceeded := limitCloneSize(dir, cancel, stop)
+	err = gitClone(ctx, url, dir)
+	close(stop)
+	if <-exceeded {
+		cleanup()
+		return "", nil, fmt.Errorf("repository %s is too large to scan (more than %d MB)", url, maxCloneSize>>20)
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	size, err := dirSize(dir)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("measuring clone of %s: %w", url, err)
+	}
+	if size > maxCloneSize {
+		cleanup()
+		return "", nil, fmt.Errorf("repository %s is too large to scan (%d MB, limit %d MB)", url, size>>20, maxCloneSize>>20)
+	}
+	return dir, cleanup, nil
+}
+
+// limitCloneSize checks the size of dir every cloneSizeInterval until stop is
+// closed, and calls cancel once it passes maxCloneSize. The returned channel
+// receives whether the limit was passed.
+func limitCloneSize(dir string, cancel context.CancelFunc, stop <-chan struct{}) <-chan bool {
+	exceeded := make(chan bool, 1)
+	go func() {
+		ticker := time.NewTicker(cloneSizeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				exceeded <- false
+				return
+			case <-ticker.C:
+				// Files come and go while git runs; count what is there.
+				if size, _ := dirSize(dir); size > maxCloneSize {
+					cancel()
+					exceeded <- true
+					return
+				}
+			}
+		}
+	}()
+	return exceeded
+}
+
+// dirSize returns the total size of the regular files under dir.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+func TestIsGitURL(t *testing.T) {
+	localRepo := filepath.Join(t.TempDir(), "app.git")
+	if err := os.Mkdir(localRepo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		arg  string
+		want bool
+	}{
+		{"https://github.com/org/app.git", true},
+		{"https://github.com/org/app", true},
+		{"git@github.com:org/app.git", true},
+		{"ssh://git@example.com/org/app.git", true},
+		{"missing/app.git", true},
+		{localRepo, false},
+		{"./my-app", false},
+		{"/abs/path/app", false},
+	}
+	for _, tc := range tests {
+		if got := isGitURL(tc.arg); got != tc.want {
+			t.Errorf("isGitURL(%q) = %v, want %v", tc.arg, got, tc.want)
+		}
+	}
+}
+
+// stubClone replaces the clone step with fn for the duration of the test.
+func stubClone(t *testing.T, fn func(ctx context.Context, url, dir string) error) {
+	t.Helper()
+	orig := gitClone
+	gitClone = fn
+	t.Cleanup(func() { gitClone = orig })
+}
+
+func TestRunScan_GitURL(t *testing.T) {
+	var cloned string
+	stubClone(t, func(_ context.Context, url, dir string) error {
+		cloned = dir
+		return copyDir(filepath.Join("..", "..", "testdata", "sample-apps", "violating-app"), dir)
+	})
+
+	const url = "https://example.com/org/app.git"
+	outFile := filepath.Join(t.TempDir(), "report.json")
+	_ = runScan([]string{url}, &scanOptions{format: "json", severity: "all", output: outFile})
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("expected output file to be created: %v", err)
+	}
+	var report preflight.JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if report.ProjectPath != url {
+		t.Errorf("expected project path %q, got %q", url, report.ProjectPath)
+	}
+	if len(report.Findings) == 0 {
+		t.Error("expected findings for the cloned violating app")
+	}
+	if _, err := os.Stat(cloned); !os.IsNotExist(err) {
+		t.Errorf("expected clone %s to be removed, stat error: %v", cloned, err)
+	}
+}
+
+func TestCloneRemote_TooLarge(t *testing.T) {
+	stubClone(t, func(_ context.Context, _, dir string) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		f, err := os.Create(filepath.Join(dir, "huge.bin"))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return f.Truncate(maxCloneSize + 1) // sparse, takes no disk space
+	})
+
+	_, _, err := cloneRemote("https://example.com/org/huge.git")
+	if err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("expected too large error, got %v", err)
+	}
+}
+
+func TestCloneRemote_AbortsOnceTooLarge(t *testing.T) {
+	stubClone(t, func(ctx context.Context, _, dir string) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		f, err := os.Create(filepath.Join(dir, "huge.pack"))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := f.Truncate(maxCloneSize + 1); err != nil {
+			return err
+		}
+		// Keep "downloading" until the clone is aborted.
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	_, _, err := cloneRemote("https://example.com/org/huge.git")
+	if err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("expected too large error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= cloneTimeout {
+		t.Errorf("expected the clone to be aborted early, took %s", elapsed)
+	}
+}
+
+func TestRunScan_GitURLWrite(t *testing.T) {
+	stubClone(t, func(context.Context, string, string) error {
+		t.Error("clone should not run with --write")
+		return nil
+	})
+	err := runScan([]string{"https://example.com/org/app.git"}, &scanOptions{format: "terminal", severity: "all", fix: true, write: true})
+	if err == nil {
+		t.Error("expected error for --write on a remote repository")
+	}
+}
+
+func TestRunScan_GitURLInvalidFlags(t *testing.T) {
+	stubClone(t, func(context.Context, string, string) error {
+		t.Error("clone should not run with invalid flags")
+		return nil
+	})
+	tests := []struct {
+		name string
+		opts scanOptions
+	}{
+		{"format", scanOptions{format: "xml", severity: "all"}},
+		{"severity", scanOptions{format: "terminal", severity: "loud"}},
+		{"fail-on", scanOptions{format: "terminal", severity: "all", failOn: "never"}},
+		{"scanner", scanOptions{format: "terminal", severity: "all", scanners: []string{"nope"}}},
+		{"preset", scanOptions{format: "terminal", severity: "all", preset: "nope"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runScan([]string{"https://example.com/org/app.git"}, &tt.opts)
+			if got := ExitCode(err); got != ExitUsage {
+				t.Errorf("expected exit %d, got %d (%v)", ExitUsage, got, err)
+			}
+		})
+	}
+}
+
+func TestRunScan_GitURLIgnoresRepositoryConfig(t *testing.T) {
+	stubClone(t, func(_ context.Context, _, dir string) error {
+		if err := copyDir(filepath.Join("..", "..", "testdata", "sample-apps", "clean-app"), dir); err != nil {
+			return err
+		}
+		// Loading this config would fail the scan.
+		return os.WriteFile(filepath.Join(dir, ".playcheck.json"), []byte(`{"app_category": "bogus"}`), 0o644)
+	})
+	const url = "https://example.com/org/app.git"
+
+	var err error
+	captureStdout(t, func() {
+		err = runScan([]string{url}, &scanOptions{format: "oneline", severity: "all"})
+	})
+	if got := ExitCode(err); got == ExitUsage {
+		t.Errorf("expected the repository's config to be ignored, got %v", err)
+	}
+
+	cfgPath := filepath.Join(t.TempDir(), "playcheck.json")
+	if err := os.WriteFile(cfgPath, []byte(`{"app_category": "bogus"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() {
+		err = runScan([]string{url}, &scanOptions{format: "oneline", severity: "all", configPath: cfgPath})
+	})
+	if got := ExitCode(err); got != ExitUsage {
+		t.Errorf("expected --config to be loaded for a remote scan, got exit %d (%v)", got, err)
+	}
+}
+
+func TestCloneRemote_LocalRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	src := t.TempDir()
+	if err := copyDir(filepath.Join("..", "..", "testdata", "sample-apps", "clean-app"), src); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=playcheck", "-c", "user.email=playcheck@example.com", "commit", "--quiet", "-m", "fixture"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = src
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	dir, cleanup, err := cloneRemote("file://" + filepath.ToSlash(src))
+	if err != nil {
+		t.Fatalf("cloneRemote failed: %v", err)
+	}
+	defer cleanup()
+	if _, err := os.Stat(filepath.Join(dir, "app", "src", "main", "AndroidManifest.xml")); err != nil {
+		t.Errorf("expected manifest in clone: %v", err)
+	}
+}
+
+// copyDir copies the regular files under src to dst.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+}
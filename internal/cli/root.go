@@ -1,20 +1,70 @@
 package cli
 
 import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
 	"github.com/spf13/cobra"
 )
 
 // NewRootCmd creates the root cobra command for the playcheck CLI.
 func NewRootCmd() *cobra.Command {
+	var noColor, forceColor bool
+
 	rootCmd := &cobra.Command{
 		Use:   "playcheck",
 		Short: "Google Play Store compliance scanner",
 		Long:  "Scans Android projects for Google Play Store policy compliance issues before submission.",
 		SilenceUsage:  true,
 		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return usageError(configureColor(noColor, forceColor))
+		},
+		// Running the root command itself rejects unknown subcommands as
+		// usage errors instead of cobra's untyped error.
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
 	}
+	rootCmd.SetFlagErrorFunc(flagError)
+
+	rootCmd.SetVersionTemplate(versionTemplate())
+
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also set by the NO_COLOR environment variable)")
+	rootCmd.PersistentFlags().BoolVar(&forceColor, "force-color", false, "Color output even when stdout is not a terminal")
 
 	rootCmd.AddCommand(NewScanCmd())
+	rootCmd.AddCommand(NewWatchCmd())
+	rootCmd.AddCommand(NewDiffCmd())
+	rootCmd.AddCommand(NewRulesCmd())
 
 	return rootCmd
 }
+
+// versionTemplate returns the --version output template, which adds the
+// version of the embedded policy database to the build version so users can
+// tell which ruleset the binary carries.
+func versionTemplate() string {
+	policyVersion := "unavailable"
+	if db, err := policies.Load(); err == nil {
+		policyVersion = db.Version
+	}
+	return `{{with .Name}}{{printf "%s " .}}{{end}}{{printf "version %s" .Version}}` + "\npolicy database version " + policyVersion + "\n"
+}
+
+// configureColor decides whether terminal output is colored. By default
+// color.NoColor already honors NO_COLOR, TERM=dumb, and a non-TTY stdout;
+// --no-color and --force-color override that detection.
+func configureColor(noColor, forceColor bool) error {
+	switch {
+	case noColor && forceColor:
+		return fmt.Errorf("--no-color and --force-color are mutually exclusive")
+	case noColor:
+		color.NoColor = true
+	case forceColor:
+		color.NoColor = false
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kotaroyamazaki/playcheck/pkg/playcheck"
+	"github.com/spf13/cobra"
+)
+
+// NewRulesCmd creates the rules subcommand.
+func NewRulesCmd() *cobra.Command {
+	rulesCmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Inspect the rules playcheck checks",
+	}
+	rulesCmd.AddCommand(newRulesExportCmd())
+	return rulesCmd
+}
+
+func newRulesExportCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the rule catalog as JSON",
+		Long: "Writes every rule known to playcheck, merged from the policy database and the manifest and code scanners, as JSON.\n" +
+			"The document carries a schema_version that changes when fields are removed or change meaning.",
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRulesExport(output, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the catalog to this file instead of stdout")
+
+	return cmd
+}
+
+func runRulesExport(output string, out io.Writer) error {
+	catalog, err := playcheck.Rules()
+	if err != nil {
+		return fmt.Errorf("loading rules: %w", err)
+	}
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding rules: %w", err)
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		_, err = out.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kotaroyamazaki/playcheck/pkg/playcheck"
+)
+
+func TestRunRulesExport(t *testing.T) {
+	var out bytes.Buffer
+	if err := runRulesExport("", &out); err != nil {
+		t.Fatalf("runRulesExport failed: %v", err)
+	}
+
+	var catalog playcheck.Catalog
+	if err := json.Unmarshal(out.Bytes(), &catalog); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if catalog.SchemaVersion != playcheck.CatalogSchemaVersion {
+		t.Errorf("got schema version %d, want %d", catalog.SchemaVersion, playcheck.CatalogSchemaVersion)
+	}
+
+	byID := make(map[string]playcheck.Rule)
+	for _, r := range catalog.Rules {
+		if _, dup := byID[r.ID]; dup {
+			t.Errorf("duplicate rule %s", r.ID)
+		}
+		byID[r.ID] = r
+	}
+	for id, scanner := range map[string]string{"CS001": playcheck.ScannerCode, "DP001": playcheck.ScannerManifest, "PDS001": playcheck.ScannerDataSafety} {
+		r, ok := byID[id]
+		if !ok {
+			t.Errorf("catalog is missing %s", id)
+			continue
+		}
+		if r.Title == "" || r.Severity == "" || r.Category == "" || r.Description == "" {
+			t.Errorf("%s has empty fields: %+v", id, r)
+		}
+		if r.Scanner != scanner {
+			t.Errorf("%s scanner = %q, want %q", id, r.Scanner, scanner)
+		}
+	}
+	if r := byID["PDS001"]; r.PolicyLink == "" {
+		t.Errorf("expected PDS001 to keep its policy link, got %+v", r)
+	}
+	if r := byID["SDK003"]; r.PolicyLink == "" || r.Scanner != "" {
+		t.Errorf("expected SDK003 from the policy database only, got %+v", r)
+	}
+}
+
+func TestRunRulesExport_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	var out bytes.Buffer
+	if err := runRulesExport(path, &out); err != nil {
+		t.Fatalf("runRulesExport failed: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected nothing on stdout, got %q", out.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(data) {
+		t.Error("expected the file to hold valid JSON")
+	}
+}
@@ -1,24 +1,50 @@
 package cli
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/kotaroyamazaki/playcheck/internal/codescan"
-	"github.com/kotaroyamazaki/playcheck/internal/datasafety"
+	"github.com/fatih/color"
+	"github.com/kotaroyamazaki/playcheck/internal/config"
 	"github.com/kotaroyamazaki/playcheck/internal/manifest"
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/playcheck"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
 type scanOptions struct {
-	format   string
-	severity string
-	output   string
+	format     string
+	severity   string
+	failOn     string
+	output     string
+	configPath string
+	fix        bool
+	write      bool
+	quiet      bool
+	context    int
+	coverage   bool
+	timeout    time.Duration
+
+	summaryOnly      bool
+	includeAllInJSON bool
+
+	previousVersionCode int
+	scanners            []string
+	skipScanners        []string
+	appCategory         string
+	preset              string
+	forceColor          bool
+	followSymlinks      bool
 }
 
 // NewScanCmd creates the scan subcommand.
@@ -26,51 +52,193 @@ func NewScanCmd() *cobra.Command {
 	opts := &scanOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "scan [project-path]",
+		Use:   "scan [project-path...]",
 		Short: "Scan an Android project for Play Store compliance issues",
-		Long:  "Analyzes an Android project directory and reports any Google Play Store policy violations or compliance issues.",
-		Args:  cobra.ExactArgs(1),
+		Long: "Analyzes one or more Android project directories and reports any Google Play Store policy violations or compliance issues.\n" +
+			"When several paths are given, results are combined into a single report. Pass \"-\" to read paths from stdin, one per line.\n" +
+			"A Git URL (https://..., git@host:org/repo.git) is shallow-cloned to a temporary directory, scanned, and removed again.",
+		Args: usageArgs(cobra.MinimumNArgs(1)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runScan(args[0], opts)
+			if len(args) == 1 && args[0] == "-" {
+				paths, err := readPaths(cmd.InOrStdin())
+				if err != nil {
+					return usageError(err)
+				}
+				args = paths
+			}
+			opts.forceColor, _ = cmd.Flags().GetBool("force-color")
+			return runScan(args, opts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&opts.format, "format", "f", "terminal", "Output format: terminal, json")
+	cmd.Flags().StringVarP(&opts.format, "format", "f", "terminal", "Output format: terminal, json, ndjson, github, oneline, confluence, ids")
 	cmd.Flags().StringVarP(&opts.severity, "severity", "s", "all", "Minimum severity to display: all, critical, warn, info")
+	cmd.Flags().StringVar(&opts.failOn, "fail-on", "error", "Exit with code 1 when findings of this severity or above exist, whether displayed or not: critical, error, warn, info")
 	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Write report to file instead of stdout")
+	cmd.Flags().StringVarP(&opts.configPath, "config", "c", "", "Path to config file (default: <project>/"+config.DefaultFileName+" if present)")
+	cmd.Flags().BoolVar(&opts.fix, "fix", false, "Print a unified diff that fixes supported findings instead of the report")
+	cmd.Flags().BoolVar(&opts.write, "write", false, "With --fix, apply the fixes to the source files")
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Hide the progress bar and print nothing when no findings meet --fail-on")
+	cmd.Flags().BoolVar(&opts.coverage, "coverage", false, "Report which rules were applicable given the files found in the project")
+	cmd.Flags().BoolVar(&opts.summaryOnly, "summary-only", false, "With --format json, leave out the findings and report only the summary and counts")
+	cmd.Flags().BoolVar(&opts.includeAllInJSON, "include-all-in-json", false, "With --format json, include findings of every severity regardless of --severity")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 0, "Abort the scan after this long (e.g. 5m) and report the results gathered so far; 0 means no limit")
+	cmd.Flags().IntVar(&opts.context, "context", 0, "Show this many source lines before and after each code scan match")
+	cmd.Flags().StringArrayVar(&opts.scanners, "scanner", nil, "Run only this scanner (repeatable): "+strings.Join(playcheck.ScannerIDs(), ", "))
+	cmd.Flags().StringArrayVar(&opts.skipScanners, "skip-scanner", nil, "Do not run this scanner (repeatable)")
+	cmd.Flags().StringVar(&opts.appCategory, "app-category", "", "Apply category-specific policies: families (overrides app_category in the config file)")
+	cmd.Flags().StringVar(&opts.preset, "preset", "", "Escalate the rules that matter most for an app type: game, finance, health (overrides preset in the config file)")
+	cmd.Flags().BoolVar(&opts.followSymlinks, "follow-symlinks", false, "Follow symlinked files and directories that resolve inside the project")
+	cmd.Flags().IntVar(&opts.previousVersionCode, "previous-version-code", 0, "versionCode of the last uploaded build; fail unless the new versionCode is greater")
 
 	return cmd
 }
 
-func runScan(projectPath string, opts *scanOptions) error {
-	absPath, err := filepath.Abs(projectPath)
-	if err != nil {
-		return fmt.Errorf("invalid project path: %w", err)
+func runScan(projectPaths []string, opts *scanOptions) error {
+	if len(projectPaths) == 0 {
+		return usageError(fmt.Errorf("no project path given"))
 	}
 
-	info, err := os.Stat(absPath)
-	if err != nil {
-		return fmt.Errorf("cannot access project path: %w", err)
+	// Flags are checked before any remote repository is cloned.
+	if opts.write && !opts.fix {
+		return usageError(fmt.Errorf("--write requires --fix"))
+	}
+	if opts.summaryOnly && opts.format != "json" {
+		return usageError(fmt.Errorf("--summary-only requires --format json"))
+	}
+	if opts.includeAllInJSON && opts.format != "json" {
+		return usageError(fmt.Errorf("--include-all-in-json requires --format json"))
 	}
-	if !info.IsDir() {
-		return fmt.Errorf("project path is not a directory: %s", absPath)
+	if opts.timeout < 0 {
+		return usageError(fmt.Errorf("--timeout must not be negative"))
+	}
+	var (
+		minSeverity, failOn preflight.Severity
+		scanners            []string
+		err                 error
+	)
+	if !opts.fix {
+		if !slices.Contains(scanFormats, opts.format) {
+			return usageError(fmt.Errorf("unknown format: %s (use 'terminal', 'json', 'ndjson', 'github', 'oneline', 'confluence', or 'ids')", opts.format))
+		}
+		if opts.output != "" {
+			if err := checkOutputPath(opts.output); err != nil {
+				return usageError(err)
+			}
+		}
+		if minSeverity, err = parseSeverityFilter(opts.severity); err != nil {
+			return usageError(err)
+		}
+		if failOn, err = parseFailOn(opts.failOn); err != nil {
+			return usageError(err)
+		}
+		if scanners, err = selectScanners(opts.scanners, opts.skipScanners); err != nil {
+			return usageError(err)
+		}
+		if _, err := preflight.ParseAppCategory(opts.appCategory); err != nil {
+			return usageError(err)
+		}
+		if _, err := preflight.ParsePreset(opts.preset); err != nil {
+			return usageError(err)
+		}
+	}
+
+	absPaths := make([]string, 0, len(projectPaths))
+	for _, projectPath := range projectPaths {
+		if isGitURL(projectPath) {
+			if opts.write {
+				return usageError(fmt.Errorf("--write cannot modify a remote repository: %s", projectPath))
+			}
+			dir, cleanup, err := cloneRemote(projectPath)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			absPaths = append(absPaths, dir)
+			continue
+		}
+		absPath, err := resolveProjectDir(projectPath)
+		if err != nil {
+			return usageError(err)
+		}
+		absPaths = append(absPaths, absPath)
+	}
+
+	if opts.fix {
+		return runFix(absPaths, opts)
 	}
 
-	minSeverity, err := parseSeverityFilter(opts.severity)
+	// The config file is looked up in the first project when scanning
+	// several. A cloned repository's own config is ignored unless --config
+	// names it, so a remote scan runs with the caller's settings.
+	cfg := config.Default()
+	if opts.configPath != "" || !isGitURL(projectPaths[0]) {
+		cfg, err = config.Load(opts.configPath, configDir(absPaths[0]))
+		if err != nil {
+			return usageError(err)
+		}
+	}
+	categoryName := cfg.AppCategory
+	if opts.appCategory != "" {
+		categoryName = opts.appCategory
+	}
+	category, err := preflight.ParseAppCategory(categoryName)
+	if err != nil {
+		return usageError(err)
+	}
+	presetName := cfg.Preset
+	if opts.preset != "" {
+		presetName = opts.preset
+	}
+	preset, err := preflight.ParsePreset(presetName)
 	if err != nil {
-		return err
+		return usageError(err)
+	}
+	scanOpts := playcheck.Options{
+		Scanners:             scanners,
+		PreviousVersionCode:  opts.previousVersionCode,
+		MinSDKFloor:          cfg.MinSDKFloor,
+		AppCategory:          category,
+		Preset:               preset,
+		ContextLines:         opts.context,
+		StoreCriticalStrings: cfg.StoreCriticalStrings,
+		EndpointAllowlist:    cfg.EndpointAllowlist,
+		AcknowledgedSDKs:     cfg.AcknowledgedSDKs,
+		FollowSymlinks:       opts.followSymlinks || cfg.FollowSymlinks,
 	}
 
-	runner := preflight.NewDefaultRunner(func(r *preflight.Runner) {
-		r.RegisterScanner(manifest.NewScanner())
-		r.RegisterScanner(codescan.NewScanner())
-		r.RegisterScanner(datasafety.NewChecker())
-	})
-	checkers := runner.Checkers()
+	// NDJSON streams findings while scanners complete instead of rendering
+	// the report at the end.
+	var stream *preflight.NDJSONWriter
+	if opts.format == "ndjson" {
+		out := io.Writer(os.Stdout)
+		if opts.output != "" {
+			// Created on the first line, so a quiet clean scan leaves no file.
+			f := &lazyFile{path: opts.output}
+			defer f.Close()
+			out = f
+		}
+		stream = preflight.NewNDJSONWriter(out, minSeverity)
+		// Under --quiet, whether anything is printed is only known once the
+		// scan is done, so the findings are written with the summary.
+		if !opts.quiet {
+			scanOpts.OnFinding = stream.WriteFinding
+		}
+	}
 
-	bar := progressbar.NewOptions(len(checkers),
+	progressOut := io.Writer(os.Stderr)
+	if opts.quiet {
+		progressOut = io.Discard
+	}
+	// App Bundles only run the manifest scanner, so the total is counted
+	// per path.
+	total := 0
+	for _, absPath := range absPaths {
+		total += len(playcheck.ScannersFor(absPath, scanOpts))
+	}
+	bar := progressbar.NewOptions(total,
 		progressbar.OptionSetDescription("Scanning..."),
-		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionSetWriter(progressOut),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetWidth(40),
 		progressbar.OptionThrottle(50*time.Millisecond),
@@ -78,38 +246,132 @@ func runScan(projectPath string, opts *scanOptions) error {
 		progressbar.OptionSetPredictTime(false),
 	)
 
-	scanResult := runner.Run(absPath, func() {
+	// Scanners finish concurrently; the lock keeps the running total and the
+	// bar in step.
+	var mu sync.Mutex
+	found := 0
+	scanOpts.OnScannerDone = func(cr *preflight.CheckResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		found += len(cr.Findings)
+		bar.Describe(fmt.Sprintf("Scanning... (%d findings)", found))
 		_ = bar.Add(1)
-	})
+	}
+
+	ctx := context.Background()
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	// A timeout stops the scan but still reports what was gathered, so the
+	// error is only returned once the report is written.
+	var timeoutErr error
+	results := make([]*preflight.ScanResult, 0, len(absPaths))
+	for i, absPath := range absPaths {
+		if stream != nil && len(absPaths) > 1 {
+			stream.SetLabel(filepath.Clean(projectPaths[i]))
+		}
+		result, err := playcheck.ScanContext(ctx, absPath, scanOpts)
+		if result == nil {
+			return err
+		}
+		if isGitURL(projectPaths[i]) {
+			// Report the URL rather than the temporary checkout.
+			result.ScanMeta.ProjectPath = projectPaths[i]
+		}
+		results = append(results, result)
+		if err != nil {
+			timeoutErr = scanTimeoutError(opts.timeout, projectPaths, results)
+			break
+		}
+	}
 
 	_ = bar.Finish()
-	fmt.Fprint(os.Stderr, "\r\033[K") // clear progress bar line
+	if !opts.quiet {
+		fmt.Fprint(os.Stderr, "\r\033[K") // clear progress bar line
+	}
+
+	scanResult := results[0]
+	if len(results) > 1 {
+		labels := make([]string, len(results))
+		for i := range results {
+			labels[i] = filepath.Clean(projectPaths[i])
+		}
+		scanResult = preflight.MergeResults(labels, results)
+	}
+
+	newReport := func(minSeverity preflight.Severity) *preflight.Report {
+		report := preflight.NewReport(scanResult, minSeverity)
+		report.ScoreWeights = cfg.Weights()
+		report.ShowCoverage = opts.coverage
+		report.SummaryOnly = opts.summaryOnly
+		report.FailOn = failOn
+		return report
+	}
+	report := newReport(minSeverity)
+
+	// The exit code depends on --fail-on alone, so findings hidden by
+	// --severity still fail the scan.
+	exitErr := timeoutErr
+	if exitErr == nil && report.HasFindingsAtOrAbove(report.FailOn) {
+		exitErr = failOnError(failOn)
+	}
+
+	if opts.quiet && exitErr == nil && !report.HasFindingsAtOrAbove(failOn) {
+		return nil
+	}
 
-	report := preflight.NewReport(scanResult, minSeverity)
+	if stream != nil {
+		if opts.quiet {
+			// The merged findings already carry their module labels.
+			stream.SetLabel("")
+			for _, f := range scanResult.Findings {
+				stream.WriteFinding(f)
+			}
+		}
+		if err := stream.WriteSummary(report); err != nil {
+			return fmt.Errorf("failed to write NDJSON: %w", err)
+		}
+		if opts.output != "" {
+			fmt.Fprintf(os.Stderr, "Report written to %s\n", opts.output)
+		}
+		return exitErr
+	}
 
 	var outputData []byte
 
 	switch opts.format {
 	case "json":
-		outputData, err = json.MarshalIndent(report.ToJSON(), "", "  ")
+		jsonReport := report
+		if opts.includeAllInJSON {
+			jsonReport = newReport(preflight.SeverityInfo)
+		}
+		outputData, err = json.MarshalIndent(jsonReport.ToJSON(), "", "  ")
 		if err != nil {
 			return fmt.Errorf("failed to marshal JSON: %w", err)
 		}
 		outputData = append(outputData, '\n')
 	case "terminal":
+		if opts.output != "" && !opts.forceColor {
+			// Report files are not viewed in a terminal, so drop the escapes.
+			color.NoColor = true
+		}
 		outputData = []byte(report.RenderTerminal())
-	default:
-		return fmt.Errorf("unknown format: %s (use 'terminal' or 'json')", opts.format)
+	case "github":
+		outputData = []byte(report.RenderGitHub())
+	case "oneline":
+		outputData = []byte(report.StatusLine() + "\n")
+	case "confluence":
+		outputData = []byte(report.RenderConfluence())
+	case "ids":
+		for _, id := range report.CheckIDs() {
+			outputData = append(outputData, id+"\n"...)
+		}
 	}
 
 	if opts.output != "" {
-		// Validate output path to prevent accidental overwrites.
-		if outInfo, err := os.Stat(opts.output); err == nil {
-			if outInfo.IsDir() {
-				return fmt.Errorf("output path is a directory: %s", opts.output)
-			}
-			fmt.Fprintf(os.Stderr, "Warning: overwriting existing file %s\n", opts.output)
-		}
 		if err := os.WriteFile(opts.output, outputData, 0644); err != nil {
 			return fmt.Errorf("failed to write output file: %w", err)
 		}
@@ -118,12 +380,165 @@ func runScan(projectPath string, opts *scanOptions) error {
 		fmt.Print(string(outputData))
 	}
 
-	if report.HasCritical() {
-		return fmt.Errorf("critical issues detected")
+	return exitErr
+}
+
+// lazyFile is an io.Writer that creates the file at path on the first write.
+type lazyFile struct {
+	path string
+	f    *os.File
+}
+
+func (l *lazyFile) Write(p []byte) (int, error) {
+	if l.f == nil {
+		f, err := os.Create(l.path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create output file: %w", err)
+		}
+		l.f = f
+	}
+	return l.f.Write(p)
+}
+
+// Close closes the file if it was created.
+func (l *lazyFile) Close() error {
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+// failOnError is the error returned when findings at or above the --fail-on
+// threshold exist.
+func failOnError(threshold preflight.Severity) error {
+	if threshold >= preflight.SeverityError {
+		return findingsError("critical issues detected")
+	}
+	return findingsError(fmt.Sprintf("issues at %s or above detected", strings.ToLower(threshold.String())))
+}
+
+// scanTimeoutError describes a scan stopped by --timeout, naming the scanners
+// that had not finished in the last scanned project and the projects that
+// were not scanned at all. results holds the results gathered so far, one
+// per scanned project.
+func scanTimeoutError(timeout time.Duration, projectPaths []string, results []*preflight.ScanResult) error {
+	last := len(results) - 1
+	unfinished := results[last].ScanMeta.Unfinished
+	if len(projectPaths) > 1 {
+		unfinished = nil
+		label := filepath.Clean(projectPaths[last])
+		for _, id := range results[last].ScanMeta.Unfinished {
+			unfinished = append(unfinished, label+":"+id)
+		}
+		for _, p := range projectPaths[last+1:] {
+			unfinished = append(unfinished, filepath.Clean(p)+" (not scanned)")
+		}
+	}
+	return fmt.Errorf("scan timed out after %s before %s finished; the report shows partial results", timeout, strings.Join(unfinished, ", "))
+}
+
+// scanFormats lists the values accepted by --format.
+var scanFormats = []string{"terminal", "json", "ndjson", "github", "oneline", "confluence", "ids"}
+
+// checkOutputPath validates the output path to prevent accidental overwrites.
+func checkOutputPath(path string) error {
+	if outInfo, err := os.Stat(path); err == nil {
+		if outInfo.IsDir() {
+			return fmt.Errorf("output path is a directory: %s", path)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: overwriting existing file %s\n", path)
 	}
 	return nil
 }
 
+// resolveProjectDir returns the absolute path of projectPath after checking
+// that it is an accessible directory or App Bundle.
+func resolveProjectDir(projectPath string) (string, error) {
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid project path: %w", err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot access project path: %w", err)
+	}
+	if !info.IsDir() && !manifest.IsBundlePath(absPath) {
+		return "", fmt.Errorf("project path is not a directory or .aab bundle: %s", absPath)
+	}
+	return absPath, nil
+}
+
+// configDir returns the directory the default config file is looked up in
+// for the project at absPath: the project itself, or the directory holding
+// an App Bundle.
+func configDir(absPath string) string {
+	if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+		return filepath.Dir(absPath)
+	}
+	return absPath
+}
+
+// selectScanners returns the IDs of the scanners to run: only if non-empty,
+// otherwise every scanner, minus those in skip. Unknown IDs are an error.
+func selectScanners(only, skip []string) ([]string, error) {
+	if err := playcheck.ValidateScannerIDs(only); err != nil {
+		return nil, err
+	}
+	if err := playcheck.ValidateScannerIDs(skip); err != nil {
+		return nil, err
+	}
+	if len(only) == 0 {
+		only = playcheck.ScannerIDs()
+	}
+	var ids []string
+	for _, id := range only {
+		if !slices.Contains(skip, id) && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no scanners left to run")
+	}
+	return ids, nil
+}
+
+// readPaths reads newline-separated project paths, ignoring blank lines.
+func readPaths(r io.Reader) ([]string, error) {
+	var paths []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if p := strings.TrimSpace(sc.Text()); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading project paths from stdin: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no project paths read from stdin")
+	}
+	return paths, nil
+}
+
+// parseFailOn converts a --fail-on value to the lowest severity that fails
+// the scan. Unlike the display filter, "error" and "critical" are distinct.
+// An empty value selects the default, "error".
+func parseFailOn(s string) (preflight.Severity, error) {
+	switch s {
+	case "", "error":
+		return preflight.SeverityError, nil
+	case "critical":
+		return preflight.SeverityCritical, nil
+	case "warn", "warning":
+		return preflight.SeverityWarning, nil
+	case "info":
+		return preflight.SeverityInfo, nil
+	default:
+		return 0, fmt.Errorf("unknown --fail-on severity: %s (use critical, error, warn, or info)", s)
+	}
+}
+
 func parseSeverityFilter(s string) (preflight.Severity, error) {
 	switch s {
 	case "all":
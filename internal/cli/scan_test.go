@@ -1,9 +1,18 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/fatih/color"
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
 )
 
@@ -45,7 +54,7 @@ func TestParseSeverityFilter(t *testing.T) {
 
 func TestRunScan_NonexistentPath(t *testing.T) {
 	opts := &scanOptions{format: "terminal", severity: "all"}
-	err := runScan("/nonexistent/path/that/does/not/exist", opts)
+	err := runScan([]string{"/nonexistent/path/that/does/not/exist"}, opts)
 	if err == nil {
 		t.Error("expected error for nonexistent path")
 	}
@@ -58,7 +67,7 @@ func TestRunScan_NotADirectory(t *testing.T) {
 	}
 
 	opts := &scanOptions{format: "terminal", severity: "all"}
-	err := runScan(f, opts)
+	err := runScan([]string{f}, opts)
 	if err == nil {
 		t.Error("expected error when path is a file, not a directory")
 	}
@@ -67,7 +76,7 @@ func TestRunScan_NotADirectory(t *testing.T) {
 func TestRunScan_InvalidSeverity(t *testing.T) {
 	dir := t.TempDir()
 	opts := &scanOptions{format: "terminal", severity: "badvalue"}
-	err := runScan(dir, opts)
+	err := runScan([]string{dir}, opts)
 	if err == nil {
 		t.Error("expected error for invalid severity filter")
 	}
@@ -76,18 +85,108 @@ func TestRunScan_InvalidSeverity(t *testing.T) {
 func TestRunScan_UnknownFormat(t *testing.T) {
 	dir := t.TempDir()
 	opts := &scanOptions{format: "yaml", severity: "all"}
-	err := runScan(dir, opts)
+	err := runScan([]string{dir}, opts)
 	if err == nil {
 		t.Error("expected error for unknown output format")
 	}
 }
 
+func TestRunScan_Fix(t *testing.T) {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "AndroidManifest.xml")
+	original := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <application>
+        <service android:name=".SyncService">
+            <intent-filter>
+                <action android:name="com.example.SYNC" />
+            </intent-filter>
+        </service>
+    </application>
+</manifest>
+`
+	if err := os.WriteFile(manifestPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	patchFile := filepath.Join(dir, "fix.patch")
+	if err := runScan([]string{dir}, &scanOptions{format: "terminal", severity: "all", fix: true, output: patchFile}); err != nil {
+		t.Fatalf("runScan(--fix) error: %v", err)
+	}
+	patch, err := os.ReadFile(patchFile)
+	if err != nil {
+		t.Fatalf("expected patch file: %v", err)
+	}
+	if !strings.Contains(string(patch), `+        <service android:exported="false" android:name=".SyncService">`) {
+		t.Errorf("unexpected patch:\n%s", patch)
+	}
+	if data, _ := os.ReadFile(manifestPath); string(data) != original {
+		t.Error("--fix without --write must not modify the manifest")
+	}
+
+	if err := runScan([]string{dir}, &scanOptions{format: "terminal", severity: "all", fix: true, write: true}); err != nil {
+		t.Fatalf("runScan(--fix --write) error: %v", err)
+	}
+	data, _ := os.ReadFile(manifestPath)
+	if !strings.Contains(string(data), `<service android:exported="false" android:name=".SyncService">`) {
+		t.Errorf("expected manifest to be fixed, got:\n%s", data)
+	}
+}
+
+func TestRunScan_WriteRequiresFix(t *testing.T) {
+	opts := &scanOptions{format: "terminal", severity: "all", write: true}
+	if err := runScan([]string{t.TempDir()}, opts); err == nil {
+		t.Error("expected error for --write without --fix")
+	}
+}
+
+func TestRunScan_SummaryOnlyRequiresJSON(t *testing.T) {
+	opts := &scanOptions{format: "terminal", severity: "all", summaryOnly: true}
+	if err := runScan([]string{t.TempDir()}, opts); err == nil {
+		t.Error("expected error for --summary-only without --format json")
+	}
+}
+
+func TestRunScan_NDJSONOutput(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <uses-permission android:name="android.permission.SEND_SMS" />
+    <uses-permission android:name="android.permission.CAMERA" />
+    <application android:usesCleartextTraffic="true" />
+</manifest>`
+	if err := os.WriteFile(filepath.Join(dir, "AndroidManifest.xml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outFile := filepath.Join(t.TempDir(), "report.ndjson")
+	opts := &scanOptions{format: "ndjson", severity: "all", output: outFile}
+	_ = runScan([]string{dir}, opts)
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("expected output file to be created: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected finding lines and a summary line, got %d", len(lines))
+	}
+	for i, line := range lines {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v\n%s", i+1, err, line)
+		}
+		_, isSummary := obj["summary"]
+		if last := i == len(lines)-1; isSummary != last {
+			t.Errorf("line %d: summary=%v, want summary only on the last line", i+1, isSummary)
+		}
+	}
+}
+
 func TestRunScan_JSONOutputToFile(t *testing.T) {
 	dir := t.TempDir()
 	outFile := dir + "/report.json"
 	opts := &scanOptions{format: "json", severity: "all", output: outFile}
 	// Scanning an empty directory -- no manifest will be found, but should not panic.
-	_ = runScan(dir, opts)
+	_ = runScan([]string{dir}, opts)
 
 	data, err := os.ReadFile(outFile)
 	if err != nil {
@@ -102,7 +201,7 @@ func TestRunScan_TerminalOutputToFile(t *testing.T) {
 	dir := t.TempDir()
 	outFile := dir + "/report.txt"
 	opts := &scanOptions{format: "terminal", severity: "all", output: outFile}
-	_ = runScan(dir, opts)
+	_ = runScan([]string{dir}, opts)
 
 	data, err := os.ReadFile(outFile)
 	if err != nil {
@@ -113,9 +212,131 @@ func TestRunScan_TerminalOutputToFile(t *testing.T) {
 	}
 }
 
+func TestRunScan_MultiplePaths(t *testing.T) {
+	root := t.TempDir()
+	for _, mod := range []string{"app", "feature"} {
+		src := filepath.Join(root, mod, "src")
+		if err := os.MkdirAll(src, 0755); err != nil {
+			t.Fatal(err)
+		}
+		code := "class Api { String url = \"http://insecure.example.com\"; }"
+		if err := os.WriteFile(filepath.Join(src, "Api.java"), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outFile := filepath.Join(root, "report.json")
+	opts := &scanOptions{format: "json", severity: "all", output: outFile}
+
+	appDir := filepath.Join(root, "app")
+	featureDir := filepath.Join(root, "feature")
+	err := runScan([]string{appDir, featureDir}, opts)
+	if err == nil {
+		t.Error("expected critical issues error for modules with HTTP URLs")
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("expected output file to be created: %v", err)
+	}
+	var report preflight.JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+
+	locations := map[string]bool{}
+	for _, f := range report.Findings {
+		if f.CheckID == "CS001" {
+			locations[f.Location] = true
+		}
+	}
+	for _, want := range []string{
+		filepath.Join(appDir, "src", "Api.java") + ":1",
+		filepath.Join(featureDir, "src", "Api.java") + ":1",
+	} {
+		if !locations[want] {
+			t.Errorf("expected CS001 finding at %s, got %v", want, locations)
+		}
+	}
+}
+
+func TestRunScan_OnlyManifestScanner(t *testing.T) {
+	appDir := filepath.Join("..", "..", "testdata", "sample-apps", "violating-app")
+	outFile := filepath.Join(t.TempDir(), "report.json")
+	opts := &scanOptions{format: "json", severity: "all", output: outFile, scanners: []string{"manifest"}}
+	_ = runScan([]string{appDir}, opts)
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("expected output file to be created: %v", err)
+	}
+	var report preflight.JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if len(report.Findings) == 0 {
+		t.Fatal("expected manifest findings for violating app")
+	}
+	for _, f := range report.Findings {
+		if strings.HasPrefix(f.CheckID, "CS") {
+			t.Errorf("unexpected code scan finding %s with only the manifest scanner", f.CheckID)
+		}
+	}
+	if report.Summary.TotalChecks != 1 {
+		t.Errorf("expected 1 check, got %d", report.Summary.TotalChecks)
+	}
+}
+
+func TestSelectScanners(t *testing.T) {
+	tests := []struct {
+		name    string
+		only    []string
+		skip    []string
+		want    []string
+		wantErr string
+	}{
+		{"default", nil, nil, []string{"manifest", "code-scan", "DATA_SAFETY"}, ""},
+		{"only", []string{"manifest", "DATA_SAFETY"}, nil, []string{"manifest", "DATA_SAFETY"}, ""},
+		{"skip", nil, []string{"code-scan"}, []string{"manifest", "DATA_SAFETY"}, ""},
+		{"unknown", []string{"codescan"}, nil, nil, "valid: manifest, code-scan, DATA_SAFETY"},
+		{"unknown skip", nil, []string{"lint"}, nil, "unknown scanner \"lint\""},
+		{"nothing left", []string{"manifest"}, []string{"manifest"}, nil, "no scanners"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectScanners(tt.only, tt.skip)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectScanners() error: %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPaths(t *testing.T) {
+	paths, err := readPaths(strings.NewReader("app\n\n  feature  \n"))
+	if err != nil {
+		t.Fatalf("readPaths() error: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "app" || paths[1] != "feature" {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+
+	if _, err := readPaths(strings.NewReader("\n")); err == nil {
+		t.Error("expected error for empty input")
+	}
+}
+
 func TestNewScanCmd(t *testing.T) {
 	cmd := NewScanCmd()
-	if cmd.Use != "scan [project-path]" {
+	if cmd.Use != "scan [project-path...]" {
 		t.Errorf("unexpected Use: %s", cmd.Use)
 	}
 
@@ -130,6 +351,27 @@ func TestNewScanCmd(t *testing.T) {
 	}
 }
 
+func TestNewRootCmd_Version(t *testing.T) {
+	db, err := policies.Load()
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	cmd := NewRootCmd()
+	cmd.Version = "1.2.3 (commit: abc, built: today)"
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--version"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	want := "playcheck version 1.2.3 (commit: abc, built: today)\npolicy database version " + db.Version + "\n"
+	if out.String() != want {
+		t.Errorf("version output = %q, want %q", out.String(), want)
+	}
+}
+
 func TestNewRootCmd(t *testing.T) {
 	cmd := NewRootCmd()
 	if cmd.Use != "playcheck" {
@@ -147,3 +389,323 @@ func TestNewRootCmd(t *testing.T) {
 		t.Error("expected 'scan' subcommand")
 	}
 }
+
+func TestConfigureColor(t *testing.T) {
+	orig := color.NoColor
+	t.Cleanup(func() { color.NoColor = orig })
+
+	result := &preflight.ScanResult{
+		Findings: []preflight.Finding{
+			{CheckID: "CS001", Title: "HTTP URL", Severity: preflight.SeverityError},
+		},
+	}
+
+	color.NoColor = false
+	if err := configureColor(true, false); err != nil {
+		t.Fatalf("configureColor() error: %v", err)
+	}
+	out := preflight.NewReport(result, preflight.SeverityInfo).RenderTerminal()
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("expected no ANSI escape sequences with --no-color, got %q", out)
+	}
+
+	if err := configureColor(false, true); err != nil {
+		t.Fatalf("configureColor() error: %v", err)
+	}
+	out = preflight.NewReport(result, preflight.SeverityInfo).RenderTerminal()
+	if !strings.Contains(out, "\x1b[") {
+		t.Error("expected ANSI escape sequences with --force-color")
+	}
+
+	if err := configureColor(true, true); err == nil {
+		t.Error("expected error when both --no-color and --force-color are set")
+	}
+}
+
+func TestRunScan_TerminalOutputToFileHasNoColor(t *testing.T) {
+	orig := color.NoColor
+	t.Cleanup(func() { color.NoColor = orig })
+	color.NoColor = false
+
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "report.txt")
+	opts := &scanOptions{format: "terminal", severity: "all", output: outFile}
+	_ = runScan([]string{dir}, opts)
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("expected output file to be created: %v", err)
+	}
+	if strings.Contains(string(data), "\x1b[") {
+		t.Error("expected no ANSI escape sequences in report file")
+	}
+}
+
+// captureStdout returns what fn writes to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+func TestRunScan_QuietCleanScan(t *testing.T) {
+	appDir := filepath.Join("..", "..", "testdata", "sample-apps", "clean-app")
+	var err error
+	out := captureStdout(t, func() {
+		err = runScan([]string{appDir}, &scanOptions{format: "terminal", severity: "critical", quiet: true})
+	})
+	if err != nil {
+		t.Fatalf("expected exit 0 for a clean scan, got %v", err)
+	}
+	if out != "" {
+		t.Errorf("expected no output under --quiet, got:\n%s", out)
+	}
+}
+
+func TestRunScan_QuietCleanScanNDJSONFile(t *testing.T) {
+	appDir := filepath.Join("..", "..", "testdata", "sample-apps", "clean-app")
+	outFile := filepath.Join(t.TempDir(), "report.ndjson")
+	if err := runScan([]string{appDir}, &scanOptions{format: "ndjson", severity: "critical", output: outFile, quiet: true}); err != nil {
+		t.Fatalf("expected exit 0 for a clean scan, got %v", err)
+	}
+	if _, err := os.Stat(outFile); !os.IsNotExist(err) {
+		t.Errorf("expected no output file under --quiet, stat error: %v", err)
+	}
+}
+
+func TestRunScan_QuietBelowFailOn(t *testing.T) {
+	appDir := filepath.Join("..", "..", "testdata", "sample-apps", "clean-app")
+	for _, format := range []string{"terminal", "ndjson"} {
+		var err error
+		out := captureStdout(t, func() {
+			err = runScan([]string{appDir}, &scanOptions{format: format, severity: "all", quiet: true})
+		})
+		if err != nil {
+			t.Fatalf("%s: expected exit 0 when no finding meets --fail-on, got %v", format, err)
+		}
+		if out != "" {
+			t.Errorf("%s: expected no output under --quiet, got:\n%s", format, out)
+		}
+	}
+}
+
+func TestRunScan_QuietNDJSONWithFindings(t *testing.T) {
+	appDir := filepath.Join("..", "..", "testdata", "sample-apps", "clean-app")
+	out := captureStdout(t, func() {
+		_ = runScan([]string{appDir}, &scanOptions{format: "ndjson", severity: "all", failOn: "warn", quiet: true})
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 || !strings.Contains(lines[len(lines)-1], `"summary"`) {
+		t.Errorf("expected findings followed by a summary line, got:\n%s", out)
+	}
+}
+
+func TestRunScan_QuietWithFindings(t *testing.T) {
+	appDir := filepath.Join("..", "..", "testdata", "sample-apps", "violating-app")
+	out := captureStdout(t, func() {
+		_ = runScan([]string{appDir}, &scanOptions{format: "terminal", severity: "all", quiet: true})
+	})
+	if !strings.Contains(out, "RESULT: FAIL") {
+		t.Errorf("expected the normal report when findings exist, got:\n%s", out)
+	}
+}
+
+func TestRunScan_AppBundle(t *testing.T) {
+	bundlePath := filepath.Join("..", "..", "testdata", "sample-apps", "bundle-app.aab")
+	outFile := filepath.Join(t.TempDir(), "report.json")
+	err := runScan([]string{bundlePath}, &scanOptions{format: "json", severity: "all", output: outFile})
+	if code := ExitCode(err); code != ExitOK && code != ExitFindings {
+		t.Fatalf("expected the bundle to be scanned, got exit %d (%v)", code, err)
+	}
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("expected output file to be created: %v", err)
+	}
+	var report preflight.JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if len(report.Findings) == 0 {
+		t.Error("expected findings from the bundle's manifest")
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "app.aab")
+	if err := os.WriteFile(bundlePath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := configDir(dir); got != dir {
+		t.Errorf("configDir(%q) = %q, want the project itself", dir, got)
+	}
+	if got := configDir(bundlePath); got != dir {
+		t.Errorf("configDir(%q) = %q, want %q", bundlePath, got, dir)
+	}
+}
+
+func TestRunScan_IDsFormat(t *testing.T) {
+	appDir := filepath.Join("..", "..", "testdata", "sample-apps", "violating-app")
+	out := captureStdout(t, func() {
+		_ = runScan([]string{appDir}, &scanOptions{format: "ids", severity: "all"})
+	})
+
+	jsonFile := filepath.Join(t.TempDir(), "report.json")
+	_ = runScan([]string{appDir}, &scanOptions{format: "json", severity: "all", output: jsonFile})
+	data, err := os.ReadFile(jsonFile)
+	if err != nil {
+		t.Fatalf("expected output file to be created: %v", err)
+	}
+	var report preflight.JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	var want []string
+	for _, f := range report.Findings {
+		want = append(want, f.CheckID)
+	}
+	slices.Sort(want)
+	want = slices.Compact(want)
+
+	got := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(got) < 2 {
+		t.Fatalf("expected several rule IDs for the violating app, got:\n%s", out)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got IDs %v, want %v", got, want)
+	}
+}
+
+func TestRunScan_NegativeTimeout(t *testing.T) {
+	err := runScan([]string{t.TempDir()}, &scanOptions{format: "terminal", severity: "all", timeout: -time.Second})
+	if ExitCode(err) != ExitUsage {
+		t.Errorf("expected a usage error for a negative timeout, got %v", err)
+	}
+}
+
+func TestScanTimeoutError(t *testing.T) {
+	partial := &preflight.ScanResult{ScanMeta: preflight.ScanMetadata{Unfinished: []string{"code-scan"}}}
+
+	err := scanTimeoutError(time.Second, []string{"app"}, []*preflight.ScanResult{partial})
+	if !strings.Contains(err.Error(), "timed out after 1s before code-scan finished") {
+		t.Errorf("unexpected message: %v", err)
+	}
+	if ExitCode(err) != ExitError {
+		t.Errorf("expected exit code %d, got %d", ExitError, ExitCode(err))
+	}
+
+	done := &preflight.ScanResult{}
+	err = scanTimeoutError(time.Second, []string{"app", "lib/", "feature"}, []*preflight.ScanResult{done, partial})
+	if !strings.Contains(err.Error(), "before lib:code-scan, feature (not scanned) finished") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestParseFailOn(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    preflight.Severity
+		wantErr bool
+	}{
+		{"", preflight.SeverityError, false},
+		{"error", preflight.SeverityError, false},
+		{"critical", preflight.SeverityCritical, false},
+		{"warn", preflight.SeverityWarning, false},
+		{"info", preflight.SeverityInfo, false},
+		{"all", 0, true},
+	}
+	for _, tc := range tests {
+		got, err := parseFailOn(tc.input)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseFailOn(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && got != tc.want {
+			t.Errorf("parseFailOn(%q) = %v, want %v", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestRunScan_FailOnIndependentOfSeverity(t *testing.T) {
+	cleanApp := filepath.Join("..", "..", "testdata", "sample-apps", "clean-app")
+	violatingApp := filepath.Join("..", "..", "testdata", "sample-apps", "violating-app")
+	tests := []struct {
+		name     string
+		dir      string
+		severity string
+		failOn   string
+		want     int
+	}{
+		{"warnings hidden but failing", cleanApp, "critical", "warn", ExitFindings},
+		{"warnings shown but not failing", cleanApp, "all", "error", ExitOK},
+		{"everything shown, fail on critical", violatingApp, "all", "critical", ExitFindings},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			captureStdout(t, func() {
+				err = runScan([]string{tc.dir}, &scanOptions{format: "oneline", severity: tc.severity, failOn: tc.failOn, quiet: true})
+			})
+			if got := ExitCode(err); got != tc.want {
+				t.Errorf("expected exit %d, got %d (%v)", tc.want, got, err)
+			}
+		})
+	}
+}
+
+func TestRunScan_TerminalResultFollowsFailOn(t *testing.T) {
+	appDir := filepath.Join("..", "..", "testdata", "sample-apps", "clean-app")
+	var err error
+	out := captureStdout(t, func() {
+		err = runScan([]string{appDir}, &scanOptions{format: "terminal", severity: "all", failOn: "warn"})
+	})
+	if got := ExitCode(err); got != ExitFindings {
+		t.Fatalf("expected exit %d, got %d (%v)", ExitFindings, got, err)
+	}
+	if !strings.Contains(out, "RESULT: FAIL") {
+		t.Errorf("expected RESULT: FAIL when the scan exits with findings, got:\n%s", out)
+	}
+}
+
+func TestRunScan_IncludeAllInJSON(t *testing.T) {
+	appDir := filepath.Join("..", "..", "testdata", "sample-apps", "clean-app")
+	counts := make(map[bool]int)
+	for _, all := range []bool{false, true} {
+		jsonFile := filepath.Join(t.TempDir(), "report.json")
+		_ = runScan([]string{appDir}, &scanOptions{format: "json", severity: "critical", output: jsonFile, includeAllInJSON: all})
+		data, err := os.ReadFile(jsonFile)
+		if err != nil {
+			t.Fatalf("expected output file to be created: %v", err)
+		}
+		var report preflight.JSONReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Fatalf("invalid JSON report: %v", err)
+		}
+		counts[all] = len(report.Findings)
+	}
+	if counts[false] != 0 {
+		t.Errorf("expected --severity critical to filter the clean app's findings, got %d", counts[false])
+	}
+	if counts[true] == 0 {
+		t.Error("expected --include-all-in-json to keep findings below --severity")
+	}
+
+	err := runScan([]string{appDir}, &scanOptions{format: "terminal", severity: "all", includeAllInJSON: true})
+	if ExitCode(err) != ExitUsage {
+		t.Errorf("expected a usage error without --format json, got %v", err)
+	}
+}
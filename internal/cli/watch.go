@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/kotaroyamazaki/playcheck/internal/manifest"
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/playcheck"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+type watchOptions struct {
+	severity string
+	debounce time.Duration
+}
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// NewWatchCmd creates the watch subcommand.
+func NewWatchCmd() *cobra.Command {
+	opts := &watchOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "watch [project-path]",
+		Short: "Re-scan an Android project whenever source files change",
+		Long:  "Runs a scan, then watches .kt, .java, .xml, and Gradle files and re-runs the affected scanners on every change. Press Ctrl+C to stop.",
+		Args:  usageArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			return runWatch(ctx, args[0], opts, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.severity, "severity", "s", "all", "Minimum severity to display: all, critical, warn, info")
+	cmd.Flags().DurationVar(&opts.debounce, "debounce", 300*time.Millisecond, "Wait this long after the last change before re-scanning")
+
+	return cmd
+}
+
+func runWatch(ctx context.Context, projectPath string, opts *watchOptions, out io.Writer) error {
+	absPath, err := resolveProjectDir(projectPath)
+	if err != nil {
+		return usageError(err)
+	}
+	if manifest.IsBundlePath(absPath) {
+		return usageError(fmt.Errorf("watch needs a project directory, not a bundle: %s", absPath))
+	}
+
+	minSeverity, err := parseSeverityFilter(opts.severity)
+	if err != nil {
+		return usageError(err)
+	}
+
+	// A re-scan only runs the affected scanners, so the last result of every
+	// scanner is kept to render the full report.
+	last := make(map[string]*preflight.CheckResult)
+	scan := func(only map[string]bool) {
+		if only != nil && len(only) == 0 {
+			return
+		}
+		var opts playcheck.Options
+		for id := range only {
+			opts.Scanners = append(opts.Scanners, id)
+		}
+		partial, err := playcheck.Scan(absPath, opts)
+		if err != nil {
+			fmt.Fprint(out, clearScreen)
+			fmt.Fprintf(out, "Scan failed: %v\n", err)
+			return
+		}
+		var results []*preflight.CheckResult
+		for _, id := range playcheck.ScannerIDs() {
+			if cr, ok := partial.ByScanner[id]; ok {
+				last[id] = cr
+			}
+			if cr, ok := last[id]; ok {
+				results = append(results, cr)
+			}
+		}
+		result := preflight.NewScanResult(absPath, results)
+		result.ScanMeta.StartTime = partial.ScanMeta.StartTime
+		result.ScanMeta.EndTime = partial.ScanMeta.EndTime
+		result.ScanMeta.Duration = partial.ScanMeta.Duration
+		report := preflight.NewReport(result, minSeverity)
+		fmt.Fprint(out, clearScreen)
+		fmt.Fprint(out, report.RenderTerminal())
+		fmt.Fprintf(out, "\nWatching %s for changes (Ctrl+C to stop)...\n", absPath)
+	}
+
+	scan(nil)
+	err = watchProject(ctx, absPath, opts.debounce, func(changed []string) {
+		scan(relevantScanners(changed))
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "Stopped watching.")
+	return nil
+}
+
+// watchedExtensions are the file types that trigger a re-scan.
+var watchedExtensions = map[string]bool{
+	".kt":     true,
+	".java":   true,
+	".xml":    true,
+	".gradle": true,
+	".kts":    true,
+	".pro":    true,
+}
+
+// relevantScanners returns the IDs of the scanners affected by the changed
+// files. Manifest and resource changes can affect every scanner, while source
+// and build file changes only affect the scanners that read them.
+func relevantScanners(changed []string) map[string]bool {
+	ids := make(map[string]bool)
+	for _, path := range changed {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".kt", ".java":
+			ids[playcheck.ScannerCode] = true
+			ids[playcheck.ScannerDataSafety] = true
+		case ".gradle", ".kts":
+			ids[playcheck.ScannerCode] = true // target SDK may be declared in Gradle
+			ids[playcheck.ScannerDataSafety] = true
+		case ".pro":
+			ids[playcheck.ScannerCode] = true
+		case ".xml":
+			ids[playcheck.ScannerManifest] = true
+			ids[playcheck.ScannerCode] = true
+			ids[playcheck.ScannerDataSafety] = true
+		}
+	}
+	return ids
+}
+
+// watchProject watches root recursively, skipping utils.DefaultSkipDirs, and
+// calls onChange with the sorted list of changed files once no further event
+// arrives for the debounce interval. It blocks until ctx is cancelled.
+func watchProject(ctx context.Context, root string, debounce time.Duration, onChange func(changed []string)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+	defer w.Close()
+
+	if err := addWatchDirs(w, root); err != nil {
+		return err
+	}
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					if !utils.DefaultSkipDirs[info.Name()] {
+						_ = addWatchDirs(w, ev.Name)
+					}
+					continue
+				}
+			}
+			if ev.Has(fsnotify.Chmod) || !watchedExtensions[strings.ToLower(filepath.Ext(ev.Name))] {
+				continue
+			}
+			pending[ev.Name] = true
+			timer.Reset(debounce)
+
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Warning: file watcher error: %v\n", err)
+
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			changed := make([]string, 0, len(pending))
+			for p := range pending {
+				changed = append(changed, p)
+			}
+			sort.Strings(changed)
+			pending = make(map[string]bool)
+			onChange(changed)
+		}
+	}
+}
+
+// addWatchDirs adds dir and all its subdirectories to the watcher, skipping
+// build output and VCS directories below dir. Callers check dir itself.
+func addWatchDirs(w *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip entries with errors
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && utils.DefaultSkipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		if err := w.Add(path); err != nil {
+			return fmt.Errorf("watching %s: %w", path, err)
+		}
+		return nil
+	})
+}
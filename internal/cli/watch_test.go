@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchProject_FileChangeTriggersRescan(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"src", "build"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan []string, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- watchProject(ctx, root, 50*time.Millisecond, func(changed []string) {
+			changes <- changed
+		})
+	}()
+
+	// Give the watcher time to register directories.
+	time.Sleep(100 * time.Millisecond)
+
+	// Build output and unrelated file types must not trigger a rescan.
+	if err := os.WriteFile(filepath.Join(root, "build", "Gen.java"), []byte("class Gen {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "notes.txt"), []byte("todo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(root, "src", "Main.kt")
+	if err := os.WriteFile(src, []byte("class Main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case changed := <-changes:
+		if len(changed) != 1 || changed[0] != src {
+			t.Errorf("expected only %s to be reported, got %v", src, changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for rescan callback")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("watchProject() error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchProject did not stop after cancellation")
+	}
+}
+
+func TestWatchProject_NewSkippedDirNotWatched(t *testing.T) {
+	root := t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan []string, 1)
+	go func() {
+		_ = watchProject(ctx, root, 50*time.Millisecond, func(changed []string) {
+			changes <- changed
+		})
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// A build directory created while watching must stay unwatched.
+	if err := os.MkdirAll(filepath.Join(root, "build"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(root, "build", "Gen.java"), []byte("class Gen {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(root, "Main.kt")
+	if err := os.WriteFile(src, []byte("class Main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case changed := <-changes:
+		if len(changed) != 1 || changed[0] != src {
+			t.Errorf("expected only %s to be reported, got %v", src, changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for rescan callback")
+	}
+}
+
+func TestRelevantScanners(t *testing.T) {
+	ids := relevantScanners([]string{"src/Main.kt"})
+	if ids["manifest"] {
+		t.Error("Kotlin change should not re-run the manifest scanner")
+	}
+	if !ids["code-scan"] || !ids["DATA_SAFETY"] {
+		t.Errorf("Kotlin change should re-run code-scan and DATA_SAFETY, got %v", ids)
+	}
+
+	ids = relevantScanners([]string{"app/proguard-rules.pro"})
+	if !ids["code-scan"] || ids["manifest"] || ids["DATA_SAFETY"] {
+		t.Errorf("ProGuard rules change should only re-run code-scan, got %v", ids)
+	}
+
+	ids = relevantScanners([]string{"app/src/main/AndroidManifest.xml"})
+	if !ids["manifest"] {
+		t.Error("manifest change should re-run the manifest scanner")
+	}
+}
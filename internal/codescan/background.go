@@ -0,0 +1,41 @@
+package codescan
+
+import (
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+var (
+	// backgroundComponentRe matches the declaration of a class extending a
+	// BroadcastReceiver or a Service subclass, in Kotlin or Java. In Kotlin
+	// the supertype list must follow the class name or its primary
+	// constructor, so a constructor parameter typed as a service, as in
+	// `class Repo(private val api: UserService)`, does not match.
+	backgroundComponentRe = regexp.MustCompile(`\bclass\s+\w+(?:<[^>]*>)?\s*(?:[\w@\s]*\bconstructor\s*)?(?:\((?:[^()]|\([^()]*\))*\))?\s*:\s*(?:[\w.<>]+(?:\([^()]*\))?\s*,\s*)*(?:[\w.]+\.)?(?:BroadcastReceiver|\w*Service)\b|\bclass\s+\w+(?:<[^>]*>)?\s+extends\s+(?:[\w.]+\.)?(?:BroadcastReceiver|\w*Service)\b`)
+
+	// newTaskFlagRe matches the flag required to start an activity from a
+	// non-activity context.
+	newTaskFlagRe = regexp.MustCompile(`\bFLAG_ACTIVITY_NEW_TASK\b`)
+
+	// startActivityRe matches Context.startActivity calls.
+	startActivityRe = regexp.MustCompile(`\bstartActivity\s*\(`)
+)
+
+// backgroundActivityLaunch builds the finding for a startActivity call in a
+// file that declares a receiver or service and sets FLAG_ACTIVITY_NEW_TASK.
+// The enclosing component is not resolved, so this is a per-file heuristic
+// like the foreground service check.
+func backgroundActivityLaunch(relPath string, line int, snippet string) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleBackgroundLaunch,
+		Title:       "Activity started from the background",
+		Description: "An activity appears to be started with FLAG_ACTIVITY_NEW_TASK from a BroadcastReceiver or Service. Since Android 10 the system blocks activity launches from the background, and Play policy does not allow working around this, e.g. through SYSTEM_ALERT_WINDOW overlays.\n  Code: " + snippet,
+		Severity:    preflight.SeverityWarning,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Post a notification the user can tap instead, or a full-screen intent notification for time-sensitive events such as incoming calls and alarms. Ignore this finding if the call runs while the app is in the foreground.",
+	}
+}
@@ -0,0 +1,24 @@
+package codescan
+
+import "github.com/kotaroyamazaki/playcheck/internal/preflight"
+
+const (
+	permFineLocation   = "android.permission.ACCESS_FINE_LOCATION"
+	permCoarseLocation = "android.permission.ACCESS_COARSE_LOCATION"
+)
+
+// escalateCellInfo raises cell tower and network operator findings to errors
+// when the manifest declares no location permission. The app then collects
+// approximate location without ever asking for it, which Play reviews as
+// undisclosed location collection.
+func escalateCellInfo(findings []preflight.Finding) {
+	for i := range findings {
+		f := &findings[i]
+		if f.CheckID != RuleCellInfo {
+			continue
+		}
+		f.Severity = preflight.SeverityError
+		f.Description += "\n  No location permission is declared, so this approximate location data is collected without the user's consent. getCellLocation and getAllCellInfo also return no data without ACCESS_FINE_LOCATION."
+		f.Suggestion = "Declare ACCESS_COARSE_LOCATION or ACCESS_FINE_LOCATION and request it at runtime with a prominent disclosure, and declare 'Approximate location' in your Data Safety form. Otherwise remove the call."
+	}
+}
@@ -0,0 +1,67 @@
+package codescan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// WithContextLines captures n source lines before and after each match in
+// Finding.Context. Zero, the default, captures none.
+func WithContextLines(n int) Option {
+	return func(s *Scanner) {
+		s.contextLines = max(n, 0)
+	}
+}
+
+// contextCollector gathers the source lines around the findings of one file.
+// Only the last n+1 lines are kept; findings still waiting for lines after
+// their match are tracked until n more lines have been read.
+type contextCollector struct {
+	n       int
+	recent  []string // ring of the last n+1 lines, ending with the current one
+	pending []pendingContext
+}
+
+type pendingContext struct {
+	findings *[]preflight.Finding
+	i, left  int
+}
+
+// next records line lineNum, appending it to findings still collecting
+// trailing context.
+func (c *contextCollector) next(lineNum int, line string) {
+	if c.n == 0 {
+		return
+	}
+	cl := fmt.Sprintf("%d: %s", lineNum, snippetOf(strings.TrimRight(line, " \t\r")))
+
+	kept := c.pending[:0]
+	for _, p := range c.pending {
+		f := &(*p.findings)[p.i]
+		f.Context = append(f.Context, cl)
+		if p.left--; p.left > 0 {
+			kept = append(kept, p)
+		}
+	}
+	c.pending = kept
+
+	if len(c.recent) > c.n {
+		c.recent = append(c.recent[:0], c.recent[1:]...)
+	}
+	c.recent = append(c.recent, cl)
+}
+
+// attach gives the findings appended to *ff from index from onwards the
+// lines before the current one, and collects the lines after it.
+func (c *contextCollector) attach(ff *[]preflight.Finding, from int) {
+	if c.n == 0 {
+		return
+	}
+	for i := from; i < len(*ff); i++ {
+		(*ff)[i].Context = slices.Clone(c.recent[:len(c.recent)-1])
+		c.pending = append(c.pending, pendingContext{findings: ff, i: i, left: c.n})
+	}
+}
@@ -0,0 +1,62 @@
+package codescan
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// resourceRules are the code scanning rules checked in XML resources.
+var resourceRules = map[string]bool{RuleHTTPUsage: true, RuleTestAdUnit: true, RuleTapjacking: true}
+
+// ruleCoverage reports which code scanning rules could fire on files. Source
+// rules need Kotlin or Java files, HTTP URLs and AdMob test IDs are also
+// found in XML resources, tapjacking needs a layout resource, lint overlaps need a lint configuration or
+// baseline, and shrinker rules need a ProGuard rules file. Secure screen
+// rules only run with the finance and health presets.
+func ruleCoverage(files []string, preset preflight.Preset) *preflight.RuleCoverage {
+	var ids []string
+	for _, r := range Rules() {
+		ids = append(ids, r.ID)
+	}
+	coverage := preflight.NewRuleCoverage(ids...)
+
+	var sources, resources, layouts, lint, proguard bool
+	for _, f := range files {
+		switch {
+		case isLintFile(f):
+			lint = true
+		case isProguardFile(f):
+			proguard = true
+		case strings.EqualFold(filepath.Ext(f), ".xml"):
+			resources = true
+			layouts = layouts || isLayoutFile(f)
+		default:
+			sources = true
+		}
+	}
+
+	if !checksSecureScreens(preset) {
+		coverage.Exclude("preset is not finance or health", RuleFlagSecure, RuleTapjacking)
+	}
+	if !lint {
+		coverage.Exclude("no lint.xml or lint-baseline.xml found", RuleLintOverlap)
+	}
+	if !proguard {
+		coverage.Exclude("no ProGuard rules files found", RuleShrinkerConfig)
+	}
+	if !layouts {
+		coverage.Exclude("no layout resources found", RuleTapjacking)
+	}
+	if !sources {
+		var sourceOnly []string
+		for _, id := range ids {
+			if id != RuleLintOverlap && id != RuleShrinkerConfig && (!resourceRules[id] || !resources) {
+				sourceOnly = append(sourceOnly, id)
+			}
+		}
+		coverage.Exclude("no Kotlin or Java sources found", sourceOnly...)
+	}
+	return coverage
+}
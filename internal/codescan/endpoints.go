@@ -0,0 +1,78 @@
+package codescan
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// WithEndpointAllowlist excludes URLs whose host is one of domains, or a
+// subdomain of one, from the development endpoint check.
+func WithEndpointAllowlist(domains ...string) Option {
+	return func(s *Scanner) {
+		s.endpointAllowlist = domains
+	}
+}
+
+// urlHostRe captures the host of an http(s) URL.
+var urlHostRe = regexp.MustCompile(`\bhttps?://([A-Za-z0-9.-]+)`)
+
+// devEndpointHost returns the first host in line that points at a local,
+// emulator, staging, or development server and is not allowlisted.
+func devEndpointHost(line string, allowlist []string) (string, bool) {
+	for _, m := range urlHostRe.FindAllStringSubmatch(line, -1) {
+		host := strings.ToLower(strings.TrimSuffix(m[1], "."))
+		if isDevHost(host) && !allowlisted(host, allowlist) {
+			return host, true
+		}
+	}
+	return "", false
+}
+
+// isDevHost reports whether host is localhost, the Android emulator's alias
+// for the development machine, a .local mDNS name, or has a "staging" or
+// "dev" label below the top-level domain.
+func isDevHost(host string) bool {
+	switch host {
+	case "localhost", "127.0.0.1", "10.0.2.2":
+		return true
+	}
+	if strings.HasSuffix(host, ".local") {
+		return true
+	}
+	labels := strings.Split(host, ".")
+	for _, l := range labels[:len(labels)-1] {
+		if l == "staging" || l == "dev" {
+			return true
+		}
+	}
+	return false
+}
+
+// allowlisted reports whether host equals or is a subdomain of a domain in
+// allowlist.
+func allowlisted(host string, allowlist []string) bool {
+	for _, d := range allowlist {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// devEndpointFinding builds the finding for a development endpoint URL.
+func devEndpointFinding(host, relPath string, line int, snippet string) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleDevEndpoint,
+		Title:       "Development endpoint in code: " + host,
+		Description: "The code references " + host + ", a local, emulator, staging, or development server. If this ships in a release build, the app talks to a server users cannot reach or one that is not meant for production data.\n  Code: " + snippet,
+		Severity:    preflight.SeverityWarning,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Move environment-specific URLs into build types or product flavors (buildConfigField or resource overrides) so release builds only contain production endpoints. Add intentional hosts to endpoint_allowlist in the config file.",
+	}
+}
@@ -0,0 +1,25 @@
+package codescan
+
+import "github.com/kotaroyamazaki/playcheck/internal/preflight"
+
+// familiesAdRules are the ads SDK rules escalated for apps in the Designed
+// for Families program.
+var familiesAdRules = map[string]bool{
+	RuleAdMob:       true,
+	RuleFacebookSDK: true,
+}
+
+// escalateFamiliesAds raises ads SDK findings to critical, since family apps
+// may only show ads through Families self-certified SDKs configured for
+// child-directed treatment.
+func escalateFamiliesAds(findings []preflight.Finding) {
+	for i := range findings {
+		f := &findings[i]
+		if !familiesAdRules[f.CheckID] {
+			continue
+		}
+		f.Severity = preflight.SeverityCritical
+		f.Description += "\n  Apps in the Designed for Families program may only use ads SDKs that are self-certified for Families, and ads must be appropriate for children."
+		f.Suggestion = "Use a Families self-certified ads SDK and configure it for child-directed treatment (for AdMob, setTagForChildDirectedTreatment and setMaxAdContentRating), or remove ads."
+	}
+}
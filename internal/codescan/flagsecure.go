@@ -0,0 +1,57 @@
+package codescan
+
+import (
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// WithPreset enables the checks that only apply to a type of app, e.g. the
+// FLAG_SECURE and tapjacking checks for finance and health apps.
+func WithPreset(p preflight.Preset) Option {
+	return func(s *Scanner) {
+		s.preset = p
+	}
+}
+
+// checksSecureScreens reports whether the preset expects screens showing
+// sensitive data to block screenshots and overlays. Other apps are not checked, since
+// most screens with these names show nothing sensitive.
+func checksSecureScreens(p preflight.Preset) bool {
+	return p == preflight.PresetFinance || p == preflight.PresetHealth
+}
+
+var (
+	// activityClassRe matches the declaration of a class extending an
+	// Activity, in Kotlin or Java, capturing the class name.
+	activityClassRe = regexp.MustCompile(`\bclass\s+(\w+)[^{]*(?:\bextends\s+|:\s*)(?:[\w.]+\.)?\w*Activity\b`)
+
+	// sensitiveScreenNameRe matches class names of screens that show payment
+	// or health data.
+	sensitiveScreenNameRe = regexp.MustCompile(`(?i)payment|card|health`)
+
+	// sensitiveLayoutRe matches references to payment or health layouts,
+	// through R.layout or a generated view binding class.
+	sensitiveLayoutRe = regexp.MustCompile(`(?i)\bR\.layout\.\w*(?:payment|card|health)|\b\w*(?:payment|card|health)\w*Binding\b`)
+
+	// flagSecureRe matches the window flag that blocks screenshots and
+	// screen recording.
+	flagSecureRe = regexp.MustCompile(`\bFLAG_SECURE\b`)
+)
+
+// insecureSensitiveScreen builds the finding for an activity showing payment
+// or health data in a file that never sets FLAG_SECURE. The window flag may
+// be set elsewhere, e.g. in a base class, so this is a per-file heuristic.
+func insecureSensitiveScreen(class, relPath string, line int, snippet string) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleFlagSecure,
+		Title:       "Sensitive screen without FLAG_SECURE",
+		Description: "Activity " + class + " appears to show payment or health data but does not set WindowManager.LayoutParams.FLAG_SECURE. Its content can be captured in screenshots, screen recordings, and the recent apps overview.\n  Code: " + snippet,
+		Severity:    preflight.SeverityWarning,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Call window.setFlags(WindowManager.LayoutParams.FLAG_SECURE, WindowManager.LayoutParams.FLAG_SECURE) in onCreate before setContentView, or set it in a shared base activity for sensitive screens.",
+	}
+}
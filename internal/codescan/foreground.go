@@ -0,0 +1,33 @@
+package codescan
+
+import (
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+var (
+	// startForegroundRe matches Service.startForeground and the ServiceCompat
+	// variant.
+	startForegroundRe = regexp.MustCompile(`\bstartForeground\s*\(`)
+
+	// notificationBuildRe matches code that constructs a notification.
+	notificationBuildRe = regexp.MustCompile(`\b(?:NotificationCompat\.Builder|Notification\.Builder|Notification)\s*\(`)
+)
+
+// foregroundWithoutNotification builds the finding for a startForeground call
+// in a file that never constructs a notification. The check is per file and
+// approximate: the notification may be built in a helper elsewhere.
+func foregroundWithoutNotification(relPath string, line int, snippet string) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleForegroundService,
+		Title:       "startForeground called without building a notification",
+		Description: "startForeground requires a valid notification. No Notification or NotificationCompat.Builder is constructed in this file, so the service may post an empty notification, which crashes with RemoteServiceException on modern Android.\n  Code: " + snippet,
+		Severity:    preflight.SeverityWarning,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Build the notification with NotificationCompat.Builder, including a small icon and a channel ID on Android 8.0+, and pass it to startForeground. Ignore this finding if the notification is built in another class.",
+	}
+}
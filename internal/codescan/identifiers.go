@@ -0,0 +1,83 @@
+package codescan
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// deviceIdentifier describes an API that returns a non-resettable device
+// identifier.
+type deviceIdentifier struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// BlockedAt is the first targetSdk at which third-party apps can no longer
+	// read the identifier (the call throws or returns a placeholder). Zero
+	// means the identifier is still readable.
+	BlockedAt int
+	Blocked   string // what happens from BlockedAt on
+}
+
+// deviceIdentifiers lists hardware and persistent device identifiers that Play's
+// User Data policy does not allow to be linked to personal data or ad IDs.
+var deviceIdentifiers = []deviceIdentifier{
+	{
+		Name:      "TelephonyManager.getDeviceId",
+		Pattern:   regexp.MustCompile(`\.getDeviceId\s*\(`),
+		BlockedAt: 29,
+		Blocked:   "throws SecurityException",
+	},
+	{
+		Name:      "TelephonyManager.getImei",
+		Pattern:   regexp.MustCompile(`\.getImei\s*\(`),
+		BlockedAt: 29,
+		Blocked:   "throws SecurityException",
+	},
+	{
+		Name:      "Build.SERIAL",
+		Pattern:   regexp.MustCompile(`\bBuild\.SERIAL\b`),
+		BlockedAt: 28,
+		Blocked:   "always returns UNKNOWN",
+	},
+	{
+		Name:      "Build.getSerial",
+		Pattern:   regexp.MustCompile(`\bBuild\.getSerial\s*\(`),
+		BlockedAt: 29,
+		Blocked:   "throws SecurityException",
+	},
+	{
+		Name:    "WifiInfo.getMacAddress",
+		Pattern: regexp.MustCompile(`\.getMacAddress\s*\(`),
+	},
+	{
+		Name:    "Settings.Secure.ANDROID_ID",
+		Pattern: regexp.MustCompile(`\bANDROID_ID\b`),
+	},
+}
+
+// deviceIdentifierFinding builds the finding for a device identifier match.
+// Usage is a warning, escalated to critical once the app's target SDK is at
+// or above the level where the identifier is blocked. targetSDK is 0 when
+// unknown.
+func deviceIdentifierFinding(id deviceIdentifier, targetSDK int, relPath string, line int, snippet string) preflight.Finding {
+	severity := preflight.SeverityWarning
+	desc := fmt.Sprintf("%s returns a non-resettable device identifier. Play's User Data policy does not allow persistent device identifiers to be linked to personal data or resettable identifiers, and collecting them must be disclosed in the Data Safety section.", id.Name)
+	if id.BlockedAt > 0 {
+		desc += fmt.Sprintf(" It %s for apps targeting API %d or higher.", id.Blocked, id.BlockedAt)
+		if targetSDK >= id.BlockedAt {
+			severity = preflight.SeverityCritical
+		}
+	}
+	return preflight.Finding{
+		CheckID:     RuleDeviceIdentifier,
+		Title:       fmt.Sprintf("Non-resettable device identifier: %s", id.Name),
+		Description: desc + "\n  Code: " + snippet,
+		Severity:    severity,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Use a resettable identifier instead: a per-install UUID, Firebase Installation ID, or the advertising ID (for ads only, respecting the user's opt-out).",
+	}
+}
@@ -0,0 +1,21 @@
+package codescan
+
+import "github.com/kotaroyamazaki/playcheck/internal/preflight"
+
+const permQueryAllPackages = "android.permission.QUERY_ALL_PACKAGES"
+
+// escalateAppInventory raises installed app inventory findings to critical
+// when the manifest declares QUERY_ALL_PACKAGES. With it, the calls see every
+// installed app instead of only those matching the <queries> element, and
+// Play only allows that for apps whose core purpose requires it.
+func escalateAppInventory(findings []preflight.Finding) {
+	for i := range findings {
+		f := &findings[i]
+		if f.CheckID != RuleAppInventory {
+			continue
+		}
+		f.Severity = preflight.SeverityCritical
+		f.Description += "\n  QUERY_ALL_PACKAGES is declared, so these calls return every installed app. Play restricts this permission to apps that must discover all installed apps for their core functionality and requires a Permissions Declaration Form."
+		f.Suggestion = "Remove QUERY_ALL_PACKAGES and declare the packages or intents the app needs in a <queries> element. Keep it only if the app's core purpose qualifies, and submit the Permissions Declaration Form."
+	}
+}
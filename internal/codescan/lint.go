@@ -0,0 +1,127 @@
+package codescan
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// Android Lint configuration files that can silence checks.
+const (
+	lintConfigFile   = "lint.xml"
+	lintBaselineFile = "lint-baseline.xml"
+)
+
+// lintIssueRules maps Android Lint issue IDs to the playcheck rules that
+// cover the same problem.
+var lintIssueRules = map[string]string{
+	"ExpiredTargetSdkVersion":      "SDK001",
+	"ExpiringTargetSdkVersion":     "SDK001",
+	"OldTargetApi":                 "SDK001",
+	"ExportedReceiver":             "MV001",
+	"ExportedService":              "MV001",
+	"ExportedContentProvider":      "MV001",
+	"IntentFilterExportedReceiver": "MV001",
+	"InsecureBaseConfiguration":    "MV004",
+	"SetJavaScriptEnabled":         RuleWebViewJS,
+	"AddJavascriptInterface":       RuleWebViewJS,
+	"GetInstance":                  RuleCryptoUsage,
+	"DeletedProvider":              RuleCryptoUsage,
+	"ScopedStorage":                "DP005",
+}
+
+// isLintFile reports whether path is an Android Lint config or baseline.
+func isLintFile(path string) bool {
+	base := filepath.Base(path)
+	return base == lintConfigFile || base == lintBaselineFile
+}
+
+// scanLintFile reports lint issues that are disabled in lint.xml, or
+// baselined in lint-baseline.xml, while playcheck still checks them, so
+// teams notice the two tools disagree.
+func scanLintFile(filePath, projectDir string) []preflight.Finding {
+	data, err := utils.ReadFileWithLimit(filePath)
+	if err != nil {
+		return nil
+	}
+	relPath, err := filepath.Rel(projectDir, filePath)
+	if err != nil {
+		relPath = filePath
+	}
+	baseline := filepath.Base(filePath) == lintBaselineFile
+
+	var findings []preflight.Finding
+	seen := make(map[string]bool)
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := decoder.InputOffset()
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return findings
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "issue" {
+			continue
+		}
+
+		var id, severity string
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "id":
+				id = attr.Value
+			case "severity":
+				severity = attr.Value
+			}
+		}
+		if !baseline && severity != "ignore" {
+			continue
+		}
+
+		var ids []string
+		if id == "all" && !baseline {
+			for lintID := range lintIssueRules {
+				ids = append(ids, lintID)
+			}
+			sort.Strings(ids)
+		} else if _, ok := lintIssueRules[id]; ok {
+			ids = []string{id}
+		}
+
+		line := bytes.Count(data[:offset], []byte("\n")) + 1
+		for _, lintID := range ids {
+			if seen[lintID] {
+				continue
+			}
+			seen[lintID] = true
+			findings = append(findings, lintOverlapFinding(lintID, baseline, relPath, line))
+		}
+	}
+
+	return findings
+}
+
+func lintOverlapFinding(lintID string, baseline bool, relPath string, line int) preflight.Finding {
+	rule := lintIssueRules[lintID]
+	how := "disabled"
+	if baseline {
+		how = "baselined"
+	}
+	return preflight.Finding{
+		CheckID:     RuleLintOverlap,
+		Title:       fmt.Sprintf("Lint issue %s is %s but checked by playcheck", lintID, how),
+		Description: fmt.Sprintf("Android Lint issue %s is %s in %s, while playcheck rule %s still reports the same problem.", lintID, how, filepath.Base(relPath), rule),
+		Severity:    preflight.SeverityInfo,
+		Location:    preflight.Location{File: relPath, Line: line},
+		Suggestion:  fmt.Sprintf("Align the two tools: re-enable %s in lint, or suppress %s in playcheck if the issue is intentionally accepted.", lintID, rule),
+	}
+}
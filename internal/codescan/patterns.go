@@ -2,6 +2,8 @@ package codescan
 
 import (
 	"regexp"
+	"regexp/syntax"
+	"strings"
 	"sync"
 )
 
@@ -40,6 +42,52 @@ func compilePattern(pattern string) (*regexp.Regexp, error) {
 type compiledRule struct {
 	rule     codeRule
 	patterns []*regexp.Regexp
+	literals []string // per pattern: text every match contains, or ""
+}
+
+// mayMatch reports whether line can match pattern i, ruling out lines
+// without the pattern's required literal before running the regexp.
+func (cr *compiledRule) mayMatch(i int, line string) bool {
+	return cr.literals[i] == "" || strings.Contains(line, cr.literals[i])
+}
+
+// mayMatchAny reports whether line can match any of the rule's patterns.
+func (cr *compiledRule) mayMatchAny(line string) bool {
+	for i := range cr.patterns {
+		if cr.mayMatch(i, line) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredLiteral returns the longest case-sensitive literal that every
+// match of pattern contains, or "" if there is none. Only literals directly
+// in the pattern's top-level concatenation are considered, so the result is
+// conservative: lines without it cannot match.
+func requiredLiteral(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	re = re.Simplify()
+	for re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+	parts := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		parts = re.Sub
+	}
+	var longest string
+	for _, sub := range parts {
+		if sub.Op != syntax.OpLiteral || sub.Flags&syntax.FoldCase != 0 {
+			continue
+		}
+		if lit := string(sub.Rune); len(lit) > len(longest) {
+			longest = lit
+		}
+	}
+	return longest
 }
 
 // compileRules compiles all pattern strings in the rule set into regexps.
@@ -54,6 +102,7 @@ func compileRules(rules []codeRule) []compiledRule {
 				continue
 			}
 			cr.patterns = append(cr.patterns, re)
+			cr.literals = append(cr.literals, requiredLiteral(p))
 		}
 		if len(cr.patterns) > 0 {
 			compiled = append(compiled, cr)
@@ -0,0 +1,121 @@
+package codescan
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// maxPendingIntentLines bounds how many lines the arguments of one
+// PendingIntent call are collected over before the call is given up on.
+const maxPendingIntentLines = 10
+
+var (
+	// pendingIntentCallRe matches the PendingIntent factory methods up to the
+	// opening parenthesis of their argument list.
+	pendingIntentCallRe = regexp.MustCompile(`\bPendingIntent\.get(?:Activity|Activities|Broadcast|Service|ForegroundService)\s*\(`)
+
+	// mutabilityFlagRe matches the flags that make a PendingIntent's
+	// mutability explicit.
+	mutabilityFlagRe = regexp.MustCompile(`\bFLAG_(?:IM)?MUTABLE\b`)
+
+	// flagConstantsRe matches a flags argument made only of FLAG_ constants
+	// combined with | (Java) or or (Kotlin).
+	flagConstantsRe = regexp.MustCompile(`^[\w.]*FLAG_\w+(?:\s*(?:\||\bor\b)\s*[\w.]*FLAG_\w+)*$`)
+)
+
+// pendingIntentCall collects the argument list of a PendingIntent factory
+// call, which is often split over several lines.
+type pendingIntentCall struct {
+	open  bool
+	depth int
+	lines int
+	args  strings.Builder
+}
+
+// start begins collecting the call whose argument list starts at rest, the
+// text following the opening parenthesis.
+func (c *pendingIntentCall) start(rest string) (done bool) {
+	*c = pendingIntentCall{open: true, depth: 1}
+	return c.feed(rest)
+}
+
+// feed adds a line of the argument list. It reports whether the closing
+// parenthesis was reached, or the call was abandoned for spanning too many
+// lines.
+func (c *pendingIntentCall) feed(s string) (done bool) {
+	if c.lines++; c.lines > maxPendingIntentLines {
+		c.open = false
+		c.args.Reset()
+		return true
+	}
+	for i, r := range s {
+		switch r {
+		case '(':
+			c.depth++
+		case ')':
+			if c.depth--; c.depth == 0 {
+				c.args.WriteString(s[:i])
+				c.open = false
+				return true
+			}
+		}
+	}
+	c.args.WriteString(s)
+	c.args.WriteByte(' ')
+	return false
+}
+
+// missingMutability reports whether the finished call's flags argument is 0
+// or a combination of FLAG_ constants without FLAG_IMMUTABLE or FLAG_MUTABLE.
+// Flags passed in a variable or computed conditionally cannot be resolved
+// and are not reported.
+func (c *pendingIntentCall) missingMutability() bool {
+	args := splitTopLevel(c.args.String())
+	if len(args) < 4 {
+		return false
+	}
+	flags := strings.TrimSpace(args[3])
+	if mutabilityFlagRe.MatchString(flags) {
+		return false
+	}
+	return flags == "0" || flagConstantsRe.MatchString(flags)
+}
+
+// splitTopLevel splits a call's argument list on commas outside nested
+// parentheses.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// pendingIntentWithoutMutability builds the finding for a PendingIntent
+// created without an explicit mutability flag.
+func pendingIntentWithoutMutability(relPath string, line int, snippet string) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RulePendingIntent,
+		Title:       "PendingIntent created without a mutability flag",
+		Description: "A PendingIntent is created without FLAG_IMMUTABLE or FLAG_MUTABLE. Apps targeting Android 12 (API 31) or higher crash with IllegalArgumentException, and a mutable PendingIntent wrapping an implicit Intent lets other apps redirect it to their own components.\n  Code: " + snippet,
+		Severity:    preflight.SeverityWarning,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Pass PendingIntent.FLAG_IMMUTABLE, or FLAG_MUTABLE only when another app must fill in the Intent (e.g. inline replies), and make the wrapped Intent explicit with setComponent or setPackage.",
+	}
+}
@@ -0,0 +1,48 @@
+package codescan
+
+import (
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// WithPolicies makes the scanner take rule severities and enabled flags from
+// db instead of the embedded policy database. A nil db keeps the hardcoded
+// rule defaults.
+func WithPolicies(db *policies.PolicyDatabase) Option {
+	return func(s *Scanner) {
+		s.policies = db
+	}
+}
+
+// applyPolicies drops findings of rules disabled in the policy database and
+// sets the database severity on findings of rules whose database severity
+// differs from the default in Rules. A database severity equal to the default
+// leaves the severity each check computed, such as CS015 escalating at the
+// breaking target SDK, unchanged. Rules missing from the database keep their
+// defaults. It runs before category escalation, which still applies.
+func applyPolicies(findings []preflight.Finding, db *policies.PolicyDatabase) []preflight.Finding {
+	if db == nil {
+		return findings
+	}
+	defaults := make(map[string]preflight.Severity)
+	for _, r := range Rules() {
+		defaults[r.ID] = r.Severity
+	}
+
+	kept := findings[:0]
+	for _, f := range findings {
+		rule := db.GetRule(f.CheckID)
+		if rule == nil {
+			kept = append(kept, f)
+			continue
+		}
+		if !rule.IsEnabled() {
+			continue
+		}
+		if sev, err := preflight.ParseSeverity(rule.Severity); err == nil && sev != defaults[f.CheckID] {
+			f.Severity = sev
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
@@ -0,0 +1,44 @@
+package codescan
+
+import (
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// sensitiveName matches identifiers and keys that name credentials or
+// personal data.
+const sensitiveName = `(?i:token|password|passwd|secret|pii)`
+
+var (
+	// sensitivePutRe matches SharedPreferences.Editor string writes whose key
+	// or stored value is sensitive, e.g. putString("auth_token", value) or
+	// putString(KEY_USER, user.password).
+	sensitivePutRe = regexp.MustCompile(`\.put(?:String|StringSet)\s*\(\s*(?:"[^"]*` + sensitiveName + `[^"]*"|[\w.]*` + sensitiveName + `\w*)\s*,` +
+		`|\.put(?:String|StringSet)\s*\([^,]+,\s*[\w.]*` + sensitiveName + `\w*\s*\)`)
+
+	// sharedPrefsRe matches code that uses plain SharedPreferences.
+	// EncryptedSharedPreferences does not match because of the word boundary.
+	sharedPrefsRe = regexp.MustCompile(`\b(?:SharedPreferences|PreferenceManager)\b|\bgetSharedPreferences\s*\(`)
+
+	// encryptedPrefsRe matches the Jetpack Security encrypted preferences.
+	encryptedPrefsRe = regexp.MustCompile(`\bEncryptedSharedPreferences\b`)
+)
+
+// sensitivePreferenceWrite builds the finding for a sensitive value written to
+// SharedPreferences in a file that uses plain preferences and never
+// EncryptedSharedPreferences. Like the foreground service check, the check is
+// per file: the preferences may be created elsewhere.
+func sensitivePreferenceWrite(relPath string, line int, snippet string) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleSensitivePrefs,
+		Title:       "Sensitive data stored in plain SharedPreferences",
+		Description: "A token, password, secret, or personal data value is written to SharedPreferences, which stores it unencrypted in the app's data directory. It can be read from backups and on rooted devices.\n  Code: " + snippet,
+		Severity:    preflight.SeverityWarning,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Store credentials and personal data in EncryptedSharedPreferences (androidx.security:security-crypto) or encrypt them with a key from the Android Keystore.",
+	}
+}
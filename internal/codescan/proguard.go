@@ -0,0 +1,132 @@
+package codescan
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+var (
+	// keepDirectiveRe matches the -keep options that keep classes or their
+	// members, capturing the class specification.
+	keepDirectiveRe = regexp.MustCompile(`^-keep(names|classmembers|classmembernames|classeswithmembers|classeswithmembernames)?\b(?:\s*,\s*\w+)*\s+(.*)$`)
+
+	// keepClassNameRe captures the class name of a class specification, after
+	// its access modifiers and annotation.
+	keepClassNameRe = regexp.MustCompile(`(?:^|\s)(?:class|interface|enum)\s+([^\s{]+)`)
+
+	// broadClassNameRe matches class names that select every class, or every
+	// class under a single top-level package such as com.**.
+	broadClassNameRe = regexp.MustCompile(`^(?:\*\*?|[\w$]+\.\*\*?)$`)
+
+	// narrowingClauseRe matches the parts of a class specification that
+	// restrict a wildcard class name: an annotation or a supertype.
+	narrowingClauseRe = regexp.MustCompile(`@|\b(?:extends|implements)\b`)
+
+	// allMembersRe matches a member specification that keeps every member.
+	allMembersRe = regexp.MustCompile(`\{\s*\*\s*;\s*\}`)
+)
+
+// disabledSteps maps the options that turn off an R8 step to the step and
+// what turning it off costs.
+var disabledSteps = map[string]struct {
+	Step   string
+	Effect string
+}{
+	"-dontobfuscate": {"obfuscation", "Class and member names are left readable, so the app is easier to reverse engineer and its release build is larger."},
+	"-dontshrink":    {"shrinking", "Unused classes from the app and its libraries are left in the release build, which increases download and install size."},
+}
+
+// isProguardFile reports whether path is a ProGuard or R8 rules file, e.g.
+// proguard-rules.pro or consumer-rules.pro.
+func isProguardFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".pro")
+}
+
+// scanProguardFile reports -keep directives broad enough to keep most of the
+// app, and options that turn off shrinking or obfuscation altogether.
+func scanProguardFile(filePath, projectDir string) []preflight.Finding {
+	data, err := utils.ReadFileWithLimit(filePath)
+	if err != nil {
+		return nil
+	}
+	relPath, err := filepath.Rel(projectDir, filePath)
+	if err != nil {
+		relPath = filePath
+	}
+
+	var findings []preflight.Finding
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, utils.MaxFileSize)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if _, ok := disabledSteps[line]; ok {
+			findings = append(findings, shrinkerDisabledFinding(line, relPath, lineNum))
+			continue
+		}
+		m := keepDirectiveRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if className, ok := broadKeepSpec(m[1], m[2]); ok {
+			findings = append(findings, broadKeepFinding(className, line, relPath, lineNum))
+		}
+	}
+	return findings
+}
+
+// broadKeepSpec reports whether the class specification of a -keep<kind>
+// directive keeps every class, or every class in a top-level package, and
+// returns the matched class name. Wildcards restricted by an annotation or
+// supertype are targeted. Member-only directives are broad when they keep
+// every member.
+func broadKeepSpec(kind, spec string) (string, bool) {
+	c := keepClassNameRe.FindStringSubmatch(spec)
+	if c == nil || !broadClassNameRe.MatchString(c[1]) || narrowingClauseRe.MatchString(spec) {
+		return "", false
+	}
+	if strings.HasPrefix(kind, "classmember") && !allMembersRe.MatchString(spec) {
+		return "", false
+	}
+	return c[1], true
+}
+
+// broadKeepFinding builds the finding for a -keep directive matching every
+// class, or every class in a top-level package.
+func broadKeepFinding(className, directive, relPath string, line int) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleShrinkerConfig,
+		Title:       "Over-broad ProGuard keep rule",
+		Description: "The keep rule matches " + className + ", which keeps almost all classes from being removed, optimized, or obfuscated by R8. The release build is larger and its code is left readable.\n  Rule: " + directive,
+		Severity:    preflight.SeverityWarning,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Keep only the classes that need it, such as those accessed through reflection or serialization, e.g. -keep class com.example.app.model.** { *; }, and rely on the consumer rules shipped with libraries.",
+	}
+}
+
+// shrinkerDisabledFinding builds the finding for an option in disabledSteps.
+func shrinkerDisabledFinding(option, relPath string, line int) preflight.Finding {
+	d := disabledSteps[option]
+	return preflight.Finding{
+		CheckID:     RuleShrinkerConfig,
+		Title:       "R8 " + d.Step + " disabled",
+		Description: option + " turns off " + d.Step + " for the whole app. " + d.Effect,
+		Severity:    preflight.SeverityInfo,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Remove " + option + " and add targeted -keep rules for the classes that break without it.",
+	}
+}
@@ -0,0 +1,90 @@
+package codescan
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+var (
+	// parcelableExtraRe matches reading a Parcelable, such as an Intent, from
+	// the extras of the Intent that started the component.
+	parcelableExtraRe = regexp.MustCompile(`\bgetParcelable(?:Extra)?\b`)
+
+	// extraAssignRe matches assigning a Parcelable extra to a variable in
+	// Java (`Intent next = ...`) or Kotlin (`val next: Intent? = ...`), with
+	// the variable name in group 1.
+	extraAssignRe = regexp.MustCompile(`\b(\w+)\s*(?::\s*Intent\??)?\s*=\s*[^=;]*\bgetParcelable(?:Extra)?\b`)
+
+	// intentExtraRe matches reading an extra that is explicitly an Intent:
+	// `getParcelableExtra<Intent>(...)`, `getParcelableExtra(name,
+	// Intent.class)` or `Intent::class.java`, or a Java `(Intent)` cast.
+	intentExtraRe = regexp.MustCompile(`\bgetParcelable(?:Extra)?\s*(?:<\s*Intent\s*>|\([^()]*,\s*Intent(?:::class\.java|\.class)\s*\))|\(\s*Intent\s*\)\s*[\w.()]*\bgetParcelable(?:Extra)?\b`)
+
+	// directExtraArgRe matches a launching call whose Intent argument is the
+	// extra itself, e.g. `startActivity(getIntent().getParcelableExtra("next"))`.
+	directExtraArgRe = regexp.MustCompile(`\b(?:startActivit(?:y|yForResult|ies)|startService|startForegroundService|sendBroadcast|bindService)\s*\(\s*(?:\(\s*Intent\s*\)\s*)?(?:\w+(?:\(\))?\s*\??\.\s*)*getParcelable(?:Extra)?\b|\bsetResult\s*\([^,()]*,\s*(?:\w+(?:\(\))?\s*\??\.\s*)*getParcelable(?:Extra)?\b`)
+
+	// redirectCallRe matches the calls that launch or return an Intent.
+	redirectCallRe = regexp.MustCompile(`\b(?:startActivit(?:y|yForResult|ies)|startService|startForegroundService|sendBroadcast|bindService|setResult)\s*\(`)
+
+	// redirectArgRe matches a launching call's Intent argument when it is a
+	// plain variable: the first argument of startActivity and friends in
+	// group 1, the second argument of setResult in group 2.
+	redirectArgRe = regexp.MustCompile(`\b(?:startActivit(?:y|yForResult|ies)|startService|startForegroundService|sendBroadcast|bindService)\s*\(\s*(\w+)\s*[,)]|\bsetResult\s*\([^,()]*,\s*(\w+)\s*\)`)
+)
+
+// intentExtras records the variables a file assigns from Intent extras, so
+// that a later call launching or returning one of them can be reported.
+type intentExtras map[string]int // variable name -> line the extra is read
+
+// feed processes line lineNum. When the line launches or returns an Intent
+// read from an extra, it reports the line the extra was read on.
+func (e *intentExtras) feed(line string, lineNum int) (from int, ok bool) {
+	if parcelableExtraRe.MatchString(line) {
+		// Read and forwarded at once, e.g. in a ?.let block. The extra must
+		// be an Intent or be passed as the launched Intent itself; other
+		// Parcelables are often put into a new Intent on the same line.
+		if redirectCallRe.MatchString(line) && (intentExtraRe.MatchString(line) || directExtraArgRe.MatchString(line)) {
+			return lineNum, true
+		}
+		if m := extraAssignRe.FindStringSubmatch(line); m != nil {
+			if *e == nil {
+				*e = make(intentExtras)
+			}
+			(*e)[m[1]] = lineNum
+		}
+		return 0, false
+	}
+	if len(*e) == 0 {
+		return 0, false
+	}
+	for _, m := range redirectArgRe.FindAllStringSubmatch(line, -1) {
+		if from, ok := (*e)[m[1]+m[2]]; ok {
+			return from, true
+		}
+	}
+	return 0, false
+}
+
+// intentRedirection builds the finding for an Intent taken from extras and
+// launched or returned without validation. from is the line the extra is
+// read on.
+func intentRedirection(from int, relPath string, line int, snippet string) preflight.Finding {
+	source := "read from an Intent extra on the same line"
+	if from != line {
+		source = fmt.Sprintf("read from an Intent extra on line %d", from)
+	}
+	return preflight.Finding{
+		CheckID:     RuleIntentRedirect,
+		Title:       "Possible Intent redirection",
+		Description: "An Intent " + source + " is launched or returned as is. Another app can put an Intent there that targets this app's non-exported components or grants URI permissions to its content providers. Google Play flags Intent redirection as a security vulnerability.\n  Code: " + snippet,
+		Severity:    preflight.SeverityWarning,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Do not launch Intents received from other apps. If forwarding is needed, check the target with resolveActivity and its package and class name against an allowlist, remove FLAG_GRANT_* flags, or make the component non-exported.",
+	}
+}
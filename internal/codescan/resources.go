@@ -0,0 +1,139 @@
+package codescan
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// xmlHTTPRe matches cleartext URLs inside XML resources and manifests.
+var xmlHTTPRe = regexp.MustCompile(`http://[^\s"'<>]+`)
+
+// nonNetworkURLPrefixes are http:// URLs that appear in XML as namespace or
+// schema identifiers and are never fetched over the network.
+var nonNetworkURLPrefixes = []string{
+	"http://schemas.android.com/",
+	"http://www.w3.org/",
+	"http://schemas.xmlsoap.org/",
+	"http://xmlpull.org/",
+	"http://ns.adobe.com/",
+	"http://purl.org/",
+	"http://www.apache.org/licenses/",
+}
+
+// isNonNetworkURL reports whether url is a well-known namespace/schema URL.
+func isNonNetworkURL(url string) bool {
+	for _, prefix := range nonNetworkURLPrefixes {
+		if strings.HasPrefix(url, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// testAdIDRe matches Google's sample AdMob app and ad unit IDs.
+var testAdIDRe = regexp.MustCompile(`\bca-app-pub-3940256099942544[/~]\d+`)
+
+// codeRuleByID returns the code rule with the given ID so resource findings
+// share its title, severity, and suggestion.
+func codeRuleByID(id string) codeRule {
+	for _, r := range codeRules {
+		if r.ID == id {
+			return r
+		}
+	}
+	return codeRule{ID: id}
+}
+
+// scanResourceFile scans an XML resource or manifest for cleartext http://
+// URLs and AdMob test IDs, skipping namespace declarations and XML comments.
+func scanResourceFile(filePath, projectDir string) []preflight.Finding {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil
+	}
+	if info.Size() > utils.MaxFileSize {
+		return nil
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	relPath, err := filepath.Rel(projectDir, filePath)
+	if err != nil {
+		relPath = filePath
+	}
+
+	rule, adRule := codeRuleByID(RuleHTTPUsage), codeRuleByID(RuleTestAdUnit)
+	var findings []preflight.Finding
+	matched := make(map[string]int) // rule ID -> count
+	const maxMatchesPerRule = 3
+
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	inComment := false
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		// Skip XML comments, including multi-line ones.
+		if inComment {
+			if strings.Contains(trimmed, "-->") {
+				inComment = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "<!--") {
+			inComment = !strings.Contains(trimmed, "-->")
+			continue
+		}
+
+		for _, url := range xmlHTTPRe.FindAllString(line, -1) {
+			if isNonNetworkURL(url) || matched[rule.ID] >= maxMatchesPerRule {
+				continue
+			}
+			matched[rule.ID]++
+			findings = append(findings, preflight.Finding{
+				CheckID:     rule.ID,
+				Title:       rule.Title,
+				Description: rule.Description + "\n  URL: " + url,
+				Severity:    rule.Severity,
+				Location: preflight.Location{
+					File: relPath,
+					Line: lineNum,
+				},
+				Suggestion: rule.Suggestion,
+			})
+			break // one finding per line is enough
+		}
+
+		if id := testAdIDRe.FindString(line); id != "" && matched[adRule.ID] < maxMatchesPerRule {
+			matched[adRule.ID]++
+			findings = append(findings, preflight.Finding{
+				CheckID:     adRule.ID,
+				Title:       adRule.Title,
+				Description: adRule.Description + "\n  ID: " + id,
+				Severity:    adRule.Severity,
+				Location: preflight.Location{
+					File: relPath,
+					Line: lineNum,
+				},
+				Suggestion: adRule.Suggestion,
+			})
+		}
+		if matched[rule.ID] >= maxMatchesPerRule && matched[adRule.ID] >= maxMatchesPerRule {
+			break
+		}
+	}
+
+	return findings
+}
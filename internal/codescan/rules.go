@@ -1,6 +1,11 @@
 package codescan
 
-import "github.com/kotaroyamazaki/playcheck/internal/preflight"
+import (
+	"sort"
+
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
 
 // Rule IDs for code scanning checks.
 const (
@@ -18,8 +23,36 @@ const (
 	RuleWebViewJS         = "CS012"
 	RuleFacebookSDK       = "CS013"
 	RuleThirdPartyTracker = "CS014"
+	RuleRemovedAPI        = "CS015"
+	RuleSensitiveLogging  = "CS016"
+	RuleLintOverlap       = "CS017"
+	RuleDeviceIdentifier  = "CS018"
+	RuleSafetyNet         = "CS019"
+	RulePlayIntegrity     = "CS020"
+	RuleForegroundService = "CS021"
+	RuleAppInventory      = "CS022"
+	RuleCleartextWebView  = "CS023"
+	RuleCellInfo          = "CS024"
+	RuleSensitivePrefs    = "CS025"
+	RuleLegacyStorage     = "CS026"
+	RulePendingIntent     = "CS027"
+	RuleBackgroundLaunch  = "CS028"
+	RuleDevEndpoint       = "CS029"
+	RuleFlagSecure        = "CS030"
+	RuleInsecureTLS       = "CS031"
+	RuleShrinkerConfig    = "CS032"
+	RuleTestAdUnit        = "CS033"
+	RuleDeprecatedTLS     = "CS034"
+	RuleIntentRedirect    = "CS035"
+	RuleAsyncTask         = "CS036"
+	RuleGCM               = "CS037"
+	RuleTapjacking        = "CS038"
+	RuleWebViewDebug      = "CS039"
 )
 
+// RuleCategory is the category of code scanning rules.
+const RuleCategory = policies.CategoryCodeScanning
+
 // codeRule describes a single code scanning rule with its detection pattern.
 type codeRule struct {
 	ID          string
@@ -219,4 +252,154 @@ var codeRules = []codeRule{
 			`com\.crashlytics`,
 		},
 	},
+	{
+		ID:          RuleSensitiveLogging,
+		Title:       "Sensitive data written to Logcat",
+		Description: "A Log call interpolates a value that looks like a token, password, email, or location. Logcat output can be read by other tools and leaks personal data.",
+		Severity:    preflight.SeverityWarning,
+		Suggestion:  "Remove sensitive values from log messages or guard the call with BuildConfig.DEBUG. Never log credentials or personal data in release builds.",
+		Patterns: []string{
+			// Kotlin string templates: "$token", "${user.email}"
+			`\bLog\.[deivw]\s*\(.*\$\{?[\w.]*(?i:token|password|passwd|email|location)`,
+			// Java concatenation: "..." + authToken
+			`\bLog\.[deivw]\s*\(.*\+\s*[\w.]*(?i:token|password|passwd|email|location)`,
+		},
+	},
+	{
+		ID:          RuleSafetyNet,
+		Title:       "Deprecated SafetyNet Attestation API usage",
+		Description: "SafetyNet Attestation is deprecated and has been shut down in favor of the Play Integrity API. Attestation calls stop returning verdicts, so integrity checks that depend on them break.",
+		Severity:    preflight.SeverityWarning,
+		Suggestion:  "Migrate to the Play Integrity API: request a token with IntegrityManager.requestIntegrityToken and verify the verdict on your server.",
+		Patterns: []string{
+			`com\.google\.android\.gms\.safetynet`,
+			`\bSafetyNet(?:Api|Client)?\b`,
+			`\.attest\s*\(`,
+		},
+	},
+	{
+		ID:          RulePlayIntegrity,
+		Title:       "Play Integrity API usage detected",
+		Description: "The app uses the Play Integrity API, the supported replacement for SafetyNet Attestation.",
+		Severity:    preflight.SeverityInfo,
+		Suggestion:  "Verify integrity verdicts on your server, not in the app, and handle devices that fail the check gracefully.",
+		Patterns: []string{
+			`com\.google\.android\.play\.core\.integrity`,
+			`\b(?:Standard)?IntegrityManager(?:Factory)?\b`,
+			`\brequestIntegrityToken\b`,
+		},
+	},
+	{
+		ID:          RuleAppInventory,
+		Title:       "Installed app inventory access",
+		Description: "The app reads the list of installed apps. Play treats the inventory of installed apps as personal and sensitive user data, which may only be collected when it is core to the app's functionality and must be disclosed in the Data Safety section.",
+		Severity:    preflight.SeverityWarning,
+		Suggestion:  "Query only the packages the app interacts with by declaring them in a <queries> element, and disclose any collection of installed apps under 'App activity' in the Data Safety form.",
+		Patterns: []string{
+			`\bgetInstalled(?:Applications|Packages)\s*\(`,
+			`\bqueryIntentActivities\s*\(`,
+		},
+	},
+	{
+		ID:          RuleCleartextWebView,
+		Title:       "WebView loads content over cleartext HTTP",
+		Description: "A WebView loads an http:// URL or uses one as the base URL for inline content. The page and everything it references travel unencrypted and can be read or modified on the network.",
+		Severity:    preflight.SeverityError,
+		Suggestion:  "Load WebView content over https://. For inline content passed to loadDataWithBaseURL, use an https:// base URL or null.",
+		Patterns: []string{
+			`\bloadUrl\s*\(\s*"http://`,
+			`\bloadDataWithBaseURL\s*\(\s*"http://`,
+		},
+	},
+	{
+		ID:          RuleCellInfo,
+		Title:       "Cell tower or network operator access",
+		Description: "The app reads cell tower or mobile network operator information. Play treats data that reveals the device's approximate position as approximate location, which must be disclosed in the Data Safety section.",
+		Severity:    preflight.SeverityWarning,
+		Suggestion:  "Declare 'Approximate location' in your Data Safety form, or drop the call if the app does not need network location data.",
+		Patterns: []string{
+			`\bgetCellLocation\s*\(`,
+			`\bgetAllCellInfo\s*\(`,
+			`\bgetNetworkOperatorName\s*\(`,
+		},
+	},
+	{
+		ID:          RuleTestAdUnit,
+		Title:       "AdMob test ad ID in app",
+		Description: "The app contains one of Google's sample AdMob IDs (publisher ca-app-pub-3940256099942544). Test ads earn no revenue, and shipping them suggests the release still uses debug ad configuration.",
+		Severity:    preflight.SeverityWarning,
+		Suggestion:  "Use your own AdMob app and ad unit IDs in release builds, e.g. from a release-only resource or buildConfigField, and keep the test IDs for debug builds.",
+		Patterns: []string{
+			`\bca-app-pub-3940256099942544[/~]\d+`,
+		},
+	},
+	{
+		ID:          RuleDeprecatedTLS,
+		Title:       "Deprecated TLS version configured",
+		Description: "The app requests SSLv3, TLS 1.0, or TLS 1.1. These protocol versions have known weaknesses, are disabled by most servers, and forcing them exposes traffic to downgrade and decryption attacks.",
+		Severity:    preflight.SeverityWarning,
+		Suggestion:  "Use SSLContext.getInstance(\"TLS\") or \"TLSv1.2\"/\"TLSv1.3\", and do not pass SSLv3, TLSv1, or TLSv1.1 to setEnabledProtocols. The platform defaults already negotiate TLS 1.2 or later.",
+		Patterns: []string{
+			`SSLContext\.getInstance\(\s*"(?:SSLv3|TLSv1|TLSv1\.1)"`,
+			`setEnabledProtocols\s*\(.*"(?:SSLv3|TLSv1|TLSv1\.1)"`,
+		},
+	},
+	{
+		ID:          RuleAsyncTask,
+		Title:       "Deprecated AsyncTask usage",
+		Description: "AsyncTask is deprecated since API 30. It leaks the enclosing activity or fragment, loses results on configuration changes, and runs tasks serially on a shared executor.",
+		Severity:    preflight.SeverityInfo,
+		Suggestion:  "Move background work to Kotlin coroutines (viewModelScope or lifecycleScope), java.util.concurrent executors, or WorkManager for work that must outlive the screen.",
+		Patterns: []string{
+			`\bandroid\.os\.AsyncTask\b`,
+			`\bAsyncTask\s*<`,
+		},
+	},
+	{
+		ID:          RuleGCM,
+		Title:       "Google Cloud Messaging (GCM) usage",
+		Description: "The app uses Google Cloud Messaging, which has been shut down. GCM registration and delivery no longer work, so the app does not receive push messages.",
+		Severity:    preflight.SeverityError,
+		Suggestion:  "Migrate to Firebase Cloud Messaging: replace GoogleCloudMessaging and GcmListenerService with FirebaseMessaging and FirebaseMessagingService, and send messages with the FCM HTTP v1 API.",
+		Patterns: []string{
+			`\bcom\.google\.android\.gms\.gcm\b`,
+			`\bcom\.google\.android\.gcm\b`,
+			`\bGoogleCloudMessaging\b`,
+			`\bGcm(?:ListenerService|Receiver|NetworkManager)\b`,
+		},
+	},
+}
+
+// Rules returns the metadata of every rule the code scanner reports, ordered
+// by ID.
+func Rules() []preflight.RuleInfo {
+	checkerID := (&Scanner{}).ID()
+	rules := make([]preflight.RuleInfo, 0, len(codeRules)+15)
+	for _, r := range codeRules {
+		rules = append(rules, preflight.RuleInfo{ID: r.ID, Title: r.Title, Description: r.Description, Severity: r.Severity})
+	}
+	// Rules whose findings are built outside codeRules.
+	rules = append(rules,
+		preflight.RuleInfo{ID: RuleRemovedAPI, Title: "API breaks at the target SDK", Description: "Code calls an API that is removed or restricted at the app's targetSdkVersion.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleLintOverlap, Title: "Lint issue suppressed but checked by playcheck", Description: "An Android Lint issue that overlaps a playcheck rule is disabled or baselined.", Severity: preflight.SeverityInfo},
+		preflight.RuleInfo{ID: RuleDeviceIdentifier, Title: "Non-resettable device identifier", Description: "Code reads a hardware or persistent identifier instead of the resettable advertising ID.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleSensitivePrefs, Title: "Sensitive data stored in plain SharedPreferences", Description: "A token, password, secret, or personal data value is written to unencrypted SharedPreferences.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleLegacyStorage, Title: "Legacy external storage path access", Description: "Code accesses shared external storage by file path, which scoped storage blocks for apps targeting API 29 or higher.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RulePendingIntent, Title: "PendingIntent created without a mutability flag", Description: "A PendingIntent factory call passes flags without FLAG_IMMUTABLE or FLAG_MUTABLE.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleBackgroundLaunch, Title: "Activity started from the background", Description: "A receiver or service starts an activity with FLAG_ACTIVITY_NEW_TASK, which background launch restrictions block.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleDevEndpoint, Title: "Development endpoint in code", Description: "A URL points at localhost, the emulator host 10.0.2.2, a .local name, or a staging or dev server.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleFlagSecure, Title: "Sensitive screen without FLAG_SECURE", Description: "With the finance or health preset, an activity showing payment or health data does not block screenshots with FLAG_SECURE.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleInsecureTLS, Title: "TLS certificate or hostname validation disabled", Description: "A TrustManager accepts every certificate or a HostnameVerifier accepts every hostname, exposing connections to man-in-the-middle attacks.", Severity: preflight.SeverityCritical},
+		preflight.RuleInfo{ID: RuleShrinkerConfig, Title: "ProGuard rules defeat R8", Description: "A ProGuard or R8 rules file keeps every class, or every class in a top-level package, or turns off shrinking or obfuscation.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleWebViewDebug, Title: "WebView contents debugging enabled", Description: "WebView.setWebContentsDebuggingEnabled(true) is called outside a BuildConfig.DEBUG guard.", Severity: preflight.SeverityError},
+		preflight.RuleInfo{ID: RuleTapjacking, Title: "Password field without tapjacking protection", Description: "With the finance or health preset, a layout with a password field does not set android:filterTouchesWhenObscured on its root view.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleIntentRedirect, Title: "Possible Intent redirection", Description: "An Intent read from the extras of an incoming Intent is passed to startActivity, setResult, or a similar call without validation.", Severity: preflight.SeverityWarning},
+		preflight.RuleInfo{ID: RuleForegroundService, Title: "startForeground called without building a notification", Description: "A service calls startForeground without a visible notification.", Severity: preflight.SeverityWarning},
+	)
+	for i := range rules {
+		rules[i].Category = RuleCategory
+		rules[i].Scanner = checkerID
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
 }
@@ -2,27 +2,70 @@ package codescan
 
 import (
 	"bufio"
+	"context"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/kotaroyamazaki/playcheck/internal/manifest"
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
 	"github.com/kotaroyamazaki/playcheck/pkg/utils"
 )
 
-// Scanner scans Kotlin and Java source files for Play Store compliance issues.
+// Scanner scans Kotlin and Java source files, plus XML resources and
+// manifests, for Play Store compliance issues.
 type Scanner struct {
-	compiled []compiledRule
+	compiled     []compiledRule
+	category     preflight.AppCategory
+	ruleBudget   time.Duration
+	policies     *policies.PolicyDatabase
+	contextLines int
+	preset       preflight.Preset
+	walkOpts     []utils.WalkOption
+
+	endpointAllowlist []string
+}
+
+// Option configures optional Scanner behavior.
+type Option func(*Scanner)
+
+// WithAppCategory applies the policies of the app's Play category, e.g.
+// escalating ads SDK findings for family apps.
+func WithAppCategory(c preflight.AppCategory) Option {
+	return func(s *Scanner) {
+		s.category = c
+	}
 }
 
-// NewScanner creates a Scanner with the default rule set pre-compiled.
-func NewScanner() *Scanner {
-	return &Scanner{
-		compiled: compileRules(codeRules),
+// WithFollowSymlinks makes the scanner follow symlinked files and
+// directories, e.g. source sets shared between modules through a link.
+func WithFollowSymlinks() Option {
+	return func(s *Scanner) {
+		s.walkOpts = append(s.walkOpts, utils.WithFollowSymlinks())
 	}
 }
 
+// NewScanner creates a Scanner with the default rule set pre-compiled. Rule
+// severities and enabled flags come from the embedded policy database unless
+// overridden with WithPolicies.
+func NewScanner(opts ...Option) *Scanner {
+	db, _ := policies.Load()
+	s := &Scanner{
+		compiled:   compileRules(codeRules),
+		ruleBudget: defaultRuleBudget,
+		policies:   db,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
 // ID implements preflight.Checker.
 func (s *Scanner) ID() string { return "code-scan" }
 
@@ -31,80 +74,198 @@ func (s *Scanner) Name() string { return "Code Scanner" }
 
 // Description implements preflight.Checker.
 func (s *Scanner) Description() string {
-	return "Scans Kotlin and Java source files for Play Store compliance issues"
+	return "Scans Kotlin, Java, and XML resource files for Play Store compliance issues"
 }
 
+// Rules implements preflight.RuleLister.
+func (s *Scanner) Rules() []preflight.RuleInfo { return Rules() }
+
 // maxSnippetLen is the maximum length of a code snippet included in findings.
 const maxSnippetLen = 120
 
+// snippetOf truncates a trimmed source line for inclusion in a finding.
+func snippetOf(trimmed string) string {
+	if len(trimmed) > maxSnippetLen {
+		return trimmed[:maxSnippetLen] + "..."
+	}
+	return trimmed
+}
+
+// maxMatchLineLen caps how much of a line is matched against rule patterns.
+// Go's regexp runs in linear time, so bounding the input bounds each match;
+// minified or generated code can otherwise put megabytes on one line.
+const maxMatchLineLen = 4096
+
+// lineBuffers holds read buffers for scanFile, so scanning many files does
+// not allocate a fresh buffer per file. Buffers grown for long lines are not
+// returned to the pool.
+var lineBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 64<<10)
+		return &buf
+	},
+}
+
+// defaultRuleBudget is the matching time a rule may spend on one file before
+// it is skipped for the rest of that file. Matches cannot be interrupted, so
+// the budget is checked after each line.
+const defaultRuleBudget = 200 * time.Millisecond
+
 // maxConcurrency limits the number of files scanned concurrently.
 const maxConcurrency = 8
 
-// Run implements preflight.Checker. It walks the project directory for .kt and
-// .java files, scans them concurrently, and returns aggregated findings.
+// Run implements preflight.Checker. It walks the project directory for .kt,
+// .java, .xml, and ProGuard .pro files, scans them concurrently, and returns
+// aggregated findings.
 func (s *Scanner) Run(projectDir string) (*preflight.CheckResult, error) {
+	return s.RunContext(context.Background(), projectDir)
+}
+
+// RunContext implements preflight.ContextChecker. Once ctx is done, no
+// further files are scanned and the findings so far are returned with ctx's
+// error.
+func (s *Scanner) RunContext(ctx context.Context, projectDir string) (*preflight.CheckResult, error) {
 	files, err := utils.WalkFiles(projectDir,
-		utils.WithExtensions(".kt", ".java"),
+		append([]utils.WalkOption{utils.WithExtensions(".kt", ".java", ".xml", ".pro")}, s.walkOpts...)...,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	result := &preflight.CheckResult{
-		CheckID: s.ID(),
-		Passed:  true,
+		CheckID:  s.ID(),
+		Passed:   true,
+		Coverage: ruleCoverage(files, s.preset),
 	}
 
 	if len(files) == 0 {
 		return result, nil
 	}
 
-	// Scan files concurrently with a semaphore to limit parallelism.
-	var (
-		mu       sync.Mutex
-		wg       sync.WaitGroup
-		sem      = make(chan struct{}, maxConcurrency)
-		findings []preflight.Finding
-	)
+	targetSDK := manifest.ResolveTargetSDK(projectDir, s.walkOpts...)
 
-	for _, file := range files {
+	result.Findings, result.SkippedRules = s.scanFiles(ctx, files, projectDir, targetSDK)
+	if err := ctx.Err(); err != nil {
+		result.Passed = false
+		return result, err
+	}
+	result.Findings = applyPolicies(result.Findings, s.policies)
+	if s.category == preflight.CategoryFamilies {
+		escalateFamiliesAds(result.Findings)
+	}
+	if m, err := manifest.FindAndParse(projectDir); err == nil {
+		if m.HasPermission(permQueryAllPackages) {
+			escalateAppInventory(result.Findings)
+		}
+		if !m.HasPermission(permFineLocation) && !m.HasPermission(permCoarseLocation) {
+			escalateCellInfo(result.Findings)
+		}
+	}
+	result.FilesScanned, result.BytesScanned = utils.Coverage(files)
+	result.Passed = len(result.Findings) == 0
+
+	return result, nil
+}
+
+// scanFiles scans files on a bounded pool of workers. Each worker collects
+// findings into its own slice, so workers never contend on a shared lock;
+// the slices are merged and sorted by file and line once all are done. It
+// also returns the sorted IDs of rules skipped in any file for exceeding
+// their time budget. Files are no longer handed to workers once ctx is done.
+func (s *Scanner) scanFiles(ctx context.Context, files []string, projectDir string, targetSDK int) ([]preflight.Finding, []string) {
+	workers := min(maxConcurrency, len(files))
+	paths := make(chan string, workers)
+	perWorker := make([][]preflight.Finding, workers)
+	skippedPerWorker := make([][]string, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
-		sem <- struct{}{} // acquire
-		go func(path string) {
+		go func(w int) {
 			defer wg.Done()
-			defer func() { <-sem }() // release
-
-			ff := s.scanFile(path, projectDir)
-			if len(ff) > 0 {
-				mu.Lock()
-				findings = append(findings, ff...)
-				mu.Unlock()
+			for path := range paths {
+				ff, skipped := s.scanPath(path, projectDir, targetSDK)
+				perWorker[w] = append(perWorker[w], ff...)
+				skippedPerWorker[w] = append(skippedPerWorker[w], skipped...)
 			}
-		}(file)
+		}(w)
 	}
-
+dispatch:
+	for _, file := range files {
+		select {
+		case paths <- file:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(paths)
 	wg.Wait()
 
-	result.Findings = findings
-	result.Passed = len(findings) == 0
+	total := 0
+	for _, ff := range perWorker {
+		total += len(ff)
+	}
+	findings := make([]preflight.Finding, 0, total)
+	for _, ff := range perWorker {
+		findings = append(findings, ff...)
+	}
+	sortByLocation(findings)
 
-	return result, nil
+	var skipped []string
+	for _, ids := range skippedPerWorker {
+		skipped = append(skipped, ids...)
+	}
+	slices.Sort(skipped)
+	return findings, slices.Compact(skipped)
+}
+
+// scanPath scans one file with the scanner that matches its type.
+func (s *Scanner) scanPath(path, projectDir string, targetSDK int) ([]preflight.Finding, []string) {
+	if isLintFile(path) {
+		return scanLintFile(path, projectDir), nil
+	}
+	if isProguardFile(path) {
+		return scanProguardFile(path, projectDir), nil
+	}
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		findings := scanResourceFile(path, projectDir)
+		if checksSecureScreens(s.preset) && isLayoutFile(path) {
+			findings = append(findings, scanLayoutFile(path, projectDir)...)
+		}
+		return findings, nil
+	}
+	return s.scanFile(path, projectDir, targetSDK)
+}
+
+// sortByLocation orders findings by file then line. The sort is stable so
+// findings on the same line keep their rule order.
+func sortByLocation(findings []preflight.Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Location, findings[j].Location
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
 }
 
-// scanFile scans a single file against all compiled rules and returns findings.
-func (s *Scanner) scanFile(filePath, projectDir string) []preflight.Finding {
+// scanFile scans a single file against all compiled rules and returns findings
+// and the IDs of rules skipped for exceeding their time budget.
+// targetSDK is the app's resolved target SDK (0 if unknown) and decides the
+// severity of SDK-bound API, device identifier, and legacy storage findings.
+func (s *Scanner) scanFile(filePath, projectDir string, targetSDK int) ([]preflight.Finding, []string) {
 	// Check file size before opening to prevent memory exhaustion.
 	info, err := os.Stat(filePath)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 	if info.Size() > utils.MaxFileSize {
-		return nil
+		return nil, nil
 	}
 
 	f, err := os.Open(filePath)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 	defer f.Close()
 
@@ -116,37 +277,96 @@ func (s *Scanner) scanFile(filePath, projectDir string) []preflight.Finding {
 	var findings []preflight.Finding
 
 	// Track which rule IDs have already matched in this file to avoid
-	// excessive duplicate findings from the same rule.
+	// excessive duplicate findings from the same rule. SDK-bound APIs and
+	// device identifiers are counted per entry.
 	matched := make(map[string]int) // rule ID -> count
+	apiMatched := make([]int, len(sdkBoundAPIs))
+	identifierMatched := make([]int, len(deviceIdentifiers))
 	const maxMatchesPerRule = 3
 
+	// Time spent matching each compiled rule in this file. A rule over its
+	// budget is skipped for the remaining lines.
+	spent := make(map[string]time.Duration)
+	var skipped []string
+
+	// startForeground calls are only reported if the file never builds a
+	// notification, which is known once the whole file has been read.
+	var foregroundCalls []preflight.Finding
+	buildsNotification := false
+
+	// Sensitive preference writes are only reported if the file uses plain
+	// SharedPreferences and never EncryptedSharedPreferences.
+	var prefsWrites []preflight.Finding
+	usesPrefs, usesEncryptedPrefs := false, false
+
+	// startActivity calls are only reported if the file declares a receiver
+	// or service and sets FLAG_ACTIVITY_NEW_TASK.
+	var activityLaunches []preflight.Finding
+	backgroundComponent, newTaskFlag := false, false
+
+	// PendingIntent calls are reported once their argument list, which may
+	// span several lines, shows no mutability flag. Candidates are recorded
+	// at the line the call starts so their context is right.
+	var pendingIntents []preflight.Finding
+	var pendingIntentKept []bool
+	var piCall pendingIntentCall
+
+	// checkServerTrusted and verify overrides are reported once their body,
+	// collected like a PendingIntent call, turns out to accept everything.
+	var tlsOverrides []preflight.Finding
+	var tlsOverrideKept []bool
+	var tlsMethod tlsOverride
+
+	// Intents read from extras are tracked by variable name until they are
+	// passed to a call that launches or returns them.
+	var extras intentExtras
+
+	// With the finance and health presets, activities are reported if their
+	// name or the file's layouts suggest sensitive data and the file never
+	// sets FLAG_SECURE.
+	checkSecure := checksSecureScreens(s.preset)
+	var secureScreens []preflight.Finding
+	var secureScreenNamed []bool
+	sensitiveLayout, setsFlagSecure := false, false
+
+	// The previous code line, to recognize calls guarded by BuildConfig.DEBUG.
+	prevCode := ""
+
+	ctx := contextCollector{n: s.contextLines}
+
+	buf := lineBuffers.Get().(*[]byte)
+	defer lineBuffers.Put(buf)
 	scanner := bufio.NewScanner(f)
+	scanner.Buffer(*buf, utils.MaxFileSize)
 	lineNum := 0
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
+		if len(line) > maxMatchLineLen {
+			line = line[:maxMatchLineLen]
+		}
+		ctx.next(lineNum, line)
 
 		// Skip comment-only lines to reduce false positives.
 		trimmed := strings.TrimSpace(line)
 		if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "/*") {
 			continue
 		}
+		nFindings, nForeground, nPrefs, nPending, nLaunches, nScreens, nTLS := len(findings), len(foregroundCalls), len(prefsWrites), len(pendingIntents), len(activityLaunches), len(secureScreens), len(tlsOverrides)
 
 		for i := range s.compiled {
 			cr := &s.compiled[i]
 
-			if matched[cr.rule.ID] >= maxMatchesPerRule {
+			if matched[cr.rule.ID] >= maxMatchesPerRule || spent[cr.rule.ID] > s.ruleBudget || !cr.mayMatchAny(line) {
 				continue
 			}
 
-			for _, re := range cr.patterns {
-				if re.MatchString(line) {
+			start := time.Now()
+			for j, re := range cr.patterns {
+				if cr.mayMatch(j, line) && re.MatchString(line) {
 					matched[cr.rule.ID]++
 
-					snippet := strings.TrimSpace(line)
-					if len(snippet) > maxSnippetLen {
-						snippet = snippet[:maxSnippetLen] + "..."
-					}
+					snippet := snippetOf(trimmed)
 
 					findings = append(findings, preflight.Finding{
 						CheckID:     cr.rule.ID,
@@ -162,8 +382,172 @@ func (s *Scanner) scanFile(filePath, projectDir string) []preflight.Finding {
 					break // one match per rule per line is enough
 				}
 			}
+			if spent[cr.rule.ID] += time.Since(start); spent[cr.rule.ID] > s.ruleBudget {
+				skipped = append(skipped, cr.rule.ID)
+			}
+		}
+
+		for j, api := range sdkBoundAPIs {
+			if apiMatched[j] >= maxMatchesPerRule || !api.Pattern.MatchString(line) {
+				continue
+			}
+			apiMatched[j]++
+			findings = append(findings, sdkBoundAPIFinding(api, targetSDK, relPath, lineNum, snippetOf(trimmed)))
+		}
+
+		for j, id := range deviceIdentifiers {
+			if identifierMatched[j] >= maxMatchesPerRule || !id.Pattern.MatchString(line) {
+				continue
+			}
+			identifierMatched[j]++
+			findings = append(findings, deviceIdentifierFinding(id, targetSDK, relPath, lineNum, snippetOf(trimmed)))
+		}
+
+		if matched[RuleLegacyStorage] < maxMatchesPerRule && legacyStorageRe.MatchString(line) {
+			matched[RuleLegacyStorage]++
+			findings = append(findings, legacyStorageFinding(targetSDK, relPath, lineNum, snippetOf(trimmed)))
+		}
+
+		if matched[RuleWebViewDebug] < maxMatchesPerRule && webViewDebuggingRe.MatchString(line) {
+			matched[RuleWebViewDebug]++
+			guarded := debugGuardRe.MatchString(line) || debugGuardRe.MatchString(prevCode)
+			findings = append(findings, webViewDebuggingFinding(guarded, relPath, lineNum, snippetOf(trimmed)))
+		}
+		if trimmed != "" {
+			prevCode = trimmed
+		}
+
+		if matched[RuleDevEndpoint] < maxMatchesPerRule {
+			if host, ok := devEndpointHost(line, s.endpointAllowlist); ok {
+				matched[RuleDevEndpoint]++
+				findings = append(findings, devEndpointFinding(host, relPath, lineNum, snippetOf(trimmed)))
+			}
+		}
+
+		if notificationBuildRe.MatchString(line) {
+			buildsNotification = true
+		}
+		if len(foregroundCalls) < maxMatchesPerRule && startForegroundRe.MatchString(line) {
+			foregroundCalls = append(foregroundCalls, foregroundWithoutNotification(relPath, lineNum, snippetOf(trimmed)))
+		}
+
+		if sharedPrefsRe.MatchString(line) {
+			usesPrefs = true
+		}
+		if encryptedPrefsRe.MatchString(line) {
+			usesEncryptedPrefs = true
+		}
+		if len(prefsWrites) < maxMatchesPerRule && sensitivePutRe.MatchString(line) {
+			prefsWrites = append(prefsWrites, sensitivePreferenceWrite(relPath, lineNum, snippetOf(trimmed)))
+		}
+
+		if backgroundComponentRe.MatchString(line) {
+			backgroundComponent = true
+		}
+		if newTaskFlagRe.MatchString(line) {
+			newTaskFlag = true
+		}
+		if len(activityLaunches) < maxMatchesPerRule && startActivityRe.MatchString(line) {
+			activityLaunches = append(activityLaunches, backgroundActivityLaunch(relPath, lineNum, snippetOf(trimmed)))
+		}
+
+		if piCall.open {
+			if piCall.feed(line) && piCall.missingMutability() {
+				pendingIntentKept[len(pendingIntentKept)-1] = true
+				matched[RulePendingIntent]++
+			}
+		} else if loc := pendingIntentCallRe.FindStringIndex(line); loc != nil && matched[RulePendingIntent] < maxMatchesPerRule {
+			pendingIntents = append(pendingIntents, pendingIntentWithoutMutability(relPath, lineNum, snippetOf(trimmed)))
+			pendingIntentKept = append(pendingIntentKept, false)
+			if piCall.start(line[loc[1]:]) && piCall.missingMutability() {
+				pendingIntentKept[len(pendingIntentKept)-1] = true
+				matched[RulePendingIntent]++
+			}
+		}
+
+		if matched[RuleInsecureTLS] < maxMatchesPerRule && permissiveVerifierRe.MatchString(line) {
+			matched[RuleInsecureTLS]++
+			findings = append(findings, permissiveHostnameVerifier(relPath, lineNum, snippetOf(trimmed)))
+		}
+		if tlsMethod.open {
+			if tlsMethod.feed(line) && tlsMethod.acceptsAll() {
+				tlsOverrideKept[len(tlsOverrideKept)-1] = true
+				matched[RuleInsecureTLS]++
+			}
+		} else if matched[RuleInsecureTLS] < maxMatchesPerRule {
+			trustManager := true
+			loc := checkServerTrustedRe.FindStringIndex(line)
+			if loc == nil {
+				trustManager = false
+				loc = verifyHostnameRe.FindStringIndex(line)
+			}
+			if loc != nil {
+				f := permissiveHostnameVerifier(relPath, lineNum, snippetOf(trimmed))
+				if trustManager {
+					f = trustAllCertificates(relPath, lineNum, snippetOf(trimmed))
+				}
+				tlsOverrides = append(tlsOverrides, f)
+				tlsOverrideKept = append(tlsOverrideKept, false)
+				if tlsMethod.start(line[loc[1]:], trustManager) && tlsMethod.acceptsAll() {
+					tlsOverrideKept[len(tlsOverrideKept)-1] = true
+					matched[RuleInsecureTLS]++
+				}
+			}
+		}
+
+		if from, ok := extras.feed(line, lineNum); ok && matched[RuleIntentRedirect] < maxMatchesPerRule {
+			matched[RuleIntentRedirect]++
+			findings = append(findings, intentRedirection(from, relPath, lineNum, snippetOf(trimmed)))
+		}
+
+		if checkSecure {
+			if sensitiveLayoutRe.MatchString(line) {
+				sensitiveLayout = true
+			}
+			if flagSecureRe.MatchString(line) {
+				setsFlagSecure = true
+			}
+			if m := activityClassRe.FindStringSubmatch(line); m != nil && len(secureScreens) < maxMatchesPerRule {
+				secureScreens = append(secureScreens, insecureSensitiveScreen(m[1], relPath, lineNum, snippetOf(trimmed)))
+				secureScreenNamed = append(secureScreenNamed, sensitiveScreenNameRe.MatchString(m[1]))
+			}
+		}
+
+		ctx.attach(&findings, nFindings)
+		ctx.attach(&foregroundCalls, nForeground)
+		ctx.attach(&prefsWrites, nPrefs)
+		ctx.attach(&pendingIntents, nPending)
+		ctx.attach(&activityLaunches, nLaunches)
+		ctx.attach(&secureScreens, nScreens)
+		ctx.attach(&tlsOverrides, nTLS)
+	}
+
+	if !buildsNotification {
+		findings = append(findings, foregroundCalls...)
+	}
+	if usesPrefs && !usesEncryptedPrefs {
+		findings = append(findings, prefsWrites...)
+	}
+	if backgroundComponent && newTaskFlag {
+		findings = append(findings, activityLaunches...)
+	}
+	if !setsFlagSecure {
+		for i, f := range secureScreens {
+			if secureScreenNamed[i] || sensitiveLayout {
+				findings = append(findings, f)
+			}
+		}
+	}
+	for i, f := range tlsOverrides {
+		if tlsOverrideKept[i] {
+			findings = append(findings, f)
+		}
+	}
+	for i, f := range pendingIntents {
+		if pendingIntentKept[i] {
+			findings = append(findings, f)
 		}
 	}
 
-	return findings
+	return findings, skipped
 }
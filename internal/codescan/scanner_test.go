@@ -1,10 +1,17 @@
 package codescan
 
 import (
+	"context"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
 )
 
@@ -88,6 +95,63 @@ public class Main {
 	}
 }
 
+func TestScanner_Run_HTTPInStringsXML(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"app/src/main/res/values/strings.xml": `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:tools="http://schemas.android.com/tools">
+    <!-- http://commented.example.com is ignored -->
+    <string name="app_name">Demo</string>
+    <string name="api_base_url">http://api.example.com/v1</string>
+</resources>`,
+	})
+
+	s := NewScanner()
+	result, err := s.Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var httpFindings []preflight.Finding
+	for _, f := range result.Findings {
+		if f.CheckID == RuleHTTPUsage {
+			httpFindings = append(httpFindings, f)
+		}
+	}
+	if len(httpFindings) != 1 {
+		t.Fatalf("expected 1 HTTP finding, got %d", len(httpFindings))
+	}
+	f := httpFindings[0]
+	if f.Location.File != filepath.Join("app", "src", "main", "res", "values", "strings.xml") {
+		t.Errorf("unexpected file: %s", f.Location.File)
+	}
+	if f.Location.Line != 5 {
+		t.Errorf("expected line 5, got %d", f.Location.Line)
+	}
+}
+
+func TestScanner_Run_XMLNamespacesIgnored(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"AndroidManifest.xml": `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android"
+    xmlns:tools="http://schemas.android.com/tools">
+    <application android:label="Demo">
+        <meta-data android:name="api" android:value="https://api.example.com" />
+    </application>
+</manifest>`,
+	})
+
+	s := NewScanner()
+	result, err := s.Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	for _, f := range result.Findings {
+		if f.CheckID == RuleHTTPUsage {
+			t.Errorf("unexpected HTTP finding for namespace URL: %s", f.Location)
+		}
+	}
+}
+
 func TestScanner_Run_SMSDetection(t *testing.T) {
 	dir := setupTestDir(t, map[string]string{
 		"SmsSender.java": `package com.example;
@@ -259,6 +323,43 @@ public class WebActivity {
 	}
 }
 
+func TestScanner_Run_CleartextWebView(t *testing.T) {
+	tests := []struct {
+		name string
+		call string
+		want int
+	}{
+		{"http loadUrl", `wv.loadUrl("http://example.com/help")`, 1},
+		{"http base URL", `wv.loadDataWithBaseURL("http://example.com/", html, "text/html", "utf-8", null)`, 1},
+		{"https loadUrl", `wv.loadUrl("https://example.com/help")`, 0},
+		{"https base URL", `wv.loadDataWithBaseURL("https://example.com/", html, "text/html", "utf-8", null)`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, map[string]string{
+				"Help.kt": "package com.example\nfun show(wv: WebView, html: String) {\n    " + tt.call + "\n}",
+			})
+			result, err := NewScanner().Run(dir)
+			if err != nil {
+				t.Fatalf("Run() error: %v", err)
+			}
+			var got int
+			for _, f := range result.Findings {
+				if f.CheckID != RuleCleartextWebView {
+					continue
+				}
+				got++
+				if f.Severity != preflight.SeverityError || f.Location.Line != 3 {
+					t.Errorf("unexpected finding: %s at %s", f.Severity, f.Location)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("got %d %s findings, want %d", got, RuleCleartextWebView, tt.want)
+			}
+		})
+	}
+}
+
 func TestScanner_Run_CryptoDetection(t *testing.T) {
 	dir := setupTestDir(t, map[string]string{
 		"Crypto.java": `package com.example;
@@ -290,6 +391,350 @@ public class Crypto {
 	}
 }
 
+func TestScanner_Run_RemovedAPIGetRunningTasks(t *testing.T) {
+	files := map[string]string{
+		"app/src/main/java/TaskSpy.java": `package com.example;
+public class TaskSpy {
+    void spy(ActivityManager am) {
+        List<RunningTaskInfo> tasks = am.getRunningTasks(10);
+    }
+}`,
+	}
+
+	tests := []struct {
+		name      string
+		gradle    string
+		wantSev   preflight.Severity
+		wantInMsg string
+	}{
+		{"unknown target", "", preflight.SeverityWarning, "app targets unknown"},
+		{"target above breaking level", "android { defaultConfig { targetSdk 34 } }", preflight.SeverityError, "app targets 34"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := map[string]string{}
+			for k, v := range files {
+				tf[k] = v
+			}
+			if tc.gradle != "" {
+				tf["app/build.gradle"] = tc.gradle
+			}
+			dir := setupTestDir(t, tf)
+
+			result, err := NewScanner().Run(dir)
+			if err != nil {
+				t.Fatalf("Run() error: %v", err)
+			}
+
+			var found *preflight.Finding
+			for i, f := range result.Findings {
+				if f.CheckID == RuleRemovedAPI {
+					found = &result.Findings[i]
+				}
+			}
+			if found == nil {
+				t.Fatal("expected CS015 finding for getRunningTasks")
+			}
+			if found.Severity != tc.wantSev {
+				t.Errorf("expected severity %s, got %s", tc.wantSev, found.Severity)
+			}
+			if found.Location.Line != 4 {
+				t.Errorf("expected line 4, got %d", found.Location.Line)
+			}
+			if !strings.Contains(found.Description, "API 21") || !strings.Contains(found.Description, tc.wantInMsg) {
+				t.Errorf("unexpected description: %s", found.Description)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_DeviceIdentifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		target    int
+		wantTitle string
+		wantSev   preflight.Severity
+	}{
+		{"getDeviceId unknown target", "val id = tm.getDeviceId()", 0, "TelephonyManager.getDeviceId", preflight.SeverityWarning},
+		{"getDeviceId modern target", "val id = tm.getDeviceId()", 34, "TelephonyManager.getDeviceId", preflight.SeverityCritical},
+		{"getImei modern target", "val imei = tm.getImei(0)", 34, "TelephonyManager.getImei", preflight.SeverityCritical},
+		{"getImei old target", "val imei = tm.getImei(0)", 28, "TelephonyManager.getImei", preflight.SeverityWarning},
+		{"Build.SERIAL modern target", "val serial = Build.SERIAL", 34, "Build.SERIAL", preflight.SeverityCritical},
+		{"getMacAddress", "val mac = wifiManager.connectionInfo.getMacAddress()", 34, "WifiInfo.getMacAddress", preflight.SeverityWarning},
+		{"ANDROID_ID", "val id = Settings.Secure.getString(resolver, Settings.Secure.ANDROID_ID)", 34, "Settings.Secure.ANDROID_ID", preflight.SeverityWarning},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"app/src/main/java/Ids.kt": "package com.example\nclass Ids {\n    fun read() {\n        " + tc.code + "\n    }\n}\n",
+			}
+			if tc.target > 0 {
+				files["app/build.gradle"] = fmt.Sprintf("android { defaultConfig { targetSdk %d } }", tc.target)
+			}
+			dir := setupTestDir(t, files)
+
+			result, err := NewScanner().Run(dir)
+			if err != nil {
+				t.Fatalf("Run() error: %v", err)
+			}
+
+			var found []preflight.Finding
+			for _, f := range result.Findings {
+				if f.CheckID == RuleDeviceIdentifier {
+					found = append(found, f)
+				}
+			}
+			if len(found) != 1 {
+				t.Fatalf("expected 1 CS018 finding, got %d", len(found))
+			}
+			f := found[0]
+			if !strings.HasSuffix(f.Title, tc.wantTitle) {
+				t.Errorf("expected title for %s, got %q", tc.wantTitle, f.Title)
+			}
+			if f.Severity != tc.wantSev {
+				t.Errorf("expected severity %s, got %s", tc.wantSev, f.Severity)
+			}
+			if f.Location.Line != 4 {
+				t.Errorf("expected line 4, got %d", f.Location.Line)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_SafetyNet(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"Attest.java": `package com.example;
+import com.google.android.gms.safetynet.SafetyNet;
+public class Attest {
+    void check(Context ctx, byte[] nonce) {
+        SafetyNet.getClient(ctx).attest(nonce, API_KEY);
+    }
+}`,
+	})
+
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var lines []int
+	for _, f := range result.Findings {
+		if f.CheckID == RuleSafetyNet {
+			lines = append(lines, f.Location.Line)
+			if f.Severity != preflight.SeverityWarning {
+				t.Errorf("expected warning severity, got %s", f.Severity)
+			}
+			if !strings.Contains(f.Suggestion, "Play Integrity") {
+				t.Errorf("expected migration suggestion, got %q", f.Suggestion)
+			}
+		}
+		if f.CheckID == RulePlayIntegrity {
+			t.Errorf("did not expect CS020 finding: %s", f.Description)
+		}
+	}
+	if len(lines) != 2 || lines[0] != 2 || lines[1] != 5 {
+		t.Errorf("expected CS019 findings on lines 2 and 5, got %v", lines)
+	}
+}
+
+func TestScanner_Run_PlayIntegrity(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"Integrity.kt": `package com.example
+import com.google.android.play.core.integrity.IntegrityManagerFactory
+class Integrity {
+    fun check(ctx: Context, nonce: String) {
+        val manager = IntegrityManagerFactory.create(ctx)
+        manager.requestIntegrityToken(IntegrityTokenRequest.builder().setNonce(nonce).build())
+    }
+}`,
+	})
+
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	found := 0
+	for _, f := range result.Findings {
+		switch f.CheckID {
+		case RulePlayIntegrity:
+			found++
+			if f.Severity != preflight.SeverityInfo {
+				t.Errorf("expected info severity, got %s", f.Severity)
+			}
+		case RuleSafetyNet:
+			t.Errorf("did not expect CS019 finding on line %d", f.Location.Line)
+		}
+	}
+	if found == 0 {
+		t.Error("expected CS020 (Play Integrity) finding")
+	}
+}
+
+func TestScanner_Run_ForegroundServiceNotification(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"SyncService.kt": `package com.example
+class SyncService : Service() {
+    override fun onStartCommand(intent: Intent?, flags: Int, startId: Int): Int {
+        startForeground(1, pendingNotification)
+        return START_STICKY
+    }
+}`,
+		"PlayerService.kt": `package com.example
+class PlayerService : Service() {
+    override fun onStartCommand(intent: Intent?, flags: Int, startId: Int): Int {
+        val notification = NotificationCompat.Builder(this, CHANNEL_ID)
+            .setSmallIcon(R.drawable.ic_play)
+            .build()
+        startForeground(1, notification)
+        return START_STICKY
+    }
+}`,
+	})
+
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var found []preflight.Finding
+	for _, f := range result.Findings {
+		if f.CheckID == RuleForegroundService {
+			found = append(found, f)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected 1 CS021 finding, got %d", len(found))
+	}
+	if found[0].Location.File != "SyncService.kt" || found[0].Location.Line != 4 {
+		t.Errorf("expected SyncService.kt:4, got %s:%d", found[0].Location.File, found[0].Location.Line)
+	}
+	if found[0].Severity != preflight.SeverityWarning {
+		t.Errorf("expected warning severity, got %s", found[0].Severity)
+	}
+}
+
+func TestScanner_Run_Coverage(t *testing.T) {
+	files := map[string]string{
+		"app/src/main/java/Main.kt":          "class Main",
+		"app/src/main/java/Util.java":        "class Util {}",
+		"app/src/main/res/values/colors.xml": "<resources/>",
+		"app/build/generated/Gen.kt":         "class Gen",
+		"README.md":                          "# readme",
+	}
+	dir := setupTestDir(t, files)
+
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if result.FilesScanned != 3 {
+		t.Errorf("expected 3 files scanned, got %d", result.FilesScanned)
+	}
+	wantBytes := int64(len(files["app/src/main/java/Main.kt"]) + len(files["app/src/main/java/Util.java"]) + len(files["app/src/main/res/values/colors.xml"]))
+	if result.BytesScanned != wantBytes {
+		t.Errorf("expected %d bytes scanned, got %d", wantBytes, result.BytesScanned)
+	}
+}
+
+func TestScanner_Run_FamiliesEscalatesAds(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"Ads.kt": `package com.example
+import com.google.android.gms.ads.MobileAds
+class Ads {
+    fun init(ctx: Context) { MobileAds.initialize(ctx) }
+}`,
+	})
+
+	severityOf := func(s *Scanner) preflight.Severity {
+		t.Helper()
+		result, err := s.Run(dir)
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		for _, f := range result.Findings {
+			if f.CheckID == RuleAdMob {
+				return f.Severity
+			}
+		}
+		t.Fatal("expected CS004 (AdMob) finding")
+		return 0
+	}
+
+	if got := severityOf(NewScanner()); got != preflight.SeverityWarning {
+		t.Errorf("expected warning by default, got %s", got)
+	}
+	if got := severityOf(NewScanner(WithAppCategory(preflight.CategoryFamilies))); got != preflight.SeverityCritical {
+		t.Errorf("expected critical for families category, got %s", got)
+	}
+}
+
+func TestScanner_Run_SensitiveLogging(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"Auth.kt": `package com.example
+class Auth {
+    fun onLogin(token: String) {
+        Log.d(TAG, "login ok, token=$token")
+        Log.i(TAG, "token refreshed")
+    }
+}`,
+		"Profile.java": `package com.example;
+public class Profile {
+    void save(User user) {
+        Log.w(TAG, "saving profile for " + user.email);
+        Log.d(TAG, "profile count " + count);
+    }
+}`,
+	})
+
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	lines := map[string]int{}
+	for _, f := range result.Findings {
+		if f.CheckID == RuleSensitiveLogging {
+			lines[f.Location.File] = f.Location.Line
+			if f.Severity != preflight.SeverityWarning {
+				t.Errorf("expected WARNING severity, got %s", f.Severity)
+			}
+		}
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected findings in 2 files, got %v", lines)
+	}
+	if lines["Auth.kt"] != 4 {
+		t.Errorf("expected Kotlin token leak at line 4, got %d", lines["Auth.kt"])
+	}
+	if lines["Profile.java"] != 4 {
+		t.Errorf("expected Java email leak at line 4, got %d", lines["Profile.java"])
+	}
+}
+
+func TestScanner_Run_BenignLogging(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"Main.kt": `package com.example
+class Main {
+    fun start() {
+        Log.d(TAG, "token refreshed")
+        Log.d(TAG, "items loaded: $count")
+    }
+}`,
+	})
+
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	for _, f := range result.Findings {
+		if f.CheckID == RuleSensitiveLogging {
+			t.Errorf("unexpected sensitive logging finding at %s", f.Location)
+		}
+	}
+}
+
 func TestScanner_Run_CleanProject(t *testing.T) {
 	dir := setupTestDir(t, map[string]string{
 		"Main.java": `package com.example;
@@ -495,3 +940,1314 @@ func TestCompilePattern_Caching(t *testing.T) {
 		t.Error("expected same regex object from cache")
 	}
 }
+
+func TestScanner_Run_DeterministicOrder(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 40; i++ {
+		files[fmt.Sprintf("src/File%02d.kt", i)] = "val a = \"http://a.example.com\"\nval b = \"http://b.example.com\"\n"
+	}
+	dir := setupTestDir(t, files)
+
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(result.Findings) != 80 {
+		t.Fatalf("expected 80 findings, got %d", len(result.Findings))
+	}
+	for i := 1; i < len(result.Findings); i++ {
+		prev, cur := result.Findings[i-1].Location, result.Findings[i].Location
+		if prev.File > cur.File || (prev.File == cur.File && prev.Line > cur.Line) {
+			t.Fatalf("findings not sorted by file then line: %s before %s", prev, cur)
+		}
+	}
+}
+
+// scanFilesMutex is the previous implementation of scanFiles, which appended
+// every file's findings to a shared slice under a mutex. It is kept for
+// comparison in BenchmarkScanFiles.
+func (s *Scanner) scanFilesMutex(files []string, projectDir string, targetSDK int) []preflight.Finding {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, maxConcurrency)
+		findings []preflight.Finding
+	)
+	for _, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if ff, _ := s.scanPath(path, projectDir, targetSDK); len(ff) > 0 {
+				mu.Lock()
+				findings = append(findings, ff...)
+				mu.Unlock()
+			}
+		}(file)
+	}
+	wg.Wait()
+	return findings
+}
+
+func BenchmarkScanFiles(b *testing.B) {
+	dir := b.TempDir()
+	files := make([]string, 2000)
+	for i := range files {
+		files[i] = filepath.Join(dir, fmt.Sprintf("F%04d.kt", i))
+		if err := os.WriteFile(files[i], []byte("val u = \"http://example.com\"\n"), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	s := NewScanner()
+
+	b.Run("mutex", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.scanFilesMutex(files, dir, 0)
+		}
+	})
+	b.Run("workers", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.scanFiles(context.Background(), files, dir, 0)
+		}
+	})
+}
+
+func TestRequiredLiteral(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`"http://[^"]+?"`, `"http://`},
+		{`\b(?:Standard)?IntegrityManager(?:Factory)?\b`, "IntegrityManager"},
+		{`\.getDeviceId\s*\(`, ".getDeviceId"},
+		{`(?i)signUp\s*\(`, ""},
+		{`DES/|"DES"`, ""},
+		{`\bLog\.[deivw]\s*\(`, "Log."},
+	}
+	for _, tt := range tests {
+		if got := requiredLiteral(tt.pattern); got != tt.want {
+			t.Errorf("requiredLiteral(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+// largeSourceFile returns a Kotlin source of n lines, mostly ordinary code
+// with an occasional line matching a rule.
+func largeSourceFile(n int) string {
+	block := []string{
+		"    private fun render(items: List<Item>, adapter: ItemAdapter) {",
+		"        val visible = items.filter { it.isVisible && it.title.isNotEmpty() }",
+		"        adapter.submitList(visible.sortedBy { it.position })",
+		"        binding.recyclerView.layoutManager = LinearLayoutManager(context)",
+		"        // Keep the scroll position across configuration changes.",
+		"        binding.recyclerView.scrollToPosition(state.lastPosition)",
+		"        val total = visible.sumOf { it.price * it.quantity }",
+		"        binding.totalText.text = getString(R.string.total, total)",
+		"    }",
+		"",
+	}
+	var b strings.Builder
+	b.WriteString("package com.example\n")
+	for i := 1; i < n; i++ {
+		if i%500 == 0 {
+			b.WriteString("        val url = \"http://example.com/api\"\n")
+			continue
+		}
+		b.WriteString(block[i%len(block)])
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func BenchmarkScanFile(b *testing.B) {
+	dir := b.TempDir()
+	path := filepath.Join(dir, "Large.kt")
+	if err := os.WriteFile(path, []byte(largeSourceFile(5000)), 0644); err != nil {
+		b.Fatal(err)
+	}
+	s := NewScanner()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.scanFile(path, dir, 34)
+	}
+}
+
+func TestScanner_Run_LintConfigOverlap(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"app/lint.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<lint>
+    <issue id="HardcodedText" severity="ignore" />
+    <issue id="SetJavaScriptEnabled" severity="ignore" />
+    <issue id="ExportedService" severity="warning" />
+</lint>`,
+	})
+
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var overlaps []preflight.Finding
+	for _, f := range result.Findings {
+		if f.CheckID == RuleLintOverlap {
+			overlaps = append(overlaps, f)
+		}
+	}
+	if len(overlaps) != 1 {
+		t.Fatalf("expected 1 lint overlap finding, got %d", len(overlaps))
+	}
+	f := overlaps[0]
+	if !strings.Contains(f.Title, "SetJavaScriptEnabled") || !strings.Contains(f.Description, RuleWebViewJS) {
+		t.Errorf("unexpected finding: %s / %s", f.Title, f.Description)
+	}
+	if f.Severity != preflight.SeverityInfo {
+		t.Errorf("expected INFO severity, got %s", f.Severity)
+	}
+	if f.Location.File != filepath.Join("app", "lint.xml") || f.Location.Line != 4 {
+		t.Errorf("expected app/lint.xml:4, got %s", f.Location)
+	}
+}
+
+func TestScanner_Run_LintBaselineOverlap(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"app/lint-baseline.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<issues format="6" by="lint 8.2.0">
+    <issue id="GetInstance" message="Potentially insecure random numbers">
+        <location file="src/main/java/Crypto.kt" line="10"/>
+    </issue>
+    <issue id="GetInstance" message="Potentially insecure random numbers">
+        <location file="src/main/java/Other.kt" line="3"/>
+    </issue>
+</issues>`,
+	})
+
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	count := 0
+	for _, f := range result.Findings {
+		if f.CheckID == RuleLintOverlap {
+			count++
+			if !strings.Contains(f.Title, "baselined") {
+				t.Errorf("expected baselined title, got %q", f.Title)
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected 1 deduplicated baseline overlap finding, got %d", count)
+	}
+}
+
+func TestScanFile_LongLineIsCapped(t *testing.T) {
+	// A 1 MiB line exceeds bufio.Scanner's default token size; it must still
+	// be read, and a greedy pattern must only see the first maxMatchLineLen
+	// bytes of it.
+	long := "val s = \"" + strings.Repeat("a", 1<<20) + "\" // tail-marker"
+	dir := setupTestDir(t, map[string]string{
+		"Long.kt": long + "\nval u = \"http://example.com\"\n",
+	})
+	s := NewScanner()
+	s.compiled = append(s.compiled, compileRules([]codeRule{{
+		ID:       "TEST001",
+		Title:    "Greedy",
+		Severity: preflight.SeverityInfo,
+		Patterns: []string{`a.*a.*tail-marker`},
+	}})...)
+
+	findings, skipped := s.scanFile(filepath.Join(dir, "Long.kt"), dir, 0)
+	if len(skipped) != 0 {
+		t.Errorf("expected no skipped rules with the default budget, got %v", skipped)
+	}
+	var greedy, http bool
+	for _, f := range findings {
+		switch f.CheckID {
+		case "TEST001":
+			greedy = true
+		case RuleHTTPUsage:
+			http = f.Location.Line == 2
+		}
+	}
+	if greedy {
+		t.Error("pattern matched text beyond the line length cap")
+	}
+	if !http {
+		t.Error("expected the line after the long line to be scanned")
+	}
+}
+
+func TestScanFile_RuleOverBudgetIsSkipped(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"Main.kt": strings.Repeat("val u = \"http://example.com/"+strings.Repeat("x", 2000)+"\"\n", 3),
+	})
+	s := NewScanner()
+	s.ruleBudget = 0
+
+	findings, skipped := s.scanFile(filepath.Join(dir, "Main.kt"), dir, 0)
+	if !slices.Contains(skipped, RuleHTTPUsage) {
+		t.Fatalf("expected %s to be skipped, got %v", RuleHTTPUsage, skipped)
+	}
+	for _, f := range findings {
+		if f.CheckID == RuleHTTPUsage && f.Location.Line > 1 {
+			t.Errorf("rule kept matching after exceeding its budget (line %d)", f.Location.Line)
+		}
+	}
+
+	result, err := s.Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !slices.Contains(result.SkippedRules, RuleHTTPUsage) {
+		t.Errorf("expected %s in result.SkippedRules, got %v", RuleHTTPUsage, result.SkippedRules)
+	}
+}
+
+func TestScanFile_ContextLines(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"Api.kt": `package com.example
+object Api {
+    val base = "http://example.com"
+    // trailing comment
+}`,
+	})
+	path := filepath.Join(dir, "Api.kt")
+
+	findings, _ := NewScanner().scanFile(path, dir, 0)
+	for _, f := range findings {
+		if len(f.Context) != 0 {
+			t.Errorf("expected no context by default, got %q", f.Context)
+		}
+	}
+
+	findings, _ = NewScanner(WithContextLines(1)).scanFile(path, dir, 0)
+	var http *preflight.Finding
+	for i := range findings {
+		if findings[i].CheckID == RuleHTTPUsage {
+			http = &findings[i]
+		}
+	}
+	if http == nil {
+		t.Fatal("expected an HTTP finding")
+	}
+	want := []string{"2: object Api {", "4:     // trailing comment"}
+	if !slices.Equal(http.Context, want) {
+		t.Errorf("got context %q, want %q", http.Context, want)
+	}
+}
+
+func TestScanner_Run_AppInventory(t *testing.T) {
+	source := `package com.example
+class Apps(private val pm: PackageManager) {
+    fun all() = pm.getInstalledApplications(0)
+    fun launchers() = pm.queryIntentActivities(intent, 0)
+}`
+	tests := []struct {
+		name     string
+		manifest string
+		want     preflight.Severity
+	}{
+		{
+			name:     "without QUERY_ALL_PACKAGES",
+			manifest: `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example" />`,
+			want:     preflight.SeverityWarning,
+		},
+		{
+			name: "with QUERY_ALL_PACKAGES",
+			manifest: `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <uses-permission android:name="android.permission.QUERY_ALL_PACKAGES" />
+</manifest>`,
+			want: preflight.SeverityCritical,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, map[string]string{
+				"AndroidManifest.xml": tt.manifest,
+				"Apps.kt":             source,
+			})
+			result, err := NewScanner().Run(dir)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var count int
+			for _, f := range result.Findings {
+				if f.CheckID != RuleAppInventory {
+					continue
+				}
+				count++
+				if f.Severity != tt.want {
+					t.Errorf("line %d: got severity %s, want %s", f.Location.Line, f.Severity, tt.want)
+				}
+			}
+			if count != 2 {
+				t.Errorf("got %d %s findings, want 2", count, RuleAppInventory)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_PolicySeverityComputedRule(t *testing.T) {
+	files := map[string]string{
+		"app/build.gradle":         "android { defaultConfig { targetSdk 34 } }",
+		"app/src/main/java/Ids.kt": "package com.example\nclass Ids {\n    fun read() {\n        val id = tm.getDeviceId()\n    }\n}\n",
+	}
+	tests := []struct {
+		name     string
+		severity string
+		want     preflight.Severity
+	}{
+		// The default leaves the escalation for the target SDK in place.
+		{"default", "WARNING", preflight.SeverityCritical},
+		{"changed", "INFO", preflight.SeverityInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := policies.Parse([]byte(`{"version": "test", "rules": [
+				{"id": "CS018", "name": "Device ID", "severity": "` + tt.severity + `", "category": "code_scanning",
+					"detection_patterns": [{"type": "code_pattern", "value": "getDeviceId"}]}
+			]}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := NewScanner(WithPolicies(db)).Run(setupTestDir(t, files))
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var found bool
+			for _, f := range result.Findings {
+				if f.CheckID != RuleDeviceIdentifier {
+					continue
+				}
+				found = true
+				if f.Severity != tt.want {
+					t.Errorf("got severity %s, want %s", f.Severity, tt.want)
+				}
+			}
+			if !found {
+				t.Errorf("expected a %s finding", RuleDeviceIdentifier)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_PolicyOverrides(t *testing.T) {
+	db, err := policies.Parse([]byte(`{"version": "test", "rules": [
+		{"id": "CS001", "name": "HTTP", "severity": "INFO", "category": "security",
+			"detection_patterns": [{"type": "code_pattern", "value": "http://"}]},
+		{"id": "CS010", "name": "Camera", "severity": "WARNING", "category": "security", "enabled": false,
+			"detection_patterns": [{"type": "code_pattern", "value": "Camera.open"}]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := setupTestDir(t, map[string]string{
+		"Main.kt": `val u = "http://example.com"
+val cm = getSystemService(CameraManager::class.java)`,
+	})
+
+	result, err := NewScanner(WithPolicies(db)).Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var http bool
+	for _, f := range result.Findings {
+		switch f.CheckID {
+		case RuleHTTPUsage:
+			http = true
+			if f.Severity != preflight.SeverityInfo {
+				t.Errorf("expected CS001 severity from the policy database (INFO), got %s", f.Severity)
+			}
+		case RuleCameraUsage:
+			t.Error("expected no findings for CS010, which is disabled in the policy database")
+		}
+	}
+	if !http {
+		t.Error("expected a CS001 finding")
+	}
+
+	// Without a database the hardcoded defaults apply.
+	result, err = NewScanner(WithPolicies(nil)).Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var camera bool
+	for _, f := range result.Findings {
+		if f.CheckID == RuleHTTPUsage && f.Severity != preflight.SeverityError {
+			t.Errorf("expected default CS001 severity ERROR, got %s", f.Severity)
+		}
+		camera = camera || f.CheckID == RuleCameraUsage
+	}
+	if !camera {
+		t.Error("expected a CS010 finding with the default rules")
+	}
+}
+
+func TestScanner_Run_CellInfo(t *testing.T) {
+	source := `package com.example
+class Network(private val tm: TelephonyManager) {
+    fun cells() = tm.getAllCellInfo()
+    fun carrier() = tm.getNetworkOperatorName()
+}`
+	tests := []struct {
+		name     string
+		manifest string
+		want     preflight.Severity
+	}{
+		{
+			name: "with location permission",
+			manifest: `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <uses-permission android:name="android.permission.ACCESS_FINE_LOCATION" />
+</manifest>`,
+			want: preflight.SeverityWarning,
+		},
+		{
+			name:     "without location permission",
+			manifest: `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example" />`,
+			want:     preflight.SeverityError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, map[string]string{
+				"AndroidManifest.xml": tt.manifest,
+				"Network.kt":          source,
+			})
+			result, err := NewScanner().Run(dir)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var lines []int
+			for _, f := range result.Findings {
+				if f.CheckID != RuleCellInfo {
+					continue
+				}
+				lines = append(lines, f.Location.Line)
+				if f.Severity != tt.want {
+					t.Errorf("line %d: got severity %s, want %s", f.Location.Line, f.Severity, tt.want)
+				}
+			}
+			if !slices.Equal(lines, []int{3, 4}) {
+				t.Errorf("expected %s findings on lines 3 and 4, got %v", RuleCellInfo, lines)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_CoverageResourcesOnly(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"res/values/strings.xml": `<resources><string name="api">http://example.com</string></resources>`,
+	})
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := result.Coverage.Applicable; !slices.Equal(got, []string{RuleHTTPUsage, RuleTestAdUnit}) {
+		t.Errorf("expected only %s and %s applicable without sources, got %v", RuleHTTPUsage, RuleTestAdUnit, got)
+	}
+}
+
+func TestScanner_Run_CoverageSecureScreens(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"PayActivity.kt":                  "class PayActivity : AppCompatActivity()",
+		"res/layout/activity_payment.xml": `<LinearLayout xmlns:android="http://schemas.android.com/apk/res/android" />`,
+	})
+	for _, tt := range []struct {
+		preset preflight.Preset
+		want   bool
+	}{
+		{preflight.PresetNone, false},
+		{preflight.PresetGame, false},
+		{preflight.PresetFinance, true},
+		{preflight.PresetHealth, true},
+	} {
+		result, err := NewScanner(WithPreset(tt.preset)).Run(dir)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		for _, id := range []string{RuleFlagSecure, RuleTapjacking} {
+			if got := slices.Contains(result.Coverage.Applicable, id); got != tt.want {
+				t.Errorf("preset %q: %s applicable = %v, want %v", tt.preset, id, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestScanner_Run_SensitivePreferences(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []int
+	}{
+		{
+			name: "sensitive key",
+			source: `package com.example
+class Session(ctx: Context) {
+    private val prefs = ctx.getSharedPreferences("session", Context.MODE_PRIVATE)
+    fun save(t: String) = prefs.edit().putString("auth_token", t).apply()
+}`,
+			want: []int{4},
+		},
+		{
+			name: "sensitive value",
+			source: `package com.example
+class Login(private val prefs: SharedPreferences) {
+    fun remember(user: User) = prefs.edit().putString(KEY_USER, user.password).apply()
+}`,
+			want: []int{3},
+		},
+		{
+			name: "benign settings",
+			source: `package com.example
+class Settings(ctx: Context) {
+    private val prefs = PreferenceManager.getDefaultSharedPreferences(ctx)
+    fun setTheme(theme: String) = prefs.edit().putString("theme", theme).apply()
+}`,
+		},
+		{
+			name: "encrypted preferences",
+			source: `package com.example
+class Session(ctx: Context, key: MasterKey) {
+    private val prefs = EncryptedSharedPreferences.create(ctx, "session", key, AES256_SIV, AES256_GCM)
+    fun save(t: String) = prefs.edit().putString("auth_token", t).apply()
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, map[string]string{"Prefs.kt": tt.source})
+			result, err := NewScanner().Run(dir)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var lines []int
+			for _, f := range result.Findings {
+				if f.CheckID == RuleSensitivePrefs {
+					lines = append(lines, f.Location.Line)
+				}
+			}
+			if !slices.Equal(lines, tt.want) {
+				t.Errorf("got %s findings on lines %v, want %v", RuleSensitivePrefs, lines, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_LegacyStorage(t *testing.T) {
+	source := `package com.example;
+public class Export {
+    void save() {
+        File dir = Environment.getExternalStorageDirectory();
+        File pics = Environment.getExternalStoragePublicDirectory(Environment.DIRECTORY_PICTURES);
+        File raw = new File("/sdcard/Download/report.csv");
+        File own = context.getExternalFilesDir(null);
+    }
+}`
+	tests := []struct {
+		name      string
+		targetSDK string
+		want      preflight.Severity
+	}{
+		{name: "scoped storage target", targetSDK: "34", want: preflight.SeverityWarning},
+		{name: "legacy target", targetSDK: "28", want: preflight.SeverityInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, map[string]string{
+				"AndroidManifest.xml": `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <uses-sdk android:targetSdkVersion="` + tt.targetSDK + `" />
+</manifest>`,
+				"Export.java": source,
+			})
+			result, err := NewScanner().Run(dir)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var lines []int
+			for _, f := range result.Findings {
+				if f.CheckID != RuleLegacyStorage {
+					continue
+				}
+				lines = append(lines, f.Location.Line)
+				if f.Severity != tt.want {
+					t.Errorf("line %d: got severity %s, want %s", f.Location.Line, f.Severity, tt.want)
+				}
+			}
+			if !slices.Equal(lines, []int{4, 5, 6}) {
+				t.Errorf("expected %s findings on lines 4-6, got %v", RuleLegacyStorage, lines)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_PendingIntentMutability(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"Alarms.kt": `package com.example
+class Alarms(private val ctx: Context) {
+    fun schedule() {
+        val pi = PendingIntent.getBroadcast(
+            ctx,
+            0,
+            Intent("com.example.ALARM"),
+            PendingIntent.FLAG_UPDATE_CURRENT
+        )
+        val open = PendingIntent.getActivity(ctx, 0, Intent(ctx, Main::class.java), 0)
+        val safe = PendingIntent.getService(
+            ctx, 0, Intent(ctx, Sync::class.java),
+            PendingIntent.FLAG_UPDATE_CURRENT or PendingIntent.FLAG_IMMUTABLE
+        )
+        val reply = PendingIntent.getBroadcast(ctx, 1, Intent(ctx, Reply::class.java), PendingIntent.FLAG_MUTABLE)
+        val dynamic = PendingIntent.getActivity(ctx, 2, Intent(ctx, Main::class.java), flags)
+    }
+}`,
+	})
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var lines []int
+	for _, f := range result.Findings {
+		if f.CheckID == RulePendingIntent {
+			lines = append(lines, f.Location.Line)
+			if f.Severity != preflight.SeverityWarning {
+				t.Errorf("line %d: got severity %s, want %s", f.Location.Line, f.Severity, preflight.SeverityWarning)
+			}
+		}
+	}
+	if !slices.Equal(lines, []int{4, 10}) {
+		t.Errorf("expected %s findings on lines 4 and 10, got %v", RulePendingIntent, lines)
+	}
+}
+
+func TestScanner_Run_BackgroundActivityLaunch(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []int
+	}{
+		{
+			name: "receiver starts activity",
+			source: `package com.example
+class AlarmReceiver : BroadcastReceiver() {
+    override fun onReceive(context: Context, intent: Intent) {
+        val launch = Intent(context, AlarmActivity::class.java)
+        launch.addFlags(Intent.FLAG_ACTIVITY_NEW_TASK)
+        context.startActivity(launch)
+    }
+}`,
+			want: []int{6},
+		},
+		{
+			name: "service with constructor",
+			source: `package com.example
+class SyncService(private val repo: Repo = Repo()) : LifecycleService() {
+    fun wake(context: Context) {
+        context.startActivity(Intent(context, Main::class.java).addFlags(Intent.FLAG_ACTIVITY_NEW_TASK))
+    }
+}`,
+			want: []int{4},
+		},
+		{
+			name: "java receiver",
+			source: `package com.example;
+public class BootReceiver extends BroadcastReceiver {
+    public void onReceive(Context context, Intent intent) {
+        context.startActivity(new Intent(context, Main.class).addFlags(Intent.FLAG_ACTIVITY_NEW_TASK));
+    }
+}`,
+			want: []int{4},
+		},
+		{
+			name: "service typed constructor parameter",
+			source: `package com.example
+class Repo(private val api: UserService) {
+    fun open(context: Context) {
+        context.startActivity(Intent(context, Main::class.java).addFlags(Intent.FLAG_ACTIVITY_NEW_TASK))
+    }
+}`,
+		},
+		{
+			name: "activity starts activity",
+			source: `package com.example
+class MainActivity : AppCompatActivity() {
+    fun open() {
+        startActivity(Intent(this, Detail::class.java).addFlags(Intent.FLAG_ACTIVITY_NEW_TASK))
+    }
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, map[string]string{"Component.kt": tt.source})
+			result, err := NewScanner().Run(dir)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var lines []int
+			for _, f := range result.Findings {
+				if f.CheckID == RuleBackgroundLaunch {
+					lines = append(lines, f.Location.Line)
+				}
+			}
+			if !slices.Equal(lines, tt.want) {
+				t.Errorf("expected %s findings on lines %v, got %v", RuleBackgroundLaunch, tt.want, lines)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_DevEndpoints(t *testing.T) {
+	files := map[string]string{
+		"Api.kt": `package com.example
+object Api {
+    const val EMULATOR = "http://10.0.2.2:8080/api"
+    const val STAGING = "https://api.staging.example.com/v1"
+    const val PROD = "https://api.example.com/v1"
+    const val DOCS = "https://developer.android.com/guide"
+    const val QA = "https://dev.partner.io/hooks"
+}`,
+	}
+	tests := []struct {
+		name  string
+		opts  []Option
+		lines []int
+	}{
+		{name: "default", lines: []int{3, 4, 7}},
+		{name: "allowlisted partner", opts: []Option{WithEndpointAllowlist("partner.io")}, lines: []int{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, files)
+			result, err := NewScanner(tt.opts...).Run(dir)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var lines []int
+			for _, f := range result.Findings {
+				if f.CheckID == RuleDevEndpoint {
+					lines = append(lines, f.Location.Line)
+					if f.Severity != preflight.SeverityWarning {
+						t.Errorf("line %d: got severity %s, want %s", f.Location.Line, f.Severity, preflight.SeverityWarning)
+					}
+				}
+			}
+			if !slices.Equal(lines, tt.lines) {
+				t.Errorf("expected %s findings on lines %v, got %v", RuleDevEndpoint, tt.lines, lines)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_FlagSecure(t *testing.T) {
+	payment := `package com.example
+class PaymentActivity : AppCompatActivity() {
+    override fun onCreate(savedInstanceState: Bundle?) {
+        super.onCreate(savedInstanceState)
+        setContentView(R.layout.activity_payment)
+    }
+}`
+	tests := []struct {
+		name  string
+		files map[string]string
+		opts  []Option
+		want  int
+	}{
+		{name: "payment activity, finance preset", files: map[string]string{"PaymentActivity.kt": payment}, opts: []Option{WithPreset(preflight.PresetFinance)}, want: 1},
+		{name: "payment activity, no preset", files: map[string]string{"PaymentActivity.kt": payment}, want: 0},
+		{name: "payment activity, game preset", files: map[string]string{"PaymentActivity.kt": payment}, opts: []Option{WithPreset(preflight.PresetGame)}, want: 0},
+		{
+			name: "sensitive layout, health preset",
+			files: map[string]string{"SummaryActivity.java": `package com.example;
+public class SummaryActivity extends Activity {
+    protected void onCreate(Bundle b) {
+        setContentView(R.layout.health_summary);
+    }
+}`},
+			opts: []Option{WithPreset(preflight.PresetHealth)},
+			want: 1,
+		},
+		{
+			name: "FLAG_SECURE set",
+			files: map[string]string{"PaymentActivity.kt": `package com.example
+class PaymentActivity : AppCompatActivity() {
+    override fun onCreate(savedInstanceState: Bundle?) {
+        window.setFlags(WindowManager.LayoutParams.FLAG_SECURE, WindowManager.LayoutParams.FLAG_SECURE)
+        setContentView(R.layout.activity_payment)
+    }
+}`},
+			opts: []Option{WithPreset(preflight.PresetFinance)},
+			want: 0,
+		},
+		{
+			name: "unrelated activity",
+			files: map[string]string{"MainActivity.kt": `package com.example
+class MainActivity : AppCompatActivity() {
+    override fun onCreate(savedInstanceState: Bundle?) {
+        setContentView(R.layout.activity_main)
+    }
+}`},
+			opts: []Option{WithPreset(preflight.PresetFinance)},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, tt.files)
+			result, err := NewScanner(tt.opts...).Run(dir)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var got []preflight.Finding
+			for _, f := range result.Findings {
+				if f.CheckID == RuleFlagSecure {
+					got = append(got, f)
+				}
+			}
+			if len(got) != tt.want {
+				t.Fatalf("expected %d %s findings, got %d: %+v", tt.want, RuleFlagSecure, len(got), got)
+			}
+			if tt.want > 0 && got[0].Location.Line != 2 {
+				t.Errorf("expected finding on the class declaration (line 2), got line %d", got[0].Location.Line)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_Tapjacking(t *testing.T) {
+	unprotected := `<?xml version="1.0" encoding="utf-8"?>
+<LinearLayout xmlns:android="http://schemas.android.com/apk/res/android"
+    android:orientation="vertical">
+    <EditText
+        android:id="@+id/username"
+        android:inputType="text" />
+    <EditText
+        android:id="@+id/password"
+        android:inputType="textPassword" />
+</LinearLayout>`
+	protected := `<?xml version="1.0" encoding="utf-8"?>
+<LinearLayout xmlns:android="http://schemas.android.com/apk/res/android"
+    android:filterTouchesWhenObscured="true">
+    <EditText
+        android:id="@+id/password"
+        android:inputType="textPassword" />
+</LinearLayout>`
+	noPassword := `<?xml version="1.0" encoding="utf-8"?>
+<FrameLayout xmlns:android="http://schemas.android.com/apk/res/android">
+    <TextView android:text="@string/hello" />
+</FrameLayout>`
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		opts  []Option
+		want  []string
+	}{
+		{
+			name:  "password layout without attribute, finance preset",
+			files: map[string]string{"app/src/main/res/layout/activity_login.xml": unprotected},
+			opts:  []Option{WithPreset(preflight.PresetFinance)},
+			want:  []string{"app/src/main/res/layout/activity_login.xml:7"},
+		},
+		{
+			name:  "password layout with attribute on root view",
+			files: map[string]string{"app/src/main/res/layout/activity_login.xml": protected},
+			opts:  []Option{WithPreset(preflight.PresetFinance)},
+		},
+		{
+			name:  "layout without password field",
+			files: map[string]string{"app/src/main/res/layout-land/activity_main.xml": noPassword},
+			opts:  []Option{WithPreset(preflight.PresetFinance)},
+		},
+		{
+			name:  "password layout, no preset",
+			files: map[string]string{"app/src/main/res/layout/activity_login.xml": unprotected},
+		},
+		{
+			name:  "password field outside a layout directory",
+			files: map[string]string{"app/src/main/res/values/activity_login.xml": unprotected},
+			opts:  []Option{WithPreset(preflight.PresetFinance)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, tt.files)
+			result, err := NewScanner(tt.opts...).Run(dir)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var got []string
+			for _, f := range result.Findings {
+				if f.CheckID == RuleTapjacking {
+					got = append(got, f.Location.String())
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %s findings at %v, got %v", RuleTapjacking, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_InsecureTLS(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"TrustAll.java": `package com.example;
+public class TrustAll implements X509TrustManager {
+    @Override
+    public void checkClientTrusted(X509Certificate[] chain, String authType) {}
+
+    @Override
+    public void checkServerTrusted(X509Certificate[] chain, String authType)
+            throws CertificateException {
+        // trust everything
+    }
+
+    @Override
+    public X509Certificate[] getAcceptedIssuers() { return new X509Certificate[0]; }
+}`,
+		"Pinned.kt": `package com.example
+class Pinned(private val delegate: X509TrustManager) : X509TrustManager {
+    override fun checkServerTrusted(chain: Array<X509Certificate>, authType: String) {
+        delegate.checkServerTrusted(chain, authType)
+        if (!pins.contains(chain[0].publicKey)) throw CertificateException("pin mismatch")
+    }
+}`,
+		"Client.kt": `package com.example
+object Client {
+    val loose = OkHttpClient.Builder()
+        .hostnameVerifier { _, _ -> true }
+        .build()
+    val apache = SSLSocketFactory.ALLOW_ALL_HOSTNAME_VERIFIER
+    val strict = OkHttpClient.Builder().build()
+}`,
+		"Verifier.java": `package com.example;
+public class Verifier implements HostnameVerifier {
+    @Override
+    public boolean verify(String hostname, SSLSession session) {
+        return true;
+    }
+}`,
+	})
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var got []string
+	for _, f := range result.Findings {
+		if f.CheckID != RuleInsecureTLS {
+			continue
+		}
+		got = append(got, f.Location.String())
+		if f.Severity != preflight.SeverityCritical {
+			t.Errorf("%s: got severity %s, want %s", f.Location, f.Severity, preflight.SeverityCritical)
+		}
+	}
+	slices.Sort(got)
+	want := []string{"Client.kt:4", "Client.kt:6", "TrustAll.java:7", "Verifier.java:4"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %s findings at %v, got %v", RuleInsecureTLS, want, got)
+	}
+}
+
+func TestScanner_Run_ProguardRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  string
+		titles []string
+	}{
+		{
+			name:   "keep everything",
+			rules:  "-keep class ** { *; }\n",
+			titles: []string{"Over-broad ProGuard keep rule"},
+		},
+		{
+			name:   "keep top-level package",
+			rules:  "-keepclassmembers,allowoptimization class com.** { *; }\n",
+			titles: []string{"Over-broad ProGuard keep rule"},
+		},
+		{
+			name:  "targeted keep",
+			rules: "# Gson models\n-keep class com.example.app.model.** { *; }\n-keep public class * extends android.app.Activity\n-keep @androidx.annotation.Keep class *\n-keepclassmembers class * { @javax.inject.Inject <init>(...); }\n-keepattributes *Annotation*\n",
+		},
+		{
+			name:   "shrinker disabled",
+			rules:  "-dontobfuscate\n-dontshrink # debugging\n",
+			titles: []string{"R8 obfuscation disabled", "R8 shrinking disabled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestDir(t, map[string]string{"app/proguard-rules.pro": tt.rules})
+			result, err := NewScanner().Run(dir)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			var titles []string
+			for _, f := range result.Findings {
+				if f.CheckID == RuleShrinkerConfig {
+					titles = append(titles, f.Title)
+				}
+			}
+			if !slices.Equal(titles, tt.titles) {
+				t.Errorf("expected %s findings %q, got %q", RuleShrinkerConfig, tt.titles, titles)
+			}
+			if !slices.Contains(result.Coverage.Applicable, RuleShrinkerConfig) {
+				t.Errorf("expected %s applicable with a rules file, got %v", RuleShrinkerConfig, result.Coverage.Applicable)
+			}
+		})
+	}
+}
+
+func TestScanner_Run_TestAdUnitIDs(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"app/src/main/java/Ads.kt": `package com.example
+object Ads {
+    const val BANNER = "ca-app-pub-3940256099942544/6300978111"
+    const val REAL = "ca-app-pub-1234567890123456/1234567890"
+}`,
+		"app/src/main/res/values/ads.xml": `<resources>
+    <string name="interstitial_id">ca-app-pub-3940256099942544/1033173712</string>
+</resources>`,
+		"app/src/main/AndroidManifest.xml": `<manifest xmlns:android="http://schemas.android.com/apk/res/android">
+    <application>
+        <meta-data android:name="com.google.android.gms.ads.APPLICATION_ID"
+            android:value="ca-app-pub-3940256099942544~3347511713" />
+    </application>
+</manifest>`,
+	})
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var got []string
+	for _, f := range result.Findings {
+		if f.CheckID == RuleTestAdUnit {
+			got = append(got, f.Location.String())
+			if f.Severity != preflight.SeverityWarning {
+				t.Errorf("%s: got severity %s, want %s", f.Location, f.Severity, preflight.SeverityWarning)
+			}
+		}
+	}
+	slices.Sort(got)
+	want := []string{"app/src/main/AndroidManifest.xml:4", "app/src/main/java/Ads.kt:3", "app/src/main/res/values/ads.xml:2"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %s findings at %v, got %v", RuleTestAdUnit, want, got)
+	}
+}
+
+func TestScanner_Run_DeprecatedTLS(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"app/src/main/java/Legacy.java": `package com.example;
+class Legacy {
+    SSLContext legacy() throws Exception {
+        return SSLContext.getInstance("SSLv3");
+    }
+    SSLContext modern() throws Exception {
+        return SSLContext.getInstance("TLSv1.2");
+    }
+    void protocols(SSLSocket socket) {
+        socket.setEnabledProtocols(new String[] {"TLSv1", "TLSv1.2"});
+        socket.setEnabledProtocols(new String[] {"TLSv1.2", "TLSv1.3"});
+    }
+}`,
+	})
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var got []int
+	for _, f := range result.Findings {
+		if f.CheckID == RuleDeprecatedTLS {
+			got = append(got, f.Location.Line)
+			if f.Severity != preflight.SeverityWarning {
+				t.Errorf("line %d: got severity %s, want %s", f.Location.Line, f.Severity, preflight.SeverityWarning)
+			}
+		}
+	}
+	slices.Sort(got)
+	if want := []int{4, 10}; !slices.Equal(got, want) {
+		t.Errorf("expected %s findings on lines %v, got %v", RuleDeprecatedTLS, want, got)
+	}
+}
+
+func TestScanner_Run_AsyncTask(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"app/src/main/java/Loader.java": `package com.example;
+import android.os.AsyncTask;
+class Loader extends AsyncTask<String, Void, String> {
+    protected String doInBackground(String... urls) {
+        return fetch(urls[0]);
+    }
+}`,
+		"app/src/main/java/Worker.kt": `package com.example
+class Worker {
+    fun load() = viewModelScope.launch { fetch() }
+}`,
+	})
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var got []string
+	for _, f := range result.Findings {
+		if f.CheckID == RuleAsyncTask {
+			got = append(got, f.Location.String())
+			if f.Severity != preflight.SeverityInfo {
+				t.Errorf("%s: got severity %s, want %s", f.Location, f.Severity, preflight.SeverityInfo)
+			}
+		}
+	}
+	slices.Sort(got)
+	if want := []string{"app/src/main/java/Loader.java:2", "app/src/main/java/Loader.java:3"}; !slices.Equal(got, want) {
+		t.Errorf("expected %s findings at %v, got %v", RuleAsyncTask, want, got)
+	}
+}
+
+func TestScanner_Run_GCM(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"app/src/main/java/Push.java": `package com.example;
+import com.google.android.gms.gcm.GoogleCloudMessaging;
+class Push {
+    String register(Context ctx) throws IOException {
+        return GoogleCloudMessaging.getInstance(ctx).register(SENDER_ID);
+    }
+}`,
+		"app/src/main/java/Listener.kt": `package com.example
+class Listener : GcmListenerService()`,
+		"app/src/main/java/Messaging.kt": `package com.example
+import com.google.firebase.messaging.FirebaseMessagingService
+class Messaging : FirebaseMessagingService()`,
+	})
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var got []string
+	for _, f := range result.Findings {
+		if f.CheckID == RuleGCM {
+			got = append(got, f.Location.String())
+			if f.Severity != preflight.SeverityError {
+				t.Errorf("%s: got severity %s, want %s", f.Location, f.Severity, preflight.SeverityError)
+			}
+		}
+	}
+	slices.Sort(got)
+	want := []string{"app/src/main/java/Listener.kt:2", "app/src/main/java/Push.java:2", "app/src/main/java/Push.java:5"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %s findings at %v, got %v", RuleGCM, want, got)
+	}
+}
+
+func TestScanner_Run_IntentRedirection(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"app/src/main/java/ForwardActivity.java": `package com.example;
+public class ForwardActivity extends Activity {
+    @Override
+    protected void onCreate(Bundle savedInstanceState) {
+        super.onCreate(savedInstanceState);
+        Intent forward = getIntent().getParcelableExtra("next");
+        if (forward != null) {
+            startActivity(forward);
+        }
+        setResult(RESULT_OK, forward);
+    }
+}`,
+		"app/src/main/java/Proxy.kt": `package com.example
+class Proxy : Activity() {
+    override fun onCreate(savedInstanceState: Bundle?) {
+        super.onCreate(savedInstanceState)
+        intent.getParcelableExtra<Intent>("next")?.let { startActivity(it) }
+    }
+}`,
+		"app/src/main/java/Relay.java": `package com.example;
+public class Relay extends Activity {
+    @Override
+    protected void onCreate(Bundle savedInstanceState) {
+        super.onCreate(savedInstanceState);
+        startActivity(getIntent().getParcelableExtra("next"));
+    }
+}`,
+		"app/src/main/java/Details.kt": `package com.example
+class Details : Activity() {
+    override fun onCreate(savedInstanceState: Bundle?) {
+        super.onCreate(savedInstanceState)
+        startActivity(Intent(this, DetailActivity::class.java).putExtra("item", intent.getParcelableExtra<Item>("item")))
+    }
+}`,
+		"app/src/main/java/Safe.kt": `package com.example
+class Safe : Activity() {
+    override fun onCreate(savedInstanceState: Bundle?) {
+        super.onCreate(savedInstanceState)
+        val user: User? = intent.getParcelableExtra("user")
+        val details = Intent(this, DetailActivity::class.java)
+        startActivity(details)
+    }
+}`,
+	})
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var got []string
+	for _, f := range result.Findings {
+		if f.CheckID == RuleIntentRedirect {
+			got = append(got, f.Location.String())
+			if f.Severity != preflight.SeverityWarning {
+				t.Errorf("%s: got severity %s, want %s", f.Location, f.Severity, preflight.SeverityWarning)
+			}
+		}
+	}
+	slices.Sort(got)
+	want := []string{"app/src/main/java/ForwardActivity.java:10", "app/src/main/java/ForwardActivity.java:8", "app/src/main/java/Proxy.kt:5", "app/src/main/java/Relay.java:6"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %s findings at %v, got %v", RuleIntentRedirect, want, got)
+	}
+}
+
+func TestScanner_Run_WebViewDebugging(t *testing.T) {
+	dir := setupTestDir(t, map[string]string{
+		"app/src/main/java/App.java": `package com.example;
+public class App extends Application {
+    @Override
+    public void onCreate() {
+        super.onCreate();
+        WebView.setWebContentsDebuggingEnabled(true);
+    }
+}`,
+		"app/src/main/java/DebugApp.kt": `package com.example
+class DebugApp : Application() {
+    override fun onCreate() {
+        super.onCreate()
+        if (BuildConfig.DEBUG) {
+            WebView.setWebContentsDebuggingEnabled(true)
+        }
+        if (BuildConfig.DEBUG) WebView.setWebContentsDebuggingEnabled(true)
+    }
+}`,
+		"app/src/main/java/ReleaseApp.kt": `package com.example
+class ReleaseApp : Application() {
+    override fun onCreate() {
+        super.onCreate()
+        WebView.setWebContentsDebuggingEnabled(BuildConfig.DEBUG)
+    }
+}`,
+	})
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	got := make(map[string]preflight.Severity)
+	for _, f := range result.Findings {
+		if f.CheckID == RuleWebViewDebug {
+			got[f.Location.String()] = f.Severity
+		}
+	}
+	want := map[string]preflight.Severity{
+		"app/src/main/java/App.java:6":    preflight.SeverityError,
+		"app/src/main/java/DebugApp.kt:6": preflight.SeverityInfo,
+		"app/src/main/java/DebugApp.kt:8": preflight.SeverityInfo,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("expected %s findings %v, got %v", RuleWebViewDebug, want, got)
+	}
+}
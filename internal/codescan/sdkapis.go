@@ -0,0 +1,83 @@
+package codescan
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// sdkBoundAPI describes an API that was removed or changed behavior for apps
+// targeting a given SDK level.
+type sdkBoundAPI struct {
+	API      string
+	Pattern  *regexp.Regexp
+	BreaksAt int    // first targetSdk at which the API is removed or changes behavior
+	Change   string // what happens at that level
+	Fix      string
+}
+
+// sdkBoundAPIs lists APIs that commonly break when bumping targetSdk.
+var sdkBoundAPIs = []sdkBoundAPI{
+	{
+		API:      "ActivityManager.getRunningTasks",
+		Pattern:  regexp.MustCompile(`\.getRunningTasks\s*\(`),
+		BreaksAt: 21,
+		Change:   "only returns the caller's own tasks",
+		Fix:      "Use ActivityManager.getAppTasks() for your own tasks; other apps' tasks are no longer visible.",
+	},
+	{
+		API:      "ActivityManager.getRecentTasks",
+		Pattern:  regexp.MustCompile(`\.getRecentTasks\s*\(`),
+		BreaksAt: 21,
+		Change:   "only returns the caller's own tasks",
+		Fix:      "Use ActivityManager.getAppTasks() for your own tasks; other apps' tasks are no longer visible.",
+	},
+	{
+		API:      "WifiManager.setWifiEnabled",
+		Pattern:  regexp.MustCompile(`\.setWifiEnabled\s*\(`),
+		BreaksAt: 29,
+		Change:   "is a no-op that always returns false",
+		Fix:      "Prompt the user with the Settings.Panel.ACTION_WIFI settings panel instead.",
+	},
+	{
+		API:      "Intent.ACTION_CLOSE_SYSTEM_DIALOGS",
+		Pattern:  regexp.MustCompile(`\bACTION_CLOSE_SYSTEM_DIALOGS\b`),
+		BreaksAt: 31,
+		Change:   "throws SecurityException",
+		Fix:      "Remove the broadcast; the system closes dialogs itself when launching activities from notifications.",
+	},
+	{
+		API:      "BluetoothAdapter.enable/disable",
+		Pattern:  regexp.MustCompile(`BluetoothAdapter[\w.()]*\.(?:enable|disable)\s*\(\s*\)`),
+		BreaksAt: 33,
+		Change:   "is a no-op that always returns false",
+		Fix:      "Ask the user to turn Bluetooth on with BluetoothAdapter.ACTION_REQUEST_ENABLE.",
+	},
+}
+
+// sdkBoundAPIFinding builds the finding for an SDK-bound API match. Usage is
+// a warning, escalated to an error once the app's target SDK is at or above
+// the level where the API breaks. targetSDK is 0 when unknown.
+func sdkBoundAPIFinding(api sdkBoundAPI, targetSDK int, relPath string, line int, snippet string) preflight.Finding {
+	severity := preflight.SeverityWarning
+	target := "unknown"
+	if targetSDK > 0 {
+		target = fmt.Sprintf("%d", targetSDK)
+		if targetSDK >= api.BreaksAt {
+			severity = preflight.SeverityError
+		}
+	}
+	return preflight.Finding{
+		CheckID: RuleRemovedAPI,
+		Title:   fmt.Sprintf("%s breaks at targetSdk %d", api.API, api.BreaksAt),
+		Description: fmt.Sprintf("%s %s for apps targeting API %d or higher (app targets %s).\n  Code: %s",
+			api.API, api.Change, api.BreaksAt, target, snippet),
+		Severity: severity,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: api.Fix,
+	}
+}
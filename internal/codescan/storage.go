@@ -0,0 +1,38 @@
+package codescan
+
+import (
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// scopedStorageSDK is the first targetSdk at which apps get scoped storage
+// and lose direct file path access to shared external storage.
+const scopedStorageSDK = 29
+
+// legacyStorageRe matches direct file path access to shared external storage:
+// the deprecated Environment accessors and hardcoded /sdcard paths.
+var legacyStorageRe = regexp.MustCompile(`\bgetExternalStorage(?:Public)?Directory\s*\(|["']/sdcard(?:/|["'])`)
+
+// legacyStorageFinding builds the finding for direct external storage path
+// access. It is informational while the app targets an SDK below 29 and a
+// warning once scoped storage applies. targetSDK is 0 when unknown.
+func legacyStorageFinding(targetSDK int, relPath string, line int, snippet string) preflight.Finding {
+	severity := preflight.SeverityInfo
+	desc := "The app accesses shared external storage through a file path. Environment.getExternalStorageDirectory and getExternalStoragePublicDirectory are deprecated, and apps targeting API 29 or higher use scoped storage, where these paths are not readable or writable without broad storage access that Play restricts."
+	if targetSDK >= scopedStorageSDK {
+		severity = preflight.SeverityWarning
+		desc += " The app targets API 29 or higher, so this access fails on Android 10 and later (requestLegacyExternalStorage is ignored from API 30)."
+	}
+	return preflight.Finding{
+		CheckID:     RuleLegacyStorage,
+		Title:       "Legacy external storage path access",
+		Description: desc + "\n  Code: " + snippet,
+		Severity:    severity,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Save and read shared media through MediaStore, let the user pick documents with the Storage Access Framework (ACTION_OPEN_DOCUMENT / ACTION_CREATE_DOCUMENT), or use app-specific storage from Context.getExternalFilesDir.",
+	}
+}
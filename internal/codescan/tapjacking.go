@@ -0,0 +1,101 @@
+package codescan
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// isLayoutFile reports whether path is a layout resource, i.e. an XML file
+// in res/layout or a qualified variant such as res/layout-land.
+func isLayoutFile(path string) bool {
+	dir := filepath.Base(filepath.Dir(path))
+	return strings.EqualFold(filepath.Ext(path), ".xml") && (dir == "layout" || strings.HasPrefix(dir, "layout-"))
+}
+
+// scanLayoutFile reports a layout with a password field when neither its root
+// view nor the field sets android:filterTouchesWhenObscured, so touches are
+// still delivered while another app's window covers the screen.
+func scanLayoutFile(filePath, projectDir string) []preflight.Finding {
+	data, err := utils.ReadFileWithLimit(filePath)
+	if err != nil {
+		return nil
+	}
+	relPath, err := filepath.Rel(projectDir, filePath)
+	if err != nil {
+		relPath = filePath
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root := true
+	for {
+		offset := decoder.InputOffset()
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil // io.EOF or malformed XML
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root {
+			root = false
+			if filtersObscuredTouches(start) {
+				return nil
+			}
+		}
+		if isPasswordField(start) && !filtersObscuredTouches(start) {
+			line := bytes.Count(data[:offset], []byte("\n")) + 1
+			return []preflight.Finding{tapjackingFinding(start.Name.Local, relPath, line)}
+		}
+	}
+}
+
+// filtersObscuredTouches reports whether the element sets
+// android:filterTouchesWhenObscured="true".
+func filtersObscuredTouches(e xml.StartElement) bool {
+	for _, attr := range e.Attr {
+		if attr.Name.Local == "filterTouchesWhenObscured" && attr.Value == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// isPasswordField reports whether the element is a text field for a
+// password or PIN, by its inputType or the deprecated android:password.
+func isPasswordField(e xml.StartElement) bool {
+	for _, attr := range e.Attr {
+		switch attr.Name.Local {
+		case "inputType":
+			if strings.Contains(strings.ToLower(attr.Value), "password") {
+				return true
+			}
+		case "password":
+			if attr.Value == "true" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tapjackingFinding builds the finding for a password field in a layout that
+// does not filter touches while obscured.
+func tapjackingFinding(view, relPath string, line int) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleTapjacking,
+		Title:       "Password field without tapjacking protection",
+		Description: "Layout " + filepath.Base(relPath) + " has a password " + view + " but neither the root view nor the field sets android:filterTouchesWhenObscured. A malicious overlay can cover the screen and trick the user into typing or tapping on it (tapjacking).",
+		Severity:    preflight.SeverityWarning,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Set android:filterTouchesWhenObscured=\"true\" on the root view of the layout, or call setFilterTouchesWhenObscured(true) on sensitive views, so touches are dropped while another window covers them.",
+	}
+}
@@ -0,0 +1,150 @@
+package codescan
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// maxTLSOverrideLines bounds how many lines of a certificate or hostname
+// check method are collected before the method is given up on. Methods that
+// actually validate are longer than the empty bodies this check looks for.
+const maxTLSOverrideLines = 20
+
+var (
+	// checkServerTrustedRe matches the declaration of an X509TrustManager's
+	// checkServerTrusted override up to its opening parenthesis.
+	checkServerTrustedRe = regexp.MustCompile(`\b(?:void|fun)\s+checkServerTrusted\s*\(`)
+
+	// verifyHostnameRe matches the declaration of a HostnameVerifier's verify
+	// override up to its opening parenthesis.
+	verifyHostnameRe = regexp.MustCompile(`\b(?:boolean|fun)\s+verify\s*\((?:[^)]*\bSSLSession\b)`)
+
+	// permissiveVerifierRe matches hostname verifiers that accept every host
+	// on one line: the Apache and OkHttp allow-all verifiers and lambdas
+	// returning true.
+	permissiveVerifierRe = regexp.MustCompile(`\bALLOW_ALL_HOSTNAME_VERIFIER\b|\bNoopHostnameVerifier\b|(?i:hostnameVerifier)\b.*(?:\{\s*\w+\s*,\s*\w+\s*->\s*true\s*\}|\(\s*\w+\s*,\s*\w+\s*\)\s*->\s*true\b)`)
+
+	// commentRe matches line and block comments in a collected method body.
+	commentRe = regexp.MustCompile(`//[^\n]*|(?s)/\*.*?\*/`)
+)
+
+// tlsOverride collects the body of a checkServerTrusted or verify override,
+// whose declaration may span several lines, from the parameter list to the
+// closing brace, or to the end of the line for a Kotlin expression body.
+type tlsOverride struct {
+	open         bool
+	trustManager bool // checkServerTrusted rather than verify
+	braced       bool
+	parens       int
+	depth        int
+	lines        int
+	body         strings.Builder
+}
+
+// start begins collecting the method whose parameter list starts at rest,
+// the text following the opening parenthesis.
+func (m *tlsOverride) start(rest string, trustManager bool) (done bool) {
+	*m = tlsOverride{open: true, trustManager: trustManager, parens: 1}
+	return m.feed(rest)
+}
+
+// feed adds a line of the method. It reports whether the end of the body was
+// reached, or the method was abandoned for spanning too many lines or having
+// no body.
+func (m *tlsOverride) feed(s string) (done bool) {
+	if m.lines++; m.lines > maxTLSOverrideLines {
+		m.abandon()
+		return true
+	}
+	for i, r := range s {
+		if m.braced {
+			switch r {
+			case '{':
+				m.depth++
+			case '}':
+				if m.depth--; m.depth == 0 {
+					m.open = false
+					return true
+				}
+			}
+			m.body.WriteRune(r)
+			continue
+		}
+		switch {
+		case r == '(':
+			m.parens++
+		case r == ')':
+			m.parens--
+		case m.parens > 0:
+		case r == '{':
+			m.braced, m.depth = true, 1
+		case r == '=':
+			m.body.WriteString(s[i+1:])
+			m.open = false
+			return true
+		case r == ';':
+			// Abstract or interface declaration.
+			m.abandon()
+			return true
+		}
+	}
+	m.body.WriteByte('\n')
+	return false
+}
+
+// abandon stops collecting and marks the body as unknown.
+func (m *tlsOverride) abandon() {
+	m.open = false
+	m.body.Reset()
+	m.body.WriteString("?")
+}
+
+// code returns the finished body without comments and whitespace.
+func (m *tlsOverride) code() string {
+	return strings.Join(strings.Fields(commentRe.ReplaceAllString(m.body.String(), "")), "")
+}
+
+// acceptsAll reports whether the finished method accepts everything: a
+// checkServerTrusted body that is empty, or a verify body that returns true
+// without looking at the hostname.
+func (m *tlsOverride) acceptsAll() bool {
+	code := m.code()
+	if m.trustManager {
+		return code == "" || code == "Unit" || code == "return" || code == "return;"
+	}
+	return code == "true" || code == "returntrue" || code == "returntrue;"
+}
+
+// trustAllCertificates builds the finding for a TrustManager whose
+// checkServerTrusted accepts every certificate.
+func trustAllCertificates(relPath string, line int, snippet string) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleInsecureTLS,
+		Title:       "TrustManager accepts all certificates",
+		Description: "checkServerTrusted is implemented without validating the certificate chain, so TLS connections accept any certificate, including one presented by an attacker intercepting traffic. Google Play rejects apps with unsafe X509TrustManager implementations.\n  Code: " + snippet,
+		Severity:    preflight.SeverityCritical,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Remove the custom TrustManager and use the platform default. To trust a private CA or pin certificates, declare them in a network security config instead, or throw CertificateException when validation fails.",
+	}
+}
+
+// permissiveHostnameVerifier builds the finding for a HostnameVerifier that
+// accepts every hostname.
+func permissiveHostnameVerifier(relPath string, line int, snippet string) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleInsecureTLS,
+		Title:       "Hostname verification disabled",
+		Description: "A HostnameVerifier accepts every hostname, so a valid certificate for any domain is accepted for this connection, allowing man-in-the-middle attacks. Google Play rejects apps with unsafe HostnameVerifier implementations.\n  Code: " + snippet,
+		Severity:    preflight.SeverityCritical,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Use the default hostname verifier. If a custom verifier is needed, check the hostname against the session's certificate and return false when it does not match.",
+	}
+}
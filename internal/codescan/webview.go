@@ -0,0 +1,40 @@
+package codescan
+
+import (
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+var (
+	// webViewDebuggingRe matches enabling remote debugging of WebView
+	// contents, in Java and Kotlin.
+	webViewDebuggingRe = regexp.MustCompile(`\bsetWebContentsDebuggingEnabled\s*\(\s*true\s*\)`)
+
+	// debugGuardRe matches the usual guard that limits a call to debug
+	// builds, e.g. `if (BuildConfig.DEBUG) {`.
+	debugGuardRe = regexp.MustCompile(`\bif\s*\(\s*(?:[\w.]+\.)?BuildConfig\.DEBUG\s*\)`)
+)
+
+// webViewDebuggingFinding builds the finding for enabling WebView contents
+// debugging. It is an error unless guarded is set, meaning the call or the
+// line before it checks BuildConfig.DEBUG, in which case it is informational.
+func webViewDebuggingFinding(guarded bool, relPath string, line int, snippet string) preflight.Finding {
+	f := preflight.Finding{
+		CheckID:     RuleWebViewDebug,
+		Title:       "WebView contents debugging enabled",
+		Description: "WebView.setWebContentsDebuggingEnabled(true) lets anyone with USB or adb access inspect and modify the DOM, JavaScript state, cookies, and storage of every WebView in the app through Chrome DevTools.\n  Code: " + snippet,
+		Severity:    preflight.SeverityError,
+		Location: preflight.Location{
+			File: relPath,
+			Line: line,
+		},
+		Suggestion: "Only enable WebView debugging in debug builds: wrap the call in if (BuildConfig.DEBUG), or pass BuildConfig.DEBUG instead of true.",
+	}
+	if guarded {
+		f.Severity = preflight.SeverityInfo
+		f.Description = "WebView.setWebContentsDebuggingEnabled(true) is guarded by BuildConfig.DEBUG, so WebView contents can only be inspected in debug builds. Make sure release builds are never built with DEBUG set.\n  Code: " + snippet
+		f.Suggestion = "No change needed if the guard covers the call. Passing BuildConfig.DEBUG directly makes the intent explicit."
+	}
+	return f
+}
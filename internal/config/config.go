@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// DefaultFileName is the config file looked up in the project root when no
+// explicit path is given.
+const DefaultFileName = ".playcheck.json"
+
+// Config holds user-tunable scan settings loaded from a JSON file.
+type Config struct {
+	// ScoreWeights overrides the per-severity penalties used for the
+	// compliance score. Omitted fields fall back to the defaults.
+	ScoreWeights *ScoreWeights `json:"score_weights,omitempty"`
+
+	// AppCategory selects category-specific policies, e.g. "families" for
+	// apps in the Designed for Families program. The --app-category flag
+	// takes precedence.
+	AppCategory string `json:"app_category,omitempty"`
+
+	// Preset raises the severity of the rules that matter most for a type
+	// of app: "game", "finance", or "health". The --preset flag takes
+	// precedence.
+	Preset string `json:"preset,omitempty"`
+
+	// StoreCriticalStrings lists the string resource names every locale in
+	// res/values-*/strings.xml must translate. Defaults to ["app_name"].
+	StoreCriticalStrings []string `json:"store_critical_strings,omitempty"`
+
+	// EndpointAllowlist lists domains, including their subdomains, that the
+	// development endpoint check (CS029) does not report.
+	EndpointAllowlist []string `json:"endpoint_allowlist,omitempty"`
+
+	// AcknowledgedSDKs lists SDKs, by name as reported in SDK007 findings,
+	// that are already declared in the Data Safety form. Their disclosure
+	// reminders are counted in the summary instead of reported.
+	AcknowledgedSDKs []string `json:"acknowledged_sdks,omitempty"`
+
+	// MinSDKFloor is the lowest minSdkVersion accepted without a warning
+	// (SDK006). Defaults to 21.
+	MinSDKFloor int `json:"min_sdk_floor,omitempty"`
+
+	// FollowSymlinks makes scans follow symlinked files and directories
+	// that resolve inside the project. The --follow-symlinks flag also
+	// enables it.
+	FollowSymlinks bool `json:"follow_symlinks,omitempty"`
+}
+
+// ScoreWeights holds the configured per-severity penalties. Fields are
+// pointers so that an explicit 0, which makes findings of that severity free,
+// can be told apart from an omitted field.
+type ScoreWeights struct {
+	Critical *float64 `json:"critical,omitempty"`
+	Error    *float64 `json:"error,omitempty"`
+	Warning  *float64 `json:"warning,omitempty"`
+	Info     *float64 `json:"info,omitempty"`
+}
+
+// Default returns an empty configuration.
+func Default() *Config {
+	return &Config{}
+}
+
+// Load reads the config file at path. When path is empty, the default file in
+// projectDir is used if it exists; otherwise an empty configuration is returned.
+func Load(path, projectDir string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(projectDir, DefaultFileName)
+	}
+
+	data, err := utils.ReadFileWithLimit(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return Default(), nil
+		}
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	cfg := Default()
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Weights returns the effective compliance score weights, filling any
+// unset field from preflight.DefaultScoreWeights.
+func (c *Config) Weights() preflight.ScoreWeights {
+	w := preflight.DefaultScoreWeights
+	if c.ScoreWeights == nil {
+		return w
+	}
+	if c.ScoreWeights.Critical != nil {
+		w.Critical = *c.ScoreWeights.Critical
+	}
+	if c.ScoreWeights.Error != nil {
+		w.Error = *c.ScoreWeights.Error
+	}
+	if c.ScoreWeights.Warning != nil {
+		w.Warning = *c.ScoreWeights.Warning
+	}
+	if c.ScoreWeights.Info != nil {
+		w.Info = *c.ScoreWeights.Info
+	}
+	return w
+}
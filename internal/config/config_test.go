@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+func TestLoad_NoFile(t *testing.T) {
+	cfg, err := Load("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Weights() != preflight.DefaultScoreWeights {
+		t.Errorf("expected default weights, got %+v", cfg.Weights())
+	}
+}
+
+func TestLoad_DefaultFileInProject(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"score_weights": {"critical": 50, "info": 0.5}, "app_category": "families"}`
+	if err := os.WriteFile(filepath.Join(dir, DefaultFileName), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load("", dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	w := cfg.Weights()
+	if w.Critical != 50 {
+		t.Errorf("expected critical weight 50, got %v", w.Critical)
+	}
+	if w.Info != 0.5 {
+		t.Errorf("expected info weight 0.5, got %v", w.Info)
+	}
+	if w.Warning != preflight.DefaultScoreWeights.Warning {
+		t.Errorf("expected default warning weight, got %v", w.Warning)
+	}
+	if cfg.AppCategory != "families" {
+		t.Errorf("expected app category families, got %q", cfg.AppCategory)
+	}
+}
+
+func TestWeights_Zero(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"score_weights": {"info": 0}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path, "")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	want := preflight.DefaultScoreWeights
+	want.Info = 0
+	if got := cfg.Weights(); got != want {
+		t.Errorf("expected weights %+v, got %+v", want, got)
+	}
+}
+
+func TestLoad_ExplicitMissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"), "")
+	if err == nil {
+		t.Error("expected error for missing explicit config file")
+	}
+}
+
+func TestLoad_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path, ""); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
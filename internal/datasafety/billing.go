@@ -0,0 +1,95 @@
+package datasafety
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// sdkMatch records where a third-party SDK dependency was declared.
+type sdkMatch struct {
+	SDK        sdkInfo
+	Dependency string // the matched entry of SDK.Dependencies
+	Location   preflight.Location
+}
+
+// playBillingCodeRe matches Play Billing Library usage in source code.
+var playBillingCodeRe = regexp.MustCompile(`\bBillingClient\b|\bqueryPurchasesAsync\b|\blaunchBillingFlow\b`)
+
+// digitalGoodsRe matches code that suggests the app sells digital goods or
+// subscriptions, such as premium unlocks or virtual currency.
+var digitalGoodsRe = regexp.MustCompile(`\b(?:ProductDetails|SkuDetails|queryProductDetailsAsync)\b|(?i)\b(?:in_?app_?purchase|unlock_?(?:pro|premium)|premium_?(?:upgrade|content|feature)s?|virtual_?currency|buy_?coins)\b`)
+
+// checkBilling reports Play Billing usage and flags non-Play payment SDKs in
+// apps that appear to sell digital goods. paymentSDKs are the billing and
+// payment dependencies found by checkSDKDisclosures.
+func checkBilling(proj *project, paymentSDKs []sdkMatch) []preflight.Finding {
+	var findings []preflight.Finding
+
+	var billingLoc *preflight.Location
+	for _, m := range paymentSDKs {
+		if m.SDK.PlayBilling {
+			loc := m.Location
+			billingLoc = &loc
+			break
+		}
+	}
+
+	var goodsLoc *preflight.Location
+	for _, cf := range proj.sources {
+		if billingLoc != nil && goodsLoc != nil {
+			break
+		}
+		content, ok := proj.read(cf)
+		if !ok {
+			continue
+		}
+		relPath, _ := filepath.Rel(proj.dir, cf)
+
+		if loc := playBillingCodeRe.FindStringIndex(content); loc != nil {
+			l := preflight.Location{File: relPath, Line: findLineNumber(content, content[loc[0]:loc[1]])}
+			if billingLoc == nil {
+				billingLoc = &l
+			}
+			if goodsLoc == nil {
+				goodsLoc = &l
+			}
+		}
+		if goodsLoc == nil {
+			if loc := digitalGoodsRe.FindStringIndex(content); loc != nil {
+				goodsLoc = &preflight.Location{File: relPath, Line: findLineNumber(content, content[loc[0]:loc[1]])}
+			}
+		}
+	}
+
+	if billingLoc != nil {
+		findings = append(findings, preflight.Finding{
+			CheckID:     "MP001",
+			Title:       "Play Billing detected: subscription disclosures required",
+			Description: "App uses the Google Play Billing Library. Subscriptions and in-app purchases must clearly disclose price, billing period, and how to cancel before the user commits.",
+			Severity:    preflight.SeverityInfo,
+			Location:    *billingLoc,
+			Suggestion:  "Show price, billing frequency, free-trial terms, and cancellation instructions in your purchase UI, and disclose 'Purchase history' in Data Safety if you store it.",
+		})
+	}
+
+	if goodsLoc == nil {
+		return findings
+	}
+	for _, m := range paymentSDKs {
+		if !m.SDK.ExternalPayments {
+			continue
+		}
+		findings = append(findings, preflight.Finding{
+			CheckID:     "MP002",
+			Title:       "Non-Play payment SDK alongside digital goods",
+			Description: m.SDK.Name + " is included, and code at " + goodsLoc.String() + " suggests the app sells digital goods or subscriptions. Digital goods sold on Google Play must use Play Billing.",
+			Severity:    preflight.SeverityWarning,
+			Location:    m.Location,
+			Suggestion:  "Use Google Play Billing for digital goods and subscriptions. Keep " + m.SDK.Name + " only for physical goods and services.",
+		})
+	}
+
+	return findings
+}
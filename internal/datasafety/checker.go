@@ -3,6 +3,8 @@ package datasafety
 import (
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
@@ -10,17 +12,67 @@ import (
 )
 
 // Checker validates data safety compliance for Google Play Store requirements.
-type Checker struct{}
+type Checker struct {
+	category     preflight.AppCategory
+	storeStrings []string
+	acknowledged []string
+	walkOpts     []utils.WalkOption
+}
+
+// Option configures optional Checker behavior.
+type Option func(*Checker)
+
+// WithAppCategory applies the policies of the app's Play category, e.g. the
+// certified ads SDK requirement for family apps.
+func WithAppCategory(c preflight.AppCategory) Option {
+	return func(ch *Checker) {
+		ch.category = c
+	}
+}
+
+// WithStoreCriticalStrings sets the string resource names that every locale
+// must translate. Without it, or with nil keys, DefaultStoreCriticalStrings
+// is used.
+func WithStoreCriticalStrings(keys ...string) Option {
+	return func(ch *Checker) {
+		ch.storeStrings = keys
+	}
+}
+
+// WithAcknowledgedSDKs suppresses the SDK007 disclosure reminder for SDKs
+// the team has already declared in the Data Safety form. Names match the SDK
+// names in the findings, e.g. "Firebase Analytics", ignoring case. Suppressed
+// findings are counted in CheckResult.Acknowledged.
+func WithAcknowledgedSDKs(names ...string) Option {
+	return func(ch *Checker) {
+		ch.acknowledged = names
+	}
+}
+
+// WithFollowSymlinks makes the checker follow symlinked files and
+// directories within the project, e.g. shared modules linked into the app.
+func WithFollowSymlinks() Option {
+	return func(ch *Checker) {
+		ch.walkOpts = append(ch.walkOpts, utils.WithFollowSymlinks())
+	}
+}
 
 // NewChecker creates a new data safety Checker.
-func NewChecker() *Checker {
-	return &Checker{}
+func NewChecker(opts ...Option) *Checker {
+	c := &Checker{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *Checker) ID() string          { return "DATA_SAFETY" }
 func (c *Checker) Name() string        { return "Data Safety Compliance" }
 func (c *Checker) Description() string { return "Checks data safety declarations, privacy policies, and disclosure requirements" }
 
+// Rules implements preflight.RuleLister.
+func (c *Checker) Rules() []preflight.RuleInfo { return Rules() }
+
 // Run executes all data safety compliance checks on the given project directory.
 func (c *Checker) Run(projectDir string) (*preflight.CheckResult, error) {
 	result := &preflight.CheckResult{
@@ -28,37 +80,60 @@ func (c *Checker) Run(projectDir string) (*preflight.CheckResult, error) {
 		Passed:  true,
 	}
 
-	manifests, err := utils.FindAndroidManifests(projectDir)
+	proj, err := loadProject(projectDir, c.walkOpts...)
 	if err != nil {
 		result.Err = err
 		return result, nil
 	}
 
 	// Parse manifest permissions and metadata.
-	manifestData := parseManifests(manifests)
+	manifestData := parseManifests(proj)
 
 	// Check privacy policy presence.
-	privacyFindings := checkPrivacyPolicy(projectDir, manifests)
+	privacyFindings := checkPrivacyPolicy(proj)
 	result.Findings = append(result.Findings, privacyFindings...)
 
 	// Check permission disclosures.
-	permFindings := checkPermissionDisclosures(manifestData, projectDir)
+	permFindings := checkPermissionDisclosures(manifestData, proj)
 	result.Findings = append(result.Findings, permFindings...)
 
 	// Check third-party SDK disclosures.
-	sdkFindings := checkSDKDisclosures(projectDir)
+	sdkFindings, acknowledged := checkSDKDisclosures(proj, c.acknowledged)
 	result.Findings = append(result.Findings, sdkFindings...)
+	result.Acknowledged += acknowledged
+
+	// Family apps may only use certified ads SDKs.
+	if c.category == preflight.CategoryFamilies {
+		result.Findings = append(result.Findings, checkFamiliesAds(proj)...)
+	}
 
 	// Check account deletion requirement.
-	acctFindings := checkAccountDeletion(projectDir)
+	acctFindings := checkAccountDeletion(proj)
 	result.Findings = append(result.Findings, acctFindings...)
 
 	// Check user consent patterns.
-	consentFindings := checkUserConsent(projectDir)
+	consentFindings := checkUserConsent(proj)
 	result.Findings = append(result.Findings, consentFindings...)
 
+	// Check notification permission for apps posting notifications.
+	notifFindings := checkNotificationPermission(manifestData, proj)
+	result.Findings = append(result.Findings, notifFindings...)
+
+	// Check that queried sensitive providers have their permission declared.
+	result.Findings = append(result.Findings, checkProviderPermissions(manifestData, proj)...)
+
+	// Recommend the Photo Picker over broad media permissions.
+	result.Findings = append(result.Findings, checkPhotoPicker(manifestData, proj)...)
+
+	// Check that store-critical strings are translated in every locale.
+	storeStrings := c.storeStrings
+	if storeStrings == nil {
+		storeStrings = DefaultStoreCriticalStrings
+	}
+	result.Findings = append(result.Findings, checkLocalizedStrings(proj, storeStrings)...)
+
 	// Cross-reference manifest permissions with actual code usage.
-	crossRefFindings := crossReferencePermissionsWithCode(manifestData, projectDir)
+	crossRefFindings := crossReferencePermissionsWithCode(manifestData, proj)
 	result.Findings = append(result.Findings, crossRefFindings...)
 
 	for _, f := range result.Findings {
@@ -68,6 +143,9 @@ func (c *Checker) Run(projectDir string) (*preflight.CheckResult, error) {
 		}
 	}
 
+	result.FilesScanned, result.BytesScanned = proj.coverage()
+	result.Coverage = ruleCoverage(proj.files(), c.category)
+
 	return result, nil
 }
 
@@ -76,9 +154,19 @@ type manifestInfo struct {
 	FilePath    string
 	Permissions []string
 	HasMeta     map[string]bool
+	TargetSDK   int // 0 when not declared in the manifest
 }
 
-var permissionRe = regexp.MustCompile(`<uses-permission\s+android:name="([^"]+)"`)
+var (
+	// permissionElementRe matches <uses-permission> and
+	// <uses-permission-sdk-23> elements, capturing their attributes, which
+	// may span several lines.
+	permissionElementRe = regexp.MustCompile(`<uses-permission(?:-sdk-23|-sdk-m)?\s([^>]*)>`)
+	permissionNameRe    = regexp.MustCompile(`\bandroid:name\s*=\s*"([^"]+)"`)
+	toolsRemoveRe       = regexp.MustCompile(`\btools:node\s*=\s*"remove"`)
+	xmlCommentRe        = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+var targetSdkRe = regexp.MustCompile(`android:targetSdkVersion="(\d+)"`)
 var metadataNameRe = regexp.MustCompile(`<meta-data\s+android:name="([^"]+)"`)
 
 // Account creation/deletion detection patterns.
@@ -95,6 +183,13 @@ var deleteAccountPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`(?i)account.?delet`),
 }
 
+// deletionRequestPatterns match a web URL or resource through which users can
+// request account and data deletion without the app installed.
+var deletionRequestPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)https?://[^\s"'<>]*(?:delete[-_]?(?:my[-_]?)?(?:account|data)|data[-_]?deletion|account[-_]?deletion)`),
+	regexp.MustCompile(`(?i)name="[^"]*(?:data|account)_?deletion[^"]*"`),
+}
+
 // Data collection and consent detection patterns.
 var dataCollectionPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`getDeviceId\(`),
@@ -113,52 +208,94 @@ var consentPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`(?i)privacy.?accept`),
 }
 
-func parseManifests(paths []string) []manifestInfo {
+func parseManifests(proj *project) []manifestInfo {
 	var results []manifestInfo
-	for _, p := range paths {
+	for _, p := range proj.manifests {
 		info := manifestInfo{
 			FilePath: p,
 			HasMeta:  make(map[string]bool),
 		}
-		data, err := utils.ReadFileWithLimit(p)
-		if err != nil {
+		content, ok := proj.read(p)
+		if !ok {
 			continue
 		}
-		content := string(data)
-		for _, m := range permissionRe.FindAllStringSubmatch(content, -1) {
-			info.Permissions = append(info.Permissions, m[1])
-		}
+		info.Permissions = declaredPermissions(content)
 		for _, m := range metadataNameRe.FindAllStringSubmatch(content, -1) {
 			info.HasMeta[m[1]] = true
 		}
+		if m := targetSdkRe.FindStringSubmatch(content); m != nil {
+			info.TargetSDK, _ = strconv.Atoi(m[1])
+		}
 		results = append(results, info)
 	}
 	return results
 }
 
+// declaredPermissions returns the permissions a manifest requests, like the
+// manifest package's parser: both <uses-permission> and
+// <uses-permission-sdk-23> count, while commented-out elements and those
+// marked tools:node="remove" do not.
+func declaredPermissions(content string) []string {
+	var perms []string
+	content = xmlCommentRe.ReplaceAllString(content, "")
+	for _, m := range permissionElementRe.FindAllStringSubmatch(content, -1) {
+		if toolsRemoveRe.MatchString(m[1]) {
+			continue
+		}
+		if name := permissionNameRe.FindStringSubmatch(m[1]); name != nil {
+			perms = append(perms, name[1])
+		}
+	}
+	return perms
+}
+
+// RuleSDKDisclosure is reported for third-party SDKs whose data collection
+// must be declared in the Data Safety form.
+const RuleSDKDisclosure = "SDK007"
+
 // checkSDKDisclosures scans Gradle files for third-party SDKs that require data safety disclosures.
-func checkSDKDisclosures(projectDir string) []preflight.Finding {
+// Disclosure reminders for SDKs named in acknowledged are not reported; their
+// number is returned instead.
+func checkSDKDisclosures(proj *project, acknowledged []string) ([]preflight.Finding, int) {
 	var findings []preflight.Finding
+	var paymentSDKs, playServicesSDKs []sdkMatch
+	suppressed := 0
 
-	gradleFiles, err := utils.FindGradleFiles(projectDir)
-	if err != nil {
-		return findings
-	}
-
-	for _, gf := range gradleFiles {
-		data, err := utils.ReadFileWithLimit(gf)
-		if err != nil {
+	for _, gf := range proj.gradle {
+		content, ok := proj.read(gf)
+		if !ok {
 			continue
 		}
-		content := string(data)
-		relPath, _ := filepath.Rel(projectDir, gf)
+		relPath, _ := filepath.Rel(proj.dir, gf)
 
 		for _, sdk := range thirdPartySDKs {
+			versionChecked := make(map[int]bool)
 			for _, dep := range sdk.Dependencies {
 				if strings.Contains(content, dep) {
 					line := findLineNumber(content, dep)
+					if !versionChecked[line] {
+						versionChecked[line] = true
+						if f := checkSDKVersion(sdk, content, relPath, line); f != nil {
+							findings = append(findings, *f)
+						}
+					}
+					match := sdkMatch{SDK: sdk, Dependency: dep, Location: preflight.Location{File: relPath, Line: line}}
+					if sdk.PlayBilling || sdk.ExternalPayments {
+						paymentSDKs = append(paymentSDKs, match)
+					}
+					if sdk.PlayServices {
+						playServicesSDKs = append(playServicesSDKs, match)
+					}
+					if sdk.PlayBilling {
+						// Reported by checkBilling with subscription guidance.
+						continue
+					}
+					if slices.ContainsFunc(acknowledged, func(name string) bool { return strings.EqualFold(name, sdk.Name) }) {
+						suppressed++
+						continue
+					}
 					findings = append(findings, preflight.Finding{
-						CheckID:     "SDK001",
+						CheckID:     RuleSDKDisclosure,
 						Title:       "Third-party SDK requires data safety disclosure",
 						Description: sdk.Name + " SDK detected (" + dep + "). " + sdk.DisclosureNote,
 						Severity:    preflight.SeverityWarning,
@@ -168,31 +305,39 @@ func checkSDKDisclosures(projectDir string) []preflight.Finding {
 				}
 			}
 		}
+
+		for _, lib := range versionedLibraries {
+			for _, dep := range lib.Dependencies {
+				if !strings.Contains(content, dep) {
+					continue
+				}
+				if f := checkSDKVersion(lib, content, relPath, findLineNumber(content, dep)); f != nil {
+					findings = append(findings, *f)
+				}
+			}
+		}
 	}
 
-	return findings
+	findings = append(findings, checkBilling(proj, paymentSDKs)...)
+	findings = append(findings, checkPlayServicesAvailability(proj, playServicesSDKs)...)
+
+	return findings, suppressed
 }
 
 // checkAccountDeletion checks if apps that create accounts also provide account deletion.
-func checkAccountDeletion(projectDir string) []preflight.Finding {
+func checkAccountDeletion(proj *project) []preflight.Finding {
 	var findings []preflight.Finding
 
-	codeFiles, err := utils.WalkFiles(projectDir, utils.WithExtensions(".kt", ".java"))
-	if err != nil {
-		return findings
-	}
-
 	var hasCreateAccount bool
 	var hasDeleteAccount bool
 	var createAccountLoc preflight.Location
 
-	for _, cf := range codeFiles {
-		data, err := utils.ReadFileWithLimit(cf)
-		if err != nil {
+	for _, cf := range proj.sources {
+		content, ok := proj.read(cf)
+		if !ok {
 			continue
 		}
-		content := string(data)
-		relPath, _ := filepath.Rel(projectDir, cf)
+		relPath, _ := filepath.Rel(proj.dir, cf)
 
 		if !hasCreateAccount {
 			for _, p := range createAccountPatterns {
@@ -229,9 +374,37 @@ func checkAccountDeletion(projectDir string) []preflight.Finding {
 		})
 	}
 
+	if hasCreateAccount && hasDeleteAccount && !hasDeletionRequestChannel(proj) {
+		findings = append(findings, preflight.Finding{
+			CheckID:     "AD002",
+			Title:       "Data deletion request URL not found",
+			Description: "App offers in-app account deletion, but no web URL for requesting account and data deletion was detected. Google Play also requires a link, entered in the Data Safety form, where users can request deletion without reinstalling the app.",
+			Severity:    preflight.SeverityWarning,
+			Location:    createAccountLoc,
+			Suggestion:  "Publish a page or form for account and data deletion requests (e.g. https://example.com/delete-account) and enter it in the Play Console Data Safety form.",
+		})
+	}
+
 	return findings
 }
 
+// hasDeletionRequestChannel reports whether the sources, string resources, or
+// manifest reference a data deletion request URL.
+func hasDeletionRequestChannel(proj *project) bool {
+	for _, f := range slices.Concat(proj.sources, proj.strings, proj.manifests) {
+		content, ok := proj.read(f)
+		if !ok {
+			continue
+		}
+		for _, p := range deletionRequestPatterns {
+			if p.MatchString(content) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // findLineNumber returns the 1-based line number of the first occurrence of substr in content.
 func findLineNumber(content, substr string) int {
 	idx := strings.Index(content, substr)
@@ -242,21 +415,15 @@ func findLineNumber(content, substr string) int {
 }
 
 // checkUserConsent scans code files for data collection without consent patterns.
-func checkUserConsent(projectDir string) []preflight.Finding {
+func checkUserConsent(proj *project) []preflight.Finding {
 	var findings []preflight.Finding
 
-	codeFiles, err := utils.WalkFiles(projectDir, utils.WithExtensions(".kt", ".java"))
-	if err != nil {
-		return findings
-	}
-
-	for _, cf := range codeFiles {
-		data, err := utils.ReadFileWithLimit(cf)
-		if err != nil {
+	for _, cf := range proj.sources {
+		content, ok := proj.read(cf)
+		if !ok {
 			continue
 		}
-		content := string(data)
-		relPath, _ := filepath.Rel(projectDir, cf)
+		relPath, _ := filepath.Rel(proj.dir, cf)
 
 		for _, dp := range dataCollectionPatterns {
 			loc := dp.FindStringIndex(content)
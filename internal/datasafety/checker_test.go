@@ -3,6 +3,7 @@ package datasafety
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -24,6 +25,16 @@ func setupTestProject(t *testing.T, files map[string]string) string {
 	return dir
 }
 
+// loadTestProject collects the files of a project created by setupTestProject.
+func loadTestProject(t *testing.T, dir string) *project {
+	t.Helper()
+	p, err := loadProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
 func TestChecker_ID(t *testing.T) {
 	c := &Checker{}
 	if c.ID() != "DATA_SAFETY" {
@@ -139,8 +150,7 @@ func TestCheckPrivacyPolicy_InManifest(t *testing.T) {
 </manifest>`,
 	})
 
-	manifests := []string{filepath.Join(dir, "AndroidManifest.xml")}
-	findings := checkPrivacyPolicy(dir, manifests)
+	findings := checkPrivacyPolicy(loadTestProject(t, dir))
 
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings when privacy policy in manifest, got %d", len(findings))
@@ -159,8 +169,7 @@ func TestCheckPrivacyPolicy_InStrings(t *testing.T) {
 </resources>`,
 	})
 
-	manifests := []string{filepath.Join(dir, "AndroidManifest.xml")}
-	findings := checkPrivacyPolicy(dir, manifests)
+	findings := checkPrivacyPolicy(loadTestProject(t, dir))
 
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings when privacy policy in strings.xml, got %d", len(findings))
@@ -179,8 +188,7 @@ func TestCheckPrivacyPolicy_Missing(t *testing.T) {
 </resources>`,
 	})
 
-	manifests := []string{filepath.Join(dir, "AndroidManifest.xml")}
-	findings := checkPrivacyPolicy(dir, manifests)
+	findings := checkPrivacyPolicy(loadTestProject(t, dir))
 
 	if len(findings) != 1 {
 		t.Fatalf("expected 1 finding for missing privacy policy, got %d", len(findings))
@@ -198,7 +206,7 @@ public class Main {
 }`,
 	})
 
-	findings := checkAccountDeletion(dir)
+	findings := checkAccountDeletion(loadTestProject(t, dir))
 	if len(findings) != 1 {
 		t.Fatalf("expected 1 finding for missing account deletion, got %d", len(findings))
 	}
@@ -217,14 +225,56 @@ public class Main {
     public void createUser(String email, String pw) {}
     public void deleteUser(String id) {}
 }`,
+		"res/values/strings.xml": `<resources>
+    <string name="delete_url">https://example.com/data-deletion</string>
+</resources>`,
 	})
 
-	findings := checkAccountDeletion(dir)
+	findings := checkAccountDeletion(loadTestProject(t, dir))
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings when deletion exists, got %d", len(findings))
 	}
 }
 
+func TestCheckAccountDeletion_InAppOnly(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"Main.java": `package com.example;
+public class Main {
+    public void createUser(String email, String pw) {}
+    public void deleteUser(String id) {}
+}`,
+	})
+
+	findings := checkAccountDeletion(loadTestProject(t, dir))
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding for in-app-only deletion, got %d", len(findings))
+	}
+	if findings[0].CheckID != "AD002" {
+		t.Errorf("expected check ID AD002, got %s", findings[0].CheckID)
+	}
+	if findings[0].Severity != preflight.SeverityWarning {
+		t.Errorf("expected severity WARNING, got %s", findings[0].Severity)
+	}
+	if findings[0].Location.Line != 3 {
+		t.Errorf("expected line 3, got %d", findings[0].Location.Line)
+	}
+}
+
+func TestCheckAccountDeletion_DeletionURLInCode(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"Account.kt": `package com.example
+class Account {
+    fun createAccount() {}
+    fun deleteAccount() {}
+    val deletionUrl = "https://example.com/account/delete-account"
+}`,
+	})
+
+	if findings := checkAccountDeletion(loadTestProject(t, dir)); len(findings) != 0 {
+		t.Errorf("expected 0 findings when a deletion URL is present, got %d", len(findings))
+	}
+}
+
 func TestCheckAccountDeletion_NoAccountCode(t *testing.T) {
 	dir := setupTestProject(t, map[string]string{
 		"Main.java": `package com.example;
@@ -233,7 +283,7 @@ public class Main {
 }`,
 	})
 
-	findings := checkAccountDeletion(dir)
+	findings := checkAccountDeletion(loadTestProject(t, dir))
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings when no account code, got %d", len(findings))
 	}
@@ -249,7 +299,7 @@ public class Tracker {
 }`,
 	})
 
-	findings := checkUserConsent(dir)
+	findings := checkUserConsent(loadTestProject(t, dir))
 	if len(findings) == 0 {
 		t.Error("expected findings for data collection without consent")
 	}
@@ -277,7 +327,7 @@ public class Tracker {
 }`,
 	})
 
-	findings := checkUserConsent(dir)
+	findings := checkUserConsent(loadTestProject(t, dir))
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings when consent present, got %d", len(findings))
 	}
@@ -309,7 +359,7 @@ func TestCheckBackgroundLocation(t *testing.T) {
 	// Should have at least one finding about background location
 	hasBackgroundFinding := false
 	for _, f := range findings {
-		if f.CheckID == "DP006" {
+		if f.CheckID == RuleBackgroundLocation {
 			hasBackgroundFinding = true
 		}
 	}
@@ -346,7 +396,7 @@ func TestCheckPermissionDisclosures(t *testing.T) {
 		},
 	}
 
-	findings := checkPermissionDisclosures(manifests, "/test")
+	findings := checkPermissionDisclosures(manifests, &project{dir: "/test"})
 	// Should find disclosures for READ_SMS and CAMERA (INTERNET is not dangerous)
 	hasSMSDisclosure := false
 	hasCameraDisclosure := false
@@ -381,7 +431,7 @@ dependencies {
 }`,
 	})
 
-	findings := checkSDKDisclosures(dir)
+	findings, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
 	if len(findings) == 0 {
 		t.Fatal("expected findings for Firebase SDK dependencies")
 	}
@@ -389,7 +439,7 @@ dependencies {
 	hasAnalytics := false
 	hasCrashlytics := false
 	for _, f := range findings {
-		if f.CheckID == "SDK001" {
+		if f.CheckID == RuleSDKDisclosure {
 			if strings.Contains(f.Description, "Firebase Analytics") {
 				hasAnalytics = true
 			}
@@ -411,7 +461,7 @@ func TestCheckSDKDisclosures_NoGradleFiles(t *testing.T) {
 		"Main.java": `class Main {}`,
 	})
 
-	findings := checkSDKDisclosures(dir)
+	findings, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings when no gradle files, got %d", len(findings))
 	}
@@ -428,7 +478,7 @@ dependencies {
 }`,
 	})
 
-	findings := checkSDKDisclosures(dir)
+	findings, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings for clean gradle, got %d", len(findings))
 	}
@@ -443,7 +493,7 @@ func TestCheckSDKDisclosures_MultipleSDKs(t *testing.T) {
 }`,
 	})
 
-	findings := checkSDKDisclosures(dir)
+	findings, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
 	if len(findings) < 3 {
 		t.Errorf("expected at least 3 findings for multiple SDKs, got %d", len(findings))
 	}
@@ -470,7 +520,7 @@ public class Main {
 		},
 	}
 
-	findings := crossReferencePermissionsWithCode(manifests, dir)
+	findings := crossReferencePermissionsWithCode(manifests, loadTestProject(t, dir))
 	for _, f := range findings {
 		if f.CheckID == "SDK004" && strings.Contains(f.Description, "CAMERA") {
 			t.Error("did not expect unused CAMERA finding when CameraManager is in code")
@@ -494,7 +544,7 @@ public class Main {
 		},
 	}
 
-	findings := crossReferencePermissionsWithCode(manifests, dir)
+	findings := crossReferencePermissionsWithCode(manifests, loadTestProject(t, dir))
 	found := false
 	for _, f := range findings {
 		if f.CheckID == "SDK004" && strings.Contains(f.Description, "CAMERA") {
@@ -506,6 +556,125 @@ public class Main {
 	}
 }
 
+func TestCheckProviderPermissions(t *testing.T) {
+	contactsQuery := `package com.example
+class ContactsRepository(private val context: Context) {
+    fun load() {
+        val cursor = context.contentResolver.query(
+            ContactsContract.Contacts.CONTENT_URI, null, null, null, null)
+    }
+}`
+	tests := []struct {
+		name  string
+		perms []string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name:  "contacts query without READ_CONTACTS",
+			perms: []string{"android.permission.INTERNET"},
+			files: map[string]string{"ContactsRepository.kt": contactsQuery},
+			want:  []string{"ContactsRepository.kt:5"},
+		},
+		{
+			name:  "contacts query with READ_CONTACTS",
+			perms: []string{"android.permission.READ_CONTACTS"},
+			files: map[string]string{"ContactsRepository.kt": contactsQuery},
+		},
+		{
+			name:  "contacts constant without a query",
+			perms: []string{"android.permission.INTERNET"},
+			files: map[string]string{"Picker.kt": `package com.example
+class Picker {
+    fun intent() = Intent(Intent.ACTION_PICK, ContactsContract.Contacts.CONTENT_URI)
+}`},
+		},
+		{
+			name:  "call log query in Java",
+			perms: []string{"android.permission.READ_CONTACTS"},
+			files: map[string]string{"Calls.java": `package com.example;
+public class Calls {
+    Cursor load(Context ctx) {
+        return ctx.getContentResolver().query(CallLog.Calls.CONTENT_URI, null, null, null, null);
+    }
+}`},
+			want: []string{"Calls.java:4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestProject(t, tt.files)
+			manifests := []manifestInfo{
+				{
+					FilePath:    filepath.Join(dir, "AndroidManifest.xml"),
+					Permissions: tt.perms,
+					HasMeta:     map[string]bool{},
+				},
+			}
+			var got []string
+			for _, f := range checkProviderPermissions(manifests, loadTestProject(t, dir)) {
+				if f.CheckID != RuleProviderPermission {
+					continue
+				}
+				got = append(got, f.Location.String())
+				if f.Severity != preflight.SeverityError {
+					t.Errorf("%s: got severity %s, want %s", f.Location, f.Severity, preflight.SeverityError)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %s findings at %v, got %v", RuleProviderPermission, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckProviderPermissions_TargetsAppManifest(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/src/main/java/Calls.kt": `val cursor = contentResolver.query(CallLog.Calls.CONTENT_URI, null, null, null, null)`,
+	})
+	manifests := []manifestInfo{
+		{FilePath: filepath.Join(dir, "core", "src", "main", "AndroidManifest.xml"), HasMeta: map[string]bool{}},
+		{FilePath: filepath.Join(dir, "app", "src", "main", "AndroidManifest.xml"), HasMeta: map[string]bool{}},
+	}
+	findings := checkProviderPermissions(manifests, loadTestProject(t, dir))
+	if len(findings) != 1 || findings[0].Remediation == nil {
+		t.Fatalf("expected 1 finding with a remediation, got %+v", findings)
+	}
+	if got, want := findings[0].Remediation.Target, filepath.Join("app", "src", "main", "AndroidManifest.xml"); got != want {
+		t.Errorf("remediation target = %q, want %q", got, want)
+	}
+}
+
+func TestCrossReferencePermissions_UsedByLibrary(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"Main.java": `package com.example;
+public class Main {
+    public void doNothing() {}
+}`,
+		"app/build.gradle": `dependencies {
+    implementation 'com.github.vestrel00:contacts-android:0.3.1'
+}`,
+	})
+
+	manifests := []manifestInfo{
+		{
+			FilePath:    filepath.Join(dir, "AndroidManifest.xml"),
+			Permissions: []string{"android.permission.READ_CONTACTS", "android.permission.CAMERA"},
+			HasMeta:     map[string]bool{},
+		},
+	}
+
+	var unused []string
+	for _, f := range crossReferencePermissionsWithCode(manifests, loadTestProject(t, dir)) {
+		if f.CheckID == "SDK004" {
+			unused = append(unused, f.Description)
+		}
+	}
+	if len(unused) != 1 || !strings.Contains(unused[0], "CAMERA") {
+		t.Errorf("expected only CAMERA reported unused with a contacts library present, got %q", unused)
+	}
+}
+
 func TestCrossReferencePermissions_NonDangerousPermission(t *testing.T) {
 	dir := setupTestProject(t, map[string]string{
 		"Main.java": `class Main {}`,
@@ -519,12 +688,73 @@ func TestCrossReferencePermissions_NonDangerousPermission(t *testing.T) {
 		},
 	}
 
-	findings := crossReferencePermissionsWithCode(manifests, dir)
+	findings := crossReferencePermissionsWithCode(manifests, loadTestProject(t, dir))
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings for non-dangerous permission, got %d", len(findings))
 	}
 }
 
+func TestCrossReferencePermissions_PhoneNumberWithoutPermission(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"Main.kt": `package com.example
+class Main {
+    fun number(tm: TelephonyManager): String? {
+        return tm.line1Number ?: tm.getLine1Number()
+    }
+}`,
+	})
+
+	manifests := []manifestInfo{
+		{
+			FilePath:    filepath.Join(dir, "AndroidManifest.xml"),
+			Permissions: []string{"android.permission.INTERNET"},
+			HasMeta:     map[string]bool{},
+		},
+	}
+
+	findings := crossReferencePermissionsWithCode(manifests, loadTestProject(t, dir))
+	var found *preflight.Finding
+	for i, f := range findings {
+		if f.CheckID == "PDS002" && strings.Contains(f.Title, "Phone number") {
+			found = &findings[i]
+		}
+	}
+	if found == nil {
+		t.Fatal("expected PDS002 finding for phone number access without permission")
+	}
+	if found.Severity != preflight.SeverityWarning {
+		t.Errorf("expected warning severity, got %v", found.Severity)
+	}
+	if found.Location.File != "Main.kt" || found.Location.Line != 4 {
+		t.Errorf("expected location Main.kt:4, got %s", found.Location)
+	}
+}
+
+func TestCrossReferencePermissions_PhoneNumberWithPermission(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"Main.java": `package com.example;
+public class Main {
+    String number(SubscriptionManager subscriptionManager, int id) {
+        return subscriptionManager.getPhoneNumber(id);
+    }
+}`,
+	})
+
+	manifests := []manifestInfo{
+		{
+			FilePath:    filepath.Join(dir, "AndroidManifest.xml"),
+			Permissions: []string{"android.permission.READ_PHONE_NUMBERS"},
+			HasMeta:     map[string]bool{},
+		},
+	}
+
+	for _, f := range crossReferencePermissionsWithCode(manifests, loadTestProject(t, dir)) {
+		if f.CheckID == "PDS002" || f.CheckID == "SDK004" {
+			t.Errorf("unexpected finding when READ_PHONE_NUMBERS is declared and used: %s", f.Title)
+		}
+	}
+}
+
 // --- Tests for checkRuntimePermissions ---
 
 func TestCheckRuntimePermissions_WithRequest(t *testing.T) {
@@ -543,7 +773,7 @@ public class Main {
 		HasMeta:     map[string]bool{},
 	}
 
-	findings := checkRuntimePermissions(m, dir)
+	findings := checkRuntimePermissions(m, loadTestProject(t, dir))
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings when runtime permission request present, got %d", len(findings))
 	}
@@ -565,7 +795,7 @@ public class Main {
 		HasMeta:     map[string]bool{},
 	}
 
-	findings := checkRuntimePermissions(m, dir)
+	findings := checkRuntimePermissions(m, loadTestProject(t, dir))
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings when checkSelfPermission present, got %d", len(findings))
 	}
@@ -585,7 +815,7 @@ public class Main {
 		HasMeta:     map[string]bool{},
 	}
 
-	findings := checkRuntimePermissions(m, dir)
+	findings := checkRuntimePermissions(m, loadTestProject(t, dir))
 	found := false
 	for _, f := range findings {
 		if f.CheckID == "PDS004" {
@@ -608,12 +838,221 @@ func TestCheckRuntimePermissions_NoDangerousPerms(t *testing.T) {
 		HasMeta:     map[string]bool{},
 	}
 
-	findings := checkRuntimePermissions(m, dir)
+	findings := checkRuntimePermissions(m, loadTestProject(t, dir))
 	if len(findings) != 0 {
 		t.Errorf("expected 0 findings when no dangerous permissions, got %d", len(findings))
 	}
 }
 
+func TestChecker_Run_CoverageWithoutGradle(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/src/main/AndroidManifest.xml": `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example" />`,
+		"app/src/main/java/Main.kt":        "package com.example\nclass Main",
+	})
+
+	result, err := NewChecker().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Coverage == nil {
+		t.Fatal("expected rule coverage")
+	}
+
+	notApplicable := make(map[string]string)
+	for _, g := range result.Coverage.NotApplicable {
+		for _, id := range g.RuleIDs {
+			notApplicable[id] = g.Reason
+		}
+	}
+	for _, id := range []string{RuleSDKDisclosure, "SDK002"} {
+		if reason := notApplicable[id]; !strings.Contains(reason, "Gradle") {
+			t.Errorf("expected %s not applicable for missing Gradle files, got reason %q", id, reason)
+		}
+	}
+	for _, id := range []string{"PDS001", "PDS002", "AD001", "MP001"} {
+		if !slices.Contains(result.Coverage.Applicable, id) {
+			t.Errorf("expected %s to be applicable, got %v", id, result.Coverage.Applicable)
+		}
+	}
+}
+
+func TestChecker_Run_CountsFilesRead(t *testing.T) {
+	manifest := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example" />`
+	source := "package com.example\nclass Main"
+	dir := setupTestProject(t, map[string]string{
+		"app/src/main/AndroidManifest.xml": manifest,
+		"app/src/main/java/Main.kt":        source,
+		"app/src/main/assets/data.bin":     "not read by any check",
+	})
+
+	result, err := NewChecker().Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.FilesScanned != 2 {
+		t.Errorf("expected 2 files scanned, got %d", result.FilesScanned)
+	}
+	if want := int64(len(manifest) + len(source)); result.BytesScanned != want {
+		t.Errorf("expected %d bytes scanned, got %d", want, result.BytesScanned)
+	}
+}
+
+// --- Tests for checkPhotoPicker ---
+
+func TestCheckPhotoPicker(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   int
+	}{
+		{
+			name: "picker-style usage",
+			source: `package com.example
+class Avatar : Fragment() {
+    private val pick = registerForActivityResult(ActivityResultContracts.GetContent()) { uri -> show(uri) }
+    fun choose() = pick.launch("image/*")
+}`,
+			want: 1,
+		},
+		{
+			name: "bulk MediaStore query",
+			source: `package com.example
+class Gallery(private val resolver: ContentResolver) {
+    fun load() = resolver.query(MediaStore.Images.Media.EXTERNAL_CONTENT_URI, null, null, null, null)
+    fun pickOne() = Intent(Intent.ACTION_PICK)
+}`,
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestProject(t, map[string]string{"Media.kt": tt.source})
+			m := manifestInfo{
+				FilePath:    filepath.Join(dir, "AndroidManifest.xml"),
+				Permissions: []string{"android.permission.READ_MEDIA_IMAGES"},
+				HasMeta:     map[string]bool{},
+				TargetSDK:   34,
+			}
+
+			findings := checkPhotoPicker([]manifestInfo{m}, loadTestProject(t, dir))
+			if len(findings) != tt.want {
+				t.Fatalf("expected %d findings, got %d", tt.want, len(findings))
+			}
+			if tt.want == 0 {
+				return
+			}
+			f := findings[0]
+			if f.Severity != preflight.SeverityInfo || !strings.Contains(f.Suggestion, "PickVisualMedia") {
+				t.Errorf("unexpected finding: %s %q", f.Severity, f.Suggestion)
+			}
+			if f.Location.File != "Media.kt" || f.Location.Line != 3 {
+				t.Errorf("expected location Media.kt:3, got %s", f.Location)
+			}
+		})
+	}
+}
+
+func TestCheckPhotoPicker_NoMediaPermission(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"Media.kt": "val i = Intent(Intent.ACTION_GET_CONTENT)",
+	})
+	m := manifestInfo{FilePath: filepath.Join(dir, "AndroidManifest.xml"), HasMeta: map[string]bool{}}
+	if findings := checkPhotoPicker([]manifestInfo{m}, loadTestProject(t, dir)); len(findings) != 0 {
+		t.Errorf("expected no findings without media permissions, got %d", len(findings))
+	}
+}
+
+// --- Tests for checkNotificationPermission ---
+
+func TestCheckNotificationPermission_NotDeclared(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"PushService.kt": `package com.example
+import com.google.firebase.messaging.FirebaseMessagingService
+class PushService : FirebaseMessagingService()`,
+	})
+
+	m := manifestInfo{
+		FilePath:    filepath.Join(dir, "AndroidManifest.xml"),
+		Permissions: []string{"android.permission.INTERNET"},
+		HasMeta:     map[string]bool{},
+		TargetSDK:   34,
+	}
+
+	findings := checkNotificationPermission([]manifestInfo{m}, loadTestProject(t, dir))
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Severity != preflight.SeverityError {
+		t.Errorf("expected ERROR severity, got %s", findings[0].Severity)
+	}
+	if findings[0].Location.File != "PushService.kt" || findings[0].Location.Line != 2 {
+		t.Errorf("expected location PushService.kt:2, got %s", findings[0].Location)
+	}
+}
+
+func TestCheckNotificationPermission_DeclaredNotRequested(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"Notifier.java": `package com.example;
+public class Notifier {
+    void show() { NotificationCompat.Builder b = new NotificationCompat.Builder(ctx, "ch"); }
+}`,
+	})
+
+	m := manifestInfo{
+		FilePath:    filepath.Join(dir, "AndroidManifest.xml"),
+		Permissions: []string{"android.permission.POST_NOTIFICATIONS"},
+		HasMeta:     map[string]bool{},
+		TargetSDK:   34,
+	}
+
+	findings := checkNotificationPermission([]manifestInfo{m}, loadTestProject(t, dir))
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Severity != preflight.SeverityWarning {
+		t.Errorf("expected WARNING severity, got %s", findings[0].Severity)
+	}
+}
+
+func TestCheckNotificationPermission_DeclaredAndRequested(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"Notifier.kt": `package com.example
+class Notifier {
+    fun ask() { requestPermissions(arrayOf(Manifest.permission.POST_NOTIFICATIONS), 1) }
+    fun show() { NotificationManagerCompat.from(ctx).notify(1, n) }
+}`,
+	})
+
+	m := manifestInfo{
+		FilePath:    filepath.Join(dir, "AndroidManifest.xml"),
+		Permissions: []string{"android.permission.POST_NOTIFICATIONS"},
+		HasMeta:     map[string]bool{},
+		TargetSDK:   34,
+	}
+
+	findings := checkNotificationPermission([]manifestInfo{m}, loadTestProject(t, dir))
+	if len(findings) != 0 {
+		t.Errorf("expected 0 findings, got %d", len(findings))
+	}
+}
+
+func TestCheckNotificationPermission_OldTarget(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"PushService.kt": `class PushService : FirebaseMessagingService()`,
+	})
+
+	m := manifestInfo{
+		FilePath:  filepath.Join(dir, "AndroidManifest.xml"),
+		HasMeta:   map[string]bool{},
+		TargetSDK: 32,
+	}
+
+	findings := checkNotificationPermission([]manifestInfo{m}, loadTestProject(t, dir))
+	if len(findings) != 0 {
+		t.Errorf("expected 0 findings when targeting SDK 32, got %d", len(findings))
+	}
+}
+
 // --- Tests for parseManifests ---
 
 func TestParseManifests(t *testing.T) {
@@ -628,8 +1067,7 @@ func TestParseManifests(t *testing.T) {
 </manifest>`,
 	})
 
-	paths := []string{filepath.Join(dir, "AndroidManifest.xml")}
-	result := parseManifests(paths)
+	result := parseManifests(loadTestProject(t, dir))
 
 	if len(result) != 1 {
 		t.Fatalf("expected 1 manifest, got %d", len(result))
@@ -642,8 +1080,48 @@ func TestParseManifests(t *testing.T) {
 	}
 }
 
+func TestParseManifests_PermissionVariants(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"AndroidManifest.xml": `<manifest xmlns:android="http://schemas.android.com/apk/res/android"
+    xmlns:tools="http://schemas.android.com/tools">
+    <uses-permission android:name="android.permission.INTERNET" />
+    <uses-permission-sdk-23 android:name="android.permission.CAMERA" />
+    <uses-permission
+        android:name="android.permission.READ_CONTACTS"
+        tools:node="remove" />
+    <!-- <uses-permission android:name="android.permission.READ_SMS" /> -->
+    <uses-permission android:maxSdkVersion="28" android:name="android.permission.WRITE_EXTERNAL_STORAGE" />
+</manifest>`,
+	})
+
+	result := parseManifests(loadTestProject(t, dir))
+	if len(result) != 1 {
+		t.Fatalf("expected 1 manifest, got %d", len(result))
+	}
+	want := []string{"android.permission.INTERNET", "android.permission.CAMERA", "android.permission.WRITE_EXTERNAL_STORAGE"}
+	if !slices.Equal(result[0].Permissions, want) {
+		t.Errorf("got permissions %v, want %v", result[0].Permissions, want)
+	}
+}
+
+func TestParseManifests_TargetSDK(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"AndroidManifest.xml": `<manifest xmlns:android="http://schemas.android.com/apk/res/android">
+    <uses-sdk android:minSdkVersion="24" android:targetSdkVersion="34" />
+</manifest>`,
+	})
+
+	result := parseManifests(loadTestProject(t, dir))
+	if len(result) != 1 {
+		t.Fatalf("expected 1 manifest, got %d", len(result))
+	}
+	if result[0].TargetSDK != 34 {
+		t.Errorf("expected TargetSDK 34, got %d", result[0].TargetSDK)
+	}
+}
+
 func TestParseManifests_NonexistentFile(t *testing.T) {
-	result := parseManifests([]string{"/nonexistent/AndroidManifest.xml"})
+	result := parseManifests(&project{manifests: []string{"/nonexistent/AndroidManifest.xml"}})
 	if len(result) != 0 {
 		t.Errorf("expected 0 results for nonexistent file, got %d", len(result))
 	}
@@ -665,3 +1143,402 @@ func TestChecker_Description(t *testing.T) {
 	}
 }
 
+// --- Tests for checkBilling ---
+
+func TestCheckSDKDisclosures_PlayBilling(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/build.gradle": `dependencies {
+    implementation 'com.android.billingclient:billing-ktx:7.0.0'
+}`,
+		"app/src/main/java/com/example/Store.kt": `package com.example
+import com.android.billingclient.api.BillingClient
+class Store(context: Context) {
+    private val billingClient = BillingClient.newBuilder(context).build()
+    fun refresh() {
+        billingClient.queryPurchasesAsync(params) { _, _ -> }
+    }
+}`,
+	})
+
+	findings, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
+	var billing *preflight.Finding
+	for i, f := range findings {
+		if f.CheckID == "MP001" {
+			billing = &findings[i]
+		}
+		if f.CheckID == "MP002" {
+			t.Errorf("did not expect MP002 for Play Billing only, got %q", f.Title)
+		}
+		if f.CheckID == RuleSDKDisclosure {
+			t.Errorf("Play Billing should be reported as MP001, not SDK007")
+		}
+	}
+	if billing == nil {
+		t.Fatal("expected MP001 finding for Play Billing")
+	}
+	if billing.Severity != preflight.SeverityInfo {
+		t.Errorf("expected INFO severity, got %s", billing.Severity)
+	}
+	if billing.Location.File != filepath.Join("app", "build.gradle") {
+		t.Errorf("expected finding on the gradle dependency, got %s", billing.Location)
+	}
+}
+
+func TestCheckBilling_CodeOnly(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"Billing.java": `class Billing {
+    BillingClient client;
+}`,
+	})
+
+	findings := checkBilling(loadTestProject(t, dir), nil)
+	if len(findings) != 1 || findings[0].CheckID != "MP001" {
+		t.Fatalf("expected one MP001 finding from BillingClient code, got %v", findings)
+	}
+	if findings[0].Location.Line != 2 {
+		t.Errorf("expected line 2, got %d", findings[0].Location.Line)
+	}
+}
+
+func TestCheckSDKDisclosures_ExternalPaymentWithDigitalGoods(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/build.gradle": `dependencies {
+    implementation 'com.stripe:stripe-android:20.30.0'
+}`,
+		"app/src/main/java/com/example/Paywall.kt": `class Paywall {
+    fun unlockPremium() {}
+}`,
+	})
+
+	findings, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
+	found := false
+	for _, f := range findings {
+		if f.CheckID == "MP002" {
+			found = true
+			if f.Severity != preflight.SeverityWarning {
+				t.Errorf("expected WARNING severity, got %s", f.Severity)
+			}
+			if !strings.Contains(f.Description, "Stripe") {
+				t.Errorf("expected description to name the SDK, got %q", f.Description)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected MP002 finding for Stripe alongside digital goods code")
+	}
+}
+
+func TestCheckSDKDisclosures_ExternalPaymentPhysicalGoods(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/build.gradle": `dependencies {
+    implementation 'com.stripe:stripe-android:20.30.0'
+}`,
+		"app/src/main/java/com/example/Cart.kt": `class Cart {
+    fun checkout(items: List<ShippingItem>) {}
+}`,
+	})
+
+	findings, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
+	for _, f := range findings {
+		if f.CheckID == "MP002" {
+			t.Errorf("did not expect MP002 without digital goods code, got %q", f.Description)
+		}
+	}
+}
+
+// --- Tests for SDK version checks ---
+
+func TestCheckSDKDisclosures_OutdatedFirebase(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/build.gradle": `dependencies {
+    implementation 'com.google.firebase:firebase-analytics:17.4.0'
+    implementation "com.google.firebase:firebase-crashlytics:18.6.0"
+}`,
+	})
+
+	findings, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
+	var outdated []preflight.Finding
+	for _, f := range findings {
+		if f.CheckID == "SDK002" {
+			outdated = append(outdated, f)
+		}
+	}
+	if len(outdated) != 1 {
+		t.Fatalf("expected 1 SDK002 finding, got %d", len(outdated))
+	}
+	f := outdated[0]
+	if !strings.Contains(f.Title, "Firebase Analytics") || !strings.Contains(f.Description, "17.4.0") {
+		t.Errorf("unexpected finding: %s / %s", f.Title, f.Description)
+	}
+	if f.Location.Line != 2 {
+		t.Errorf("expected line 2, got %d", f.Location.Line)
+	}
+	if f.Severity != preflight.SeverityWarning {
+		t.Errorf("expected WARNING severity, got %s", f.Severity)
+	}
+}
+
+func TestCheckSDKDisclosures_UnpinnedKotlinDSL(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/build.gradle.kts": `dependencies {
+    implementation("com.google.android.gms:play-services-ads:22.+")
+    implementation("com.squareup.okhttp3:okhttp:[4.0,5.0)")
+    implementation("com.squareup.okhttp3:okhttp-tls:3.12.0")
+}`,
+	})
+
+	findings, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
+	titles := make(map[string]bool)
+	for _, f := range findings {
+		if f.CheckID == "SDK002" {
+			titles[f.Title] = true
+		}
+	}
+	if !titles["Unpinned version for Google AdMob"] {
+		t.Error("expected unpinned finding for play-services-ads:22.+")
+	}
+	if !titles["Unpinned version for OkHttp"] {
+		t.Error("expected unpinned finding for OkHttp version range")
+	}
+	if len(titles) != 2 {
+		t.Errorf("expected exactly 2 SDK002 findings, got %v", titles)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"17.4.0", "21.0.0", -1},
+		{"21.0.0", "21.0.0", 0},
+		{"21.0", "21.0.0", 0},
+		{"22.1.0-alpha01", "22.0.0", 1},
+		{"4.10.0", "4.9.2", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFamiliesAds(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/build.gradle": `dependencies {
+    implementation 'com.google.android.gms:play-services-ads:23.0.0'
+    implementation 'com.facebook.android:audience-network-sdk:6.16.0'
+}`,
+	})
+
+	findings := checkFamiliesAds(loadTestProject(t, dir))
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.CheckID != RuleFamiliesAdsSDK || f.Severity != preflight.SeverityCritical {
+		t.Errorf("expected critical %s, got %s %s", RuleFamiliesAdsSDK, f.Severity, f.CheckID)
+	}
+	if !strings.Contains(f.Title, "Meta Audience Network") || f.Location.Line != 3 {
+		t.Errorf("unexpected finding %q at line %d", f.Title, f.Location.Line)
+	}
+
+	// Only reported for the families category.
+	for _, opts := range [][]Option{nil, {WithAppCategory(preflight.CategoryFamilies)}} {
+		result, err := NewChecker(opts...).Run(dir)
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		got := 0
+		for _, f := range result.Findings {
+			if f.CheckID == RuleFamiliesAdsSDK {
+				got++
+			}
+		}
+		if want := len(opts); got != want {
+			t.Errorf("with %d options: expected %d FAM001 findings, got %d", len(opts), want, got)
+		}
+	}
+}
+
+func TestCheckLocalizedStrings(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/src/main/res/values/strings.xml": `<resources>
+    <string name="app_name">Example</string>
+    <string name="tagline">Do more</string>
+    <string name="brand" translatable="false">ExampleCo</string>
+</resources>`,
+		"app/src/main/res/values-fr/strings.xml": `<resources>
+    <string name="app_name">Exemple</string>
+</resources>`,
+		"app/src/main/res/values-ja/strings.xml": `<resources>
+    <string name="tagline">もっと</string>
+</resources>`,
+		"app/src/main/res/values-night/strings.xml": `<resources>
+</resources>`,
+	})
+
+	c := NewChecker(WithStoreCriticalStrings("app_name", "tagline", "brand"))
+	result, err := c.Run(dir)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// Each locale misses one key; "brand" is not translatable and
+	// values-night is not a locale.
+	want := map[string]string{
+		"app/src/main/res/values-fr/strings.xml": `"tagline"`,
+		"app/src/main/res/values-ja/strings.xml": `"app_name"`,
+	}
+	got := 0
+	for _, f := range result.Findings {
+		if f.CheckID != RuleStoreStrings {
+			continue
+		}
+		got++
+		key, ok := want[filepath.ToSlash(f.Location.File)]
+		if !ok || !strings.Contains(f.Description, key) {
+			t.Errorf("unexpected finding at %s: %s", f.Location.File, f.Description)
+		}
+		if f.Severity != preflight.SeverityInfo {
+			t.Errorf("expected info severity, got %s", f.Severity)
+		}
+	}
+	if got != len(want) {
+		t.Errorf("expected %d %s findings, got %d", len(want), RuleStoreStrings, got)
+	}
+}
+
+func TestCheckLocalizedStrings_DefaultKeys(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"res/values/strings.xml": `<resources>
+    <string name="app_name">Example</string>
+    <string name="tagline">Do more</string>
+</resources>`,
+		"res/values-pt-rBR/strings.xml": `<resources>
+</resources>`,
+	})
+
+	findings := checkLocalizedStrings(loadTestProject(t, dir), DefaultStoreCriticalStrings)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding for app_name, got %d: %+v", len(findings), findings)
+	}
+	if !strings.Contains(findings[0].Description, `"app_name"`) || !strings.Contains(findings[0].Description, "pt-rBR") {
+		t.Errorf("unexpected description: %s", findings[0].Description)
+	}
+}
+
+func TestCheckPlayServicesAvailability(t *testing.T) {
+	const mapsGradle = `dependencies {
+    implementation 'com.google.android.gms:play-services-maps:18.2.0'
+}`
+	const mapsActivity = `package com.example
+import com.google.android.gms.maps.GoogleMap
+class MapActivity : AppCompatActivity()`
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string // location, empty for no finding
+		dep   string // dependency named in the description
+	}{
+		{
+			name:  "maps without availability check",
+			files: map[string]string{"app/build.gradle": mapsGradle, "MapActivity.kt": mapsActivity},
+			want:  "app/build.gradle:2",
+			dep:   "com.google.android.gms:play-services-maps",
+		},
+		{
+			name: "maps with availability check",
+			files: map[string]string{
+				"app/build.gradle": mapsGradle,
+				"MapActivity.kt":   mapsActivity,
+				"Startup.kt": `package com.example
+fun checkServices(ctx: Context) =
+    GoogleApiAvailability.getInstance().isGooglePlayServicesAvailable(ctx) == ConnectionResult.SUCCESS`,
+			},
+		},
+		{
+			name:  "gms import without gradle dependency",
+			files: map[string]string{"MapActivity.kt": mapsActivity},
+			want:  "MapActivity.kt:2",
+		},
+		{
+			name:  "firebase messaging without availability check",
+			files: map[string]string{"app/build.gradle": "dependencies {\n    implementation 'com.google.firebase:firebase-messaging:23.4.0'\n}"},
+			want:  "app/build.gradle:2",
+			dep:   "com.google.firebase:firebase-messaging",
+		},
+		{
+			name:  "no play services",
+			files: map[string]string{"Main.kt": "package com.example\nclass Main"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupTestProject(t, tt.files)
+			all, _ := checkSDKDisclosures(loadTestProject(t, dir), nil)
+			var findings []preflight.Finding
+			for _, f := range all {
+				if f.CheckID == RulePlayServicesCheck {
+					findings = append(findings, f)
+				}
+			}
+			if tt.want == "" {
+				if len(findings) != 0 {
+					t.Errorf("expected no findings, got %+v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+			}
+			f := findings[0]
+			if f.CheckID != RulePlayServicesCheck || f.Severity != preflight.SeverityInfo {
+				t.Errorf("expected INFO %s, got %s %s", RulePlayServicesCheck, f.Severity, f.CheckID)
+			}
+			if got := f.Location.String(); got != tt.want {
+				t.Errorf("expected location %s, got %s", tt.want, got)
+			}
+			if !strings.Contains(f.Description, tt.dep) {
+				t.Errorf("expected description to name the dependency, got %q", f.Description)
+			}
+		})
+	}
+}
+
+func TestCheckSDKDisclosures_Acknowledged(t *testing.T) {
+	dir := setupTestProject(t, map[string]string{
+		"app/build.gradle": `dependencies {
+    implementation 'com.google.firebase:firebase-analytics:21.5.0'
+    implementation 'com.google.firebase:firebase-crashlytics:18.6.0'
+}`,
+	})
+
+	findings, acknowledged := checkSDKDisclosures(loadTestProject(t, dir), []string{"firebase analytics"})
+	if acknowledged != 1 {
+		t.Errorf("expected 1 acknowledged SDK, got %d", acknowledged)
+	}
+	hasCrashlytics := false
+	for _, f := range findings {
+		if f.CheckID != RuleSDKDisclosure {
+			continue
+		}
+		if strings.Contains(f.Description, "Firebase Analytics") {
+			t.Errorf("acknowledged SDK should not be reported: %s", f.Description)
+		}
+		if strings.Contains(f.Description, "Firebase Crashlytics") {
+			hasCrashlytics = true
+		}
+	}
+	if !hasCrashlytics {
+		t.Error("expected finding for unacknowledged Firebase Crashlytics SDK")
+	}
+
+	result, err := NewChecker(WithAcknowledgedSDKs("Firebase Analytics")).Run(dir)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Acknowledged != 1 {
+		t.Errorf("expected CheckResult.Acknowledged = 1, got %d", result.Acknowledged)
+	}
+}
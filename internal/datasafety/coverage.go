@@ -0,0 +1,62 @@
+package datasafety
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// Data safety rule IDs grouped by the project files they need to fire.
+var (
+	gradleRules   = []string{RuleSDKDisclosure, "SDK002", "MP002", RuleFamiliesAdsSDK}
+	manifestRules = []string{"PDS002", "PDS004", RulePhotoPicker, RuleBackgroundLocation, "DP011", "SDK004", RuleProviderPermission}
+	sourceRules   = []string{"AD001", "AD002", "PDS003", RulePhotoPicker, "DP011", RuleProviderPermission}
+	billingRules  = []string{"MP001", RulePlayServicesCheck} // Gradle dependency or code
+	stringsRules  = []string{RuleStoreStrings}
+	alwaysRules   = []string{"PDS001"}
+)
+
+// ruleCoverage reports which data safety rules could fire given the files the
+// checker reads and the app category.
+func ruleCoverage(files []string, category preflight.AppCategory) *preflight.RuleCoverage {
+	var all []string
+	for _, ids := range [][]string{gradleRules, manifestRules, sourceRules, billingRules, stringsRules, alwaysRules} {
+		all = append(all, ids...)
+	}
+	coverage := preflight.NewRuleCoverage(all...)
+
+	var gradle, manifest, sources, strs bool
+	for _, f := range files {
+		switch base := filepath.Base(f); {
+		case base == "build.gradle" || base == "build.gradle.kts":
+			gradle = true
+		case base == "AndroidManifest.xml":
+			manifest = true
+		case base == "strings.xml":
+			strs = true
+		case strings.HasSuffix(base, ".kt") || strings.HasSuffix(base, ".java"):
+			sources = true
+		}
+	}
+
+	if category != preflight.CategoryFamilies {
+		coverage.Exclude("app category is not families", RuleFamiliesAdsSDK)
+	}
+	if !gradle {
+		coverage.Exclude("no Gradle build files found", gradleRules...)
+	}
+	if !manifest {
+		coverage.Exclude("no AndroidManifest.xml found", manifestRules...)
+	}
+	if !sources {
+		coverage.Exclude("no Kotlin or Java sources found", sourceRules...)
+	}
+	if !strs {
+		coverage.Exclude("no strings.xml found", stringsRules...)
+	}
+	if !gradle && !sources {
+		coverage.Exclude("no Gradle build files or sources found", billingRules...)
+	}
+	return coverage
+}
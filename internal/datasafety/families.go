@@ -0,0 +1,47 @@
+package datasafety
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// RuleFamiliesAdsSDK is reported for ads SDKs that are not Families
+// self-certified in apps in the Designed for Families program.
+const RuleFamiliesAdsSDK = "FAM001"
+
+// checkFamiliesAds flags ads SDKs declared in Gradle files that are not on
+// Google Play's Families Self-Certified Ads SDK list. Family apps may only
+// serve ads through certified SDKs.
+func checkFamiliesAds(proj *project) []preflight.Finding {
+	var findings []preflight.Finding
+	for _, gf := range proj.gradle {
+		content, ok := proj.read(gf)
+		if !ok {
+			continue
+		}
+		relPath, _ := filepath.Rel(proj.dir, gf)
+
+		for _, sdk := range thirdPartySDKs {
+			if !sdk.Ads || sdk.FamiliesCertified {
+				continue
+			}
+			for _, dep := range sdk.Dependencies {
+				if !strings.Contains(content, dep) {
+					continue
+				}
+				findings = append(findings, preflight.Finding{
+					CheckID:     RuleFamiliesAdsSDK,
+					Title:       "Ads SDK not certified for Families: " + sdk.Name,
+					Description: sdk.Name + " (" + dep + ") is not on the Families Self-Certified Ads SDK list. Apps in the Designed for Families program may only serve ads through certified SDKs.",
+					Severity:    preflight.SeverityCritical,
+					Location:    preflight.Location{File: relPath, Line: findLineNumber(content, dep)},
+					Suggestion:  "Replace " + sdk.Name + " with a Families self-certified ads SDK such as Google AdMob, or remove ads from the app.",
+				})
+				break
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,102 @@
+package datasafety
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// RuleStoreStrings is reported when a locale lacks a translation of a string
+// shown on the store listing or the launcher.
+const RuleStoreStrings = "SL001"
+
+// DefaultStoreCriticalStrings are the string resource names checked when no
+// list is configured.
+var DefaultStoreCriticalStrings = []string{"app_name"}
+
+// localeQualifierRe matches a values directory whose first qualifier is a
+// language, e.g. values-fr, values-pt-rBR, or values-b+sr+Latn.
+var localeQualifierRe = regexp.MustCompile(`^values-(?:[a-z]{2,3}(?:-r[A-Z]{2})?|b\+[A-Za-z0-9+]+)(?:-|$)`)
+
+// checkLocalizedStrings reports store-critical strings that are defined in a
+// default values/strings.xml but missing from a locale's strings.xml in the
+// same res directory. Users in that locale would see the default language on
+// the launcher and in system UI.
+func checkLocalizedStrings(proj *project, keys []string) []preflight.Finding {
+	var findings []preflight.Finding
+	if len(keys) == 0 {
+		return findings
+	}
+
+	// Default strings and locale variants, keyed by res directory.
+	defaults := make(map[string][]string)
+	locales := make(map[string][]string)
+	for _, xf := range proj.strings {
+		valuesDir := filepath.Dir(xf)
+		resDir := filepath.Dir(valuesDir)
+		switch dir := filepath.Base(valuesDir); {
+		case dir == "values":
+			res, ok := parseStringsXML(proj, xf)
+			if !ok {
+				continue
+			}
+			for _, entry := range res.Strings {
+				if slices.Contains(keys, entry.Name) && entry.Translatable != "false" {
+					defaults[resDir] = append(defaults[resDir], entry.Name)
+				}
+			}
+		case localeQualifierRe.MatchString(dir):
+			locales[resDir] = append(locales[resDir], xf)
+		}
+	}
+
+	for _, xf := range proj.strings {
+		resDir := filepath.Dir(filepath.Dir(xf))
+		if len(defaults[resDir]) == 0 || !slices.Contains(locales[resDir], xf) {
+			continue
+		}
+		res, ok := parseStringsXML(proj, xf)
+		if !ok {
+			continue
+		}
+		translated := make(map[string]bool, len(res.Strings))
+		for _, entry := range res.Strings {
+			translated[entry.Name] = true
+		}
+
+		relPath, _ := filepath.Rel(proj.dir, xf)
+		locale := strings.TrimPrefix(filepath.Base(filepath.Dir(xf)), "values-")
+		for _, key := range defaults[resDir] {
+			if translated[key] {
+				continue
+			}
+			findings = append(findings, preflight.Finding{
+				CheckID:     RuleStoreStrings,
+				Title:       "Store-critical string not translated",
+				Description: "String \"" + key + "\" is defined in the default values/strings.xml but missing from " + relPath + ". Users with the " + locale + " locale see the default-language text on the launcher and in system UI, which may not match the localized store listing.",
+				Severity:    preflight.SeverityInfo,
+				Location:    preflight.Location{File: relPath},
+				Suggestion:  "Add a translation of \"" + key + "\" to " + relPath + ", or mark it translatable=\"false\" in the default strings.xml if it must not change.",
+			})
+		}
+	}
+
+	return findings
+}
+
+// parseStringsXML reads and parses a strings.xml file. ok is false if the
+// file cannot be read or is not a valid <resources> document.
+func parseStringsXML(proj *project, path string) (res stringsXMLResource, ok bool) {
+	content, ok := proj.read(path)
+	if !ok {
+		return res, false
+	}
+	if err := xml.Unmarshal([]byte(content), &res); err != nil {
+		return res, false
+	}
+	return res, true
+}
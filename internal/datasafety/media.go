@@ -0,0 +1,74 @@
+package datasafety
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// pickerStyleRe matches code that lets the user pick individual media items,
+// which the Android Photo Picker handles without any media permission.
+var pickerStyleRe = regexp.MustCompile(`\bACTION_(?:PICK|GET_CONTENT)\b|\bGet(?:Multiple)?Contents?\s*\(|\bPick(?:Multiple)?VisualMedia\b`)
+
+// mediaStoreQueryRe matches bulk MediaStore access to images or videos, the
+// use case that actually needs READ_MEDIA_IMAGES / READ_MEDIA_VIDEO.
+var mediaStoreQueryRe = regexp.MustCompile(`\bMediaStore\.(?:Images|Video|Files)\b`)
+
+// RulePhotoPicker is reported when broad media permissions are declared but
+// the Photo Picker would suffice.
+const RulePhotoPicker = "DP014"
+
+// checkPhotoPicker recommends the Photo Picker when READ_MEDIA_IMAGES or
+// READ_MEDIA_VIDEO is declared but the code only picks media one item at a
+// time. Play's Photo and Video Permissions policy limits broad media access
+// to apps whose core functionality needs it.
+func checkPhotoPicker(manifests []manifestInfo, proj *project) []preflight.Finding {
+	var findings []preflight.Finding
+
+	var declaring []manifestInfo
+	for _, m := range manifests {
+		if declaresAny([]manifestInfo{m}, "android.permission.READ_MEDIA_IMAGES", "android.permission.READ_MEDIA_VIDEO") {
+			declaring = append(declaring, m)
+		}
+	}
+	if len(declaring) == 0 {
+		return findings
+	}
+
+	var pickerLoc preflight.Location
+	hasPicker := false
+	for _, cf := range proj.sources {
+		content, ok := proj.read(cf)
+		if !ok {
+			continue
+		}
+		if mediaStoreQueryRe.MatchString(content) {
+			return findings
+		}
+		if !hasPicker {
+			if loc := pickerStyleRe.FindStringIndex(content); loc != nil {
+				hasPicker = true
+				relPath, _ := filepath.Rel(proj.dir, cf)
+				pickerLoc = preflight.Location{File: relPath, Line: findLineNumber(content, content[loc[0]:loc[1]])}
+			}
+		}
+	}
+	if !hasPicker {
+		return findings
+	}
+
+	for _, m := range declaring {
+		relPath, _ := filepath.Rel(proj.dir, m.FilePath)
+		findings = append(findings, preflight.Finding{
+			CheckID:     RulePhotoPicker,
+			Title:       "Broad media permission where the Photo Picker would suffice",
+			Description: "READ_MEDIA_IMAGES or READ_MEDIA_VIDEO is declared in " + relPath + ", but the code only picks individual items and never queries MediaStore. Google Play limits broad photo and video access to apps that need it for core functionality and reviews other apps that request it.",
+			Severity:    preflight.SeverityInfo,
+			Location:    pickerLoc,
+			Suggestion:  "Use the Android Photo Picker (ActivityResultContracts.PickVisualMedia) and remove READ_MEDIA_IMAGES and READ_MEDIA_VIDEO from the manifest.",
+		})
+	}
+
+	return findings
+}
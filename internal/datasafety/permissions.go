@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
-	"github.com/kotaroyamazaki/playcheck/pkg/utils"
 )
 
 // permissionDisclosure maps dangerous Android permissions to required data safety disclosures.
@@ -72,6 +71,24 @@ var dangerousPermissionDisclosures = []permissionDisclosure{
 		DisclosureMsg: "READ_EXTERNAL_STORAGE permission requires disclosure of file/document data access",
 		CheckID:       "PDS002",
 	},
+	{
+		Permission:    "android.permission.READ_MEDIA_IMAGES",
+		DataType:      "Photos",
+		DisclosureMsg: "READ_MEDIA_IMAGES permission requires disclosure of photo data access",
+		CheckID:       "PDS002",
+	},
+	{
+		Permission:    "android.permission.READ_MEDIA_VIDEO",
+		DataType:      "Videos",
+		DisclosureMsg: "READ_MEDIA_VIDEO permission requires disclosure of video data access",
+		CheckID:       "PDS002",
+	},
+	{
+		Permission:    "android.permission.READ_MEDIA_AUDIO",
+		DataType:      "Music files",
+		DisclosureMsg: "READ_MEDIA_AUDIO permission requires disclosure of audio file access",
+		CheckID:       "PDS002",
+	},
 	{
 		Permission:    "android.permission.READ_CALENDAR",
 		DataType:      "Calendar events",
@@ -84,14 +101,20 @@ var dangerousPermissionDisclosures = []permissionDisclosure{
 		DisclosureMsg: "BODY_SENSORS permission requires disclosure of health/fitness data collection",
 		CheckID:       "PDS002",
 	},
+	{
+		Permission:    "android.permission.READ_PHONE_NUMBERS",
+		DataType:      "Phone number",
+		DisclosureMsg: "READ_PHONE_NUMBERS permission requires disclosure of phone number data collection",
+		CheckID:       "PDS002",
+	},
 }
 
 // checkPermissionDisclosures validates that manifest permissions have corresponding data safety disclosures.
-func checkPermissionDisclosures(manifests []manifestInfo, projectDir string) []preflight.Finding {
+func checkPermissionDisclosures(manifests []manifestInfo, proj *project) []preflight.Finding {
 	var findings []preflight.Finding
 
 	for _, m := range manifests {
-		relPath, _ := filepath.Rel(projectDir, m.FilePath)
+		relPath, _ := filepath.Rel(proj.dir, m.FilePath)
 
 		for _, perm := range m.Permissions {
 			for _, disc := range dangerousPermissionDisclosures {
@@ -109,15 +132,19 @@ func checkPermissionDisclosures(manifests []manifestInfo, projectDir string) []p
 		}
 
 		// Check background location access.
-		findings = append(findings, checkBackgroundLocation(m, relPath, projectDir)...)
+		findings = append(findings, checkBackgroundLocation(m, relPath, proj.dir)...)
 
 		// Check runtime permission requests in code.
-		findings = append(findings, checkRuntimePermissions(m, projectDir)...)
+		findings = append(findings, checkRuntimePermissions(m, proj)...)
 	}
 
 	return findings
 }
 
+// RuleBackgroundLocation is reported when ACCESS_BACKGROUND_LOCATION is
+// declared.
+const RuleBackgroundLocation = "DP015"
+
 // checkBackgroundLocation validates background location permission usage.
 func checkBackgroundLocation(m manifestInfo, relPath, projectDir string) []preflight.Finding {
 	var findings []preflight.Finding
@@ -135,7 +162,7 @@ func checkBackgroundLocation(m manifestInfo, relPath, projectDir string) []prefl
 
 	if hasBackgroundLocation {
 		findings = append(findings, preflight.Finding{
-			CheckID:     "DP006",
+			CheckID:     RuleBackgroundLocation,
 			Title:       "Background location access declared",
 			Description: "ACCESS_BACKGROUND_LOCATION requires prominent disclosure and Play Store policy review. Apps must demonstrate the need for background location.",
 			Severity:    preflight.SeverityError,
@@ -145,7 +172,7 @@ func checkBackgroundLocation(m manifestInfo, relPath, projectDir string) []prefl
 
 		if !hasForegroundLocation {
 			findings = append(findings, preflight.Finding{
-				CheckID:     "DP006",
+				CheckID:     RuleBackgroundLocation,
 				Title:       "Background location without foreground location",
 				Description: "ACCESS_BACKGROUND_LOCATION is declared but no foreground location permission (ACCESS_FINE_LOCATION or ACCESS_COARSE_LOCATION) is present. Background location requires a foreground location permission.",
 				Severity:    preflight.SeverityError,
@@ -163,7 +190,7 @@ var runtimePermissionRe = regexp.MustCompile(`requestPermissions?\s*\(`)
 var checkSelfPermissionRe = regexp.MustCompile(`checkSelfPermission\s*\(`)
 
 // checkRuntimePermissions verifies that dangerous permissions are requested at runtime.
-func checkRuntimePermissions(m manifestInfo, projectDir string) []preflight.Finding {
+func checkRuntimePermissions(m manifestInfo, proj *project) []preflight.Finding {
 	var findings []preflight.Finding
 
 	// Only check if the manifest has dangerous permissions that require runtime request.
@@ -183,18 +210,12 @@ func checkRuntimePermissions(m manifestInfo, projectDir string) []preflight.Find
 		return findings
 	}
 
-	codeFiles, err := utils.WalkFiles(projectDir, utils.WithExtensions(".kt", ".java"))
-	if err != nil {
-		return findings
-	}
-
 	hasRuntimeRequest := false
-	for _, cf := range codeFiles {
-		data, err := utils.ReadFileWithLimit(cf)
-		if err != nil {
+	for _, cf := range proj.sources {
+		content, ok := proj.read(cf)
+		if !ok {
 			continue
 		}
-		content := string(data)
 		if runtimePermissionRe.MatchString(content) || checkSelfPermissionRe.MatchString(content) {
 			hasRuntimeRequest = true
 			break
@@ -202,7 +223,7 @@ func checkRuntimePermissions(m manifestInfo, projectDir string) []preflight.Find
 	}
 
 	if !hasRuntimeRequest {
-		relPath, _ := filepath.Rel(projectDir, m.FilePath)
+		relPath, _ := filepath.Rel(proj.dir, m.FilePath)
 		findings = append(findings, preflight.Finding{
 			CheckID:     "PDS004",
 			Title:       "No runtime permission request detected",
@@ -216,11 +237,99 @@ func checkRuntimePermissions(m manifestInfo, projectDir string) []preflight.Find
 	return findings
 }
 
+// notificationUsageRe matches code that posts notifications, either directly or via FCM.
+var notificationUsageRe = regexp.MustCompile(`FirebaseMessaging|NotificationCompat|NotificationManager(?:Compat)?\b`)
+
+const postNotificationsPerm = "android.permission.POST_NOTIFICATIONS"
+
+// notificationPermissionSDK is the API level (Android 13) at which
+// POST_NOTIFICATIONS became a runtime permission.
+const notificationPermissionSDK = 33
+
+// checkNotificationPermission verifies that apps posting notifications declare
+// POST_NOTIFICATIONS and request it at runtime. Without the runtime grant,
+// notifications are silently dropped on Android 13+.
+func checkNotificationPermission(manifests []manifestInfo, proj *project) []preflight.Finding {
+	var findings []preflight.Finding
+
+	var usageLoc preflight.Location
+	hasUsage := false
+	hasRuntimeRequest := false
+	for _, cf := range proj.sources {
+		content, ok := proj.read(cf)
+		if !ok {
+			continue
+		}
+		if !hasUsage {
+			if loc := notificationUsageRe.FindStringIndex(content); loc != nil {
+				hasUsage = true
+				relPath, _ := filepath.Rel(proj.dir, cf)
+				usageLoc = preflight.Location{File: relPath, Line: findLineNumber(content, content[loc[0]:loc[1]])}
+			}
+		}
+		if strings.Contains(content, "POST_NOTIFICATIONS") && runtimePermissionRe.MatchString(content) {
+			hasRuntimeRequest = true
+		}
+	}
+	if !hasUsage {
+		return findings
+	}
+
+	for _, m := range manifests {
+		relPath, _ := filepath.Rel(proj.dir, m.FilePath)
+		declared := false
+		for _, p := range m.Permissions {
+			if p == postNotificationsPerm {
+				declared = true
+				break
+			}
+		}
+
+		switch {
+		case !declared && m.TargetSDK >= notificationPermissionSDK:
+			findings = append(findings, preflight.Finding{
+				CheckID:     "DP011",
+				Title:       "Notifications used without POST_NOTIFICATIONS permission",
+				Description: "Code posts notifications but POST_NOTIFICATIONS is not declared. On Android 13+ (API 33) notifications from apps without this permission are silently dropped.",
+				Severity:    preflight.SeverityError,
+				Location:    usageLoc,
+				Suggestion:  "Declare <uses-permission android:name=\"android.permission.POST_NOTIFICATIONS\" /> in " + relPath + " and request it at runtime.",
+			})
+		case declared && !hasRuntimeRequest:
+			findings = append(findings, preflight.Finding{
+				CheckID:     "DP011",
+				Title:       "POST_NOTIFICATIONS declared but not requested at runtime",
+				Description: "POST_NOTIFICATIONS is declared in the manifest but no runtime request for it was found in code. Android 13+ requires the user to grant it at runtime.",
+				Severity:    preflight.SeverityWarning,
+				Location:    preflight.Location{File: relPath},
+				Suggestion:  "Request Manifest.permission.POST_NOTIFICATIONS with ActivityCompat.requestPermissions() or the Activity Result API before posting notifications.",
+			})
+		}
+	}
+
+	return findings
+}
+
 // sdkInfo describes a third-party SDK that requires data safety disclosure.
 type sdkInfo struct {
 	Name           string
 	Dependencies   []string
 	DisclosureNote string
+	// PlayBilling marks the Google Play Billing Library.
+	PlayBilling bool
+	// ExternalPayments marks payment processors that must not be used for
+	// digital goods sold on Google Play.
+	ExternalPayments bool
+	// MinVersion is the oldest release without known policy or security
+	// problems; VersionNote explains what is wrong with older releases.
+	MinVersion  string
+	VersionNote string
+	// Ads marks ads SDKs. FamiliesCertified marks those on Google Play's
+	// Families Self-Certified Ads SDK list.
+	Ads               bool
+	FamiliesCertified bool
+	// PlayServices marks SDKs that need Google Play services on the device.
+	PlayServices bool
 }
 
 // thirdPartySDKs lists common SDKs that require data safety form disclosures.
@@ -229,21 +338,52 @@ var thirdPartySDKs = []sdkInfo{
 		Name:           "Firebase Analytics",
 		Dependencies:   []string{"com.google.firebase:firebase-analytics", "firebase-analytics-ktx"},
 		DisclosureNote: "Collects app interactions, device identifiers, and crash data. Disclose 'App interactions', 'Device or other IDs' in Data Safety.",
+		MinVersion:     "21.0.0",
+		VersionNote:    "Releases before 21.0.0 are flagged as outdated in the Google Play SDK Index.",
 	},
 	{
 		Name:           "Firebase Crashlytics",
 		Dependencies:   []string{"com.google.firebase:firebase-crashlytics", "firebase-crashlytics-ktx"},
 		DisclosureNote: "Collects crash logs and device state. Disclose 'Crash logs', 'Device or other IDs' in Data Safety.",
+		MinVersion:     "18.0.0",
+		VersionNote:    "Releases before 18.0.0 are flagged as outdated in the Google Play SDK Index.",
 	},
 	{
-		Name:           "Google AdMob",
-		Dependencies:   []string{"com.google.android.gms:play-services-ads", "com.google.ads:"},
-		DisclosureNote: "Collects advertising ID, device info, and interaction data. Disclose 'Device or other IDs', 'Ads data' in Data Safety.",
+		Name:              "Google AdMob",
+		Dependencies:      []string{"com.google.android.gms:play-services-ads", "com.google.ads:"},
+		DisclosureNote:    "Collects advertising ID, device info, and interaction data. Disclose 'Device or other IDs', 'Ads data' in Data Safety.",
+		MinVersion:        "22.0.0",
+		VersionNote:       "Releases before 22.0.0 lack current consent (UMP) and privacy-sandbox support and are flagged as outdated in the Google Play SDK Index.",
+		Ads:               true,
+		FamiliesCertified: true,
+		PlayServices:      true,
 	},
 	{
 		Name:           "Facebook SDK",
 		Dependencies:   []string{"com.facebook.android:facebook-", "implementation 'com.facebook.android"},
 		DisclosureNote: "Collects device info, app events, and advertising data. Disclose 'Device or other IDs', 'App interactions' in Data Safety.",
+		MinVersion:     "16.0.0",
+		VersionNote:    "Releases before 16.0.0 are flagged as outdated in the Google Play SDK Index.",
+	},
+	{
+		Name:           "Meta Audience Network",
+		Dependencies:   []string{"com.facebook.android:audience-network-sdk"},
+		DisclosureNote: "Collects advertising ID, device info, and ad interactions. Disclose 'Device or other IDs', 'Ads data' in Data Safety.",
+		Ads:            true,
+	},
+	{
+		Name:              "AppLovin SDK",
+		Dependencies:      []string{"com.applovin:applovin-sdk"},
+		DisclosureNote:    "Collects advertising ID, device info, and ad interactions. Disclose 'Device or other IDs', 'Ads data' in Data Safety.",
+		Ads:               true,
+		FamiliesCertified: true,
+	},
+	{
+		Name:              "Unity Ads",
+		Dependencies:      []string{"com.unity3d.ads:unity-ads"},
+		DisclosureNote:    "Collects advertising ID, device info, and ad interactions. Disclose 'Device or other IDs', 'Ads data' in Data Safety.",
+		Ads:               true,
+		FamiliesCertified: true,
 	},
 	{
 		Name:           "Adjust SDK",
@@ -264,6 +404,13 @@ var thirdPartySDKs = []sdkInfo{
 		Name:           "Google Maps SDK",
 		Dependencies:   []string{"com.google.android.gms:play-services-maps", "com.google.android.gms:play-services-location"},
 		DisclosureNote: "May collect location data. Disclose 'Approximate location' or 'Precise location' in Data Safety if location is used.",
+		PlayServices:   true,
+	},
+	{
+		Name:           "Firebase Cloud Messaging",
+		Dependencies:   []string{"com.google.firebase:firebase-messaging"},
+		DisclosureNote: "Collects device identifiers (registration tokens) to deliver push messages. Disclose 'Device or other IDs' in Data Safety.",
+		PlayServices:   true,
 	},
 	{
 		Name:           "Mixpanel SDK",
@@ -286,12 +433,60 @@ var thirdPartySDKs = []sdkInfo{
 		DisclosureNote: "Collects push notification tokens and device identifiers. Disclose 'Device or other IDs' in Data Safety.",
 	},
 	{
-		Name:           "Stripe SDK",
-		Dependencies:   []string{"com.stripe:stripe-android"},
-		DisclosureNote: "Processes payment information. Disclose 'Financial info' and 'Purchase history' in Data Safety.",
+		Name:             "Stripe SDK",
+		Dependencies:     []string{"com.stripe:stripe-android"},
+		DisclosureNote:   "Processes payment information. Disclose 'Financial info' and 'Purchase history' in Data Safety.",
+		ExternalPayments: true,
+	},
+	{
+		Name:             "PayPal SDK",
+		Dependencies:     []string{"com.paypal.checkout:android-sdk", "com.paypal.android"},
+		DisclosureNote:   "Processes payment information. Disclose 'Financial info' and 'Purchase history' in Data Safety.",
+		ExternalPayments: true,
+	},
+	{
+		Name:             "Braintree SDK",
+		Dependencies:     []string{"com.braintreepayments.api"},
+		DisclosureNote:   "Processes payment information. Disclose 'Financial info' and 'Purchase history' in Data Safety.",
+		ExternalPayments: true,
+	},
+	{
+		Name:             "Razorpay SDK",
+		Dependencies:     []string{"com.razorpay:checkout"},
+		DisclosureNote:   "Processes payment information. Disclose 'Financial info' and 'Purchase history' in Data Safety.",
+		ExternalPayments: true,
+	},
+	{
+		Name:           "Google Play Billing",
+		Dependencies:   []string{"com.android.billingclient:billing"},
+		DisclosureNote: "Disclose 'Purchase history' in Data Safety if you store or share purchase records.",
+		PlayBilling:    true,
+	},
+}
+
+// versionedLibraries lists libraries that need no data safety disclosure
+// but have releases with known security or policy problems.
+var versionedLibraries = []sdkInfo{
+	{
+		Name:         "OkHttp",
+		Dependencies: []string{"com.squareup.okhttp3:okhttp:"},
+		MinVersion:   "4.9.2",
+		VersionNote:  "Releases before 4.9.2 are affected by CVE-2021-0341 (improper certificate hostname verification).",
+	},
+	{
+		Name:         "Play Core",
+		Dependencies: []string{"com.google.android.play:core:"},
+		MinVersion:   "1.10.0",
+		VersionNote:  "Releases before 1.10.0 do not meet the Play Core API requirements.",
 	},
 }
 
+// phoneNumberAPIs matches APIs that read the device phone number. They need
+// READ_PHONE_NUMBERS, or READ_PHONE_STATE on older releases.
+const phoneNumberAPIs = `getLine1Number|(?i:subscriptionManager)\w*\.getPhoneNumber\s*\(`
+
+var phoneNumberAPIRe = regexp.MustCompile(phoneNumberAPIs)
+
 // permissionAPIs maps permissions to common API usage patterns for cross-referencing.
 var permissionAPIs = map[string][]*regexp.Regexp{
 	"android.permission.CAMERA": {
@@ -315,6 +510,12 @@ var permissionAPIs = map[string][]*regexp.Regexp{
 	"android.permission.READ_CALL_LOG": {
 		regexp.MustCompile(`CallLog|CallLog\.Calls`),
 	},
+	"android.permission.READ_PHONE_STATE": {
+		regexp.MustCompile(`TelephonyManager|PhoneStateListener|TelephonyCallback|getLine1Number`),
+	},
+	"android.permission.READ_PHONE_NUMBERS": {
+		regexp.MustCompile(phoneNumberAPIs),
+	},
 	"android.permission.READ_CALENDAR": {
 		regexp.MustCompile(`CalendarContract|CalendarProvider`),
 	},
@@ -323,35 +524,94 @@ var permissionAPIs = map[string][]*regexp.Regexp{
 	},
 }
 
+// permissionLibraries maps Gradle dependencies to the dangerous permissions
+// the library uses itself. Apps declaring the permission for such a library
+// have no matching API call in their own code.
+var permissionLibraries = []struct {
+	Dependency  string
+	Permissions []string
+}{
+	{"com.github.vestrel00:contacts-android", []string{"android.permission.READ_CONTACTS"}},
+	{"com.github.tamir7.contacts:contacts", []string{"android.permission.READ_CONTACTS"}},
+	{"com.truecaller.android.sdk", []string{"android.permission.READ_CALL_LOG", "android.permission.READ_PHONE_STATE"}},
+	{"com.journeyapps:zxing-android-embedded", []string{"android.permission.CAMERA"}},
+	{"me.dm7.barcodescanner", []string{"android.permission.CAMERA"}},
+	{"io.agora.rtc", []string{"android.permission.RECORD_AUDIO", "android.permission.CAMERA"}},
+	{"com.twilio:video-android", []string{"android.permission.RECORD_AUDIO", "android.permission.CAMERA"}},
+	{"com.mapbox.navigation", []string{"android.permission.ACCESS_FINE_LOCATION", "android.permission.ACCESS_COARSE_LOCATION"}},
+}
+
+// libraryPermissions returns the permissions used by libraries declared in
+// the project's Gradle files, mapped to the first declaring dependency.
+func libraryPermissions(proj *project) map[string]string {
+	perms := make(map[string]string)
+	for _, gf := range proj.gradle {
+		content, ok := proj.read(gf)
+		if !ok {
+			continue
+		}
+		for _, lib := range permissionLibraries {
+			if !strings.Contains(content, lib.Dependency) {
+				continue
+			}
+			for _, p := range lib.Permissions {
+				if _, ok := perms[p]; !ok {
+					perms[p] = lib.Dependency
+				}
+			}
+		}
+	}
+	return perms
+}
+
 // crossReferencePermissionsWithCode checks that permissions declared in manifest
 // are actually used in code, and flags unused dangerous permissions.
-func crossReferencePermissionsWithCode(manifests []manifestInfo, projectDir string) []preflight.Finding {
+// Permissions used by a known library in the Gradle dependencies are not
+// reported as unused.
+func crossReferencePermissionsWithCode(manifests []manifestInfo, proj *project) []preflight.Finding {
 	var findings []preflight.Finding
 
-	codeFiles, err := utils.WalkFiles(projectDir, utils.WithExtensions(".kt", ".java"))
-	if err != nil {
-		return findings
-	}
-
 	// Build a set of all code content for searching.
 	var allCode strings.Builder
-	for _, cf := range codeFiles {
-		data, err := utils.ReadFileWithLimit(cf)
-		if err != nil {
+	var phoneNumberLoc *preflight.Location
+	for _, cf := range proj.sources {
+		content, ok := proj.read(cf)
+		if !ok {
 			continue
 		}
-		allCode.Write(data)
+		allCode.WriteString(content)
 		allCode.WriteByte('\n')
+		if phoneNumberLoc == nil {
+			if m := phoneNumberAPIRe.FindString(content); m != "" {
+				relPath, _ := filepath.Rel(proj.dir, cf)
+				phoneNumberLoc = &preflight.Location{File: relPath, Line: findLineNumber(content, m)}
+			}
+		}
 	}
 	codeContent := allCode.String()
+	libPerms := libraryPermissions(proj)
+
+	if phoneNumberLoc != nil && !declaresAny(manifests, "android.permission.READ_PHONE_NUMBERS", "android.permission.READ_PHONE_STATE") {
+		findings = append(findings, preflight.Finding{
+			CheckID:     "PDS002",
+			Title:       "Phone number accessed without declared permission",
+			Description: "Code reads the device phone number, but neither READ_PHONE_NUMBERS nor READ_PHONE_STATE is declared. The call fails without the permission, and phone numbers are personal data that must be disclosed as 'Phone number' in Data Safety.",
+			Severity:    preflight.SeverityWarning,
+			Location:    *phoneNumberLoc,
+			Suggestion:  "Declare READ_PHONE_NUMBERS (READ_PHONE_STATE with android:maxSdkVersion=\"29\" for older releases) and disclose 'Phone number' in your Data Safety form, or remove the phone number access.",
+		})
+	}
 
 	for _, m := range manifests {
-		relPath, _ := filepath.Rel(projectDir, m.FilePath)
+		relPath, _ := filepath.Rel(proj.dir, m.FilePath)
 		for _, perm := range m.Permissions {
 			apis, exists := permissionAPIs[perm]
 			if !exists {
 				continue
 			}
+			if _, ok := libPerms[perm]; ok {
+				continue
+			}
 			usedInCode := false
 			for _, api := range apis {
 				if api.MatchString(codeContent) {
@@ -378,3 +638,17 @@ func crossReferencePermissionsWithCode(manifests []manifestInfo, projectDir stri
 
 	return findings
 }
+
+// declaresAny reports whether any manifest declares one of the permissions.
+func declaresAny(manifests []manifestInfo, perms ...string) bool {
+	for _, m := range manifests {
+		for _, p := range m.Permissions {
+			for _, want := range perms {
+				if p == want {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
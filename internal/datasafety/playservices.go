@@ -0,0 +1,65 @@
+package datasafety
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// RulePlayServicesCheck is reported when an app uses Google Play services
+// without checking that they are available on the device.
+const RulePlayServicesCheck = "SDK005"
+
+var (
+	// playServicesCodeRe matches imports of Google Play services APIs.
+	playServicesCodeRe = regexp.MustCompile(`\bimport\s+com\.google\.android\.gms\.`)
+
+	// availabilityCheckRe matches the calls that check, or prompt the user to
+	// fix, Google Play services availability.
+	availabilityCheckRe = regexp.MustCompile(`\b(?:isGooglePlayServicesAvailable|makeGooglePlayServicesAvailable)\s*\(`)
+)
+
+// checkPlayServicesAvailability reports apps that depend on Google Play
+// services, through an SDK in playServicesSDKs or gms imports, but never call
+// GoogleApiAvailability.isGooglePlayServicesAvailable. Such apps fail on
+// devices without Play services, or with an outdated version, instead of
+// degrading gracefully. playServicesSDKs are the Play services dependencies
+// found by checkSDKDisclosures.
+func checkPlayServicesAvailability(proj *project, playServicesSDKs []sdkMatch) []preflight.Finding {
+	var usage *preflight.Location
+	var usageName string
+	if len(playServicesSDKs) > 0 {
+		usage = &playServicesSDKs[0].Location
+		usageName = playServicesSDKs[0].Dependency
+	}
+
+	for _, cf := range proj.sources {
+		content, ok := proj.read(cf)
+		if !ok {
+			continue
+		}
+		if availabilityCheckRe.MatchString(content) {
+			return nil
+		}
+		if usage == nil {
+			if loc := playServicesCodeRe.FindStringIndex(content); loc != nil {
+				relPath, _ := filepath.Rel(proj.dir, cf)
+				usage = &preflight.Location{File: relPath, Line: findLineNumber(content, content[loc[0]:loc[1]])}
+				usageName = "com.google.android.gms APIs"
+			}
+		}
+	}
+
+	if usage == nil {
+		return nil
+	}
+	return []preflight.Finding{{
+		CheckID:     RulePlayServicesCheck,
+		Title:       "Google Play services used without an availability check",
+		Description: "The app uses Google Play services (" + usageName + ") but never calls GoogleApiAvailability.isGooglePlayServicesAvailable. On devices without Play services, or with an outdated version, features such as Maps, ads, and push messaging fail instead of degrading gracefully.",
+		Severity:    preflight.SeverityInfo,
+		Location:    *usage,
+		Suggestion:  "Call GoogleApiAvailability.getInstance().isGooglePlayServicesAvailable(context) before using Play services APIs, and use makeGooglePlayServicesAvailable or a fallback when it does not return ConnectionResult.SUCCESS.",
+	}}
+}
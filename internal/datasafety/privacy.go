@@ -7,22 +7,22 @@ import (
 	"strings"
 
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
-	"github.com/kotaroyamazaki/playcheck/pkg/utils"
 )
 
 // checkPrivacyPolicy checks for privacy policy URL presence in both
 // AndroidManifest.xml and strings.xml resource files.
-func checkPrivacyPolicy(projectDir string, manifests []string) []preflight.Finding {
+func checkPrivacyPolicy(proj *project) []preflight.Finding {
 	var findings []preflight.Finding
 
-	manifestHasPolicy := checkManifestPrivacyPolicy(manifests, projectDir)
-	stringsHasPolicy := checkStringsPrivacyPolicy(projectDir)
+	manifests := proj.manifests
+	manifestHasPolicy := checkManifestPrivacyPolicy(proj)
+	stringsHasPolicy := checkStringsPrivacyPolicy(proj)
 
 	if !manifestHasPolicy && !stringsHasPolicy {
 		// Determine the best location to report.
 		loc := preflight.Location{File: "AndroidManifest.xml"}
 		if len(manifests) > 0 {
-			relPath, _ := filepath.Rel(projectDir, manifests[0])
+			relPath, _ := filepath.Rel(proj.dir, manifests[0])
 			loc.File = relPath
 		}
 		findings = append(findings, preflight.Finding{
@@ -48,13 +48,12 @@ var privacyURLPatterns = []*regexp.Regexp{
 }
 
 // checkManifestPrivacyPolicy checks AndroidManifest.xml files for privacy policy references.
-func checkManifestPrivacyPolicy(manifests []string, projectDir string) bool {
-	for _, m := range manifests {
-		data, err := utils.ReadFileWithLimit(m)
-		if err != nil {
+func checkManifestPrivacyPolicy(proj *project) bool {
+	for _, m := range proj.manifests {
+		content, ok := proj.read(m)
+		if !ok {
 			continue
 		}
-		content := string(data)
 		for _, p := range privacyURLPatterns {
 			if p.MatchString(content) {
 				return true
@@ -71,33 +70,28 @@ type stringsXMLResource struct {
 }
 
 type stringsXMLEntry struct {
-	Name  string `xml:"name,attr"`
-	Value string `xml:",chardata"`
+	Name         string `xml:"name,attr"`
+	Translatable string `xml:"translatable,attr"`
+	Value        string `xml:",chardata"`
 }
 
 // checkStringsPrivacyPolicy scans res/values/strings.xml files for privacy policy URLs.
-func checkStringsPrivacyPolicy(projectDir string) bool {
-	xmlFiles, err := utils.WalkFiles(projectDir, utils.WithFilenames("strings.xml"))
-	if err != nil {
-		return false
-	}
-
-	for _, xf := range xmlFiles {
+func checkStringsPrivacyPolicy(proj *project) bool {
+	for _, xf := range proj.strings {
 		// Only consider files under a "values" directory.
 		dir := filepath.Base(filepath.Dir(xf))
 		if !strings.HasPrefix(dir, "values") {
 			continue
 		}
 
-		data, err := utils.ReadFileWithLimit(xf)
-		if err != nil {
+		content, ok := proj.read(xf)
+		if !ok {
 			continue
 		}
 
 		var res stringsXMLResource
-		if err := xml.Unmarshal(data, &res); err != nil {
+		if err := xml.Unmarshal([]byte(content), &res); err != nil {
 			// Fall back to raw text search.
-			content := string(data)
 			for _, p := range privacyURLPatterns {
 				if p.MatchString(content) {
 					return true
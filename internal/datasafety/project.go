@@ -0,0 +1,85 @@
+package datasafety
+
+import (
+	"path/filepath"
+
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// project lists the files of an Android project the data safety checks read,
+// collected in a single walk.
+type project struct {
+	dir       string
+	manifests []string
+	gradle    []string
+	sources   []string // Kotlin and Java files
+	strings   []string // strings.xml resources
+
+	// contents caches file contents so that each file is read once, however
+	// many checks look at it.
+	contents map[string]string
+}
+
+// dataSafetyFiles selects the files the data safety checks read: sources,
+// manifests, string resources, and Gradle build files.
+var dataSafetyFiles = []utils.WalkOption{
+	utils.WithExtensions(".kt", ".java"),
+	utils.WithFilenames("AndroidManifest.xml", "strings.xml", "build.gradle", "build.gradle.kts"),
+}
+
+// loadProject walks dir for the files the data safety checks read. opts may
+// add walk options such as utils.WithFollowSymlinks.
+func loadProject(dir string, opts ...utils.WalkOption) (*project, error) {
+	files, err := utils.WalkFiles(dir, append(append([]utils.WalkOption{}, dataSafetyFiles...), opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	p := &project{dir: dir}
+	for _, f := range files {
+		switch filepath.Base(f) {
+		case "AndroidManifest.xml":
+			p.manifests = append(p.manifests, f)
+		case "build.gradle", "build.gradle.kts":
+			p.gradle = append(p.gradle, f)
+		case "strings.xml":
+			p.strings = append(p.strings, f)
+		default:
+			p.sources = append(p.sources, f)
+		}
+	}
+	return p, nil
+}
+
+// read returns the contents of path, reading the file only on first use.
+func (p *project) read(path string) (string, bool) {
+	if content, ok := p.contents[path]; ok {
+		return content, true
+	}
+	data, err := utils.ReadFileWithLimit(path)
+	if err != nil {
+		return "", false
+	}
+	if p.contents == nil {
+		p.contents = make(map[string]string)
+	}
+	p.contents[path] = string(data)
+	return p.contents[path], true
+}
+
+// coverage returns the number and total size of the files the checks read.
+func (p *project) coverage() (files int, bytes int64) {
+	for _, content := range p.contents {
+		files++
+		bytes += int64(len(content))
+	}
+	return files, bytes
+}
+
+// files returns every file of the project the checks read.
+func (p *project) files() []string {
+	var files []string
+	for _, group := range [][]string{p.manifests, p.gradle, p.sources, p.strings} {
+		files = append(files, group...)
+	}
+	return files
+}
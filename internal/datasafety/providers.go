@@ -0,0 +1,106 @@
+package datasafety
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/manifest"
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// RuleProviderPermission is reported when code queries a content provider
+// guarded by a dangerous permission that no manifest declares.
+const RuleProviderPermission = "DP012"
+
+// resolverQueryRe matches ContentResolver.query calls in Kotlin and Java.
+var resolverQueryRe = regexp.MustCompile(`\b(?:contentResolver|getContentResolver\s*\(\s*\))\s*\.\s*query\s*\(`)
+
+// sensitiveProvider is a system content provider that needs a dangerous
+// permission to be read.
+type sensitiveProvider struct {
+	Name       string
+	Permission string
+	URIRe      *regexp.Regexp
+}
+
+// sensitiveProviders are the providers checked against the manifest. Reading
+// them without the permission throws a SecurityException.
+var sensitiveProviders = []sensitiveProvider{
+	{Name: "Contacts", Permission: "android.permission.READ_CONTACTS", URIRe: regexp.MustCompile(`\bContactsContract\.\w+`)},
+	{Name: "Call log", Permission: "android.permission.READ_CALL_LOG", URIRe: regexp.MustCompile(`\bCallLog\.Calls\b`)},
+	{Name: "SMS", Permission: "android.permission.READ_SMS", URIRe: regexp.MustCompile(`\bTelephony\.(?:Sms|Mms|MmsSms|Threads)\b`)},
+}
+
+// checkProviderPermissions reports sensitive providers that are queried
+// through a ContentResolver in a file, while no manifest declares the
+// permission the provider needs. It is the inverse of the unused permission
+// check in crossReferencePermissionsWithCode. Projects without a manifest are
+// not checked.
+func checkProviderPermissions(manifests []manifestInfo, proj *project) []preflight.Finding {
+	var findings []preflight.Finding
+	if len(manifests) == 0 {
+		return findings
+	}
+
+	var missing []sensitiveProvider
+	for _, p := range sensitiveProviders {
+		if !declaresAny(manifests, p.Permission) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 {
+		return findings
+	}
+
+	target, _ := filepath.Rel(proj.dir, appManifest(manifests, proj.dir))
+	reported := make(map[string]bool)
+	for _, cf := range proj.sources {
+		content, ok := proj.read(cf)
+		if !ok || !resolverQueryRe.MatchString(content) {
+			continue
+		}
+		for _, p := range missing {
+			if reported[p.Name] {
+				continue
+			}
+			m := p.URIRe.FindString(content)
+			if m == "" {
+				continue
+			}
+			reported[p.Name] = true
+			relPath, _ := filepath.Rel(proj.dir, cf)
+			findings = append(findings, providerPermissionFinding(p, m, target, preflight.Location{File: relPath, Line: findLineNumber(content, m)}))
+		}
+	}
+	return findings
+}
+
+// appManifest returns the path of the app module's manifest, the one the
+// manifest scanner validates, so remediations do not land in a library
+// module. Projects with another layout fall back to the first manifest found.
+func appManifest(manifests []manifestInfo, projectDir string) string {
+	for _, candidate := range manifest.ManifestCandidates(projectDir) {
+		for _, m := range manifests {
+			if m.FilePath == candidate {
+				return m.FilePath
+			}
+		}
+	}
+	return manifests[0].FilePath
+}
+
+// providerPermissionFinding builds the finding for a provider queried
+// without its permission, which the remediation adds to manifest.
+func providerPermissionFinding(p sensitiveProvider, uri, manifest string, loc preflight.Location) preflight.Finding {
+	perm := strings.TrimPrefix(p.Permission, "android.permission.")
+	return preflight.Finding{
+		CheckID:     RuleProviderPermission,
+		Title:       p.Name + " provider queried without " + perm,
+		Description: "Code queries " + uri + " through a ContentResolver, but " + perm + " is not declared in any manifest. The query throws a SecurityException at runtime, which usually means the permission was removed from the manifest while the feature still depends on it.",
+		Severity:    preflight.SeverityError,
+		Location:    loc,
+		Suggestion:  "Declare <uses-permission android:name=\"" + p.Permission + "\" /> and request it at runtime, or remove the " + p.Name + " query. Use a picker intent such as ACTION_PICK when the app only needs a single entry chosen by the user.",
+		Remediation: &preflight.Remediation{Type: preflight.RemediationAddPermission, Target: manifest, Value: p.Permission},
+	}
+}
@@ -0,0 +1,38 @@
+package datasafety
+
+import (
+	"sort"
+
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// Rules returns every rule the data safety checker can report.
+func Rules() []preflight.RuleInfo {
+	checkerID := (&Checker{}).ID()
+	rules := []preflight.RuleInfo{
+		{ID: "PDS001", Title: "Privacy policy URL not found", Severity: preflight.SeverityError, Category: policies.CategoryPrivacyDataSafety},
+		{ID: "PDS002", Title: "Permission requires data safety disclosure", Severity: preflight.SeverityWarning, Category: policies.CategoryPrivacyDataSafety},
+		{ID: "PDS003", Title: "Data collection without apparent consent", Severity: preflight.SeverityWarning, Category: policies.CategoryPrivacyDataSafety},
+		{ID: "PDS004", Title: "No runtime permission request detected", Severity: preflight.SeverityError, Category: policies.CategoryPrivacyDataSafety},
+		{ID: "AD001", Title: "Account deletion not found", Severity: preflight.SeverityError, Category: policies.CategoryAccountManagement},
+		{ID: "AD002", Title: "Data deletion request URL not found", Severity: preflight.SeverityWarning, Category: policies.CategoryAccountManagement},
+		{ID: RulePhotoPicker, Title: "Broad media permission where the Photo Picker would suffice", Severity: preflight.SeverityInfo, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleBackgroundLocation, Title: "Background location access declared", Severity: preflight.SeverityError, Category: policies.CategoryDangerousPermissions},
+		{ID: "DP011", Title: "Notifications used without POST_NOTIFICATIONS permission", Severity: preflight.SeverityError, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleProviderPermission, Title: "Provider queried without its read permission", Severity: preflight.SeverityError, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleSDKDisclosure, Title: "Third-party SDK requires data safety disclosure", Severity: preflight.SeverityWarning, Category: policies.CategorySDKCompliance},
+		{ID: "SDK002", Title: "Outdated or unpinned SDK version", Severity: preflight.SeverityWarning, Category: policies.CategorySDKCompliance},
+		{ID: "SDK004", Title: "Declared permission not used in code", Severity: preflight.SeverityWarning, Category: policies.CategorySDKCompliance},
+		{ID: RulePlayServicesCheck, Title: "Google Play services used without an availability check", Severity: preflight.SeverityInfo, Category: policies.CategorySDKCompliance},
+		{ID: "MP001", Title: "Play Billing detected: subscription disclosures required", Severity: preflight.SeverityInfo, Category: policies.CategoryMonetization},
+		{ID: "MP002", Title: "Non-Play payment SDK alongside digital goods", Severity: preflight.SeverityWarning, Category: policies.CategoryMonetization},
+		{ID: RuleFamiliesAdsSDK, Title: "Ads SDK not certified for Families", Severity: preflight.SeverityCritical, Category: policies.CategoryFamilies},
+		{ID: RuleStoreStrings, Title: "Store-critical string not translated", Severity: preflight.SeverityInfo, Category: policies.CategoryStoreListing},
+	}
+	for i := range rules {
+		rules[i].Scanner = checkerID
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
@@ -0,0 +1,115 @@
+package datasafety
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// gradleCoordinateRe matches a quoted group:name:version coordinate, as used
+// by both Groovy (`implementation 'g:n:1.0'`) and Kotlin DSL
+// (`implementation("g:n:1.0")`) dependency declarations.
+var gradleCoordinateRe = regexp.MustCompile(`["'][\w.\-]+:[\w.\-]+:([^"'\s@]+)(?:@\w+)?["']`)
+
+// gradleNamedVersionRe matches the version of a map-style declaration such as
+// `group: 'g', name: 'n', version: '1.0'` or `version = "1.0"`.
+var gradleNamedVersionRe = regexp.MustCompile(`\bversion\s*[:=]\s*["']([^"']+)["']`)
+
+// dependencyVersion returns the version declared on a Gradle dependency line,
+// or "" if the line has no literal version (BOMs, version catalogs).
+func dependencyVersion(line string) string {
+	if m := gradleCoordinateRe.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	if m := gradleNamedVersionRe.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// isUnpinnedVersion reports whether v is a dynamic version or range rather
+// than a fixed release.
+func isUnpinnedVersion(v string) bool {
+	return strings.Contains(v, "+") ||
+		strings.HasPrefix(v, "[") || strings.HasPrefix(v, "(") ||
+		strings.HasPrefix(v, "latest.")
+}
+
+// compareVersions compares the numeric components of two dotted versions and
+// returns -1, 0, or 1. Qualifiers such as "-alpha01" are ignored.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	if i := strings.IndexAny(v, "-_ "); i >= 0 {
+		v = v[:i]
+	}
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// checkSDKVersion checks the version declared for sdk on the given 1-based
+// line of a Gradle file. It returns a finding for unpinned versions and for
+// versions older than sdk.MinVersion, or nil otherwise.
+func checkSDKVersion(sdk sdkInfo, content, relPath string, line int) *preflight.Finding {
+	if sdk.MinVersion == "" || line <= 0 {
+		return nil
+	}
+	lines := strings.Split(content, "\n")
+	if line > len(lines) {
+		return nil
+	}
+	version := dependencyVersion(lines[line-1])
+	if version == "" {
+		return nil
+	}
+
+	loc := preflight.Location{File: relPath, Line: line}
+	if isUnpinnedVersion(version) {
+		return &preflight.Finding{
+			CheckID:     "SDK002",
+			Title:       "Unpinned version for " + sdk.Name,
+			Description: sdk.Name + " is declared with dynamic version \"" + version + "\", so builds may pick up any release, including ones with known problems.",
+			Severity:    preflight.SeverityWarning,
+			Location:    loc,
+			Suggestion:  "Pin " + sdk.Name + " to a fixed version, " + sdk.MinVersion + " or later.",
+		}
+	}
+	if compareVersions(version, sdk.MinVersion) < 0 {
+		return &preflight.Finding{
+			CheckID:     "SDK002",
+			Title:       "Outdated " + sdk.Name + " version",
+			Description: sdk.Name + " " + version + " is older than " + sdk.MinVersion + ". " + sdk.VersionNote,
+			Severity:    preflight.SeverityWarning,
+			Location:    loc,
+			Suggestion:  "Update " + sdk.Name + " to " + sdk.MinVersion + " or later.",
+		}
+	}
+	return nil
+}
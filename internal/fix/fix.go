@@ -0,0 +1,148 @@
+// Package fix generates and applies line-based patches for findings that
+// have a deterministic fix.
+package fix
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change in
+// a unified diff.
+const contextLines = 3
+
+// Edit replaces a single 1-based line with zero or more lines. Lines carry no
+// trailing newline.
+type Edit struct {
+	Line int
+	New  []string
+}
+
+// FilePatch is a set of edits against one file.
+type FilePatch struct {
+	Path    string
+	Content []byte
+	Edits   []Edit
+}
+
+// lines splits content into lines without their trailing "\n". A final
+// newline does not produce an empty last line.
+func lines(content []byte) []string {
+	s := strings.TrimSuffix(string(content), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// sortedEdits returns the edits ordered by line, keeping the first edit when
+// several target the same line and dropping edits outside the file.
+func (p *FilePatch) sortedEdits() []Edit {
+	n := len(lines(p.Content))
+	edits := append([]Edit(nil), p.Edits...)
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Line < edits[j].Line })
+	out := edits[:0]
+	for _, e := range edits {
+		if e.Line < 1 || e.Line > n {
+			continue
+		}
+		if len(out) > 0 && out[len(out)-1].Line == e.Line {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// Result returns the file content with all edits applied.
+func (p *FilePatch) Result() []byte {
+	orig := lines(p.Content)
+	edits := p.sortedEdits()
+
+	var b strings.Builder
+	next := 0
+	for i, line := range orig {
+		if next < len(edits) && edits[next].Line == i+1 {
+			for _, nl := range edits[next].New {
+				b.WriteString(nl)
+				b.WriteByte('\n')
+			}
+			next++
+			continue
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	out := b.String()
+	if !strings.HasSuffix(string(p.Content), "\n") {
+		out = strings.TrimSuffix(out, "\n")
+	}
+	return []byte(out)
+}
+
+// Diff returns the patch as a unified diff. name is the path written in the
+// --- and +++ headers, with git-style a/ and b/ prefixes.
+func (p *FilePatch) Diff(name string) string {
+	orig := lines(p.Content)
+	edits := p.sortedEdits()
+	if len(edits) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+
+	delta := 0 // lines added minus removed by earlier hunks
+	for start := 0; start < len(edits); {
+		// Group edits whose context windows touch into one hunk.
+		end := start + 1
+		for end < len(edits) && edits[end].Line-edits[end-1].Line <= 2*contextLines+1 {
+			end++
+		}
+		hunk := edits[start:end]
+
+		from := max(1, hunk[0].Line-contextLines)
+		to := min(len(orig), hunk[len(hunk)-1].Line+contextLines)
+
+		var body strings.Builder
+		oldLen, newLen := 0, 0
+		next := 0
+		for ln := from; ln <= to; ln++ {
+			if next < len(hunk) && hunk[next].Line == ln {
+				fmt.Fprintf(&body, "-%s\n", orig[ln-1])
+				oldLen++
+				for _, nl := range hunk[next].New {
+					fmt.Fprintf(&body, "+%s\n", nl)
+					newLen++
+				}
+				next++
+				continue
+			}
+			fmt.Fprintf(&body, " %s\n", orig[ln-1])
+			oldLen++
+			newLen++
+		}
+
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", from, oldLen, from+delta, newLen)
+		b.WriteString(body.String())
+		delta += newLen - oldLen
+		start = end
+	}
+
+	return b.String()
+}
+
+// Write applies the patch to the file at Path, keeping its permissions.
+func (p *FilePatch) Write() error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(p.Path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(p.Path, p.Result(), mode); err != nil {
+		return fmt.Errorf("writing %s: %w", p.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilePatch_ResultAndDiff(t *testing.T) {
+	content := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n"
+	p := &FilePatch{
+		Content: []byte(content),
+		Edits: []Edit{
+			{Line: 12, New: []string{"L"}},
+			{Line: 2, New: []string{"b", "b2"}},
+		},
+	}
+
+	wantResult := "a\nb\nb2\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\nm\nn\n"
+	if got := string(p.Result()); got != wantResult {
+		t.Errorf("Result() =\n%q\nwant\n%q", got, wantResult)
+	}
+
+	wantDiff := `--- a/file.txt
++++ b/file.txt
+@@ -1,5 +1,6 @@
+ a
+-b
++b
++b2
+ c
+ d
+ e
+@@ -9,6 +10,6 @@
+ i
+ j
+ k
+-l
++L
+ m
+ n
+`
+	if got := p.Diff("file.txt"); got != wantDiff {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, wantDiff)
+	}
+}
+
+func TestFilePatch_MergesNearbyEdits(t *testing.T) {
+	p := &FilePatch{
+		Content: []byte("1\n2\n3\n4\n5\n6\n"),
+		Edits:   []Edit{{Line: 2, New: []string{"two"}}, {Line: 5, New: []string{"five"}}},
+	}
+	want := "--- a/f\n+++ b/f\n@@ -1,6 +1,6 @@\n 1\n-2\n+two\n 3\n 4\n-5\n+five\n 6\n"
+	if got := p.Diff("f"); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFilePatch_NoTrailingNewline(t *testing.T) {
+	p := &FilePatch{
+		Content: []byte("x\ny"),
+		Edits:   []Edit{{Line: 2, New: []string{"z"}}},
+	}
+	if got := string(p.Result()); got != "x\nz" {
+		t.Errorf("Result() = %q, want %q", got, "x\nz")
+	}
+}
+
+func TestFilePatch_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f.txt")
+	if err := os.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p := &FilePatch{Path: path, Content: []byte("old\n"), Edits: []Edit{{Line: 1, New: []string{"new"}}}}
+	if err := p.Write(); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new\n" {
+		t.Errorf("file content = %q, want %q", data, "new\n")
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600 to be kept, got %v", info.Mode().Perm())
+	}
+}
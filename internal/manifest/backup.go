@@ -0,0 +1,101 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// DataExtractionRulesSDK is the Android version (API 31, Android 12) from
+// which android:dataExtractionRules replaces android:fullBackupContent.
+const DataExtractionRulesSDK = 31
+
+// CheckBackupRules warns when backups are enabled, explicitly or by default,
+// for an app that declares sensitive permissions, but no backup rules file
+// with <exclude> entries is declared. Referenced rules files are read when
+// they can be found next to the manifest; unresolvable references are
+// assumed to contain exclusions.
+func (v *Validator) CheckBackupRules() []preflight.Finding {
+	m := v.manifest
+	if m.AllowBackup != nil && !*m.AllowBackup {
+		return nil
+	}
+	if strings.EqualFold(m.FullBackupContent, "false") {
+		return nil
+	}
+
+	var sensitive []string
+	for _, p := range m.Permissions {
+		if _, ok := dangerousPermissions[p.Name]; ok {
+			sensitive = append(sensitive, shortPermName(p.Name))
+		}
+	}
+	if len(sensitive) == 0 {
+		return nil
+	}
+
+	var declared, withoutExcludes []string
+	for _, ref := range []string{m.DataExtractionRules, m.FullBackupContent} {
+		if ref == "" {
+			continue
+		}
+		declared = append(declared, ref)
+		if path, ok := resolveXMLResource(m, ref); ok && !hasBackupExcludes(path) {
+			withoutExcludes = append(withoutExcludes, ref)
+		}
+	}
+	if len(declared) > len(withoutExcludes) {
+		return nil
+	}
+
+	backups := "Backups are enabled by default"
+	if m.AllowBackup != nil {
+		backups = "android:allowBackup is true"
+	}
+	desc := fmt.Sprintf("%s and no backup rules file is declared, so all app data is copied to Google Drive and to new devices, including data collected with sensitive permissions (%s).", backups, strings.Join(sensitive, ", "))
+	if len(withoutExcludes) > 0 {
+		desc = fmt.Sprintf("%s and the declared backup rules (%s) contain no <exclude> entries, so all app data is copied to Google Drive and to new devices, including data collected with sensitive permissions (%s).", backups, strings.Join(withoutExcludes, ", "), strings.Join(sensitive, ", "))
+	}
+	suggestion := "Exclude tokens, databases, and files with personal data from backups in android:fullBackupContent rules, or set android:allowBackup=\"false\"."
+	if m.TargetSdkVersion >= DataExtractionRulesSDK {
+		suggestion = "Declare android:dataExtractionRules (Android 12+) and android:fullBackupContent (Android 11 and lower) with <exclude> entries for tokens, databases, and files with personal data, or set android:allowBackup=\"false\"."
+	}
+
+	return []preflight.Finding{{
+		CheckID:     RuleBackupRules,
+		Title:       "Backups enabled without exclusion rules",
+		Description: desc,
+		Severity:    preflight.SeverityWarning,
+		Location:    preflight.Location{File: m.filePath, Line: m.ApplicationLine},
+		Suggestion:  suggestion,
+	}}
+}
+
+// hasBackupExcludes reports whether the backup rules file at path has at
+// least one <exclude> element. Unreadable or malformed files count as having
+// exclusions so they are not reported as empty.
+func hasBackupExcludes(path string) bool {
+	data, err := utils.ReadFileWithLimit(path)
+	if err != nil {
+		return true
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return false
+		}
+		if err != nil {
+			return true
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "exclude" {
+			return true
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package manifest
+
+import "github.com/kotaroyamazaki/playcheck/internal/preflight"
+
+const (
+	permBluetoothScan = "android.permission.BLUETOOTH_SCAN"
+	flagNeverLocation = "neverForLocation"
+)
+
+// CheckBluetoothScan suggests android:usesPermissionFlags="neverForLocation"
+// when BLUETOOTH_SCAN is declared without it and the app requests no location
+// permission. Without the flag, scan results are treated as location data and
+// the app is reviewed under the location permission policy.
+func (v *Validator) CheckBluetoothScan() []preflight.Finding {
+	var scan *Permission
+	for i, perm := range v.manifest.Permissions {
+		switch perm.Name {
+		case "android.permission.ACCESS_FINE_LOCATION", "android.permission.ACCESS_COARSE_LOCATION":
+			return nil
+		case permBluetoothScan:
+			scan = &v.manifest.Permissions[i]
+		}
+	}
+	if scan == nil || scan.HasFlag(flagNeverLocation) {
+		return nil
+	}
+	return []preflight.Finding{{
+		CheckID:     RuleLocationPerm,
+		Title:       "BLUETOOTH_SCAN without neverForLocation",
+		Description: "BLUETOOTH_SCAN is declared without android:usesPermissionFlags=\"neverForLocation\" and the app requests no location permission. Without the flag, Bluetooth scan results can be used to derive location, which can trigger a location permission review.",
+		Severity:    preflight.SeverityWarning,
+		Location:    preflight.Location{File: v.manifest.filePath, Line: scan.Line},
+		Suggestion:  "If the app never derives physical location from scan results, add android:usesPermissionFlags=\"neverForLocation\" to the BLUETOOTH_SCAN <uses-permission> element.",
+	}}
+}
@@ -0,0 +1,68 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// bootActions are the broadcasts that start an app when the device boots.
+var bootActions = []string{
+	"android.intent.action.BOOT_COMPLETED",
+	"android.intent.action.LOCKED_BOOT_COMPLETED",
+}
+
+// foregroundStartRe matches code that starts a foreground service.
+var foregroundStartRe = regexp.MustCompile(`\bstartForegroundService\s*\(`)
+
+// CheckBootReceivers notes receivers that start the app on boot, which
+// reviewers check against the background execution and battery policies.
+// When the validator has a project directory and the sources start a
+// foreground service, the finding is a warning: from Android 15, receivers
+// of BOOT_COMPLETED may not start most foreground service types.
+func (v *Validator) CheckBootReceivers() []preflight.Finding {
+	m := v.manifest
+	var findings []preflight.Finding
+	var fgsSource string
+	searched := false
+	for _, r := range m.Receivers {
+		action := bootAction(r.IntentFilters)
+		if action == "" {
+			continue
+		}
+		if !searched && v.projectDir != "" {
+			fgsSource = findSource(v.projectDir, foregroundStartRe, v.walkOpts...)
+			searched = true
+		}
+		name := shortComponentName(r.Name)
+		f := preflight.Finding{
+			CheckID:     RuleBootReceiver,
+			Title:       fmt.Sprintf("Receiver starts on boot: %s", name),
+			Description: fmt.Sprintf("Receiver %q handles %s, so the app runs in the background every time the device starts. Play reviews background work against its battery and background execution policies, and users see boot-time work as battery drain.", r.Name, shortPermName(action)),
+			Severity:    preflight.SeverityInfo,
+			Location:    preflight.Location{File: m.filePath, Line: r.Line},
+			Suggestion:  "Only listen for boot if the app must restore alarms, notifications, or user-visible state. Schedule deferrable work with WorkManager, which persists across reboots, instead.",
+		}
+		if fgsSource != "" {
+			f.Severity = preflight.SeverityWarning
+			f.Description += fmt.Sprintf(" The app also starts a foreground service (%s); apps targeting Android 15 cannot start dataSync, camera, mediaPlayback, phoneCall, mediaProjection, or microphone foreground services from a BOOT_COMPLETED receiver.", fgsSource)
+			f.Suggestion = "Do not start foreground services from the boot receiver. Enqueue WorkManager work instead, or start the service once the user opens the app."
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// bootAction returns the first boot broadcast action in filters, or "".
+func bootAction(filters []IntentFilter) string {
+	for _, f := range filters {
+		for _, a := range f.Actions {
+			if slices.Contains(bootActions, a) {
+				return a
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// implicitBroadcastSDK is the targetSdk from which most implicit broadcasts
+// are no longer delivered to receivers declared in the manifest.
+const implicitBroadcastSDK = 26
+
+// restrictedBroadcasts maps implicit broadcast actions that are not
+// delivered to manifest-declared receivers to the targetSdk from which the
+// restriction applies. Actions at 1 are never delivered to them. Broadcasts
+// exempt from the Android 8.0 limits, such as BOOT_COMPLETED, LOCALE_CHANGED,
+// and MY_PACKAGE_REPLACED, are not listed.
+var restrictedBroadcasts = map[string]int{
+	"android.intent.action.SCREEN_ON":                 1,
+	"android.intent.action.SCREEN_OFF":                1,
+	"android.intent.action.TIME_TICK":                 1,
+	"android.intent.action.BATTERY_CHANGED":           1,
+	"android.net.conn.CONNECTIVITY_CHANGE":            24,
+	"android.hardware.action.NEW_PICTURE":             24,
+	"android.hardware.action.NEW_VIDEO":               24,
+	"android.intent.action.ACTION_POWER_CONNECTED":    implicitBroadcastSDK,
+	"android.intent.action.ACTION_POWER_DISCONNECTED": implicitBroadcastSDK,
+	"android.intent.action.BATTERY_LOW":               implicitBroadcastSDK,
+	"android.intent.action.BATTERY_OKAY":              implicitBroadcastSDK,
+	"android.intent.action.DEVICE_STORAGE_LOW":        implicitBroadcastSDK,
+	"android.intent.action.DEVICE_STORAGE_OK":         implicitBroadcastSDK,
+	"android.intent.action.HEADSET_PLUG":              implicitBroadcastSDK,
+	"android.intent.action.USER_PRESENT":              implicitBroadcastSDK,
+	"android.intent.action.PACKAGE_ADDED":             implicitBroadcastSDK,
+	"android.intent.action.PACKAGE_REMOVED":           implicitBroadcastSDK,
+	"android.intent.action.PACKAGE_REPLACED":          implicitBroadcastSDK,
+	"android.intent.action.PACKAGE_CHANGED":           implicitBroadcastSDK,
+	"android.intent.action.PACKAGE_RESTARTED":         implicitBroadcastSDK,
+	"android.net.wifi.STATE_CHANGE":                   implicitBroadcastSDK,
+	"android.net.wifi.WIFI_STATE_CHANGED":             implicitBroadcastSDK,
+}
+
+// CheckImplicitBroadcasts warns about manifest-declared receivers whose
+// intent filters list implicit broadcasts that the app's targetSdk no longer
+// delivers to them, so the receiver silently never runs for those actions.
+// When the targetSdk is unknown, every restricted action is reported.
+func (v *Validator) CheckImplicitBroadcasts() []preflight.Finding {
+	m := v.manifest
+	var findings []preflight.Finding
+	for _, r := range m.Receivers {
+		actions := v.restrictedActions(r.IntentFilters)
+		if len(actions) == 0 {
+			continue
+		}
+		names := make([]string, len(actions))
+		for i, a := range actions {
+			names[i] = shortPermName(a)
+		}
+		findings = append(findings, preflight.Finding{
+			CheckID:     RuleImplicitBroadcast,
+			Title:       fmt.Sprintf("Receiver declared for restricted implicit broadcasts: %s", shortComponentName(r.Name)),
+			Description: fmt.Sprintf("Receiver %q is declared in the manifest for %s. Since Android 8.0 (API 26), apps cannot receive most implicit broadcasts through manifest-declared receivers, and these actions are not delivered to the receiver at the app's target SDK.", r.Name, strings.Join(names, ", ")),
+			Severity:    preflight.SeverityWarning,
+			Location:    preflight.Location{File: m.filePath, Line: r.Line},
+			Suggestion:  "Register the receiver at runtime with Context.registerReceiver while the app needs the broadcast, or use WorkManager constraints (network, charging, storage, battery) or JobScheduler instead of listening for state changes.",
+		})
+	}
+	return findings
+}
+
+// restrictedActions returns the actions in filters that are not delivered to
+// manifest-declared receivers at the app's targetSdk.
+func (v *Validator) restrictedActions(filters []IntentFilter) []string {
+	target := v.manifest.TargetSdkVersion
+	var actions []string
+	for _, f := range filters {
+		for _, a := range f.Actions {
+			since, ok := restrictedBroadcasts[a]
+			if ok && (target == 0 || target >= since) {
+				actions = append(actions, a)
+			}
+		}
+	}
+	return actions
+}
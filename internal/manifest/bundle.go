@@ -0,0 +1,329 @@
+package manifest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// BaseModule is the name of the module every App Bundle must contain.
+const BaseModule = "base"
+
+// Bundle holds the manifests of the modules in an Android App Bundle (.aab).
+type Bundle struct {
+	Path    string
+	Modules []BundleModule // base first, then feature modules by name
+}
+
+// BundleModule is one module of an App Bundle.
+type BundleModule struct {
+	Name     string
+	Manifest *AndroidManifest
+}
+
+// Base returns the base module's manifest.
+func (b *Bundle) Base() *AndroidManifest {
+	for _, mod := range b.Modules {
+		if mod.Name == BaseModule {
+			return mod.Manifest
+		}
+	}
+	return nil
+}
+
+// IsBundlePath reports whether path names an Android App Bundle.
+func IsBundlePath(p string) bool {
+	return strings.EqualFold(path.Ext(p), ".aab")
+}
+
+// ParseBundle reads the module manifests of the App Bundle at bundlePath.
+// Bundles store each manifest at <module>/manifest/AndroidManifest.xml in
+// aapt2's protobuf XML format, which is converted to text and handed to Parse.
+// Finding locations point into the bundle as "<bundle>!/<entry>".
+func ParseBundle(bundlePath string) (*Bundle, error) {
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening bundle: %w", err)
+	}
+	defer zr.Close()
+
+	b := &Bundle{Path: bundlePath}
+	for _, f := range zr.File {
+		module, ok := strings.CutSuffix(f.Name, "/manifest/AndroidManifest.xml")
+		if !ok || module == "" || strings.Contains(module, "/") {
+			continue
+		}
+		m, err := parseBundleManifest(f)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", f.Name, err)
+		}
+		m.filePath = bundlePath + "!/" + f.Name
+		b.Modules = append(b.Modules, BundleModule{Name: module, Manifest: m})
+	}
+	if b.Base() == nil {
+		return nil, fmt.Errorf("%s has no %s/manifest/AndroidManifest.xml", bundlePath, BaseModule)
+	}
+	sort.Slice(b.Modules, func(i, j int) bool {
+		a, c := b.Modules[i].Name, b.Modules[j].Name
+		if (a == BaseModule) != (c == BaseModule) {
+			return a == BaseModule
+		}
+		return a < c
+	})
+	return b, nil
+}
+
+func parseBundleManifest(f *zip.File) (*AndroidManifest, error) {
+	if f.UncompressedSize64 > utils.MaxFileSize {
+		return nil, fmt.Errorf("manifest exceeds %d bytes", utils.MaxFileSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, utils.MaxFileSize))
+	if err != nil {
+		return nil, err
+	}
+	text, err := protoXMLToText(data)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(text)
+}
+
+// CheckBundleModules checks launcher placement across bundle modules. The
+// base module's launcher is covered by CheckLauncherActivity; a launcher in a
+// dynamic feature module is an error because the feature may not be
+// installed when the user taps the icon.
+func CheckBundleModules(b *Bundle) []preflight.Finding {
+	var findings []preflight.Finding
+	for _, mod := range b.Modules {
+		if mod.Name == BaseModule {
+			continue
+		}
+		for _, a := range mod.Manifest.Activities {
+			if !isLauncherActivity(a) {
+				continue
+			}
+			findings = append(findings, preflight.Finding{
+				CheckID:     RuleLauncherActivity,
+				Title:       fmt.Sprintf("Launcher activity in feature module %q", mod.Name),
+				Description: fmt.Sprintf("Activity %s in dynamic feature module %q declares ACTION_MAIN with CATEGORY_LAUNCHER. Feature modules can be delivered on demand, so the launcher entry must live in the base module.", shortComponentName(a.Name), mod.Name),
+				Severity:    preflight.SeverityError,
+				Location:    preflight.Location{File: mod.Manifest.filePath, Line: a.Line},
+				Suggestion:  "Move the launcher intent-filter to an activity in the base module, and start the feature activity from there once the module is installed.",
+			})
+		}
+	}
+	return findings
+}
+
+// protoXMLToText renders an aapt2 protobuf XmlNode as XML text. Elements are
+// placed on their recorded source lines so that finding line numbers match
+// the merged manifest the bundle was built from.
+func protoXMLToText(data []byte) ([]byte, error) {
+	root, err := decodeXMLNode(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.element == nil {
+		return nil, errors.New("manifest has no root element")
+	}
+	w := &protoXMLWriter{line: 1}
+	w.writeElement(root.element, root.line)
+	return w.buf.Bytes(), nil
+}
+
+type protoXMLNode struct {
+	element *protoXMLElement
+	line    int
+}
+
+type protoXMLElement struct {
+	namespaces []protoXMLNamespace
+	name       string
+	attrs      []protoXMLAttr
+	children   []protoXMLNode
+}
+
+type protoXMLNamespace struct{ prefix, uri string }
+
+type protoXMLAttr struct{ uri, name, value string }
+
+type protoXMLWriter struct {
+	buf      bytes.Buffer
+	line     int
+	prefixes map[string]string // namespace URI -> prefix
+}
+
+func (w *protoXMLWriter) writeElement(e *protoXMLElement, line int) {
+	if w.prefixes == nil {
+		w.prefixes = make(map[string]string)
+	}
+	for w.line < line {
+		w.buf.WriteByte('\n')
+		w.line++
+	}
+	w.buf.WriteByte('<')
+	w.buf.WriteString(e.name)
+	for _, ns := range e.namespaces {
+		w.prefixes[ns.uri] = ns.prefix
+		fmt.Fprintf(&w.buf, " xmlns:%s=%q", ns.prefix, ns.uri)
+	}
+	for _, a := range e.attrs {
+		w.buf.WriteByte(' ')
+		if p := w.prefixes[a.uri]; p != "" {
+			w.buf.WriteString(p + ":")
+		}
+		w.buf.WriteString(a.name + `="`)
+		_ = xml.EscapeText(&w.buf, []byte(a.value))
+		w.buf.WriteByte('"')
+	}
+	w.buf.WriteByte('>')
+	for _, c := range e.children {
+		if c.element != nil {
+			w.writeElement(c.element, c.line)
+		}
+	}
+	fmt.Fprintf(&w.buf, "</%s>", e.name)
+}
+
+// The decoders below read the subset of aapt2's Resources.proto needed for
+// manifests:
+//
+//	XmlNode      { XmlElement element = 1; string text = 2; SourcePosition source = 3; }
+//	XmlElement   { repeated XmlNamespace namespace_declaration = 1; string namespace_uri = 2;
+//	               string name = 3; repeated XmlAttribute attribute = 4; repeated XmlNode child = 5; }
+//	XmlNamespace { string prefix = 1; string uri = 2; }
+//	XmlAttribute { string namespace_uri = 1; string name = 2; string value = 3; }
+//	SourcePosition { uint32 line_number = 1; }
+
+func decodeXMLNode(data []byte) (protoXMLNode, error) {
+	var n protoXMLNode
+	err := walkProto(data, func(field int, v uint64, b []byte) error {
+		switch field {
+		case 1:
+			e, err := decodeXMLElement(b)
+			if err != nil {
+				return err
+			}
+			n.element = e
+		case 3:
+			return walkProto(b, func(field int, v uint64, _ []byte) error {
+				if field == 1 {
+					n.line = int(v)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return n, err
+}
+
+func decodeXMLElement(data []byte) (*protoXMLElement, error) {
+	e := &protoXMLElement{}
+	err := walkProto(data, func(field int, _ uint64, b []byte) error {
+		switch field {
+		case 1:
+			var ns protoXMLNamespace
+			err := walkProto(b, func(field int, _ uint64, b []byte) error {
+				switch field {
+				case 1:
+					ns.prefix = string(b)
+				case 2:
+					ns.uri = string(b)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			e.namespaces = append(e.namespaces, ns)
+		case 3:
+			e.name = string(b)
+		case 4:
+			var a protoXMLAttr
+			err := walkProto(b, func(field int, _ uint64, b []byte) error {
+				switch field {
+				case 1:
+					a.uri = string(b)
+				case 2:
+					a.name = string(b)
+				case 3:
+					a.value = string(b)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			e.attrs = append(e.attrs, a)
+		case 5:
+			child, err := decodeXMLNode(b)
+			if err != nil {
+				return err
+			}
+			e.children = append(e.children, child)
+		}
+		return nil
+	})
+	return e, err
+}
+
+var errMalformedProto = errors.New("malformed protobuf manifest")
+
+// walkProto calls fn for each field in a protobuf message. Varint fields are
+// passed in v and length-delimited fields in b; fixed-width fields are skipped.
+func walkProto(data []byte, fn func(field int, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errMalformedProto
+		}
+		data = data[n:]
+		field := int(key >> 3)
+		var v uint64
+		var b []byte
+		switch key & 7 {
+		case 0:
+			v, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errMalformedProto
+			}
+		case 1:
+			n = 8
+		case 2:
+			l, ln := binary.Uvarint(data)
+			if ln <= 0 || l > uint64(len(data)-ln) {
+				return errMalformedProto
+			}
+			b = data[ln : ln+int(l)]
+			n = ln + int(l)
+		case 5:
+			n = 4
+		default:
+			return errMalformedProto
+		}
+		if n > len(data) {
+			return errMalformedProto
+		}
+		data = data[n:]
+		if err := fn(field, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
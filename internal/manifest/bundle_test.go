@@ -0,0 +1,205 @@
+package manifest
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+const androidNS = "http://schemas.android.com/apk/res/android"
+
+// pbElem describes an element to encode as an aapt2 protobuf XmlNode.
+// Attribute names without a colon are unqualified; "android:x" uses the
+// Android namespace.
+type pbElem struct {
+	name     string
+	line     int
+	attrs    []string // name=value pairs
+	children []pbElem
+}
+
+func pbField(field int, data []byte) []byte {
+	out := binary.AppendUvarint(nil, uint64(field<<3|2))
+	out = binary.AppendUvarint(out, uint64(len(data)))
+	return append(out, data...)
+}
+
+func pbVarintField(field int, v int) []byte {
+	return binary.AppendUvarint(binary.AppendUvarint(nil, uint64(field<<3)), uint64(v))
+}
+
+func encodePBNode(e pbElem, root bool) []byte {
+	var el []byte
+	if root {
+		el = append(el, pbField(1, append(pbField(1, []byte("android")), pbField(2, []byte(androidNS))...))...)
+	}
+	el = append(el, pbField(3, []byte(e.name))...)
+	for _, kv := range e.attrs {
+		name, value, _ := strings.Cut(kv, "=")
+		var attr []byte
+		if local, ok := strings.CutPrefix(name, "android:"); ok {
+			attr = append(attr, pbField(1, []byte(androidNS))...)
+			name = local
+		}
+		attr = append(attr, pbField(2, []byte(name))...)
+		attr = append(attr, pbField(3, []byte(value))...)
+		el = append(el, pbField(4, attr)...)
+	}
+	for _, c := range e.children {
+		el = append(el, pbField(5, encodePBNode(c, false))...)
+	}
+	return append(pbField(1, el), pbField(3, pbVarintField(1, e.line))...)
+}
+
+func launcherActivity(name string, line int) pbElem {
+	return pbElem{name: "activity", line: line, attrs: []string{"android:name=" + name, "android:exported=true"}, children: []pbElem{
+		{name: "intent-filter", line: line + 1, children: []pbElem{
+			{name: "action", line: line + 2, attrs: []string{"android:name=android.intent.action.MAIN"}},
+			{name: "category", line: line + 3, attrs: []string{"android:name=android.intent.category.LAUNCHER"}},
+		}},
+	}}
+}
+
+// writeBundle writes an .aab containing the given module manifests.
+func writeBundle(t *testing.T, modules map[string]pbElem) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.aab")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, root := range modules {
+		w, err := zw.Create(name + "/manifest/AndroidManifest.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(encodePBNode(root, true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func baseManifest(children ...pbElem) pbElem {
+	return pbElem{name: "manifest", line: 2, attrs: []string{"package=com.example.app", "android:versionCode=7"}, children: append([]pbElem{
+		{name: "uses-sdk", line: 4, attrs: []string{"android:minSdkVersion=24", "android:targetSdkVersion=35"}},
+		{name: "uses-permission", line: 5, attrs: []string{"android:name=android.permission.CAMERA"}},
+	}, children...)}
+}
+
+func TestParseBundle(t *testing.T) {
+	path := writeBundle(t, map[string]pbElem{
+		"feature": {name: "manifest", line: 1, attrs: []string{"package=com.example.app"}},
+		"base": baseManifest(pbElem{name: "application", line: 7, children: []pbElem{
+			launcherActivity(".MainActivity", 8),
+		}}),
+	})
+
+	b, err := ParseBundle(path)
+	if err != nil {
+		t.Fatalf("ParseBundle failed: %v", err)
+	}
+	if len(b.Modules) != 2 || b.Modules[0].Name != BaseModule || b.Modules[1].Name != "feature" {
+		t.Fatalf("unexpected modules: %+v", b.Modules)
+	}
+
+	m := b.Base()
+	if m.Package != "com.example.app" || m.VersionCode != 7 || m.TargetSdkVersion != 35 {
+		t.Errorf("unexpected manifest: package=%q versionCode=%d target=%d", m.Package, m.VersionCode, m.TargetSdkVersion)
+	}
+	if len(m.Permissions) != 1 || m.Permissions[0].Line != 5 {
+		t.Errorf("expected CAMERA permission at line 5, got %+v", m.Permissions)
+	}
+	if !m.HasLauncherActivity() {
+		t.Error("expected base launcher activity")
+	}
+	if want := path + "!/base/manifest/AndroidManifest.xml"; m.FilePath() != want {
+		t.Errorf("got file path %q, want %q", m.FilePath(), want)
+	}
+}
+
+func TestParseBundle_NoBase(t *testing.T) {
+	path := writeBundle(t, map[string]pbElem{
+		"feature": {name: "manifest", line: 1},
+	})
+	if _, err := ParseBundle(path); err == nil {
+		t.Error("expected error for bundle without base module")
+	}
+}
+
+func TestParseBundle_MalformedManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.aab")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("base/manifest/AndroidManifest.xml")
+	_, _ = w.Write([]byte{0x0a, 0xff})
+	_ = zw.Close()
+	_ = f.Close()
+
+	if _, err := ParseBundle(path); err == nil {
+		t.Error("expected error for truncated protobuf manifest")
+	}
+}
+
+func TestCheckBundleModules(t *testing.T) {
+	path := writeBundle(t, map[string]pbElem{
+		"base": baseManifest(pbElem{name: "application", line: 7, children: []pbElem{
+			launcherActivity(".MainActivity", 8),
+		}}),
+		"camera": {name: "manifest", line: 1, attrs: []string{"package=com.example.app"}, children: []pbElem{
+			{name: "application", line: 3, children: []pbElem{launcherActivity(".CameraActivity", 4)}},
+		}},
+	})
+	b, err := ParseBundle(path)
+	if err != nil {
+		t.Fatalf("ParseBundle failed: %v", err)
+	}
+
+	findings := CheckBundleModules(b)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if f.CheckID != RuleLauncherActivity || f.Severity != preflight.SeverityError {
+		t.Errorf("got %s/%s, want %s/error", f.CheckID, f.Severity, RuleLauncherActivity)
+	}
+	if !strings.HasSuffix(f.Location.File, "!/camera/manifest/AndroidManifest.xml") || f.Location.Line != 4 {
+		t.Errorf("unexpected location %s", f.Location)
+	}
+}
+
+func TestManifestScanner_Bundle(t *testing.T) {
+	path := writeBundle(t, map[string]pbElem{
+		"base": baseManifest(pbElem{name: "application", line: 7}),
+	})
+
+	result, err := NewScanner().Run(path)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, f := range result.Findings {
+		ids[f.CheckID] = true
+	}
+	if !ids[RuleCameraPerm] {
+		t.Error("expected camera permission finding from the base manifest")
+	}
+	if !ids[RuleLauncherActivity] {
+		t.Error("expected missing launcher finding for base module")
+	}
+}
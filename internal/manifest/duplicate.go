@@ -0,0 +1,81 @@
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// CheckDuplicatePermissions reports permissions declared by more than one
+// <uses-permission> element, usually left behind by a manual merge. Exact
+// duplicates are informational; duplicates whose android:maxSdkVersion or
+// android:required differ are a warning, since which declaration the
+// manifest merger keeps decides the devices the permission is requested on.
+func (v *Validator) CheckDuplicatePermissions() []preflight.Finding {
+	m := v.manifest
+	byName := make(map[string][]Permission)
+	var order []string
+	for _, perm := range m.Permissions {
+		if perm.Name == "" {
+			continue
+		}
+		if _, seen := byName[perm.Name]; !seen {
+			order = append(order, perm.Name)
+		}
+		byName[perm.Name] = append(byName[perm.Name], perm)
+	}
+
+	var findings []preflight.Finding
+	for _, name := range order {
+		perms := byName[name]
+		if len(perms) < 2 {
+			continue
+		}
+		lines := make([]string, len(perms))
+		conflict := false
+		for i, p := range perms {
+			lines[i] = strconv.Itoa(p.Line)
+			if p.MaxSdk != perms[0].MaxSdk || p.Required != perms[0].Required {
+				conflict = true
+			}
+		}
+		short := shortPermName(name)
+		f := preflight.Finding{
+			CheckID:     RuleDuplicatePerm,
+			Title:       fmt.Sprintf("Duplicate permission declaration: %s", short),
+			Description: fmt.Sprintf("%s is declared %d times (lines %s). Duplicate declarations usually come from a manual merge and are redundant.", name, len(perms), strings.Join(lines, ", ")),
+			Severity:    preflight.SeverityInfo,
+			Location:    preflight.Location{File: m.filePath, Line: perms[1].Line},
+			Suggestion:  fmt.Sprintf("Keep a single <uses-permission> element for %s.", short),
+		}
+		if conflict {
+			f.Severity = preflight.SeverityWarning
+			f.Description = fmt.Sprintf("%s is declared %d times with different attributes: %s. The manifest merger keeps only one declaration, so the permission may be requested on more or fewer Android versions than intended.", name, len(perms), describePermissionDecls(perms))
+			f.Suggestion = fmt.Sprintf("Keep a single <uses-permission> element for %s with the intended android:maxSdkVersion and android:required values.", short)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// describePermissionDecls summarizes the line, maxSdkVersion, and required
+// attribute of each declaration, e.g. `line 4 (maxSdkVersion=28)`.
+func describePermissionDecls(perms []Permission) string {
+	parts := make([]string, len(perms))
+	for i, p := range perms {
+		var attrs []string
+		if p.MaxSdk > 0 {
+			attrs = append(attrs, fmt.Sprintf("maxSdkVersion=%d", p.MaxSdk))
+		}
+		if !p.Required {
+			attrs = append(attrs, "required=false")
+		}
+		if len(attrs) == 0 {
+			attrs = append(attrs, "no cap")
+		}
+		parts[i] = fmt.Sprintf("line %d (%s)", p.Line, strings.Join(attrs, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,78 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// locationFeatureSDK is the targetSdk (Android 5.0) from which location
+// permissions no longer imply the GPS and network location features.
+const locationFeatureSDK = 21
+
+// impliedFeatures maps permissions to the hardware features Google Play
+// treats as required when the manifest does not declare them with
+// <uses-feature>. See
+// https://developer.android.com/guide/topics/manifest/uses-feature-element#permissions.
+var impliedFeatures = map[string]struct {
+	Features    []string
+	BelowTarget int // implied only below this targetSdk; 0 if always
+}{
+	"android.permission.CAMERA":                 {Features: []string{"android.hardware.camera", "android.hardware.camera.autofocus"}},
+	"android.permission.RECORD_AUDIO":           {Features: []string{"android.hardware.microphone"}},
+	"android.permission.CALL_PHONE":             {Features: []string{"android.hardware.telephony"}},
+	"android.permission.SEND_SMS":               {Features: []string{"android.hardware.telephony"}},
+	"android.permission.RECEIVE_SMS":            {Features: []string{"android.hardware.telephony"}},
+	"android.permission.ACCESS_FINE_LOCATION":   {Features: []string{"android.hardware.location", "android.hardware.location.gps"}, BelowTarget: locationFeatureSDK},
+	"android.permission.ACCESS_COARSE_LOCATION": {Features: []string{"android.hardware.location", "android.hardware.location.network"}, BelowTarget: locationFeatureSDK},
+}
+
+// CheckImpliedFeatures warns when a permission implies a hardware feature
+// that the manifest does not declare with <uses-feature>. Google Play then
+// treats the feature as required and hides the app from devices without it,
+// e.g. a camera permission hides the app from Chromebooks and tablets
+// without a rear camera. Features declared with any android:required value
+// are considered intentional.
+func (v *Validator) CheckImpliedFeatures() []preflight.Finding {
+	declared := make(map[string]bool, len(v.manifest.Features))
+	for _, f := range v.manifest.Features {
+		declared[f.Name] = true
+	}
+
+	var findings []preflight.Finding
+	reported := make(map[string]bool)
+	for _, perm := range v.manifest.Permissions {
+		implied, ok := impliedFeatures[perm.Name]
+		if !ok || (implied.BelowTarget > 0 && v.manifest.TargetSdkVersion >= implied.BelowTarget) {
+			continue
+		}
+		var missing []string
+		for _, feature := range implied.Features {
+			if !declared[feature] && !reported[feature] {
+				missing = append(missing, feature)
+				reported[feature] = true
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		var decls []string
+		for _, feature := range missing {
+			decls = append(decls, fmt.Sprintf("<uses-feature android:name=%q android:required=\"false\" />", feature))
+		}
+		findings = append(findings, preflight.Finding{
+			CheckID:     RuleImpliedFeature,
+			Title:       fmt.Sprintf("%s implies required hardware", shortPermName(perm.Name)),
+			Description: fmt.Sprintf("%s implies the hardware feature %s, which is not declared with <uses-feature>. Google Play treats implied features as required and does not offer the app to devices without them, reducing the install base.", shortPermName(perm.Name), strings.Join(missing, ", ")),
+			Severity:    preflight.SeverityWarning,
+			Location: preflight.Location{
+				File: v.manifest.filePath,
+				Line: perm.Line,
+			},
+			Suggestion: fmt.Sprintf("If the app works without the hardware, declare %s and check for it at runtime with PackageManager.hasSystemFeature. Otherwise declare it with android:required=\"true\" to make the requirement explicit.", strings.Join(decls, " and ")),
+		})
+	}
+	return findings
+}
@@ -0,0 +1,114 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// fileProviderPathsMeta is the <meta-data> name under which a FileProvider
+// references its paths XML. AndroidX keeps the support library name.
+const fileProviderPathsMeta = "android.support.FILE_PROVIDER_PATHS"
+
+// broadFileProviderRoots maps the FileProvider path elements whose base
+// directory is shared with other apps or the whole device to what an entry
+// covering all of it exposes.
+var broadFileProviderRoots = map[string]string{
+	"root-path":     "the entire device filesystem",
+	"external-path": "all of shared external storage",
+}
+
+// fileProviderPath is a path entry of a FileProvider paths XML file.
+type fileProviderPath struct {
+	Element string // e.g. "external-path"
+	Name    string
+	Path    string
+	Line    int
+}
+
+// CheckFileProviderPaths warns when a FileProvider's paths XML shares the
+// root of the device filesystem or of external storage. Such a provider can
+// hand out a content URI for any file there, so a single path traversal or
+// URI injection bug exposes everything. Unresolvable paths references are
+// skipped.
+func (v *Validator) CheckFileProviderPaths() []preflight.Finding {
+	var findings []preflight.Finding
+	for _, p := range v.manifest.Providers {
+		if p.PathsResource == "" {
+			continue
+		}
+		path, ok := resolveXMLResource(v.manifest, p.PathsResource)
+		if !ok {
+			continue
+		}
+		entries, err := parseFileProviderPaths(path)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			exposes, ok := broadFileProviderRoots[e.Element]
+			if !ok || !coversRoot(e.Path) {
+				continue
+			}
+			findings = append(findings, preflight.Finding{
+				CheckID:     RuleFileProviderPaths,
+				Title:       fmt.Sprintf("FileProvider shares %s: %s", exposes, shortComponentName(p.Name)),
+				Description: fmt.Sprintf("<%s name=%q path=%q> in %s lets FileProvider %q create content URIs for %s, not just the files the app means to share.", e.Element, e.Name, e.Path, p.PathsResource, p.Name, exposes),
+				Severity:    preflight.SeverityWarning,
+				Location:    preflight.Location{File: path, Line: e.Line},
+				Suggestion:  "Share only dedicated subdirectories, e.g. <cache-path name=\"shared\" path=\"shared/\" /> or <external-files-path name=\"exports\" path=\"exports/\" />, and remove <root-path> entries.",
+			})
+		}
+	}
+	return findings
+}
+
+// coversRoot reports whether a FileProvider path attribute selects the whole
+// base directory rather than a subdirectory of it.
+func coversRoot(path string) bool {
+	p := strings.Trim(strings.TrimSpace(path), "/")
+	return p == "" || p == "."
+}
+
+// parseFileProviderPaths reads the path entries of a FileProvider paths XML
+// file.
+func parseFileProviderPaths(path string) ([]fileProviderPath, error) {
+	data, err := utils.ReadFileWithLimit(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := newLineTracker(data)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var entries []fileProviderPath
+	for {
+		offset := decoder.InputOffset()
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || !strings.HasSuffix(se.Name.Local, "-path") {
+			continue
+		}
+		e := fileProviderPath{Element: se.Name.Local, Line: lines.lineAt(offset)}
+		for _, attr := range se.Attr {
+			switch attr.Name.Local {
+			case "name":
+				e.Name = attr.Value
+			case "path":
+				e.Path = attr.Value
+			}
+		}
+		entries = append(entries, e)
+	}
+}
@@ -0,0 +1,116 @@
+package manifest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/fix"
+)
+
+// componentTagRe matches the opening of a component start tag and captures
+// the text that follows the tag name on the same line.
+var componentTagRe = regexp.MustCompile(`<(activity-alias|activity|service|receiver|provider)(\s.*|>.*|/>.*)?$`)
+
+// FixExportedComponents returns a patch that adds android:exported to every
+// component that has intent-filters but no explicit android:exported. The
+// value is "true" for launcher activities and receivers of system broadcasts,
+// which stop working otherwise, and "false" for everything else. It returns
+// nil when there is nothing to fix or the manifest was not read from a file.
+func (m *AndroidManifest) FixExportedComponents() *fix.FilePatch {
+	if m.filePath == "" || len(m.rawContent) == 0 {
+		return nil
+	}
+
+	type target struct {
+		line  int
+		value string
+	}
+	var targets []target
+	for _, a := range m.Activities {
+		if a.Exported == nil && len(a.IntentFilters) > 0 {
+			targets = append(targets, target{a.Line, fixedExportedValue("Activity", isLauncherActivity(a), a.IntentFilters)})
+		}
+	}
+	for _, s := range m.Services {
+		if s.Exported == nil && len(s.IntentFilters) > 0 {
+			targets = append(targets, target{s.Line, fixedExportedValue("Service", false, s.IntentFilters)})
+		}
+	}
+	for _, r := range m.Receivers {
+		if r.Exported == nil && len(r.IntentFilters) > 0 {
+			targets = append(targets, target{r.Line, fixedExportedValue("Receiver", false, r.IntentFilters)})
+		}
+	}
+	for _, p := range m.Providers {
+		if p.Exported == nil && len(p.IntentFilters) > 0 {
+			targets = append(targets, target{p.Line, fixedExportedValue("Provider", false, p.IntentFilters)})
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	src := strings.Split(string(m.rawContent), "\n")
+	patch := &fix.FilePatch{Path: m.filePath, Content: m.rawContent}
+	for _, t := range targets {
+		if t.line < 1 || t.line > len(src) {
+			continue
+		}
+		if newLines, ok := insertExported(src, t.line, t.value); ok {
+			patch.Edits = append(patch.Edits, fix.Edit{Line: t.line, New: newLines})
+		}
+	}
+	if len(patch.Edits) == 0 {
+		return nil
+	}
+	return patch
+}
+
+// fixedExportedValue returns the android:exported value added to a component
+// of kind that lacks it: "true" for launcher activities and receivers of
+// system broadcasts, which stop working otherwise, and "false" for
+// everything else.
+func fixedExportedValue(kind string, launcher bool, filters []IntentFilter) string {
+	if launcher || (kind == "Receiver" && receivesExternalBroadcast(filters)) {
+		return "true"
+	}
+	return "false"
+}
+
+// insertExported returns the replacement for the start-tag line at the
+// 1-based index line. When the tag name ends the line, the attribute goes on
+// its own line indented like the following attribute; otherwise it is
+// inserted right after the tag name.
+func insertExported(src []string, line int, value string) ([]string, bool) {
+	text := strings.TrimSuffix(src[line-1], "\r")
+	cr := strings.TrimPrefix(src[line-1], text)
+	attr := `android:exported="` + value + `"`
+
+	loc := componentTagRe.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return nil, false
+	}
+	nameEnd := loc[3]
+
+	if strings.TrimSpace(text[nameEnd:]) == "" && line < len(src) {
+		next := strings.TrimSuffix(src[line], "\r")
+		indent := next[:len(next)-len(strings.TrimLeft(next, " \t"))]
+		return []string{text + cr, indent + attr + cr}, true
+	}
+	return []string{text[:nameEnd] + " " + attr + text[nameEnd:] + cr}, true
+}
+
+// receivesExternalBroadcast reports whether any filter handles a system
+// broadcast that is delivered from outside the app. Any action in the
+// android namespace counts, so that only receivers of app-defined actions are
+// treated as internal.
+func receivesExternalBroadcast(filters []IntentFilter) bool {
+	for _, f := range filters {
+		for _, action := range f.Actions {
+			if externalBroadcastActions[action] || strings.HasPrefix(action, "android.") {
+				return true
+			}
+		}
+	}
+	return false
+}
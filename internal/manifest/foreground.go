@@ -0,0 +1,61 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// foregroundTypesRequiredAt is the targetSdk from which each foreground
+// service type needs its FOREGROUND_SERVICE_<TYPE> permission.
+const foregroundTypesRequiredAt = 34
+
+// foregroundServicePermissions maps android:foregroundServiceType values to
+// the permission a service of that type requires. shortService needs none.
+var foregroundServicePermissions = map[string]string{
+	"camera":          "android.permission.FOREGROUND_SERVICE_CAMERA",
+	"connectedDevice": "android.permission.FOREGROUND_SERVICE_CONNECTED_DEVICE",
+	"dataSync":        "android.permission.FOREGROUND_SERVICE_DATA_SYNC",
+	"health":          "android.permission.FOREGROUND_SERVICE_HEALTH",
+	"location":        "android.permission.FOREGROUND_SERVICE_LOCATION",
+	"mediaPlayback":   "android.permission.FOREGROUND_SERVICE_MEDIA_PLAYBACK",
+	"mediaProjection": "android.permission.FOREGROUND_SERVICE_MEDIA_PROJECTION",
+	"microphone":      "android.permission.FOREGROUND_SERVICE_MICROPHONE",
+	"phoneCall":       "android.permission.FOREGROUND_SERVICE_PHONE_CALL",
+	"remoteMessaging": "android.permission.FOREGROUND_SERVICE_REMOTE_MESSAGING",
+	"specialUse":      "android.permission.FOREGROUND_SERVICE_SPECIAL_USE",
+	"systemExempted":  "android.permission.FOREGROUND_SERVICE_SYSTEM_EXEMPTED",
+}
+
+// CheckForegroundServicePermissions checks that every foregroundServiceType
+// declared on a service has its matching FOREGROUND_SERVICE_<TYPE>
+// permission. From Android 14, startForeground throws a SecurityException
+// for a type whose permission is missing, so this is an error for apps
+// targeting API 34 or higher and a warning before that.
+func (v *Validator) CheckForegroundServicePermissions() []preflight.Finding {
+	m := v.manifest
+	severity := preflight.SeverityError
+	if m.TargetSdkVersion > 0 && m.TargetSdkVersion < foregroundTypesRequiredAt {
+		severity = preflight.SeverityWarning
+	}
+
+	var findings []preflight.Finding
+	for _, svc := range m.Services {
+		for _, typ := range svc.ForegroundServiceTypes {
+			perm, ok := foregroundServicePermissions[typ]
+			if !ok || m.HasPermission(perm) {
+				continue
+			}
+			findings = append(findings, preflight.Finding{
+				CheckID:     RuleForegroundPerm,
+				Title:       fmt.Sprintf("Missing %s for %s foreground service", shortPermName(perm), typ),
+				Description: fmt.Sprintf("Service %s declares foregroundServiceType=\"%s\" but the manifest does not request %s. On Android 14 and later, starting the service in the foreground with this type throws a SecurityException for apps targeting API %d or higher.", shortComponentName(svc.Name), typ, shortPermName(perm), foregroundTypesRequiredAt),
+				Severity:    severity,
+				Location:    preflight.Location{File: m.filePath, Line: svc.Line},
+				Suggestion:  fmt.Sprintf("Add <uses-permission android:name=\"%s\" /> to the manifest, or remove the %s type if the service does not need it.", perm, typ),
+				Remediation: &preflight.Remediation{Type: preflight.RemediationAddPermission, Target: m.filePath, Value: perm},
+			})
+		}
+	}
+	return findings
+}
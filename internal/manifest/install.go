@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+const (
+	permInstallPackages        = "android.permission.INSTALL_PACKAGES"
+	permRequestInstallPackages = "android.permission.REQUEST_INSTALL_PACKAGES"
+)
+
+// installCodeRe matches code that installs APKs through the package installer.
+var installCodeRe = regexp.MustCompile(`PackageInstaller|ACTION_INSTALL_PACKAGE|android\.intent\.action\.INSTALL_PACKAGE|application/vnd\.android\.package-archive`)
+
+// CheckInstallPackages flags the permissions that let an app install other
+// APKs. INSTALL_PACKAGES is reserved for system apps; REQUEST_INSTALL_PACKAGES
+// is reviewed under the device and network abuse policy. When the validator
+// has a project directory, the sources are searched for installer usage.
+func (v *Validator) CheckInstallPackages() []preflight.Finding {
+	var findings []preflight.Finding
+	for _, perm := range v.manifest.Permissions {
+		loc := preflight.Location{File: v.manifest.filePath, Line: perm.Line}
+		switch perm.Name {
+		case permInstallPackages:
+			findings = append(findings, preflight.Finding{
+				CheckID:     RuleInstallPackages,
+				Title:       "System-only permission: INSTALL_PACKAGES",
+				Description: "INSTALL_PACKAGES is a signature|privileged permission that is only granted to system apps. Play rejects apps that request it under the device and network abuse policy.",
+				Severity:    preflight.SeverityCritical,
+				Location:    loc,
+				Suggestion:  "Remove INSTALL_PACKAGES. If the app must install APKs, request REQUEST_INSTALL_PACKAGES and use PackageInstaller, which asks the user to confirm each install.",
+			})
+		case permRequestInstallPackages:
+			f := preflight.Finding{
+				CheckID:     RuleInstallPackages,
+				Title:       "Package installation permission: REQUEST_INSTALL_PACKAGES",
+				Description: "REQUEST_INSTALL_PACKAGES lets the app install other APKs. Play only allows it when installing packages is core functionality, requires a Permissions Declaration Form, and prohibits downloading executable code from outside Play under the device and network abuse policy.",
+				Severity:    preflight.SeverityWarning,
+				Location:    loc,
+				Suggestion:  "Keep REQUEST_INSTALL_PACKAGES only if installing packages is core functionality, and complete the Permissions Declaration Form in Play Console.",
+			}
+			if v.projectDir != "" {
+				if src := findSource(v.projectDir, installCodeRe, v.walkOpts...); src != "" {
+					f.Description += fmt.Sprintf(" Package installer usage found in %s.", src)
+				} else {
+					f.Title = "REQUEST_INSTALL_PACKAGES declared without installer usage"
+					f.Suggestion = "No PackageInstaller or ACTION_INSTALL_PACKAGE usage was found. Remove REQUEST_INSTALL_PACKAGES if the app does not install packages."
+				}
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// findSource returns the path, relative to projectDir, of the first Kotlin or
+// Java file matching re, or "" if none does.
+func findSource(projectDir string, re *regexp.Regexp, opts ...utils.WalkOption) string {
+	files, err := utils.WalkFiles(projectDir, append([]utils.WalkOption{utils.WithExtensions(".kt", ".java")}, opts...)...)
+	if err != nil {
+		return ""
+	}
+	for _, f := range files {
+		data, err := utils.ReadFileWithLimit(f)
+		if err != nil || !re.Match(data) {
+			continue
+		}
+		if rel, err := filepath.Rel(projectDir, f); err == nil {
+			return rel
+		}
+		return f
+	}
+	return ""
+}
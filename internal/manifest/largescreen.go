@@ -0,0 +1,42 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// CheckLargeScreenSupport recommends large-screen support when the
+// application or an activity sets android:resizeableActivity="false". Play
+// shows large-screen quality warnings for such apps on tablets, foldables,
+// and ChromeOS devices. Wear OS, TV, and Automotive apps are not checked.
+func (v *Validator) CheckLargeScreenSupport() []preflight.Finding {
+	m := v.manifest
+	if m.FormFactor() != FormFactorPhone {
+		return nil
+	}
+	var findings []preflight.Finding
+	if m.ResizeableActivity != nil && !*m.ResizeableActivity {
+		findings = append(findings, largeScreenFinding(m.filePath, m.ApplicationLine, "The <application> element", "every activity that does not override it"))
+	}
+	for _, a := range m.Activities {
+		if a.Resizeable != nil && !*a.Resizeable {
+			name := shortComponentName(a.Name)
+			findings = append(findings, largeScreenFinding(m.filePath, a.Line, fmt.Sprintf("Activity %q", a.Name), name))
+		}
+	}
+	return findings
+}
+
+// largeScreenFinding builds the finding for a resizeableActivity="false"
+// declared by subject, which applies to scope.
+func largeScreenFinding(file string, line int, subject, scope string) preflight.Finding {
+	return preflight.Finding{
+		CheckID:     RuleLargeScreen,
+		Title:       "Activity is not resizeable on large screens",
+		Description: fmt.Sprintf("%s sets android:resizeableActivity=\"false\", so %s cannot enter multi-window mode and is letterboxed on tablets, foldables, and ChromeOS. Google Play promotes apps with large-screen support and shows quality warnings to users of large-screen devices for apps without it. Android 16 ignores the attribute on large screens for apps targeting API 36.", subject, scope),
+		Severity:    preflight.SeverityInfo,
+		Location:    preflight.Location{File: file, Line: line},
+		Suggestion:  "Remove android:resizeableActivity=\"false\" and support resizing: handle configuration changes, use window size classes for adaptive layouts, and test in multi-window and on a foldable emulator.",
+	}
+}
@@ -1,7 +1,11 @@
 package manifest
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
@@ -105,6 +109,39 @@ func TestParsePermissions(t *testing.T) {
 	}
 }
 
+func TestParsePermissions_SDK23AndToolsRemove(t *testing.T) {
+	m, err := Parse([]byte(`<manifest xmlns:android="http://schemas.android.com/apk/res/android"
+    xmlns:tools="http://schemas.android.com/tools" package="com.example">
+    <uses-permission android:name="android.permission.INTERNET" />
+    <uses-permission-sdk-23 android:name="android.permission.ACCESS_FINE_LOCATION" />
+    <uses-permission android:name="android.permission.READ_PHONE_STATE" tools:node="remove" />
+</manifest>`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(m.Permissions) != 2 {
+		t.Fatalf("got %d permissions, want 2", len(m.Permissions))
+	}
+	if p := m.Permissions[1]; p.Name != "android.permission.ACCESS_FINE_LOCATION" || !p.SDK23 || p.Line != 4 {
+		t.Errorf("Permissions[1] = %+v, want ACCESS_FINE_LOCATION from <uses-permission-sdk-23> at line 4", p)
+	}
+	if m.Permissions[0].SDK23 {
+		t.Error("<uses-permission> should not be marked SDK23")
+	}
+	if m.HasPermission("android.permission.READ_PHONE_STATE") {
+		t.Error("permission removed with tools:node=\"remove\" should not be declared")
+	}
+	if len(m.RemovedPermissions) != 1 || m.RemovedPermissions[0].Name != "android.permission.READ_PHONE_STATE" {
+		t.Errorf("RemovedPermissions = %+v, want READ_PHONE_STATE", m.RemovedPermissions)
+	}
+	for _, f := range NewValidator(m).CheckDangerousPermissions() {
+		if strings.Contains(f.Title, "READ_PHONE_STATE") {
+			t.Errorf("removed permission should not be reported: %s", f.Title)
+		}
+	}
+}
+
 func TestParseComponents(t *testing.T) {
 	m, err := Parse([]byte(sampleManifest))
 	if err != nil {
@@ -233,6 +270,25 @@ func TestValidateDangerousPermissions(t *testing.T) {
 	}
 }
 
+func TestValidateDangerousPermissions_PhoneNumbers(t *testing.T) {
+	m, err := Parse([]byte(`<manifest package="test">
+		<uses-permission android:name="android.permission.READ_PHONE_STATE" />
+		<uses-permission android:name="android.permission.READ_PHONE_NUMBERS" />
+	</manifest>`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	findings := NewValidator(m).CheckDangerousPermissions()
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2", len(findings))
+	}
+	for _, f := range findings {
+		if f.CheckID != RulePhonePerm {
+			t.Errorf("got check ID %s, want %s", f.CheckID, RulePhonePerm)
+		}
+	}
+}
+
 func TestValidateExportedComponents(t *testing.T) {
 	m, err := Parse([]byte(sampleManifest))
 	if err != nil {
@@ -498,8 +554,31 @@ func TestFindAndParse_SrcMainManifest(t *testing.T) {
 func TestFindAndParse_NotFound(t *testing.T) {
 	dir := t.TempDir()
 	_, err := FindAndParse(dir)
-	if err == nil {
-		t.Error("expected error when no manifest found")
+	if !errors.Is(err, ErrManifestNotFound) {
+		t.Errorf("expected ErrManifestNotFound, got %v", err)
+	}
+}
+
+func TestManifestScanner_NoManifest(t *testing.T) {
+	dir := t.TempDir()
+	result, err := NewScanner().Run(dir)
+	if err != nil {
+		t.Fatalf("expected no error for a directory without a manifest, got %v", err)
+	}
+	if result.Err != nil {
+		t.Errorf("expected no result error, got %v", result.Err)
+	}
+	if len(result.Findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(result.Findings))
+	}
+	f := result.Findings[0]
+	if f.CheckID != RuleManifestNotFound || f.Severity != preflight.SeverityWarning {
+		t.Errorf("got %s/%s, want %s/warning", f.CheckID, f.Severity, RuleManifestNotFound)
+	}
+	for _, path := range []string{"app/src/main/AndroidManifest.xml", "src/main/AndroidManifest.xml"} {
+		if !strings.Contains(f.Description, path) {
+			t.Errorf("expected checked path %s in description: %s", path, f.Description)
+		}
 	}
 }
 
@@ -540,3 +619,180 @@ func TestFindAndParse_PriorityOrder(t *testing.T) {
 		t.Errorf("Package = %q, want %q (app/src/main should take priority)", m.Package, "com.example.app")
 	}
 }
+
+func TestParseGradleTargetSDK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"groovy", "defaultConfig {\n    targetSdk 33\n}", 33},
+		{"groovy legacy", "defaultConfig {\n    targetSdkVersion 30\n}", 30},
+		{"kotlin dsl", "defaultConfig {\n    targetSdk = 34\n}", 34},
+		{"kotlin dsl legacy", "defaultConfig {\n    targetSdkVersion(31)\n}", 31},
+		{"missing", "defaultConfig {\n    minSdk 21\n}", 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseGradleTargetSDK(tc.content); got != tc.want {
+				t.Errorf("parseGradleTargetSDK() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveTargetSDK(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(dir+"/app/src/main", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/app/build.gradle", []byte("android {\n  defaultConfig {\n    targetSdk 33\n  }\n}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example"/>`
+	if err := os.WriteFile(dir+"/app/src/main/AndroidManifest.xml", []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ResolveTargetSDK(dir); got != 33 {
+		t.Errorf("expected target SDK 33 from gradle, got %d", got)
+	}
+
+	manifest = `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <uses-sdk android:targetSdkVersion="35" />
+</manifest>`
+	if err := os.WriteFile(dir+"/app/src/main/AndroidManifest.xml", []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := ResolveTargetSDK(dir); got != 35 {
+		t.Errorf("expected target SDK 35 from manifest, got %d", got)
+	}
+}
+
+func TestFixExportedComponents(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "AndroidManifest.xml")
+	content := `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <application>
+        <activity
+            android:name=".MainActivity">
+            <intent-filter>
+                <action android:name="android.intent.action.MAIN" />
+                <category android:name="android.intent.category.LAUNCHER" />
+            </intent-filter>
+        </activity>
+        <activity android:name=".ShareActivity">
+            <intent-filter>
+                <action android:name="android.intent.action.SEND" />
+            </intent-filter>
+        </activity>
+        <activity android:name=".SettingsActivity" android:exported="false" />
+    </application>
+</manifest>
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+
+	p := m.FixExportedComponents()
+	if p == nil {
+		t.Fatal("expected a patch for components missing android:exported")
+	}
+
+	want := `--- a/AndroidManifest.xml
++++ b/AndroidManifest.xml
+@@ -1,14 +1,15 @@
+ <?xml version="1.0" encoding="utf-8"?>
+ <manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+     <application>
+-        <activity
++        <activity
++            android:exported="true"
+             android:name=".MainActivity">
+             <intent-filter>
+                 <action android:name="android.intent.action.MAIN" />
+                 <category android:name="android.intent.category.LAUNCHER" />
+             </intent-filter>
+         </activity>
+-        <activity android:name=".ShareActivity">
++        <activity android:exported="false" android:name=".ShareActivity">
+             <intent-filter>
+                 <action android:name="android.intent.action.SEND" />
+             </intent-filter>
+`
+	if got := p.Diff("AndroidManifest.xml"); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The patched manifest parses and no longer triggers the finding.
+	fixed, err := Parse(p.Result())
+	if err != nil {
+		t.Fatalf("Parse(patched) error: %v", err)
+	}
+	for _, f := range NewValidator(fixed).CheckExportedComponents() {
+		if f.CheckID == RuleExportedComponent {
+			t.Errorf("unexpected finding after fix: %s", f.Title)
+		}
+	}
+}
+
+func TestFixExportedComponents_NothingToFix(t *testing.T) {
+	m, err := Parse([]byte(`<manifest xmlns:android="http://schemas.android.com/apk/res/android"><application /></manifest>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := m.FixExportedComponents(); p != nil {
+		t.Errorf("expected no patch, got %d edits", len(p.Edits))
+	}
+}
+
+// largeManifest returns a manifest of roughly n lines with one activity per
+// five lines, similar to manifests generated by build tooling.
+func largeManifest(n int) []byte {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <application android:label="Large">
+`)
+	for i := 0; i < n/5; i++ {
+		fmt.Fprintf(&b, `        <activity android:name=".Activity%d" android:exported="false">
+            <intent-filter>
+                <action android:name="com.example.ACTION_%d" />
+            </intent-filter>
+        </activity>
+`, i, i)
+	}
+	b.WriteString("    </application>\n</manifest>\n")
+	return []byte(b.String())
+}
+
+func TestParse_LargeManifestLines(t *testing.T) {
+	m, err := Parse(largeManifest(10000))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(m.Activities) != 2000 {
+		t.Fatalf("expected 2000 activities, got %d", len(m.Activities))
+	}
+	for i, a := range []int{0, 1, 1999} {
+		if got, want := m.Activities[a].Line, 4+5*a; got != want {
+			t.Errorf("case %d: activity %d on line %d, want %d", i, a, got, want)
+		}
+	}
+}
+
+func BenchmarkParse_LargeManifest(b *testing.B) {
+	data := largeManifest(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
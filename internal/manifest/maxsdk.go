@@ -0,0 +1,76 @@
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// legacyPermissionCaps maps permissions that newer Android versions ignore or
+// replace to the highest maxSdkVersion they should be requested up to, and
+// the targetSdk from which an uncapped declaration is reported.
+var legacyPermissionCaps = map[string]struct {
+	MaxSdk     int
+	FromTarget int
+	Reason     string
+}{
+	"android.permission.READ_EXTERNAL_STORAGE": {
+		MaxSdk:     GranularMediaSDKVersion - 1,
+		FromTarget: GranularMediaSDKVersion,
+		Reason:     "It grants no media access on Android 13+, where READ_MEDIA_IMAGES, READ_MEDIA_VIDEO, and READ_MEDIA_AUDIO replace it.",
+	},
+	"android.permission.WRITE_EXTERNAL_STORAGE": {
+		MaxSdk:     29,
+		FromTarget: 30,
+		Reason:     "It grants no additional access under scoped storage on Android 11+, where apps write their own files without a permission.",
+	},
+	"android.permission.BLUETOOTH": {
+		MaxSdk:     30,
+		FromTarget: 31,
+		Reason:     "It is replaced by BLUETOOTH_CONNECT on Android 12+.",
+	},
+	"android.permission.BLUETOOTH_ADMIN": {
+		MaxSdk:     30,
+		FromTarget: 31,
+		Reason:     "It is replaced by BLUETOOTH_SCAN and BLUETOOTH_CONNECT on Android 12+.",
+	},
+}
+
+// CheckPermissionMaxSdk warns about legacy permissions declared without an
+// android:maxSdkVersion cap, or with a cap above the recommended one, so the
+// app keeps requesting them on Android versions where they have no effect.
+func (v *Validator) CheckPermissionMaxSdk() []preflight.Finding {
+	var findings []preflight.Finding
+	for _, perm := range v.manifest.Permissions {
+		limit, ok := legacyPermissionCaps[perm.Name]
+		if !ok || v.manifest.TargetSdkVersion < limit.FromTarget {
+			continue
+		}
+		if perm.MaxSdk > 0 && perm.MaxSdk <= limit.MaxSdk {
+			continue
+		}
+		name := shortPermName(perm.Name)
+		declared := "without android:maxSdkVersion"
+		if perm.MaxSdk > 0 {
+			declared = fmt.Sprintf("with android:maxSdkVersion=\"%d\"", perm.MaxSdk)
+		}
+		findings = append(findings, preflight.Finding{
+			CheckID:     RulePermissionMaxSdk,
+			Title:       fmt.Sprintf("Legacy permission without maxSdkVersion cap: %s", name),
+			Description: fmt.Sprintf("%s is requested %s while targeting SDK %d. %s", name, declared, v.manifest.TargetSdkVersion, limit.Reason),
+			Severity:    preflight.SeverityWarning,
+			Location: preflight.Location{
+				File: v.manifest.filePath,
+				Line: perm.Line,
+			},
+			Suggestion: fmt.Sprintf("Add android:maxSdkVersion=\"%d\" to the %s declaration.", limit.MaxSdk, name),
+			Remediation: &preflight.Remediation{
+				Type:   preflight.RemediationChangeAttribute,
+				Target: "android:maxSdkVersion",
+				Value:  strconv.Itoa(limit.MaxSdk),
+			},
+		})
+	}
+	return findings
+}
@@ -0,0 +1,106 @@
+package manifest
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// gradleMinSdkRe matches minSdk declarations in Groovy and Kotlin DSL build
+// files: `minSdk 21`, `minSdkVersion 21`, `minSdk = 21`.
+var gradleMinSdkRe = regexp.MustCompile(`\bminSdk(?:Version)?\s*(?:=\s*)?\(?\s*(\d+)`)
+
+// DefaultMinSDKFloor is the lowest minSdkVersion CheckMinSDK accepts unless
+// configured otherwise: Android 5.0, the first release on ART with 64-bit
+// support.
+const DefaultMinSDKFloor = 21
+
+// WithMinSDKFloor sets the lowest minSdkVersion CheckMinSDK accepts. A value
+// of 0 or less keeps DefaultMinSDKFloor.
+func WithMinSDKFloor(floor int) ValidatorOption {
+	return func(v *Validator) {
+		if floor > 0 {
+			v.minSDKFloor = floor
+		}
+	}
+}
+
+// CheckMinSDK warns when minSdkVersion is unset, which lets the app install
+// on API 1, or below the configured floor. Supporting very old releases keeps
+// legacy code paths and security workarounds in the app. The manifest value
+// is preferred; otherwise the first minSdk in the project's Gradle build
+// files is used.
+func (v *Validator) CheckMinSDK() []preflight.Finding {
+	floor := v.minSDKFloor
+	if floor <= 0 {
+		floor = DefaultMinSDKFloor
+	}
+	minSDK, loc := v.resolveMinSDK()
+	if minSDK == 0 {
+		return []preflight.Finding{{
+			CheckID:     RuleMinSDK,
+			Title:       "Missing minSdkVersion",
+			Description: "minSdkVersion is not set in the manifest, so the app is installable on every Android version down to API 1, including releases without current TLS, permission, and storage protections.",
+			Severity:    preflight.SeverityWarning,
+			Location:    loc,
+			Suggestion:  fmt.Sprintf("Set minSdk to %d or higher in your build.gradle or android:minSdkVersion in AndroidManifest.xml.", floor),
+		}}
+	}
+
+	if minSDK < floor {
+		return []preflight.Finding{{
+			CheckID:     RuleMinSDK,
+			Title:       fmt.Sprintf("minSdkVersion %d is below %d", minSDK, floor),
+			Description: fmt.Sprintf("minSdkVersion is %d, below the recommended minimum of %d. Supporting older releases keeps legacy code paths and compatibility workarounds in the app and widens its attack surface.", minSDK, floor),
+			Severity:    preflight.SeverityWarning,
+			Location:    loc,
+			Suggestion:  fmt.Sprintf("Raise minSdk to %d or higher and remove code paths for older API levels.", floor),
+		}}
+	}
+	return nil
+}
+
+// resolveMinSDK returns the app's minSdkVersion and where it is declared.
+func (v *Validator) resolveMinSDK() (int, preflight.Location) {
+	m := v.manifest
+	if m.MinSdkVersion > 0 || v.projectDir == "" {
+		return m.MinSdkVersion, preflight.Location{File: m.filePath}
+	}
+
+	gradleFiles, err := utils.FindGradleFiles(v.projectDir, v.walkOpts...)
+	if err != nil {
+		return 0, preflight.Location{File: m.filePath}
+	}
+	for _, gf := range gradleFiles {
+		data, err := utils.ReadFileWithLimit(gf)
+		if err != nil {
+			continue
+		}
+		minSDK, line := parseGradleMinSDK(string(data))
+		if minSDK <= 0 {
+			continue
+		}
+		file := gf
+		if rel, err := filepath.Rel(v.projectDir, gf); err == nil {
+			file = rel
+		}
+		return minSDK, preflight.Location{File: file, Line: line}
+	}
+	return 0, preflight.Location{File: m.filePath}
+}
+
+// parseGradleMinSDK extracts the minSdk value and its 1-based line from
+// Gradle build file content.
+func parseGradleMinSDK(content string) (int, int) {
+	loc := gradleMinSdkRe.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return 0, 0
+	}
+	minSDK, _ := strconv.Atoi(content[loc[2]:loc[3]])
+	return minSDK, strings.Count(content[:loc[0]], "\n") + 1
+}
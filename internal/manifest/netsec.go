@@ -0,0 +1,192 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// NetworkSecurityConfig represents a parsed res/xml network security config.
+type NetworkSecurityConfig struct {
+	BaseConfig    *BaseConfig // nil if no <base-config> element
+	DomainConfigs []DomainConfig
+	filePath      string
+}
+
+// BaseConfig represents the <base-config> element, which applies to all
+// connections not covered by a domain-config.
+type BaseConfig struct {
+	CleartextPermitted *bool // nil if not explicitly set
+	Line               int
+}
+
+// DomainConfig represents a <domain-config> element.
+type DomainConfig struct {
+	Domains            []string
+	CleartextPermitted *bool // nil if not explicitly set
+	Line               int
+}
+
+// FilePath returns the file path of the parsed config.
+func (c *NetworkSecurityConfig) FilePath() string {
+	return c.filePath
+}
+
+// ResolveNetworkSecurityConfig returns the path of the network security
+// config referenced by the manifest's android:networkSecurityConfig
+// attribute, resolved against the res/xml directory next to the manifest.
+// It returns false if the manifest has no reference or the file is missing.
+func ResolveNetworkSecurityConfig(m *AndroidManifest) (string, bool) {
+	return resolveXMLResource(m, m.NetworkSecurityConfig)
+}
+
+// resolveXMLResource resolves an "@xml/name" reference from the manifest to
+// res/xml/name.xml next to it. It returns false for other references and
+// missing files.
+func resolveXMLResource(m *AndroidManifest, ref string) (string, bool) {
+	name, ok := strings.CutPrefix(ref, "@xml/")
+	if !ok || name == "" || m.filePath == "" {
+		return "", false
+	}
+	path := filepath.Join(filepath.Dir(m.filePath), "res", "xml", name+".xml")
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// ParseNetworkSecurityConfigFile parses a network security config file.
+func ParseNetworkSecurityConfigFile(path string) (*NetworkSecurityConfig, error) {
+	data, err := utils.ReadFileWithLimit(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading network security config: %w", err)
+	}
+	c, err := ParseNetworkSecurityConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	c.filePath = path
+	return c, nil
+}
+
+// ParseNetworkSecurityConfig parses network security config content from raw bytes.
+func ParseNetworkSecurityConfig(data []byte) (*NetworkSecurityConfig, error) {
+	c := &NetworkSecurityConfig{}
+	lines := newLineTracker(data)
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	// Domain configs may nest; each keeps its own domain list.
+	var stack []*DomainConfig
+	inDomain := false
+
+	for {
+		offset := decoder.InputOffset()
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("XML parse error at offset %d: %w", offset, err)
+		}
+		line := lines.lineAt(offset)
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "base-config":
+				c.BaseConfig = &BaseConfig{
+					CleartextPermitted: parseCleartextPermitted(t.Attr),
+					Line:               line,
+				}
+			case "domain-config":
+				stack = append(stack, &DomainConfig{
+					CleartextPermitted: parseCleartextPermitted(t.Attr),
+					Line:               line,
+				})
+			case "domain":
+				inDomain = true
+			}
+
+		case xml.CharData:
+			if inDomain && len(stack) > 0 {
+				if d := strings.TrimSpace(string(t)); d != "" {
+					top := stack[len(stack)-1]
+					top.Domains = append(top.Domains, d)
+				}
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "domain":
+				inDomain = false
+			case "domain-config":
+				if len(stack) > 0 {
+					c.DomainConfigs = append(c.DomainConfigs, *stack[len(stack)-1])
+					stack = stack[:len(stack)-1]
+				}
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func parseCleartextPermitted(attrs []xml.Attr) *bool {
+	for _, attr := range attrs {
+		if attr.Name.Local == "cleartextTrafficPermitted" {
+			val := strings.EqualFold(attr.Value, "true")
+			return &val
+		}
+	}
+	return nil
+}
+
+// CheckNetworkSecurityConfig flags cleartext traffic permitted by the
+// network security config referenced from the manifest. A permissive
+// <base-config> allows cleartext app-wide and is reported as an error,
+// separately from domain-scoped exceptions.
+func (v *Validator) CheckNetworkSecurityConfig() []preflight.Finding {
+	path, ok := ResolveNetworkSecurityConfig(v.manifest)
+	if !ok {
+		return nil
+	}
+	c, err := ParseNetworkSecurityConfigFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var findings []preflight.Finding
+	if b := c.BaseConfig; b != nil && b.CleartextPermitted != nil && *b.CleartextPermitted {
+		findings = append(findings, preflight.Finding{
+			CheckID:     RuleCleartextTraffic,
+			Title:       "Cleartext traffic permitted app-wide by network security config",
+			Description: "<base-config cleartextTrafficPermitted=\"true\"> allows unencrypted HTTP connections to every domain, the same as android:usesCleartextTraffic=\"true\".",
+			Severity:    preflight.SeverityError,
+			Location:    preflight.Location{File: c.filePath, Line: b.Line},
+			Suggestion:  "Set cleartextTrafficPermitted=\"false\" on <base-config> and allow cleartext only for the specific domains that need it with <domain-config>.",
+		})
+	}
+	for _, d := range c.DomainConfigs {
+		if d.CleartextPermitted == nil || !*d.CleartextPermitted {
+			continue
+		}
+		findings = append(findings, preflight.Finding{
+			CheckID:     RuleCleartextTraffic,
+			Title:       "Cleartext traffic permitted for specific domains",
+			Description: fmt.Sprintf("Network security config allows unencrypted HTTP to: %s.", strings.Join(d.Domains, ", ")),
+			Severity:    preflight.SeverityWarning,
+			Location:    preflight.Location{File: c.filePath, Line: d.Line},
+			Suggestion:  "Serve these domains over HTTPS and remove the cleartext exception when possible.",
+		})
+	}
+	return findings
+}
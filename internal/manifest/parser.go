@@ -3,6 +3,7 @@ package manifest
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -26,23 +27,69 @@ type AndroidManifest struct {
 	UsesCleartext bool // android:usesCleartextTraffic
 	HasCleartext  bool // whether the attribute was explicitly set
 
+	NetworkSecurityConfig string // android:networkSecurityConfig, e.g. "@xml/network_security_config"
+
+	AllowBackup         *bool  // android:allowBackup; nil if not set, which enables backups
+	FullBackupContent   string // android:fullBackupContent, e.g. "@xml/backup_rules"
+	DataExtractionRules string // android:dataExtractionRules, e.g. "@xml/data_extraction_rules"
+	ApplicationLine     int    // line of the <application> element; 0 if absent
+
+	TestOnly bool // android:testOnly="true"
+
+	ResizeableActivity *bool // android:resizeableActivity on <application>; nil if not set
+
 	Permissions []Permission
+	Features    []Feature
+	MetaData    []MetaData
 	Activities  []Activity
 	Services    []Service
 	Receivers   []Receiver
 	Providers   []Provider
 
+	// RemovedPermissions holds permission elements marked
+	// tools:node="remove", which strip the permission from the merged
+	// manifest. They are not in Permissions and are not reported.
+	RemovedPermissions []Permission
+
 	// Raw lines for line-number tracking.
 	rawContent []byte
 	filePath   string
 }
 
-// Permission represents a <uses-permission> element.
+// Permission represents a <uses-permission> or <uses-permission-sdk-23>
+// element.
 type Permission struct {
 	Name     string
 	MaxSdk   int
 	Line     int
-	Required bool // android:required
+	Required bool     // android:required
+	Flags    []string // android:usesPermissionFlags, e.g. "neverForLocation"
+	SDK23    bool     // declared with <uses-permission-sdk-23>, requested on API 23+ only
+}
+
+// HasFlag reports whether flag is set in android:usesPermissionFlags.
+func (p Permission) HasFlag(flag string) bool {
+	for _, f := range p.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Feature represents a <uses-feature> element.
+type Feature struct {
+	Name     string
+	Required bool // android:required, defaults to true
+	Line     int
+}
+
+// MetaData represents a <meta-data> element.
+type MetaData struct {
+	Name     string
+	Value    string
+	Resource string
+	Line     int
 }
 
 // IntentFilter represents an <intent-filter> element.
@@ -55,23 +102,30 @@ type IntentFilter struct {
 // Activity represents an <activity> element.
 type Activity struct {
 	Name          string
-	Exported      *bool // nil if not explicitly set
+	Exported      *bool  // nil if not explicitly set
+	Permission    string // android:permission required to start or bind
 	IntentFilters []IntentFilter
 	Line          int
+
+	Resizeable *bool // android:resizeableActivity; nil if not set, which inherits from <application>
 }
 
 // Service represents a <service> element.
 type Service struct {
 	Name          string
 	Exported      *bool
+	Permission    string // android:permission required to start or bind
 	IntentFilters []IntentFilter
 	Line          int
+
+	ForegroundServiceTypes []string // android:foregroundServiceType, e.g. "location"
 }
 
 // Receiver represents a <receiver> element.
 type Receiver struct {
 	Name          string
 	Exported      *bool
+	Permission    string // android:permission required to start or bind
 	IntentFilters []IntentFilter
 	Line          int
 }
@@ -80,8 +134,25 @@ type Receiver struct {
 type Provider struct {
 	Name          string
 	Exported      *bool
+	Permission    string // android:permission required to start or bind
 	IntentFilters []IntentFilter
 	Line          int
+
+	ReadPermission      string           // android:readPermission
+	WritePermission     string           // android:writePermission
+	GrantURIPermissions bool             // android:grantUriPermissions="true"
+	GrantURIPaths       []string         // path, pathPrefix, or pathPattern of each <grant-uri-permission>
+	PathPermissions     []PathPermission // <path-permission> children
+	PathsResource       string           // FileProvider paths XML, e.g. "@xml/file_paths"
+}
+
+// PathPermission represents a <path-permission> element of a provider.
+type PathPermission struct {
+	Path            string // path, pathPrefix, or pathPattern
+	Permission      string
+	ReadPermission  string
+	WritePermission string
+	Line            int
 }
 
 // HasLauncherActivity returns true if any activity has a launcher intent filter.
@@ -115,6 +186,46 @@ func isLauncherActivity(a Activity) bool {
 	return false
 }
 
+// HasFeature returns true if the manifest declares the named <uses-feature>.
+func (m *AndroidManifest) HasFeature(name string) bool {
+	for _, f := range m.Features {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission returns true if the manifest declares the named <uses-permission>.
+func (m *AndroidManifest) HasPermission(name string) bool {
+	for _, p := range m.Permissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FormFactor infers the device form factor the app is built for from its
+// declared features and metadata. Apps without a form-factor marker are
+// treated as phone/tablet apps.
+func (m *AndroidManifest) FormFactor() FormFactor {
+	switch {
+	case m.HasFeature(featureWatch):
+		return FormFactorWear
+	case m.HasFeature(featureLeanback):
+		return FormFactorTV
+	case m.HasFeature(featureAutomotive):
+		return FormFactorAutomotive
+	}
+	for _, md := range m.MetaData {
+		if md.Name == metaDataCarApplication {
+			return FormFactorAutomotive
+		}
+	}
+	return FormFactorPhone
+}
+
 // FilePath returns the file path of the parsed manifest.
 func (m *AndroidManifest) FilePath() string {
 	return m.filePath
@@ -134,19 +245,27 @@ func ParseFile(path string) (*AndroidManifest, error) {
 	return m, nil
 }
 
-// FindAndParse locates AndroidManifest.xml in a project directory and parses it.
-func FindAndParse(projectDir string) (*AndroidManifest, error) {
-	candidates := []string{
+// ErrManifestNotFound is returned by FindAndParse when none of the
+// ManifestCandidates exist.
+var ErrManifestNotFound = errors.New("AndroidManifest.xml not found")
+
+// ManifestCandidates returns the paths FindAndParse checks, in order.
+func ManifestCandidates(projectDir string) []string {
+	return []string{
 		filepath.Join(projectDir, "app", "src", "main", "AndroidManifest.xml"),
 		filepath.Join(projectDir, "AndroidManifest.xml"),
 		filepath.Join(projectDir, "src", "main", "AndroidManifest.xml"),
 	}
-	for _, path := range candidates {
+}
+
+// FindAndParse locates AndroidManifest.xml in a project directory and parses it.
+func FindAndParse(projectDir string) (*AndroidManifest, error) {
+	for _, path := range ManifestCandidates(projectDir) {
 		if _, err := os.Stat(path); err == nil {
 			return ParseFile(path)
 		}
 	}
-	return nil, fmt.Errorf("AndroidManifest.xml not found in %s", projectDir)
+	return nil, fmt.Errorf("%w in %s", ErrManifestNotFound, projectDir)
 }
 
 // Parse parses AndroidManifest.xml content from raw bytes.
@@ -155,8 +274,8 @@ func Parse(data []byte) (*AndroidManifest, error) {
 		rawContent: data,
 	}
 
-	// Build a line offset index for accurate line number reporting.
-	lineOffsets := buildLineOffsets(data)
+	// Track line numbers for accurate location reporting.
+	lines := newLineTracker(data)
 
 	decoder := xml.NewDecoder(bytes.NewReader(data))
 	// Use strict mode to reject malformed XML that could cause
@@ -170,8 +289,13 @@ func Parse(data []byte) (*AndroidManifest, error) {
 		kind          string // "activity", "service", "receiver", "provider"
 		name          string
 		exported      *bool
+		permission    string
 		intentFilters []IntentFilter
 		line          int
+
+		foregroundServiceTypes []string // services only
+		provider               Provider // providers only: permission and grant-uri details
+		resizeable             *bool    // activities only: android:resizeableActivity
 	}
 	var currentComponent *componentCtx
 	var currentIntentFilter *IntentFilter
@@ -186,7 +310,7 @@ func Parse(data []byte) (*AndroidManifest, error) {
 			return nil, fmt.Errorf("XML parse error at offset %d: %w", offset, err)
 		}
 
-		line := offsetToLine(lineOffsets, offset)
+		line := lines.lineAt(offset)
 
 		switch t := tok.(type) {
 		case xml.StartElement:
@@ -201,39 +325,69 @@ func Parse(data []byte) (*AndroidManifest, error) {
 				m.parseUsesSdkAttrs(t.Attr)
 
 			case "application":
+				m.ApplicationLine = line
 				m.parseApplicationAttrs(t.Attr)
 
-			case "uses-permission":
+			case "uses-permission", "uses-permission-sdk-23", "uses-permission-sdk-m":
 				perm := parsePermission(t.Attr, line)
-				m.Permissions = append(m.Permissions, perm)
+				perm.SDK23 = name != "uses-permission"
+				if removesNode(t.Attr) {
+					m.RemovedPermissions = append(m.RemovedPermissions, perm)
+				} else {
+					m.Permissions = append(m.Permissions, perm)
+				}
+
+			case "uses-feature":
+				m.Features = append(m.Features, parseFeature(t.Attr, line))
+
+			case "meta-data":
+				md := parseMetaData(t.Attr, line)
+				m.MetaData = append(m.MetaData, md)
+				if currentComponent != nil && currentComponent.kind == "provider" && md.Name == fileProviderPathsMeta {
+					currentComponent.provider.PathsResource = md.Resource
+				}
 
 			case "activity", "activity-alias":
 				currentComponent = &componentCtx{
 					kind: "activity",
 					line: line,
 				}
-				currentComponent.name, currentComponent.exported = parseComponentAttrs(t.Attr)
+				currentComponent.name, currentComponent.exported, currentComponent.permission = parseComponentAttrs(t.Attr)
+				currentComponent.resizeable = parseResizeable(t.Attr)
 
 			case "service":
 				currentComponent = &componentCtx{
 					kind: "service",
 					line: line,
 				}
-				currentComponent.name, currentComponent.exported = parseComponentAttrs(t.Attr)
+				currentComponent.name, currentComponent.exported, currentComponent.permission = parseComponentAttrs(t.Attr)
+				currentComponent.foregroundServiceTypes = parseForegroundServiceTypes(t.Attr)
 
 			case "receiver":
 				currentComponent = &componentCtx{
 					kind: "receiver",
 					line: line,
 				}
-				currentComponent.name, currentComponent.exported = parseComponentAttrs(t.Attr)
+				currentComponent.name, currentComponent.exported, currentComponent.permission = parseComponentAttrs(t.Attr)
 
 			case "provider":
 				currentComponent = &componentCtx{
 					kind: "provider",
 					line: line,
 				}
-				currentComponent.name, currentComponent.exported = parseComponentAttrs(t.Attr)
+				currentComponent.name, currentComponent.exported, currentComponent.permission = parseComponentAttrs(t.Attr)
+				currentComponent.provider = parseProviderAttrs(t.Attr)
+
+			case "grant-uri-permission":
+				if currentComponent != nil && currentComponent.kind == "provider" {
+					pp := parsePathPermission(t.Attr, line)
+					currentComponent.provider.GrantURIPaths = append(currentComponent.provider.GrantURIPaths, pp.Path)
+				}
+
+			case "path-permission":
+				if currentComponent != nil && currentComponent.kind == "provider" {
+					currentComponent.provider.PathPermissions = append(currentComponent.provider.PathPermissions, parsePathPermission(t.Attr, line))
+				}
 
 			case "intent-filter":
 				currentIntentFilter = &IntentFilter{
@@ -274,8 +428,11 @@ func Parse(data []byte) (*AndroidManifest, error) {
 					m.Activities = append(m.Activities, Activity{
 						Name:          currentComponent.name,
 						Exported:      currentComponent.exported,
+						Permission:    currentComponent.permission,
 						IntentFilters: currentComponent.intentFilters,
 						Line:          currentComponent.line,
+
+						Resizeable: currentComponent.resizeable,
 					})
 					currentComponent = nil
 				}
@@ -285,8 +442,11 @@ func Parse(data []byte) (*AndroidManifest, error) {
 					m.Services = append(m.Services, Service{
 						Name:          currentComponent.name,
 						Exported:      currentComponent.exported,
+						Permission:    currentComponent.permission,
 						IntentFilters: currentComponent.intentFilters,
 						Line:          currentComponent.line,
+
+						ForegroundServiceTypes: currentComponent.foregroundServiceTypes,
 					})
 					currentComponent = nil
 				}
@@ -296,6 +456,7 @@ func Parse(data []byte) (*AndroidManifest, error) {
 					m.Receivers = append(m.Receivers, Receiver{
 						Name:          currentComponent.name,
 						Exported:      currentComponent.exported,
+						Permission:    currentComponent.permission,
 						IntentFilters: currentComponent.intentFilters,
 						Line:          currentComponent.line,
 					})
@@ -304,12 +465,13 @@ func Parse(data []byte) (*AndroidManifest, error) {
 
 			case "provider":
 				if currentComponent != nil && currentComponent.kind == "provider" {
-					m.Providers = append(m.Providers, Provider{
-						Name:          currentComponent.name,
-						Exported:      currentComponent.exported,
-						IntentFilters: currentComponent.intentFilters,
-						Line:          currentComponent.line,
-					})
+					p := currentComponent.provider
+					p.Name = currentComponent.name
+					p.Exported = currentComponent.exported
+					p.Permission = currentComponent.permission
+					p.IntentFilters = currentComponent.intentFilters
+					p.Line = currentComponent.line
+					m.Providers = append(m.Providers, p)
 					currentComponent = nil
 				}
 			}
@@ -360,13 +522,43 @@ func (m *AndroidManifest) parseUsesSdkAttrs(attrs []xml.Attr) {
 
 func (m *AndroidManifest) parseApplicationAttrs(attrs []xml.Attr) {
 	for _, attr := range attrs {
-		if attr.Name.Local == "usesCleartextTraffic" {
+		switch attr.Name.Local {
+		case "usesCleartextTraffic":
 			m.HasCleartext = true
 			m.UsesCleartext = strings.EqualFold(attr.Value, "true")
+		case "networkSecurityConfig":
+			m.NetworkSecurityConfig = attr.Value
+		case "allowBackup":
+			allow := strings.EqualFold(attr.Value, "true")
+			m.AllowBackup = &allow
+		case "fullBackupContent":
+			m.FullBackupContent = attr.Value
+		case "dataExtractionRules":
+			m.DataExtractionRules = attr.Value
+		case "testOnly":
+			m.TestOnly = strings.EqualFold(attr.Value, "true")
+		case "resizeableActivity":
+			resizeable := strings.EqualFold(attr.Value, "true")
+			m.ResizeableActivity = &resizeable
 		}
 	}
 }
 
+// toolsNamespace is the namespace of the manifest merger's tools: attributes.
+const toolsNamespace = "http://schemas.android.com/tools"
+
+// removesNode reports whether attrs include tools:node="remove", which
+// deletes the element from the merged manifest. The prefix is accepted even
+// when the manifest does not declare xmlns:tools.
+func removesNode(attrs []xml.Attr) bool {
+	for _, attr := range attrs {
+		if attr.Name.Local == "node" && (attr.Name.Space == toolsNamespace || attr.Name.Space == "tools") {
+			return attr.Value == "remove"
+		}
+	}
+	return false
+}
+
 func parsePermission(attrs []xml.Attr, line int) Permission {
 	p := Permission{Line: line, Required: true}
 	for _, attr := range attrs {
@@ -377,16 +569,52 @@ func parsePermission(attrs []xml.Attr, line int) Permission {
 			p.MaxSdk = parseIntAttr("maxSdkVersion", attr.Value)
 		case "required":
 			p.Required = strings.EqualFold(attr.Value, "true")
+		case "usesPermissionFlags":
+			for _, f := range strings.Split(attr.Value, "|") {
+				if f = strings.TrimSpace(f); f != "" {
+					p.Flags = append(p.Flags, f)
+				}
+			}
 		}
 	}
 	return p
 }
 
-func parseComponentAttrs(attrs []xml.Attr) (name string, exported *bool) {
+func parseFeature(attrs []xml.Attr, line int) Feature {
+	f := Feature{Line: line, Required: true}
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "name":
+			f.Name = attr.Value
+		case "required":
+			f.Required = strings.EqualFold(attr.Value, "true")
+		}
+	}
+	return f
+}
+
+func parseMetaData(attrs []xml.Attr, line int) MetaData {
+	md := MetaData{Line: line}
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "name":
+			md.Name = attr.Value
+		case "value":
+			md.Value = attr.Value
+		case "resource":
+			md.Resource = attr.Value
+		}
+	}
+	return md
+}
+
+func parseComponentAttrs(attrs []xml.Attr) (name string, exported *bool, permission string) {
 	for _, attr := range attrs {
 		switch attr.Name.Local {
 		case "name":
 			name = attr.Value
+		case "permission":
+			permission = attr.Value
 		case "exported":
 			val := strings.EqualFold(attr.Value, "true")
 			exported = &val
@@ -395,27 +623,91 @@ func parseComponentAttrs(attrs []xml.Attr) (name string, exported *bool) {
 	return
 }
 
-// buildLineOffsets creates an index of byte offsets for the start of each line.
-func buildLineOffsets(data []byte) []int {
-	offsets := []int{0}
-	for i, b := range data {
-		if b == '\n' {
-			offsets = append(offsets, i+1)
+// parseResizeable returns the value of android:resizeableActivity, or nil if
+// it is not set.
+func parseResizeable(attrs []xml.Attr) *bool {
+	for _, attr := range attrs {
+		if attr.Name.Local == "resizeableActivity" {
+			val := strings.EqualFold(attr.Value, "true")
+			return &val
 		}
 	}
-	return offsets
-}
-
-// offsetToLine converts a byte offset to a 1-based line number.
-func offsetToLine(lineOffsets []int, offset int64) int {
-	lo, hi := 0, len(lineOffsets)-1
-	for lo <= hi {
-		mid := (lo + hi) / 2
-		if int64(lineOffsets[mid]) <= offset {
-			lo = mid + 1
-		} else {
-			hi = mid - 1
+	return nil
+}
+
+// parseProviderAttrs reads the provider-specific permission attributes.
+func parseProviderAttrs(attrs []xml.Attr) Provider {
+	var p Provider
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "readPermission":
+			p.ReadPermission = attr.Value
+		case "writePermission":
+			p.WritePermission = attr.Value
+		case "grantUriPermissions":
+			p.GrantURIPermissions = strings.EqualFold(attr.Value, "true")
+		}
+	}
+	return p
+}
+
+// parsePathPermission reads a <path-permission> or <grant-uri-permission>
+// element. Both name the URI path with one of path, pathPrefix, or
+// pathPattern (or their Advanced/Suffix variants).
+func parsePathPermission(attrs []xml.Attr, line int) PathPermission {
+	pp := PathPermission{Line: line}
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "path", "pathPrefix", "pathPattern", "pathAdvancedPattern", "pathSuffix":
+			pp.Path = attr.Value
+		case "permission":
+			pp.Permission = attr.Value
+		case "readPermission":
+			pp.ReadPermission = attr.Value
+		case "writePermission":
+			pp.WritePermission = attr.Value
 		}
 	}
-	return lo // 1-based since offsets[0]=0 and lo ends up at correct 1-based line
+	return pp
+}
+
+// parseForegroundServiceTypes splits android:foregroundServiceType, which
+// combines types with "|".
+func parseForegroundServiceTypes(attrs []xml.Attr) []string {
+	var types []string
+	for _, attr := range attrs {
+		if attr.Name.Local != "foregroundServiceType" {
+			continue
+		}
+		for _, t := range strings.Split(attr.Value, "|") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+	return types
+}
+
+// lineTracker maps byte offsets to 1-based line numbers. Decoder offsets only
+// grow, so it counts newlines incrementally from the previous offset instead
+// of indexing every line up front.
+type lineTracker struct {
+	data   []byte
+	offset int
+	line   int
+}
+
+func newLineTracker(data []byte) lineTracker {
+	return lineTracker{data: data, line: 1}
+}
+
+// lineAt returns the line containing offset. Offsets must not decrease
+// between calls.
+func (t *lineTracker) lineAt(offset int64) int {
+	end := min(int(offset), len(t.data))
+	if end > t.offset {
+		t.line += bytes.Count(t.data[t.offset:end], []byte{'\n'})
+		t.offset = end
+	}
+	return t.line
 }
@@ -0,0 +1,86 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// providerExportedDefaultSDK is the API level (Android 4.2) below which
+// providers without android:exported are exported by default.
+const providerExportedDefaultSDK = 17
+
+// broadGrantPaths are <grant-uri-permission> paths that cover every URI.
+var broadGrantPaths = map[string]bool{"": true, "/": true, ".*": true, "/.*": true}
+
+// CheckProviderSecurity validates exported content providers. An exported
+// provider without a read or write permission lets any app query or modify
+// its data, and broad URI grants let any app the provider's data is shared
+// with reach all of it.
+func (v *Validator) CheckProviderSecurity() []preflight.Finding {
+	var findings []preflight.Finding
+	m := v.manifest
+
+	for _, p := range m.Providers {
+		exported := p.Exported != nil && *p.Exported ||
+			p.Exported == nil && m.TargetSdkVersion > 0 && m.TargetSdkVersion < providerExportedDefaultSDK
+		if !exported {
+			continue
+		}
+		name := shortComponentName(p.Name)
+		loc := preflight.Location{File: m.filePath, Line: p.Line}
+
+		readGuarded := p.Permission != "" || p.ReadPermission != ""
+		writeGuarded := p.Permission != "" || p.WritePermission != ""
+		switch {
+		case !readGuarded && !writeGuarded:
+			findings = append(findings, preflight.Finding{
+				CheckID:     RuleProviderSecurity,
+				Title:       fmt.Sprintf("Exported provider without permission: %s", name),
+				Description: fmt.Sprintf("Provider %q is exported but sets no android:permission, android:readPermission, or android:writePermission. Any app on the device can read and modify its data.", p.Name),
+				Severity:    preflight.SeverityError,
+				Location:    loc,
+				Suggestion:  "Set android:exported=\"false\" if only this app uses the provider. Otherwise guard it with android:readPermission and android:writePermission using a signature-level permission.",
+			})
+		case !readGuarded || !writeGuarded:
+			open := "read"
+			if readGuarded {
+				open = "write"
+			}
+			findings = append(findings, preflight.Finding{
+				CheckID:     RuleProviderSecurity,
+				Title:       fmt.Sprintf("Exported provider allows unguarded %s access: %s", open, name),
+				Description: fmt.Sprintf("Provider %q is exported and guards only one direction of access. Any app can %s its data.", p.Name, open),
+				Severity:    preflight.SeverityWarning,
+				Location:    loc,
+				Suggestion:  fmt.Sprintf("Add android:%sPermission, or android:permission to guard both reads and writes.", open),
+			})
+		}
+
+		if broad := broadGrant(p); broad != "" {
+			findings = append(findings, preflight.Finding{
+				CheckID:     RuleProviderSecurity,
+				Title:       fmt.Sprintf("Exported provider grants URI permissions broadly: %s", name),
+				Description: fmt.Sprintf("Provider %q is exported and %s, so a temporary URI grant can expose any of its data rather than the items being shared.", p.Name, broad),
+				Severity:    preflight.SeverityWarning,
+				Location:    loc,
+				Suggestion:  "Set android:grantUriPermissions=\"false\" and list the shareable paths with <grant-uri-permission android:pathPrefix=\"...\"> elements.",
+			})
+		}
+	}
+	return findings
+}
+
+// broadGrant describes how provider p grants URI permissions without path
+// restrictions, or returns "" if its grants are restricted.
+func broadGrant(p Provider) string {
+	if p.GrantURIPermissions {
+		return "sets android:grantUriPermissions=\"true\""
+	}
+	for _, path := range p.GrantURIPaths {
+		if broadGrantPaths[path] {
+			return fmt.Sprintf("declares <grant-uri-permission> for %q", path)
+		}
+	}
+	return ""
+}
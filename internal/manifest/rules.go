@@ -1,11 +1,16 @@
 package manifest
 
-import "github.com/kotaroyamazaki/playcheck/internal/preflight"
+import (
+	"sort"
+
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
 
 // Rule IDs for manifest validation checks.
 const (
 	RuleTargetSDK         = "SDK001"
-	RuleMinSDK            = "SDK004"
+	RuleMinSDK            = "SDK006"
 	RuleDangerousPerm     = "DP001"
 	RuleLocationPerm      = "DP002"
 	RuleCameraPerm        = "DP003"
@@ -17,13 +22,34 @@ const (
 	RuleLauncherActivity  = "MV002"
 	RuleCleartextTraffic  = "MV004"
 	RuleComponentSecurity = "MC001"
+	RuleSpecialPerm       = "SP001"
+	RuleInstallPackages   = "DP013"
+	RuleVersionCode       = "MV003"
+	RuleManifestNotFound  = "MV000"
+	RuleForegroundPerm    = "DP010"
+	RuleProviderSecurity  = "MS003"
+	RuleFileProviderPaths = "MS005"
+	RuleSigningScheme     = "MS006"
+	RuleBackupRules       = "MV006"
+	RulePermissionMaxSdk  = "MV007"
+	RuleImpliedFeature    = "MV008"
+	RuleBootReceiver      = "MV009"
+	RuleTestOnly          = "MV010"
+	RuleDuplicatePerm     = "MV011"
+	RuleImplicitBroadcast = "MV012"
+	RuleLargeScreen       = "MV013"
 )
 
+// smsCallLogPolicy is the Play policy on SMS and Call Log permissions, which
+// is more specific than the general permissions policy of their rules.
+const smsCallLogPolicy = "https://support.google.com/googleplay/android-developer/answer/9047303"
+
 // dangerousPermissions maps Android permission names to their rule IDs and descriptions.
 var dangerousPermissions = map[string]struct {
 	RuleID      string
 	Category    string
 	Description string
+	PolicyLink  string // set when a policy more specific than the rule's applies
 }{
 	"android.permission.ACCESS_FINE_LOCATION": {
 		RuleID:      RuleLocationPerm,
@@ -70,11 +96,31 @@ var dangerousPermissions = map[string]struct {
 		Category:    "Storage",
 		Description: "All-files access requires Play Store policy justification",
 	},
+	"android.permission.READ_MEDIA_IMAGES": {
+		RuleID:      RuleStoragePerm,
+		Category:    "Media",
+		Description: "Image library access requires disclosure; consider the Photo Picker for one-off selection",
+	},
+	"android.permission.READ_MEDIA_VIDEO": {
+		RuleID:      RuleStoragePerm,
+		Category:    "Media",
+		Description: "Video library access requires disclosure; consider the Photo Picker for one-off selection",
+	},
+	"android.permission.READ_MEDIA_AUDIO": {
+		RuleID:      RuleStoragePerm,
+		Category:    "Media",
+		Description: "Audio library access requires disclosure",
+	},
 	"android.permission.READ_PHONE_STATE": {
 		RuleID:      RulePhonePerm,
 		Category:    "Phone",
 		Description: "Phone state access includes device identifiers; requires justification",
 	},
+	"android.permission.READ_PHONE_NUMBERS": {
+		RuleID:      RulePhonePerm,
+		Category:    "Phone",
+		Description: "Phone number access exposes personal data; requires disclosure and justification",
+	},
 	"android.permission.CALL_PHONE": {
 		RuleID:      RulePhonePerm,
 		Category:    "Phone",
@@ -84,6 +130,7 @@ var dangerousPermissions = map[string]struct {
 		RuleID:      RulePhonePerm,
 		Category:    "Phone",
 		Description: "Call log access is restricted; requires default handler or Play Store exception",
+		PolicyLink:  smsCallLogPolicy,
 	},
 	"android.permission.READ_CALENDAR": {
 		RuleID:      RuleCalendarPerm,
@@ -104,16 +151,19 @@ var dangerousPermissions = map[string]struct {
 		RuleID:      RuleDangerousPerm,
 		Category:    "SMS",
 		Description: "SMS read access is restricted; requires default handler or Play Store exception",
+		PolicyLink:  smsCallLogPolicy,
 	},
 	"android.permission.SEND_SMS": {
 		RuleID:      RuleDangerousPerm,
 		Category:    "SMS",
 		Description: "SMS send access is restricted; requires default handler or Play Store exception",
+		PolicyLink:  smsCallLogPolicy,
 	},
 	"android.permission.RECEIVE_SMS": {
 		RuleID:      RuleDangerousPerm,
 		Category:    "SMS",
 		Description: "SMS receive access is restricted; requires default handler or Play Store exception",
+		PolicyLink:  smsCallLogPolicy,
 	},
 	"android.permission.BODY_SENSORS": {
 		RuleID:      RuleDangerousPerm,
@@ -122,9 +172,77 @@ var dangerousPermissions = map[string]struct {
 	},
 }
 
-// MinTargetSDKVersion is the minimum target SDK version required by Play Store.
+// externalBroadcastActions lists system broadcast actions that are sent from
+// outside the app, so a receiver handling them must be exported to function.
+// Actions outside the android namespace, such as those sent by Play services,
+// are only recognised when listed here.
+var externalBroadcastActions = map[string]bool{
+	"android.intent.action.BOOT_COMPLETED":            true,
+	"android.intent.action.LOCKED_BOOT_COMPLETED":     true,
+	"android.intent.action.PHONE_STATE":               true,
+	"android.intent.action.NEW_OUTGOING_CALL":         true,
+	"android.intent.action.MEDIA_BUTTON":              true,
+	"android.provider.Telephony.SMS_RECEIVED":         true,
+	"android.provider.Telephony.SMS_DELIVER":          true,
+	"android.provider.Telephony.WAP_PUSH_DELIVER":     true,
+	"android.appwidget.action.APPWIDGET_UPDATE":       true,
+	"android.app.action.DEVICE_ADMIN_ENABLED":         true,
+	"com.google.android.c2dm.intent.RECEIVE":          true,
+	"com.android.vending.INSTALL_REFERRER":            true,
+	"android.bluetooth.device.action.ACL_CONNECTED":   true,
+	"android.intent.action.ACTION_POWER_CONNECTED":    true,
+	"android.intent.action.ACTION_POWER_DISCONNECTED": true,
+	"android.net.wifi.STATE_CHANGE":                   true,
+	"android.intent.action.PACKAGE_ADDED":             true,
+	"android.intent.action.PACKAGE_REMOVED":           true,
+	"android.intent.action.TIMEZONE_CHANGED":          true,
+	"android.intent.action.TIME_SET":                  true,
+}
+
+// MinTargetSDKVersion is the minimum target SDK version required by Play Store
+// for phone and tablet apps.
 const MinTargetSDKVersion = 35
 
+// GranularMediaSDKVersion is the API level (Android 13) at which
+// READ_EXTERNAL_STORAGE was replaced by the READ_MEDIA_* permissions.
+const GranularMediaSDKVersion = 33
+
+// FormFactor identifies the class of device an app is built for.
+type FormFactor string
+
+// Supported form factors.
+const (
+	FormFactorPhone      FormFactor = "phone"
+	FormFactorWear       FormFactor = "wear"
+	FormFactorTV         FormFactor = "tv"
+	FormFactorAutomotive FormFactor = "automotive"
+)
+
+// Manifest markers used to detect non-phone form factors.
+const (
+	featureWatch           = "android.hardware.type.watch"
+	featureLeanback        = "android.software.leanback"
+	featureAutomotive      = "android.hardware.type.automotive"
+	metaDataCarApplication = "com.google.android.gms.car.application"
+)
+
+// minTargetSDKByFormFactor holds the Play Store target SDK requirement for
+// form factors that lag behind the phone requirement.
+var minTargetSDKByFormFactor = map[FormFactor]int{
+	FormFactorWear:       34,
+	FormFactorTV:         34,
+	FormFactorAutomotive: 34,
+}
+
+// MinTargetSDKFor returns the minimum target SDK version Play Store requires
+// for the given form factor.
+func MinTargetSDKFor(ff FormFactor) int {
+	if v, ok := minTargetSDKByFormFactor[ff]; ok {
+		return v
+	}
+	return MinTargetSDKVersion
+}
+
 // severityForPermission returns the severity for a dangerous permission finding.
 func severityForPermission(permName string) preflight.Severity {
 	// Restricted permissions (SMS, call log) are critical
@@ -140,3 +258,45 @@ func severityForPermission(permName string) preflight.Severity {
 		return preflight.SeverityWarning
 	}
 }
+
+// Rules returns the metadata of every rule the manifest validator reports,
+// ordered by ID.
+func Rules() []preflight.RuleInfo {
+	checkerID := (&ManifestScanner{}).ID()
+	rules := []preflight.RuleInfo{
+		{ID: RuleTargetSDK, Title: "targetSdkVersion is below required minimum", Severity: preflight.SeverityCritical, Category: policies.CategorySDKCompliance},
+		{ID: RuleMinSDK, Title: "minSdkVersion is below recommended minimum", Severity: preflight.SeverityWarning, Category: policies.CategorySDKCompliance},
+		{ID: RuleDangerousPerm, Title: "Dangerous permission", Severity: preflight.SeverityWarning, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleLocationPerm, Title: "Location permission", Severity: preflight.SeverityWarning, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleCameraPerm, Title: "Camera permission", Severity: preflight.SeverityWarning, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleContactsPerm, Title: "Contacts permission", Severity: preflight.SeverityWarning, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleStoragePerm, Title: "Storage permission", Severity: preflight.SeverityWarning, Category: policies.CategoryDangerousPermissions},
+		{ID: RulePhonePerm, Title: "Phone permission", Severity: preflight.SeverityWarning, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleCalendarPerm, Title: "Calendar permission", Severity: preflight.SeverityWarning, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleForegroundPerm, Title: "Missing foreground service type permission", Severity: preflight.SeverityError, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleInstallPackages, Title: "Package installation permission", Severity: preflight.SeverityCritical, Category: policies.CategoryDangerousPermissions},
+		{ID: RuleManifestNotFound, Title: "No AndroidManifest.xml found in expected locations", Severity: preflight.SeverityWarning, Category: policies.CategoryManifestValidation},
+		{ID: RuleExportedComponent, Title: "Component missing android:exported", Severity: preflight.SeverityError, Category: policies.CategoryManifestValidation},
+		{ID: RuleLauncherActivity, Title: "No launcher activity found", Severity: preflight.SeverityWarning, Category: policies.CategoryManifestValidation},
+		{ID: RuleVersionCode, Title: "Invalid versionCode", Severity: preflight.SeverityError, Category: policies.CategoryManifestValidation},
+		{ID: RuleCleartextTraffic, Title: "Cleartext traffic enabled", Severity: preflight.SeverityWarning, Category: policies.CategorySecurity},
+		{ID: RuleComponentSecurity, Title: "Exported component", Severity: preflight.SeverityInfo, Category: policies.CategorySecurity},
+		{ID: RuleSpecialPerm, Title: "Special permission", Severity: preflight.SeverityWarning, Category: policies.CategorySpecialPermissions},
+		{ID: RuleProviderSecurity, Title: "Exported provider without permission", Severity: preflight.SeverityError, Category: policies.CategorySecurity},
+		{ID: RuleFileProviderPaths, Title: "FileProvider shares a filesystem root", Severity: preflight.SeverityWarning, Category: policies.CategorySecurity},
+		{ID: RuleSigningScheme, Title: "Release build disables APK Signature Scheme v2", Severity: preflight.SeverityWarning, Category: policies.CategorySecurity},
+		{ID: RuleBackupRules, Title: "Backups enabled without exclusion rules", Severity: preflight.SeverityWarning, Category: policies.CategoryManifestValidation},
+		{ID: RulePermissionMaxSdk, Title: "Legacy permission without maxSdkVersion cap", Severity: preflight.SeverityWarning, Category: policies.CategoryManifestValidation},
+		{ID: RuleImpliedFeature, Title: "Permission implies required hardware", Severity: preflight.SeverityWarning, Category: policies.CategoryManifestValidation},
+		{ID: RuleBootReceiver, Title: "Receiver starts on boot", Severity: preflight.SeverityInfo, Category: policies.CategoryManifestValidation},
+		{ID: RuleTestOnly, Title: "Application is marked testOnly", Severity: preflight.SeverityError, Category: policies.CategoryManifestValidation},
+		{ID: RuleDuplicatePerm, Title: "Duplicate permission declaration", Severity: preflight.SeverityInfo, Category: policies.CategoryManifestValidation},
+		{ID: RuleImplicitBroadcast, Title: "Receiver declared for restricted implicit broadcasts", Severity: preflight.SeverityWarning, Category: policies.CategoryManifestValidation},
+		{ID: RuleLargeScreen, Title: "Activity is not resizeable on large screens", Severity: preflight.SeverityInfo, Category: policies.CategoryManifestValidation},
+	}
+	for i := range rules {
+		rules[i].Scanner = checkerID
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
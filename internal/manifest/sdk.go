@@ -0,0 +1,47 @@
+package manifest
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// gradleTargetSdkRe matches targetSdk declarations in Groovy and Kotlin DSL
+// build files: `targetSdk 34`, `targetSdkVersion 34`, `targetSdk = 34`.
+var gradleTargetSdkRe = regexp.MustCompile(`\btargetSdk(?:Version)?\s*(?:=\s*)?\(?\s*(\d+)`)
+
+// ResolveTargetSDK returns the app's target SDK version, preferring the value
+// declared in AndroidManifest.xml and falling back to the first targetSdk
+// found in the project's Gradle build files. It returns 0 when neither
+// declares one. opts are applied to the Gradle file walk.
+func ResolveTargetSDK(projectDir string, opts ...utils.WalkOption) int {
+	if m, err := FindAndParse(projectDir); err == nil && m.TargetSdkVersion > 0 {
+		return m.TargetSdkVersion
+	}
+
+	gradleFiles, err := utils.FindGradleFiles(projectDir, opts...)
+	if err != nil {
+		return 0
+	}
+	for _, gf := range gradleFiles {
+		data, err := utils.ReadFileWithLimit(gf)
+		if err != nil {
+			continue
+		}
+		if v := parseGradleTargetSDK(string(data)); v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// parseGradleTargetSDK extracts the targetSdk value from Gradle build file content.
+func parseGradleTargetSDK(content string) int {
+	m := gradleTargetSdkRe.FindStringSubmatch(content)
+	if m == nil {
+		return 0
+	}
+	v, _ := strconv.Atoi(m[1])
+	return v
+}
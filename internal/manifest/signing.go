@@ -0,0 +1,154 @@
+package manifest
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+var (
+	// gradleLineCommentRe matches // comments in Gradle build files. Newlines
+	// are kept so line numbers stay correct once comments are removed.
+	gradleLineCommentRe = regexp.MustCompile(`(?m)//.*$`)
+
+	// signingConfigsRe and buildTypesRe match the opening of the
+	// signingConfigs and buildTypes blocks.
+	signingConfigsRe = regexp.MustCompile(`\bsigningConfigs\s*\{`)
+	buildTypesRe     = regexp.MustCompile(`\bbuildTypes\s*\{`)
+
+	// namedBlockRe matches the end of the opening of a named block inside a
+	// Gradle container, in Groovy (`release {`) and Kotlin DSL
+	// (`create("release") {`, `getByName("release") {`), capturing the name.
+	namedBlockRe = regexp.MustCompile(`(?:\b(\w+)|\b(?:create|getByName|register|maybeCreate)\s*\(\s*"(\w+)"\s*\))\s*\{$`)
+
+	// releaseSigningRe matches the signing config a build type uses:
+	// `signingConfig signingConfigs.upload` or
+	// `signingConfig = signingConfigs.getByName("upload")`.
+	releaseSigningRe = regexp.MustCompile(`\bsigningConfig\s*(?:=\s*)?signingConfigs\.(?:getByName\s*\(\s*"(\w+)"\s*\)|(\w+))`)
+
+	// v1SigningRe, v2SigningRe, and v3SigningRe match the signing scheme
+	// switches of the old (v1SigningEnabled, isV2SigningEnabled) and current
+	// (enableV2Signing) DSLs.
+	v1SigningRe = regexp.MustCompile(`\b(?:v1SigningEnabled|isV1SigningEnabled|enableV1Signing)\s*(?:=\s*)?(true|false)\b`)
+	v2SigningRe = regexp.MustCompile(`\b(?:v2SigningEnabled|isV2SigningEnabled|enableV2Signing)\s*(?:=\s*)?(true|false)\b`)
+	v3SigningRe = regexp.MustCompile(`\benableV3Signing\s*(?:=\s*)?(true|false)\b`)
+)
+
+// CheckSigningSchemes warns when the signing config used by the release build
+// type turns off APK Signature Scheme v2, leaving JAR (v1) signing, which is
+// slower to verify and was open to the Janus manipulation attack. The release
+// build type's signingConfig is followed; a config named "release" is used
+// otherwise. Each Gradle build file is checked on its own.
+func (v *Validator) CheckSigningSchemes() []preflight.Finding {
+	if v.projectDir == "" {
+		return nil
+	}
+	gradleFiles, err := utils.FindGradleFiles(v.projectDir, v.walkOpts...)
+	if err != nil {
+		return nil
+	}
+
+	var findings []preflight.Finding
+	for _, gf := range gradleFiles {
+		data, err := utils.ReadFileWithLimit(gf)
+		if err != nil {
+			continue
+		}
+		line, v1Only, ok := releaseV2Disabled(gradleLineCommentRe.ReplaceAllString(string(data), ""))
+		if !ok {
+			continue
+		}
+		file := gf
+		if rel, err := filepath.Rel(v.projectDir, gf); err == nil {
+			file = rel
+		}
+		desc := "The release signing config sets v2 signing to false, so the APK is not signed with APK Signature Scheme v2."
+		if v1Only {
+			desc += " Only the deprecated JAR (v1) signature is left: it is slow to verify, does not protect the whole APK, and was open to the Janus attack (CVE-2017-13156) that injects code without breaking the signature."
+		}
+		findings = append(findings, preflight.Finding{
+			CheckID:     RuleSigningScheme,
+			Title:       "Release build disables APK Signature Scheme v2",
+			Description: desc,
+			Severity:    preflight.SeverityWarning,
+			Location:    preflight.Location{File: file, Line: line},
+			Suggestion:  "Remove the v2 setting (it is on by default) or set enableV2Signing = true, and enroll in Play App Signing so Google Play signs releases with the current schemes.",
+		})
+	}
+	return findings
+}
+
+// releaseV2Disabled finds the release signing config in Gradle build file
+// content and reports the 1-based line of a v2 switch set to false, and
+// whether v1 signing is then the only scheme left, i.e. v1 is not turned off
+// and v3 is not turned on.
+func releaseV2Disabled(content string) (line int, v1Only bool, ok bool) {
+	loc := signingConfigsRe.FindStringIndex(content)
+	if loc == nil {
+		return 0, false, false
+	}
+	configs := namedBlocks(content, loc[1]-1)
+
+	name := "release"
+	if bt := buildTypesRe.FindStringIndex(content); bt != nil {
+		if b, found := namedBlocks(content, bt[1]-1)["release"]; found {
+			if m := releaseSigningRe.FindStringSubmatch(content[b[0]:b[1]]); m != nil {
+				name = m[1] + m[2]
+			}
+		}
+	}
+	b, found := configs[name]
+	if !found {
+		return 0, false, false
+	}
+
+	body := content[b[0]:b[1]]
+	m := v2SigningRe.FindStringSubmatchIndex(body)
+	if m == nil || body[m[2]:m[3]] != "false" {
+		return 0, false, false
+	}
+	v1 := v1SigningRe.FindStringSubmatch(body)
+	v3 := v3SigningRe.FindStringSubmatch(body)
+	line = strings.Count(content[:b[0]+m[0]], "\n") + 1
+	return line, (v1 == nil || v1[1] == "true") && (v3 == nil || v3[1] == "false"), true
+}
+
+// namedBlocks returns the bodies, as start and end offsets into content, of
+// the named blocks directly inside the block whose opening brace is at open.
+func namedBlocks(content string, open int) map[string][2]int {
+	end := closingBrace(content, open)
+	blocks := make(map[string][2]int)
+	for pos := open + 1; pos < end; {
+		i := strings.IndexByte(content[pos:end], '{')
+		if i < 0 {
+			break
+		}
+		brace := pos + i
+		closing := closingBrace(content, brace)
+		if m := namedBlockRe.FindStringSubmatch(content[pos : brace+1]); m != nil {
+			blocks[m[1]+m[2]] = [2]int{brace + 1, closing}
+		}
+		pos = closing + 1
+	}
+	return blocks
+}
+
+// closingBrace returns the offset of the brace closing the one at open, or
+// len(content) when it is unbalanced.
+func closingBrace(content string, open int) int {
+	depth := 0
+	for i := open; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(content)
+}
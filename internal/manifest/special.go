@@ -0,0 +1,126 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// specialPermission describes a permission that is not granted through the
+// runtime permission dialog but by the user on a dedicated settings screen.
+type specialPermission struct {
+	GrantFlow     string
+	Justification string
+	// GrantCode matches code that sends the user to the settings screen or
+	// checks the grant state.
+	GrantCode *regexp.Regexp
+}
+
+// permBindDeviceAdmin is declared as android:permission on a device admin
+// receiver rather than with <uses-permission>.
+const permBindDeviceAdmin = "android.permission.BIND_DEVICE_ADMIN"
+
+// specialPermissions maps special app-op permissions to their grant flow.
+var specialPermissions = map[string]specialPermission{
+	"android.permission.WRITE_SETTINGS": {
+		GrantFlow:     "The user must enable \"Modify system settings\" from the screen opened by Settings.ACTION_MANAGE_WRITE_SETTINGS.",
+		Justification: "Play reviews apps that change system settings; the permission must be needed for core functionality.",
+		GrantCode:     regexp.MustCompile(`ACTION_MANAGE_WRITE_SETTINGS|android\.settings\.action\.MANAGE_WRITE_SETTINGS|Settings\.System\.canWrite`),
+	},
+	"android.permission.PACKAGE_USAGE_STATS": {
+		GrantFlow:     "The user must enable usage access from the screen opened by Settings.ACTION_USAGE_ACCESS_SETTINGS.",
+		Justification: "Usage access is limited to core use cases such as device management, parental control, and digital wellbeing.",
+		GrantCode:     regexp.MustCompile(`ACTION_USAGE_ACCESS_SETTINGS|android\.settings\.USAGE_ACCESS_SETTINGS`),
+	},
+	"android.permission.SYSTEM_ALERT_WINDOW": {
+		GrantFlow:     "The user must allow display over other apps from the screen opened by Settings.ACTION_MANAGE_OVERLAY_PERMISSION.",
+		Justification: "Overlays must not obstruct other apps or be used deceptively.",
+		GrantCode:     regexp.MustCompile(`ACTION_MANAGE_OVERLAY_PERMISSION|android\.settings\.action\.MANAGE_OVERLAY_PERMISSION|Settings\.canDrawOverlays`),
+	},
+	permBindDeviceAdmin: {
+		GrantFlow:     "The user must activate the device admin from the screen opened by DevicePolicyManager.ACTION_ADD_DEVICE_ADMIN.",
+		Justification: "Device admin is restricted to enterprise and device management apps and requires a prominent disclosure.",
+		GrantCode:     regexp.MustCompile(`ACTION_ADD_DEVICE_ADMIN|android\.app\.action\.ADD_DEVICE_ADMIN`),
+	},
+}
+
+// CheckSpecialPermissions flags special app-op permissions, which the user
+// grants from a system settings screen. When the validator has a project
+// directory, the sources are searched for the matching grant flow.
+func (v *Validator) CheckSpecialPermissions() []preflight.Finding {
+	type declared struct {
+		name string
+		line int
+	}
+	var perms []declared
+	for _, perm := range v.manifest.Permissions {
+		if _, ok := specialPermissions[perm.Name]; ok {
+			perms = append(perms, declared{perm.Name, perm.Line})
+		}
+	}
+	for _, r := range v.manifest.Receivers {
+		if r.Permission == permBindDeviceAdmin {
+			perms = append(perms, declared{permBindDeviceAdmin, r.Line})
+		}
+	}
+	if len(perms) == 0 {
+		return nil
+	}
+
+	var sources []string
+	if v.projectDir != "" {
+		sources = readSources(v.projectDir, v.walkOpts...)
+	}
+
+	var findings []preflight.Finding
+	for _, p := range perms {
+		info := specialPermissions[p.name]
+		short := shortPermName(p.name)
+		f := preflight.Finding{
+			CheckID:     RuleSpecialPerm,
+			Title:       fmt.Sprintf("Special permission: %s", short),
+			Description: fmt.Sprintf("%s is a special permission that the runtime permission dialog cannot grant. %s %s", short, info.GrantFlow, info.Justification),
+			Severity:    preflight.SeverityWarning,
+			Location: preflight.Location{
+				File: v.manifest.filePath,
+				Line: p.line,
+			},
+			Suggestion: fmt.Sprintf("Keep %s only if core functionality needs it, and be ready to justify it in Play Console.", short),
+		}
+		if v.projectDir != "" && !anyMatch(info.GrantCode, sources) {
+			f.Title = fmt.Sprintf("Special permission %s declared without grant flow", short)
+			f.Suggestion = fmt.Sprintf("No code launching the settings screen for %s was found, so the permission can never be granted. Remove it, or add the grant flow and be ready to justify it in Play Console.", short)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// readSources returns the contents of the Kotlin and Java files in projectDir.
+func readSources(projectDir string, opts ...utils.WalkOption) []string {
+	files, err := utils.WalkFiles(projectDir, append([]utils.WalkOption{utils.WithExtensions(".kt", ".java")}, opts...)...)
+	if err != nil {
+		return nil
+	}
+	sources := make([]string, 0, len(files))
+	for _, f := range files {
+		data, err := utils.ReadFileWithLimit(f)
+		if err != nil {
+			continue
+		}
+		sources = append(sources, string(data))
+	}
+	return sources
+}
+
+// anyMatch reports whether re matches any of the given sources.
+func anyMatch(re *regexp.Regexp, sources []string) bool {
+	for _, s := range sources {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package manifest
+
+import (
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// CheckTestOnly reports an application marked android:testOnly="true".
+// Android Studio adds the attribute to builds run from the IDE; such an APK
+// installs only through adb install -t, and Play rejects it on upload.
+func (v *Validator) CheckTestOnly() []preflight.Finding {
+	m := v.manifest
+	if !m.TestOnly {
+		return nil
+	}
+	return []preflight.Finding{{
+		CheckID:     RuleTestOnly,
+		Title:       "Application is marked testOnly",
+		Description: "android:testOnly is set to true on the <application> element. Test-only packages cannot be installed normally (only with adb install -t) and Google Play rejects them on upload.",
+		Severity:    preflight.SeverityError,
+		Location:    preflight.Location{File: m.filePath, Line: m.ApplicationLine},
+		Suggestion:  "Remove android:testOnly from the manifest and build the release from Gradle (bundleRelease or assembleRelease) rather than from an IDE run configuration.",
+		Remediation: &preflight.Remediation{Type: preflight.RemediationChangeAttribute, Target: "android:testOnly", Value: "false"},
+	}}
+}
@@ -1,21 +1,44 @@
 package manifest
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
 )
 
 // ManifestScanner implements preflight.Checker for manifest validation.
-type ManifestScanner struct{}
+type ManifestScanner struct {
+	opts []ValidatorOption
+}
 
 func (s *ManifestScanner) ID() string          { return "manifest" }
 func (s *ManifestScanner) Name() string        { return "AndroidManifest Validator" }
 func (s *ManifestScanner) Description() string { return "Validates AndroidManifest.xml for Play Store compliance" }
 
+// Rules implements preflight.RuleLister.
+func (s *ManifestScanner) Rules() []preflight.RuleInfo { return Rules() }
+
 func (s *ManifestScanner) Run(projectDir string) (*preflight.CheckResult, error) {
+	if IsBundlePath(projectDir) {
+		return s.runBundle(projectDir)
+	}
 	m, err := FindAndParse(projectDir)
+	if errors.Is(err, ErrManifestNotFound) {
+		coverage := ruleCoverage()
+		coverage.Exclude("no AndroidManifest.xml found", ruleIDsExcept(RuleManifestNotFound)...)
+		return &preflight.CheckResult{
+			CheckID:  s.ID(),
+			Passed:   false,
+			Findings: []preflight.Finding{manifestNotFound(projectDir)},
+			Coverage: coverage,
+		}, nil
+	}
 	if err != nil {
 		return &preflight.CheckResult{
 			CheckID: s.ID(),
@@ -24,64 +47,218 @@ func (s *ManifestScanner) Run(projectDir string) (*preflight.CheckResult, error)
 		}, err
 	}
 
-	v := NewValidator(m)
+	opts := append([]ValidatorOption{WithProjectDir(projectDir)}, s.opts...)
+	v := NewValidator(m, opts...)
 	findings := v.ValidateAll()
 
 	return &preflight.CheckResult{
 		CheckID:  s.ID(),
 		Passed:   len(findings) == 0,
 		Findings: findings,
+		Coverage: ruleCoverage(),
+	}, nil
+}
+
+// ruleCoverage returns a coverage with every manifest rule applicable.
+func ruleCoverage() *preflight.RuleCoverage {
+	return preflight.NewRuleCoverage(ruleIDsExcept()...)
+}
+
+// ruleIDsExcept returns the IDs of the manifest rules other than except.
+func ruleIDsExcept(except ...string) []string {
+	var ids []string
+	for _, r := range Rules() {
+		if !slices.Contains(except, r.ID) {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+// manifestNotFound reports that no manifest exists at the standard locations,
+// listing them relative to projectDir so non-standard layouts are easy to
+// diagnose.
+func manifestNotFound(projectDir string) preflight.Finding {
+	var checked []string
+	for _, path := range ManifestCandidates(projectDir) {
+		if rel, err := filepath.Rel(projectDir, path); err == nil {
+			path = rel
+		}
+		checked = append(checked, filepath.ToSlash(path))
+	}
+	return preflight.Finding{
+		CheckID:     RuleManifestNotFound,
+		Title:       "No AndroidManifest.xml found in expected locations",
+		Description: fmt.Sprintf("Manifest checks were skipped because no manifest was found. Checked: %s.", strings.Join(checked, ", ")),
+		Severity:    preflight.SeverityWarning,
+		Location:    preflight.Location{File: projectDir},
+		Suggestion:  "Scan the module directory that contains src/main/AndroidManifest.xml, e.g. pass ./app or list each module as a separate path.",
+	}
+}
+
+// runBundle validates the base module manifest of an App Bundle and checks
+// launcher placement across its modules. Bundles carry no sources, so checks
+// that cross-reference code are skipped.
+func (s *ManifestScanner) runBundle(bundlePath string) (*preflight.CheckResult, error) {
+	b, err := ParseBundle(bundlePath)
+	if err != nil {
+		return &preflight.CheckResult{
+			CheckID: s.ID(),
+			Passed:  false,
+			Err:     err,
+		}, err
+	}
+
+	findings := NewValidator(b.Base(), s.opts...).ValidateAll()
+	findings = append(findings, CheckBundleModules(b)...)
+
+	return &preflight.CheckResult{
+		CheckID:  s.ID(),
+		Passed:   len(findings) == 0,
+		Findings: findings,
+		Coverage: ruleCoverage(),
 	}, nil
 }
 
 // NewScanner creates a new ManifestScanner for use with the preflight runner.
-func NewScanner() *ManifestScanner {
-	return &ManifestScanner{}
+// The options are applied to the validator of every scanned project.
+func NewScanner(opts ...ValidatorOption) *ManifestScanner {
+	return &ManifestScanner{opts: opts}
 }
 
 // Validator runs compliance checks against a parsed AndroidManifest.
 type Validator struct {
-	manifest *AndroidManifest
+	manifest            *AndroidManifest
+	projectDir          string
+	previousVersionCode int
+	minSDKFloor         int
+	policies            *policies.PolicyDatabase
+	walkOpts            []utils.WalkOption
+}
+
+// ValidatorOption configures optional Validator behavior.
+type ValidatorOption func(*Validator)
+
+// WithProjectDir lets checks cross-reference manifest declarations with the
+// project's Kotlin and Java sources.
+func WithProjectDir(dir string) ValidatorOption {
+	return func(v *Validator) {
+		v.projectDir = dir
+	}
+}
+
+// WithFollowSymlinks makes checks that read the project's sources and Gradle
+// files follow symlinked files and directories.
+func WithFollowSymlinks() ValidatorOption {
+	return func(v *Validator) {
+		v.walkOpts = append(v.walkOpts, utils.WithFollowSymlinks())
+	}
+}
+
+// WithPolicies makes ValidateAll take rule severities and enabled flags from
+// db instead of the embedded policy database. A nil db runs every check with
+// its default severity.
+func WithPolicies(db *policies.PolicyDatabase) ValidatorOption {
+	return func(v *Validator) {
+		v.policies = db
+	}
 }
 
 // NewValidator creates a new manifest validator.
-func NewValidator(m *AndroidManifest) *Validator {
-	return &Validator{manifest: m}
+func NewValidator(m *AndroidManifest, opts ...ValidatorOption) *Validator {
+	db, _ := policies.Load()
+	v := &Validator{manifest: m, policies: db}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
 // ValidateAll runs all manifest validation checks and returns findings.
 func (v *Validator) ValidateAll() []preflight.Finding {
 	var findings []preflight.Finding
 	findings = append(findings, v.CheckTargetSDK()...)
+	findings = append(findings, v.CheckMinSDK()...)
+	findings = append(findings, v.CheckVersionCode()...)
+	findings = append(findings, v.CheckTestOnly()...)
+	findings = append(findings, v.CheckSigningSchemes()...)
 	findings = append(findings, v.CheckDangerousPermissions()...)
+	findings = append(findings, v.CheckPermissionMaxSdk()...)
+	findings = append(findings, v.CheckDuplicatePermissions()...)
+	findings = append(findings, v.CheckImpliedFeatures()...)
+	findings = append(findings, v.CheckSpecialPermissions()...)
+	findings = append(findings, v.CheckInstallPackages()...)
+	findings = append(findings, v.CheckBluetoothScan()...)
+	findings = append(findings, v.CheckForegroundServicePermissions()...)
+	findings = append(findings, v.CheckBootReceivers()...)
+	findings = append(findings, v.CheckImplicitBroadcasts()...)
 	findings = append(findings, v.CheckExportedComponents()...)
+	findings = append(findings, v.CheckProviderSecurity()...)
+	findings = append(findings, v.CheckFileProviderPaths()...)
 	findings = append(findings, v.CheckLauncherActivity()...)
+	findings = append(findings, v.CheckLargeScreenSupport()...)
 	findings = append(findings, v.CheckCleartextTraffic()...)
-	return findings
+	findings = append(findings, v.CheckNetworkSecurityConfig()...)
+	findings = append(findings, v.CheckBackupRules()...)
+	return v.applyPolicies(findings)
 }
 
-// CheckTargetSDK validates that targetSdkVersion meets Play Store requirements.
+// applyPolicies removes findings of rules disabled in the policy database and
+// sets the database severity on findings of rules whose database severity
+// differs from the default in Rules. A database severity equal to the default
+// leaves the severity each check computed from the manifest, such as CRITICAL
+// for SMS permissions, unchanged.
+func (v *Validator) applyPolicies(findings []preflight.Finding) []preflight.Finding {
+	if v.policies == nil {
+		return findings
+	}
+	defaults := make(map[string]preflight.Severity)
+	for _, r := range Rules() {
+		defaults[r.ID] = r.Severity
+	}
+	kept := findings[:0]
+	for _, f := range findings {
+		r := v.policies.GetRule(f.CheckID)
+		if r == nil {
+			kept = append(kept, f)
+			continue
+		}
+		if !r.IsEnabled() {
+			continue
+		}
+		if sev, err := preflight.ParseSeverity(r.Severity); err == nil && sev != defaults[f.CheckID] {
+			f.Severity = sev
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// CheckTargetSDK validates that targetSdkVersion meets Play Store requirements
+// for the app's form factor.
 func (v *Validator) CheckTargetSDK() []preflight.Finding {
 	m := v.manifest
+	ff := m.FormFactor()
+	minSDK := MinTargetSDKFor(ff)
 	if m.TargetSdkVersion == 0 {
 		return []preflight.Finding{{
 			CheckID:     RuleTargetSDK,
 			Title:       "Missing targetSdkVersion",
-			Description: "targetSdkVersion is not set in the manifest. Play Store requires targetSdkVersion >= 35.",
+			Description: fmt.Sprintf("targetSdkVersion is not set in the manifest. Play Store requires targetSdkVersion >= %d for %s apps.", minSDK, ff),
 			Severity:    preflight.SeverityCritical,
 			Location:    preflight.Location{File: m.filePath},
-			Suggestion:  "Set targetSdkVersion to 35 or higher in your build.gradle or AndroidManifest.xml.",
+			Suggestion:  fmt.Sprintf("Set targetSdkVersion to %d or higher in your build.gradle or AndroidManifest.xml.", minSDK),
 		}}
 	}
 
-	if m.TargetSdkVersion < MinTargetSDKVersion {
+	if m.TargetSdkVersion < minSDK {
 		return []preflight.Finding{{
 			CheckID:     RuleTargetSDK,
 			Title:       fmt.Sprintf("targetSdkVersion %d is below required minimum", m.TargetSdkVersion),
-			Description: fmt.Sprintf("targetSdkVersion is %d but Play Store requires >= %d for new apps and updates.", m.TargetSdkVersion, MinTargetSDKVersion),
+			Description: fmt.Sprintf("targetSdkVersion is %d but Play Store requires >= %d for new %s apps and updates.", m.TargetSdkVersion, minSDK, ff),
 			Severity:    preflight.SeverityCritical,
 			Location:    preflight.Location{File: m.filePath},
-			Suggestion:  fmt.Sprintf("Update targetSdkVersion to %d or higher.", MinTargetSDKVersion),
+			Suggestion:  fmt.Sprintf("Update targetSdkVersion to %d or higher.", minSDK),
 		}}
 	}
 
@@ -106,6 +283,7 @@ func (v *Validator) CheckDangerousPermissions() []preflight.Finding {
 				Line: perm.Line,
 			},
 			Suggestion: fmt.Sprintf("Ensure %s permission usage complies with Play Store policies. Add prominent disclosure if required.", info.Category),
+			PolicyLink: info.PolicyLink,
 		})
 	}
 	return findings
@@ -116,7 +294,7 @@ func (v *Validator) CheckDangerousPermissions() []preflight.Finding {
 func (v *Validator) CheckExportedComponents() []preflight.Finding {
 	var findings []preflight.Finding
 
-	checkComponent := func(name, kind string, exported *bool, filters []IntentFilter, line int) {
+	checkComponent := func(name, kind string, launcher bool, exported *bool, filters []IntentFilter, line int) {
 		if len(filters) == 0 {
 			return
 		}
@@ -130,7 +308,12 @@ func (v *Validator) CheckExportedComponents() []preflight.Finding {
 					File: v.manifest.filePath,
 					Line: line,
 				},
-				Suggestion: fmt.Sprintf("Add android:exported=\"true\" or android:exported=\"false\" to the <%s> element.", strings.ToLower(kind)),
+				Suggestion: exportedSuggestion(kind, filters),
+				Remediation: &preflight.Remediation{
+					Type:   preflight.RemediationChangeAttribute,
+					Target: "android:exported",
+					Value:  fixedExportedValue(kind, launcher, filters),
+				},
 			})
 		} else if *exported {
 			// Warn about explicitly exported components for security review.
@@ -149,21 +332,35 @@ func (v *Validator) CheckExportedComponents() []preflight.Finding {
 	}
 
 	for _, a := range v.manifest.Activities {
-		checkComponent(a.Name, "Activity", a.Exported, a.IntentFilters, a.Line)
+		checkComponent(a.Name, "Activity", isLauncherActivity(a), a.Exported, a.IntentFilters, a.Line)
 	}
 	for _, s := range v.manifest.Services {
-		checkComponent(s.Name, "Service", s.Exported, s.IntentFilters, s.Line)
+		checkComponent(s.Name, "Service", false, s.Exported, s.IntentFilters, s.Line)
 	}
 	for _, r := range v.manifest.Receivers {
-		checkComponent(r.Name, "Receiver", r.Exported, r.IntentFilters, r.Line)
+		checkComponent(r.Name, "Receiver", false, r.Exported, r.IntentFilters, r.Line)
 	}
 	for _, p := range v.manifest.Providers {
-		checkComponent(p.Name, "Provider", p.Exported, p.IntentFilters, p.Line)
+		checkComponent(p.Name, "Provider", false, p.Exported, p.IntentFilters, p.Line)
 	}
 
 	return findings
 }
 
+// exportedSuggestion returns the fix for a component missing android:exported.
+// Receivers for system broadcasts are delivered from outside the app and must
+// be exported to work, while receivers that only handle app-defined actions
+// should not be.
+func exportedSuggestion(kind string, filters []IntentFilter) string {
+	if kind != "Receiver" {
+		return fmt.Sprintf("Add android:exported=\"true\" or android:exported=\"false\" to the <%s> element.", strings.ToLower(kind))
+	}
+	if receivesExternalBroadcast(filters) {
+		return "Add android:exported=\"true\" to the <receiver> element; it handles a system broadcast that is delivered from outside the app and is not received otherwise."
+	}
+	return "Add android:exported=\"false\" to the <receiver> element; it only handles app-defined actions and does not need to be reachable from other apps."
+}
+
 // CheckLauncherActivity checks that the manifest has a launcher activity.
 func (v *Validator) CheckLauncherActivity() []preflight.Finding {
 	if v.manifest.HasLauncherActivity() {
@@ -1,8 +1,14 @@
 package manifest
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
 	"github.com/kotaroyamazaki/playcheck/internal/preflight"
 )
 
@@ -47,6 +53,73 @@ func TestCheckTargetSDK_MeetsMinimum(t *testing.T) {
 	}
 }
 
+func TestCheckTargetSDK_WearFormFactor(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.wear">
+    <uses-sdk android:minSdkVersion="30" android:targetSdkVersion="34" />
+    <uses-feature android:name="android.hardware.type.watch" />
+</manifest>`)
+	m, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if ff := m.FormFactor(); ff != FormFactorWear {
+		t.Fatalf("expected form factor %s, got %s", FormFactorWear, ff)
+	}
+
+	findings := NewValidator(m).CheckTargetSDK()
+	if len(findings) != 0 {
+		t.Errorf("expected 0 findings for Wear app targeting 34, got %d", len(findings))
+	}
+
+	m.TargetSdkVersion = 33
+	findings = NewValidator(m).CheckTargetSDK()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding for Wear app targeting 33, got %d", len(findings))
+	}
+}
+
+func TestCheckTargetSDK_TVFormFactor(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.tv">
+    <uses-sdk android:minSdkVersion="21" android:targetSdkVersion="34" />
+    <uses-feature android:name="android.software.leanback" android:required="false" />
+    <uses-feature android:name="android.hardware.touchscreen" android:required="false" />
+</manifest>`)
+	m, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if ff := m.FormFactor(); ff != FormFactorTV {
+		t.Fatalf("expected form factor %s, got %s", FormFactorTV, ff)
+	}
+
+	findings := NewValidator(m).CheckTargetSDK()
+	if len(findings) != 0 {
+		t.Errorf("expected 0 findings for TV app targeting 34, got %d", len(findings))
+	}
+}
+
+func TestCheckTargetSDK_PhoneDefault(t *testing.T) {
+	m := &AndroidManifest{TargetSdkVersion: 34, filePath: "AndroidManifest.xml"}
+	if ff := m.FormFactor(); ff != FormFactorPhone {
+		t.Fatalf("expected form factor %s, got %s", FormFactorPhone, ff)
+	}
+	findings := NewValidator(m).CheckTargetSDK()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding for phone app targeting 34, got %d", len(findings))
+	}
+}
+
+func TestFormFactor_AutomotiveMetaData(t *testing.T) {
+	m := &AndroidManifest{
+		MetaData: []MetaData{{Name: "com.google.android.gms.car.application", Resource: "@xml/automotive_app_desc"}},
+	}
+	if ff := m.FormFactor(); ff != FormFactorAutomotive {
+		t.Errorf("expected form factor %s, got %s", FormFactorAutomotive, ff)
+	}
+}
+
 func TestCheckDangerousPermissions(t *testing.T) {
 	m := &AndroidManifest{
 		filePath: "AndroidManifest.xml",
@@ -73,6 +146,11 @@ func TestCheckDangerousPermissions(t *testing.T) {
 			if f.Severity != preflight.SeverityCritical {
 				t.Errorf("SEND_SMS should be CRITICAL, got %s", f.Severity)
 			}
+			if f.PolicyLink != smsCallLogPolicy {
+				t.Errorf("SEND_SMS should link the SMS and Call Log policy, got %q", f.PolicyLink)
+			}
+		} else if f.PolicyLink != "" {
+			t.Errorf("line %d: expected no scanner policy link, got %q", f.Location.Line, f.PolicyLink)
 		}
 	}
 	if !foundSMS {
@@ -96,6 +174,100 @@ func TestCheckDangerousPermissions_NoFindings(t *testing.T) {
 	}
 }
 
+func TestCheckDangerousPermissions_GranularMedia(t *testing.T) {
+	m := &AndroidManifest{
+		filePath: "AndroidManifest.xml",
+		Permissions: []Permission{
+			{Name: "android.permission.READ_MEDIA_IMAGES", Line: 5},
+			{Name: "android.permission.READ_MEDIA_VIDEO", Line: 6},
+			{Name: "android.permission.READ_MEDIA_AUDIO", Line: 7},
+		},
+	}
+	findings := NewValidator(m).CheckDangerousPermissions()
+
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings for granular media permissions, got %d", len(findings))
+	}
+}
+
+func TestCheckSpecialPermissions_WriteSettingsWithoutIntent(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app", "src", "main", "java", "Main.kt")
+	if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("class Main { fun onCreate() {} }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &AndroidManifest{
+		filePath: "AndroidManifest.xml",
+		Permissions: []Permission{
+			{Name: "android.permission.WRITE_SETTINGS", Line: 4},
+		},
+	}
+	findings := NewValidator(m, WithProjectDir(dir)).CheckSpecialPermissions()
+
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.CheckID != RuleSpecialPerm {
+		t.Errorf("expected check ID %s, got %s", RuleSpecialPerm, f.CheckID)
+	}
+	if !strings.Contains(f.Title, "without grant flow") {
+		t.Errorf("expected missing grant flow title, got %q", f.Title)
+	}
+	if !strings.Contains(f.Description, "ACTION_MANAGE_WRITE_SETTINGS") {
+		t.Errorf("expected description to explain the grant mechanism, got %q", f.Description)
+	}
+	if f.Location.Line != 4 {
+		t.Errorf("expected line 4, got %d", f.Location.Line)
+	}
+
+	// Adding the settings intent resolves the missing grant flow.
+	code := "class Main { fun ask() { startActivity(Intent(Settings.ACTION_MANAGE_WRITE_SETTINGS)) } }"
+	if err := os.WriteFile(src, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	findings = NewValidator(m, WithProjectDir(dir)).CheckSpecialPermissions()
+	if len(findings) != 1 || strings.Contains(findings[0].Title, "without grant flow") {
+		t.Errorf("expected only the justification finding once the intent exists, got %v", findings)
+	}
+}
+
+func TestCheckSpecialPermissions_DeviceAdminReceiver(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <application>
+        <receiver android:name=".AdminReceiver"
+            android:permission="android.permission.BIND_DEVICE_ADMIN"
+            android:exported="true" />
+    </application>
+</manifest>`)
+	m, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	findings := NewValidator(m).CheckSpecialPermissions()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Title != "Special permission: BIND_DEVICE_ADMIN" {
+		t.Errorf("unexpected title %q", findings[0].Title)
+	}
+}
+
+func TestCheckSpecialPermissions_None(t *testing.T) {
+	m := &AndroidManifest{
+		Permissions: []Permission{{Name: "android.permission.INTERNET"}},
+	}
+	if findings := NewValidator(m).CheckSpecialPermissions(); len(findings) != 0 {
+		t.Errorf("expected no findings, got %d", len(findings))
+	}
+}
+
 func TestCheckExportedComponents_MissingExported(t *testing.T) {
 	m := &AndroidManifest{
 		filePath: "AndroidManifest.xml",
@@ -220,6 +392,84 @@ func TestCheckExportedComponents_Receivers(t *testing.T) {
 	}
 }
 
+func TestCheckExportedComponents_BootReceiverMissingExported(t *testing.T) {
+	m := &AndroidManifest{
+		filePath: "AndroidManifest.xml",
+		Receivers: []Receiver{
+			{
+				Name: ".BootReceiver",
+				IntentFilters: []IntentFilter{
+					{Actions: []string{"android.intent.action.BOOT_COMPLETED"}},
+				},
+				Line: 12,
+			},
+		},
+	}
+	findings := NewValidator(m).CheckExportedComponents()
+
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Severity != preflight.SeverityError {
+		t.Errorf("expected missing attribute to remain an ERROR, got %s", findings[0].Severity)
+	}
+	if !strings.Contains(findings[0].Suggestion, `android:exported="true"`) || strings.Contains(findings[0].Suggestion, `"false"`) {
+		t.Errorf("expected suggestion to set exported=true, got %q", findings[0].Suggestion)
+	}
+}
+
+func TestCheckExportedComponents_UnlistedSystemReceiverMissingExported(t *testing.T) {
+	m := &AndroidManifest{
+		filePath: "AndroidManifest.xml",
+		Receivers: []Receiver{
+			{
+				Name: ".UpdateReceiver",
+				IntentFilters: []IntentFilter{
+					{Actions: []string{"android.intent.action.MY_PACKAGE_REPLACED"}},
+				},
+				Line: 16,
+			},
+		},
+	}
+	findings := NewValidator(m).CheckExportedComponents()
+
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if !strings.Contains(findings[0].Suggestion, `android:exported="true"`) || strings.Contains(findings[0].Suggestion, `"false"`) {
+		t.Errorf("expected suggestion to set exported=true, got %q", findings[0].Suggestion)
+	}
+	if got := fixedExportedValue("Receiver", false, m.Receivers[0].IntentFilters); got != "true" {
+		t.Errorf("expected the fix to set exported=true, got %q", got)
+	}
+}
+
+func TestCheckExportedComponents_InternalReceiverMissingExported(t *testing.T) {
+	m := &AndroidManifest{
+		filePath: "AndroidManifest.xml",
+		Receivers: []Receiver{
+			{
+				Name: ".SyncReceiver",
+				IntentFilters: []IntentFilter{
+					{Actions: []string{"com.example.app.ACTION_SYNC"}},
+				},
+				Line: 20,
+			},
+		},
+	}
+	findings := NewValidator(m).CheckExportedComponents()
+
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Severity != preflight.SeverityError {
+		t.Errorf("expected ERROR severity, got %s", findings[0].Severity)
+	}
+	if !strings.Contains(findings[0].Suggestion, `android:exported="false"`) {
+		t.Errorf("expected suggestion to set exported=false, got %q", findings[0].Suggestion)
+	}
+}
+
 func TestCheckLauncherActivity_Present(t *testing.T) {
 	m := &AndroidManifest{
 		filePath: "AndroidManifest.xml",
@@ -330,6 +580,66 @@ func TestCheckCleartextTraffic_DefaultHighSDK(t *testing.T) {
 	}
 }
 
+func TestCheckNetworkSecurityConfig_PermissiveBaseConfig(t *testing.T) {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "AndroidManifest.xml")
+	nscPath := filepath.Join(dir, "res", "xml", "network_security_config.xml")
+	if err := os.MkdirAll(filepath.Dir(nscPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifestXML := `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <application android:networkSecurityConfig="@xml/network_security_config" />
+</manifest>`
+	nscXML := `<?xml version="1.0" encoding="utf-8"?>
+<network-security-config>
+    <base-config cleartextTrafficPermitted="true" />
+    <domain-config cleartextTrafficPermitted="true">
+        <domain includeSubdomains="true">legacy.example.com</domain>
+        <domain-config cleartextTrafficPermitted="false">
+            <domain>secure.legacy.example.com</domain>
+        </domain-config>
+    </domain-config>
+</network-security-config>`
+	if err := os.WriteFile(manifestPath, []byte(manifestXML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(nscPath, []byte(nscXML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ParseFile(manifestPath)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+	findings := NewValidator(m).CheckNetworkSecurityConfig()
+
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+	base := findings[0]
+	if base.Severity != preflight.SeverityError {
+		t.Errorf("expected ERROR for permissive base-config, got %s", base.Severity)
+	}
+	if base.Location.File != nscPath || base.Location.Line != 3 {
+		t.Errorf("expected location %s:3, got %s", nscPath, base.Location)
+	}
+	domain := findings[1]
+	if domain.Severity != preflight.SeverityWarning {
+		t.Errorf("expected WARNING for domain-config, got %s", domain.Severity)
+	}
+	if !strings.Contains(domain.Description, "legacy.example.com") || strings.Contains(domain.Description, "secure.legacy") {
+		t.Errorf("expected only the permissive domain in description, got %q", domain.Description)
+	}
+}
+
+func TestCheckNetworkSecurityConfig_NoReference(t *testing.T) {
+	m := &AndroidManifest{filePath: "AndroidManifest.xml"}
+	if findings := NewValidator(m).CheckNetworkSecurityConfig(); len(findings) != 0 {
+		t.Errorf("expected no findings without networkSecurityConfig, got %d", len(findings))
+	}
+}
+
 func TestValidateAll(t *testing.T) {
 	m := &AndroidManifest{
 		filePath:         "AndroidManifest.xml",
@@ -477,3 +787,1131 @@ func TestShortPermName(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckInstallPackages_InstallPackages(t *testing.T) {
+	m := &AndroidManifest{
+		filePath: "AndroidManifest.xml",
+		Permissions: []Permission{
+			{Name: "android.permission.INSTALL_PACKAGES", Line: 5},
+		},
+	}
+	findings := NewValidator(m).CheckInstallPackages()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.CheckID != RuleInstallPackages {
+		t.Errorf("expected check ID %s, got %s", RuleInstallPackages, f.CheckID)
+	}
+	if f.Severity != preflight.SeverityCritical {
+		t.Errorf("expected critical severity, got %s", f.Severity)
+	}
+	if !strings.Contains(f.Description, "device and network abuse") {
+		t.Errorf("expected description to reference the policy, got %q", f.Description)
+	}
+	if f.Location.Line != 5 {
+		t.Errorf("expected line 5, got %d", f.Location.Line)
+	}
+}
+
+func TestCheckInstallPackages_RequestInstallPackages(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app", "src", "main", "java", "Updater.kt")
+	if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("class Updater { fun run() {} }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &AndroidManifest{
+		filePath: "AndroidManifest.xml",
+		Permissions: []Permission{
+			{Name: "android.permission.REQUEST_INSTALL_PACKAGES", Line: 6},
+		},
+	}
+	findings := NewValidator(m, WithProjectDir(dir)).CheckInstallPackages()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Severity != preflight.SeverityWarning {
+		t.Errorf("expected warning severity, got %s", findings[0].Severity)
+	}
+	if !strings.Contains(findings[0].Title, "without installer usage") {
+		t.Errorf("expected missing installer usage title, got %q", findings[0].Title)
+	}
+
+	code := "class Updater { fun run(ctx: Context) { ctx.packageManager.packageInstaller.createSession(PackageInstaller.SessionParams(MODE_FULL_INSTALL)) } }"
+	if err := os.WriteFile(src, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	findings = NewValidator(m, WithProjectDir(dir)).CheckInstallPackages()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if strings.Contains(findings[0].Title, "without installer usage") {
+		t.Errorf("did not expect missing installer usage title, got %q", findings[0].Title)
+	}
+	if !strings.Contains(findings[0].Description, "Updater.kt") {
+		t.Errorf("expected description to name the installer source, got %q", findings[0].Description)
+	}
+
+	// REQUEST_INSTALL_PACKAGES is reported here, not as a special permission.
+	if sp := NewValidator(m).CheckSpecialPermissions(); len(sp) != 0 {
+		t.Errorf("expected no SP001 findings, got %d", len(sp))
+	}
+}
+
+func TestCheckVersionCode_PreviousVersionCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		previous int
+		want     int
+	}{
+		{"higher", 11, 10, 0},
+		{"equal", 10, 10, 1},
+		{"lower", 9, 10, 1},
+		{"no previous", 1, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &AndroidManifest{filePath: "AndroidManifest.xml", VersionCode: tt.code}
+			findings := NewValidator(m, WithPreviousVersionCode(tt.previous)).CheckVersionCode()
+			if len(findings) != tt.want {
+				t.Fatalf("expected %d findings, got %d", tt.want, len(findings))
+			}
+			if tt.want == 0 {
+				return
+			}
+			if findings[0].CheckID != RuleVersionCode {
+				t.Errorf("expected check ID %s, got %s", RuleVersionCode, findings[0].CheckID)
+			}
+			if findings[0].Severity != preflight.SeverityError {
+				t.Errorf("expected error severity, got %s", findings[0].Severity)
+			}
+		})
+	}
+}
+
+func TestCheckVersionCode_Missing(t *testing.T) {
+	m := &AndroidManifest{filePath: "AndroidManifest.xml"}
+	findings := NewValidator(m, WithPreviousVersionCode(5)).CheckVersionCode()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Severity != preflight.SeverityWarning {
+		t.Errorf("expected warning severity, got %s", findings[0].Severity)
+	}
+}
+
+func TestCheckVersionCode_Gradle(t *testing.T) {
+	dir := t.TempDir()
+	gradle := filepath.Join(dir, "app", "build.gradle.kts")
+	if err := os.MkdirAll(filepath.Dir(gradle), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "android {\n    defaultConfig {\n        versionCode = 7\n    }\n}\n"
+	if err := os.WriteFile(gradle, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &AndroidManifest{filePath: "AndroidManifest.xml"}
+	findings := NewValidator(m, WithProjectDir(dir), WithPreviousVersionCode(7)).CheckVersionCode()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != preflight.SeverityError {
+		t.Errorf("expected error severity, got %s", f.Severity)
+	}
+	if f.Location.File != filepath.Join("app", "build.gradle.kts") || f.Location.Line != 3 {
+		t.Errorf("expected app/build.gradle.kts:3, got %s:%d", f.Location.File, f.Location.Line)
+	}
+
+	findings = NewValidator(m, WithProjectDir(dir), WithPreviousVersionCode(6)).CheckVersionCode()
+	if len(findings) != 0 {
+		t.Errorf("expected no findings for a higher versionCode, got %d", len(findings))
+	}
+}
+
+func TestCheckSigningSchemes(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantLine int // 0 for no finding
+		wantV1   bool
+	}{
+		{
+			name: "groovy release with v2 disabled",
+			file: "build.gradle",
+			content: `android {
+    signingConfigs {
+        debug {
+            v2SigningEnabled false
+        }
+        release {
+            storeFile file("release.jks")
+            v1SigningEnabled true
+            v2SigningEnabled false
+        }
+    }
+}
+`,
+			wantLine: 9,
+			wantV1:   true,
+		},
+		{
+			name: "kotlin DSL release with v2 disabled",
+			file: "build.gradle.kts",
+			content: `android {
+    signingConfigs {
+        create("release") {
+            storeFile = file("release.jks")
+            enableV2Signing = false
+            enableV3Signing = true
+        }
+    }
+}
+`,
+			wantLine: 5,
+		},
+		{
+			name: "release build type uses another config",
+			file: "build.gradle.kts",
+			content: `android {
+    signingConfigs {
+        create("upload") {
+            enableV1Signing = true
+            isV2SigningEnabled = false
+        }
+    }
+    buildTypes {
+        getByName("release") {
+            signingConfig = signingConfigs.getByName("upload")
+        }
+    }
+}
+`,
+			wantLine: 5,
+			wantV1:   true,
+		},
+		{
+			name: "only debug disables v2",
+			file: "build.gradle",
+			content: `android {
+    signingConfigs {
+        debug {
+            v2SigningEnabled false
+        }
+        release {
+            storeFile file("release.jks")
+        }
+    }
+}
+`,
+		},
+		{
+			name: "commented out switch",
+			file: "build.gradle",
+			content: `android {
+    signingConfigs {
+        release {
+            // v2SigningEnabled false
+            v2SigningEnabled true
+        }
+    }
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app", tt.file)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			m := &AndroidManifest{filePath: "AndroidManifest.xml"}
+			findings := NewValidator(m, WithProjectDir(dir)).CheckSigningSchemes()
+			if tt.wantLine == 0 {
+				if len(findings) != 0 {
+					t.Fatalf("expected no findings, got %+v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("expected 1 finding, got %d", len(findings))
+			}
+			f := findings[0]
+			if f.CheckID != RuleSigningScheme || f.Severity != preflight.SeverityWarning {
+				t.Errorf("expected %s warning, got %s %s", RuleSigningScheme, f.CheckID, f.Severity)
+			}
+			if want := filepath.Join("app", tt.file); f.Location.File != want || f.Location.Line != tt.wantLine {
+				t.Errorf("expected %s:%d, got %s:%d", want, tt.wantLine, f.Location.File, f.Location.Line)
+			}
+			if got := strings.Contains(f.Description, "JAR (v1)"); got != tt.wantV1 {
+				t.Errorf("expected v1-only note %v, got description %q", tt.wantV1, f.Description)
+			}
+		})
+	}
+}
+
+func TestParsePermission_UsesPermissionFlags(t *testing.T) {
+	m, err := Parse([]byte(`<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="test">
+		<uses-permission android:name="android.permission.BLUETOOTH_SCAN" android:usesPermissionFlags="neverForLocation" />
+	</manifest>`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(m.Permissions) != 1 || !m.Permissions[0].HasFlag("neverForLocation") {
+		t.Errorf("expected neverForLocation flag, got %+v", m.Permissions)
+	}
+}
+
+func TestCheckBluetoothScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		perms string
+		want  bool
+	}{
+		{
+			name:  "unflagged",
+			perms: `<uses-permission android:name="android.permission.BLUETOOTH_SCAN" />`,
+			want:  true,
+		},
+		{
+			name:  "flagged",
+			perms: `<uses-permission android:name="android.permission.BLUETOOTH_SCAN" android:usesPermissionFlags="neverForLocation" />`,
+		},
+		{
+			name: "unflagged with location permission",
+			perms: `<uses-permission android:name="android.permission.BLUETOOTH_SCAN" />
+		<uses-permission android:name="android.permission.ACCESS_FINE_LOCATION" />`,
+		},
+		{
+			name:  "no bluetooth scan",
+			perms: `<uses-permission android:name="android.permission.BLUETOOTH_CONNECT" />`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse([]byte(`<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="test">
+		` + tt.perms + `
+	</manifest>`))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			findings := NewValidator(m).CheckBluetoothScan()
+			if got := len(findings) == 1; got != tt.want {
+				t.Fatalf("got %d findings, want finding=%v", len(findings), tt.want)
+			}
+			if tt.want {
+				if findings[0].CheckID != RuleLocationPerm || findings[0].Location.Line != 2 {
+					t.Errorf("unexpected finding %s at line %d", findings[0].CheckID, findings[0].Location.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAll_PolicyDisabledRule(t *testing.T) {
+	db, err := policies.Parse([]byte(`{"version": "test", "rules": [
+		{"id": "DP003", "name": "Camera", "severity": "WARNING", "category": "dangerous_permissions", "enabled": false,
+			"detection_patterns": [{"type": "manifest_permission", "value": "android.permission.CAMERA"}]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Parse([]byte(sampleManifest))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	for _, f := range NewValidator(m, WithPolicies(db)).ValidateAll() {
+		if f.CheckID == RuleCameraPerm {
+			t.Errorf("expected no %s findings when the rule is disabled, got %q", RuleCameraPerm, f.Title)
+		}
+	}
+
+	var found bool
+	for _, f := range NewValidator(m, WithPolicies(nil)).ValidateAll() {
+		found = found || f.CheckID == RuleCameraPerm
+	}
+	if !found {
+		t.Errorf("expected a %s finding without a policy database", RuleCameraPerm)
+	}
+}
+
+func TestValidateAll_PolicySeverity(t *testing.T) {
+	db, err := policies.Parse([]byte(`{"version": "test", "rules": [
+		{"id": "DP003", "name": "Camera", "severity": "ERROR", "category": "dangerous_permissions",
+			"detection_patterns": [{"type": "manifest_permission", "value": "android.permission.CAMERA"}]},
+		{"id": "DP001", "name": "Dangerous", "severity": "WARNING", "category": "dangerous_permissions",
+			"detection_patterns": [{"type": "manifest_permission", "value": "android.permission.SEND_SMS"}]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Parse([]byte(`<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="test">
+    <uses-permission android:name="android.permission.CAMERA" />
+    <uses-permission android:name="android.permission.SEND_SMS" />
+</manifest>`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	severities := make(map[string]preflight.Severity)
+	for _, f := range NewValidator(m, WithPolicies(db)).ValidateAll() {
+		severities[f.CheckID] = f.Severity
+	}
+	if got := severities[RuleCameraPerm]; got != preflight.SeverityError {
+		t.Errorf("expected %s severity from the policy database (ERROR), got %s", RuleCameraPerm, got)
+	}
+	// A database severity equal to the rule default keeps the severity the
+	// check computed.
+	if got := severities[RuleDangerousPerm]; got != preflight.SeverityCritical {
+		t.Errorf("expected SEND_SMS to stay CRITICAL, got %s", got)
+	}
+}
+
+func TestCheckForegroundServicePermissions(t *testing.T) {
+	const manifestFmt = `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="test">
+    <uses-sdk android:targetSdkVersion="%d" />
+    %s
+    <application>
+        <service android:name=".TrackingService" android:foregroundServiceType="location|mediaPlayback" />
+    </application>
+</manifest>`
+
+	tests := []struct {
+		name      string
+		targetSDK int
+		perms     string
+		want      []string
+		severity  preflight.Severity
+	}{
+		{
+			name:      "location permission missing",
+			targetSDK: 35,
+			perms:     `<uses-permission android:name="android.permission.FOREGROUND_SERVICE_MEDIA_PLAYBACK" />`,
+			want:      []string{"location"},
+			severity:  preflight.SeverityError,
+		},
+		{
+			name:      "both missing before API 34",
+			targetSDK: 33,
+			want:      []string{"location", "mediaPlayback"},
+			severity:  preflight.SeverityWarning,
+		},
+		{
+			name:      "all declared",
+			targetSDK: 35,
+			perms: `<uses-permission android:name="android.permission.FOREGROUND_SERVICE_LOCATION" />
+    <uses-permission android:name="android.permission.FOREGROUND_SERVICE_MEDIA_PLAYBACK" />`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse([]byte(fmt.Sprintf(manifestFmt, tt.targetSDK, tt.perms)))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			findings := NewValidator(m).CheckForegroundServicePermissions()
+			if len(findings) != len(tt.want) {
+				t.Fatalf("got %d findings, want %d", len(findings), len(tt.want))
+			}
+			for i, f := range findings {
+				if f.CheckID != RuleForegroundPerm || f.Severity != tt.severity {
+					t.Errorf("got %s/%s, want %s/%s", f.CheckID, f.Severity, RuleForegroundPerm, tt.severity)
+				}
+				if !strings.Contains(f.Title, tt.want[i]) {
+					t.Errorf("finding %q should name the %s type", f.Title, tt.want[i])
+				}
+				if f.Location.Line != 5 {
+					t.Errorf("got line %d, want 5", f.Location.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckProviderSecurity(t *testing.T) {
+	m, err := Parse([]byte(`<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="test">
+	<uses-sdk android:targetSdkVersion="34" />
+	<application>
+		<provider android:name=".OpenProvider" android:authorities="test.open" android:exported="true" />
+		<provider android:name=".GuardedProvider" android:authorities="test.guarded" android:exported="true"
+			android:readPermission="test.READ" android:writePermission="test.WRITE">
+			<path-permission android:pathPrefix="/public" android:readPermission="test.PUBLIC" />
+		</provider>
+		<provider android:name=".SharedProvider" android:authorities="test.shared" android:exported="true"
+			android:permission="test.ACCESS" android:grantUriPermissions="true" />
+		<provider android:name="androidx.core.content.FileProvider" android:authorities="test.files"
+			android:exported="false" android:grantUriPermissions="true" />
+	</application>
+</manifest>`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	guarded := m.Providers[1]
+	if guarded.ReadPermission != "test.READ" || guarded.WritePermission != "test.WRITE" {
+		t.Errorf("unexpected provider permissions: %+v", guarded)
+	}
+	if len(guarded.PathPermissions) != 1 || guarded.PathPermissions[0].Path != "/public" || guarded.PathPermissions[0].Line != 7 {
+		t.Errorf("unexpected path permissions: %+v", guarded.PathPermissions)
+	}
+
+	findings := NewValidator(m).CheckProviderSecurity()
+	if len(findings) != 2 {
+		for _, f := range findings {
+			t.Logf("  %s", f)
+		}
+		t.Fatalf("got %d findings, want 2", len(findings))
+	}
+	if f := findings[0]; f.Severity != preflight.SeverityError || f.Location.Line != 4 || !strings.Contains(f.Title, "OpenProvider") {
+		t.Errorf("expected error for the unprotected provider at line 4, got %s", f)
+	}
+	if f := findings[1]; f.Severity != preflight.SeverityWarning || f.Location.Line != 9 || !strings.Contains(f.Title, "grants URI permissions broadly") {
+		t.Errorf("expected broad grant warning at line 9, got %s", f)
+	}
+	for _, f := range findings {
+		if f.CheckID != RuleProviderSecurity {
+			t.Errorf("got check ID %s, want %s", f.CheckID, RuleProviderSecurity)
+		}
+	}
+}
+
+func TestCheckBackupRules(t *testing.T) {
+	const rulesWithExclude = `<data-extraction-rules>
+    <cloud-backup>
+        <exclude domain="sharedpref" path="session.xml" />
+    </cloud-backup>
+</data-extraction-rules>`
+	const rulesWithoutExclude = `<full-backup-content>
+    <include domain="file" path="notes" />
+</full-backup-content>`
+
+	tests := []struct {
+		name        string
+		application string
+		permission  string
+		rules       map[string]string // res/xml file name -> content
+		wantFinding bool
+	}{
+		{
+			name:        "sensitive data with default backups",
+			application: `<application>`,
+			permission:  "android.permission.READ_CONTACTS",
+			wantFinding: true,
+		},
+		{
+			name:        "backups disabled",
+			application: `<application android:allowBackup="false">`,
+			permission:  "android.permission.READ_CONTACTS",
+		},
+		{
+			name:        "no sensitive permissions",
+			application: `<application android:allowBackup="true">`,
+			permission:  "android.permission.INTERNET",
+		},
+		{
+			name:        "extraction rules with exclusions",
+			application: `<application android:dataExtractionRules="@xml/data_extraction_rules">`,
+			permission:  "android.permission.READ_CONTACTS",
+			rules:       map[string]string{"data_extraction_rules.xml": rulesWithExclude},
+		},
+		{
+			name:        "backup rules without exclusions",
+			application: `<application android:fullBackupContent="@xml/backup_rules">`,
+			permission:  "android.permission.ACCESS_FINE_LOCATION",
+			rules:       map[string]string{"backup_rules.xml": rulesWithoutExclude},
+			wantFinding: true,
+		},
+		{
+			name:        "unresolvable rules reference",
+			application: `<application android:dataExtractionRules="@xml/generated_rules">`,
+			permission:  "android.permission.READ_CONTACTS",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.rules {
+				path := filepath.Join(dir, "res", "xml", name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			manifestPath := filepath.Join(dir, "AndroidManifest.xml")
+			manifestXML := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <uses-sdk android:targetSdkVersion="34" />
+    <uses-permission android:name="` + tt.permission + `" />
+    ` + tt.application + `
+    </application>
+</manifest>`
+			if err := os.WriteFile(manifestPath, []byte(manifestXML), 0644); err != nil {
+				t.Fatal(err)
+			}
+			m, err := ParseFile(manifestPath)
+			if err != nil {
+				t.Fatalf("ParseFile() error: %v", err)
+			}
+
+			findings := NewValidator(m).CheckBackupRules()
+			if !tt.wantFinding {
+				if len(findings) != 0 {
+					t.Errorf("expected no findings, got %+v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("expected 1 finding, got %d", len(findings))
+			}
+			f := findings[0]
+			if f.CheckID != RuleBackupRules || f.Severity != preflight.SeverityWarning {
+				t.Errorf("expected %s warning, got %s %s", RuleBackupRules, f.CheckID, f.Severity)
+			}
+			if f.Location.Line != 4 {
+				t.Errorf("expected finding on the <application> line 4, got %d", f.Location.Line)
+			}
+		})
+	}
+}
+
+func TestCheckBootReceivers(t *testing.T) {
+	boot := IntentFilter{Actions: []string{"android.intent.action.BOOT_COMPLETED"}}
+	tests := []struct {
+		name      string
+		receivers []Receiver
+		source    string // Kotlin source written to the project; empty for none
+		want      []preflight.Severity
+	}{
+		{
+			name:      "boot receiver",
+			receivers: []Receiver{{Name: ".BootReceiver", IntentFilters: []IntentFilter{boot}, Line: 5}},
+			source:    "class BootReceiver : BroadcastReceiver()",
+			want:      []preflight.Severity{preflight.SeverityInfo},
+		},
+		{
+			name:      "boot receiver with foreground service start",
+			receivers: []Receiver{{Name: ".BootReceiver", IntentFilters: []IntentFilter{boot}, Line: 5}},
+			source:    "ContextCompat.startForegroundService(context, Intent(context, SyncService::class.java))",
+			want:      []preflight.Severity{preflight.SeverityWarning},
+		},
+		{
+			name: "other receiver",
+			receivers: []Receiver{{Name: ".PackageReceiver", IntentFilters: []IntentFilter{
+				{Actions: []string{"android.intent.action.PACKAGE_ADDED"}},
+			}, Line: 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.source != "" {
+				if err := os.WriteFile(filepath.Join(dir, "Boot.kt"), []byte(tt.source), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			m := &AndroidManifest{filePath: "AndroidManifest.xml", Receivers: tt.receivers}
+			var got []preflight.Severity
+			for _, f := range NewValidator(m, WithProjectDir(dir)).CheckBootReceivers() {
+				if f.CheckID != RuleBootReceiver || f.Location.Line != 5 {
+					t.Errorf("expected %s at line 5, got %s at line %d", RuleBootReceiver, f.CheckID, f.Location.Line)
+				}
+				got = append(got, f.Severity)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected severities %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckFileProviderPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths string
+		want  []int // lines of findings in file_paths.xml
+	}{
+		{
+			name: "broad",
+			paths: `<paths>
+    <root-path name="root" path="/" />
+    <external-path name="external" path="." />
+    <cache-path name="cache" path="." />
+</paths>`,
+			want: []int{2, 3},
+		},
+		{
+			name: "scoped",
+			paths: `<paths>
+    <external-path name="pictures" path="Pictures/MyApp/" />
+    <cache-path name="shared" path="shared/" />
+</paths>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			pathsFile := filepath.Join(dir, "res", "xml", "file_paths.xml")
+			if err := os.MkdirAll(filepath.Dir(pathsFile), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(pathsFile, []byte(tt.paths), 0644); err != nil {
+				t.Fatal(err)
+			}
+			manifestPath := filepath.Join(dir, "AndroidManifest.xml")
+			manifestXML := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <application>
+        <provider android:name="androidx.core.content.FileProvider"
+            android:authorities="com.example.fileprovider"
+            android:exported="false"
+            android:grantUriPermissions="true">
+            <meta-data android:name="android.support.FILE_PROVIDER_PATHS"
+                android:resource="@xml/file_paths" />
+        </provider>
+    </application>
+</manifest>`
+			if err := os.WriteFile(manifestPath, []byte(manifestXML), 0644); err != nil {
+				t.Fatal(err)
+			}
+			m, err := ParseFile(manifestPath)
+			if err != nil {
+				t.Fatalf("ParseFile() error: %v", err)
+			}
+
+			var got []int
+			for _, f := range NewValidator(m).CheckFileProviderPaths() {
+				if f.CheckID != RuleFileProviderPaths || f.Severity != preflight.SeverityWarning || f.Location.File != pathsFile {
+					t.Errorf("unexpected finding %s %s at %s", f.CheckID, f.Severity, f.Location)
+				}
+				got = append(got, f.Location.Line)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected findings on lines %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckPermissionMaxSdk(t *testing.T) {
+	tests := []struct {
+		name      string
+		targetSdk int
+		perm      Permission
+		want      int
+	}{
+		{name: "uncapped read storage", targetSdk: 34, perm: Permission{Name: "android.permission.READ_EXTERNAL_STORAGE", Line: 4}, want: 1},
+		{name: "read storage capped at 32", targetSdk: 34, perm: Permission{Name: "android.permission.READ_EXTERNAL_STORAGE", MaxSdk: 32, Line: 4}, want: 0},
+		{name: "read storage on old target", targetSdk: 32, perm: Permission{Name: "android.permission.READ_EXTERNAL_STORAGE", Line: 4}, want: 0},
+		{name: "uncapped write storage", targetSdk: 34, perm: Permission{Name: "android.permission.WRITE_EXTERNAL_STORAGE", Line: 4}, want: 1},
+		{name: "write storage capped above 29", targetSdk: 34, perm: Permission{Name: "android.permission.WRITE_EXTERNAL_STORAGE", MaxSdk: 32, Line: 4}, want: 1},
+		{name: "write storage capped at 29", targetSdk: 34, perm: Permission{Name: "android.permission.WRITE_EXTERNAL_STORAGE", MaxSdk: 29, Line: 4}, want: 0},
+		{name: "write storage capped at 28", targetSdk: 34, perm: Permission{Name: "android.permission.WRITE_EXTERNAL_STORAGE", MaxSdk: 28, Line: 4}, want: 0},
+		{name: "write storage on old target", targetSdk: 29, perm: Permission{Name: "android.permission.WRITE_EXTERNAL_STORAGE", Line: 4}, want: 0},
+		{name: "uncapped bluetooth", targetSdk: 31, perm: Permission{Name: "android.permission.BLUETOOTH", Line: 4}, want: 1},
+		{name: "unrelated permission", targetSdk: 34, perm: Permission{Name: "android.permission.CAMERA", Line: 4}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &AndroidManifest{
+				filePath:         "AndroidManifest.xml",
+				TargetSdkVersion: tt.targetSdk,
+				Permissions:      []Permission{tt.perm},
+			}
+			findings := NewValidator(m).CheckPermissionMaxSdk()
+			if len(findings) != tt.want {
+				t.Fatalf("expected %d findings, got %d: %+v", tt.want, len(findings), findings)
+			}
+			if tt.want == 0 {
+				return
+			}
+			if f := findings[0]; f.CheckID != RulePermissionMaxSdk || f.Location.Line != 4 {
+				t.Errorf("expected %s at line 4, got %s at line %d", RulePermissionMaxSdk, f.CheckID, f.Location.Line)
+			}
+		})
+	}
+}
+
+func TestCheckMinSDK(t *testing.T) {
+	tests := []struct {
+		name   string
+		minSdk int
+		floor  int
+		want   string // title; empty if no finding
+	}{
+		{name: "unset", minSdk: 0, want: "Missing minSdkVersion"},
+		{name: "too low", minSdk: 16, want: "minSdkVersion 16 is below 21"},
+		{name: "at default floor", minSdk: 21},
+		{name: "above default floor", minSdk: 26},
+		{name: "below configured floor", minSdk: 21, floor: 24, want: "minSdkVersion 21 is below 24"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &AndroidManifest{filePath: "AndroidManifest.xml", MinSdkVersion: tt.minSdk}
+			findings := NewValidator(m, WithMinSDKFloor(tt.floor)).CheckMinSDK()
+			if tt.want == "" {
+				if len(findings) != 0 {
+					t.Errorf("expected no findings, got %+v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+			}
+			f := findings[0]
+			if f.CheckID != RuleMinSDK || f.Title != tt.want || f.Severity != preflight.SeverityWarning {
+				t.Errorf("got %s %q (%s), want %s %q (%s)", f.CheckID, f.Title, f.Severity, RuleMinSDK, tt.want, preflight.SeverityWarning)
+			}
+		})
+	}
+}
+
+func TestCheckMinSDK_Gradle(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		want     string // title; empty if no finding
+		wantLine int
+	}{
+		{"groovy below floor", "build.gradle", "android {\n    defaultConfig {\n        minSdkVersion 19\n    }\n}\n", "minSdkVersion 19 is below 21", 3},
+		{"kotlin below floor", "build.gradle.kts", "android {\n    defaultConfig {\n        minSdk = 16\n    }\n}\n", "minSdkVersion 16 is below 21", 3},
+		{"kotlin at floor", "build.gradle.kts", "android {\n    defaultConfig {\n        minSdk = 24\n    }\n}\n", "", 0},
+		{"not declared", "build.gradle", "android {}\n", "Missing minSdkVersion", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			gradle := filepath.Join(dir, "app", tt.file)
+			if err := os.MkdirAll(filepath.Dir(gradle), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(gradle, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			m := &AndroidManifest{filePath: "AndroidManifest.xml"}
+			findings := NewValidator(m, WithProjectDir(dir)).CheckMinSDK()
+			if tt.want == "" {
+				if len(findings) != 0 {
+					t.Errorf("expected no findings, got %+v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+			}
+			f := findings[0]
+			if f.Title != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, f.Title)
+			}
+			if tt.wantLine > 0 && (f.Location.File != filepath.Join("app", tt.file) || f.Location.Line != tt.wantLine) {
+				t.Errorf("expected app/%s:%d, got %s:%d", tt.file, tt.wantLine, f.Location.File, f.Location.Line)
+			}
+		})
+	}
+}
+
+func TestCheckImpliedFeatures(t *testing.T) {
+	tests := []struct {
+		name      string
+		targetSdk int
+		perms     []Permission
+		features  []Feature
+		want      []string // titles
+	}{
+		{
+			name:      "camera without uses-feature",
+			targetSdk: 34,
+			perms:     []Permission{{Name: "android.permission.CAMERA", Line: 3}},
+			want:      []string{"CAMERA implies required hardware"},
+		},
+		{
+			name:      "camera with optional features",
+			targetSdk: 34,
+			perms:     []Permission{{Name: "android.permission.CAMERA", Line: 3}},
+			features: []Feature{
+				{Name: "android.hardware.camera", Required: false},
+				{Name: "android.hardware.camera.autofocus", Required: false},
+			},
+		},
+		{
+			name:      "camera explicitly required",
+			targetSdk: 34,
+			perms:     []Permission{{Name: "android.permission.CAMERA", Line: 3}},
+			features: []Feature{
+				{Name: "android.hardware.camera", Required: true},
+				{Name: "android.hardware.camera.autofocus", Required: true},
+			},
+		},
+		{
+			name:      "location on modern target",
+			targetSdk: 34,
+			perms:     []Permission{{Name: "android.permission.ACCESS_FINE_LOCATION", Line: 3}},
+		},
+		{
+			name:      "location on legacy target",
+			targetSdk: 19,
+			perms: []Permission{
+				{Name: "android.permission.ACCESS_FINE_LOCATION", Line: 3},
+				{Name: "android.permission.ACCESS_COARSE_LOCATION", Line: 4},
+			},
+			want: []string{"ACCESS_FINE_LOCATION implies required hardware", "ACCESS_COARSE_LOCATION implies required hardware"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &AndroidManifest{
+				filePath:         "AndroidManifest.xml",
+				TargetSdkVersion: tt.targetSdk,
+				Permissions:      tt.perms,
+				Features:         tt.features,
+			}
+			var got []string
+			for _, f := range NewValidator(m).CheckImpliedFeatures() {
+				if f.CheckID != RuleImpliedFeature {
+					t.Errorf("unexpected check ID %s", f.CheckID)
+				}
+				got = append(got, f.Title)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTestOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		attr string
+		want int
+	}{
+		{"testOnly true", ` android:testOnly="true"`, 1},
+		{"testOnly false", ` android:testOnly="false"`, 0},
+		{"testOnly absent", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xml := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <application` + tt.attr + `>
+    </application>
+</manifest>`
+			m, err := Parse([]byte(xml))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			findings := NewValidator(m).CheckTestOnly()
+			if len(findings) != tt.want {
+				t.Fatalf("expected %d findings, got %d", tt.want, len(findings))
+			}
+			if tt.want == 0 {
+				return
+			}
+			f := findings[0]
+			if f.CheckID != RuleTestOnly || f.Severity != preflight.SeverityError {
+				t.Errorf("expected %s at ERROR, got %s at %s", RuleTestOnly, f.CheckID, f.Severity)
+			}
+			if f.Location.Line != 2 {
+				t.Errorf("expected line 2, got %d", f.Location.Line)
+			}
+		})
+	}
+}
+
+func TestCheckDuplicatePermissions(t *testing.T) {
+	const camera = "android.permission.CAMERA"
+	const storage = "android.permission.WRITE_EXTERNAL_STORAGE"
+	tests := []struct {
+		name  string
+		perms []Permission
+		want  []preflight.Severity
+	}{
+		{
+			name: "no duplicates",
+			perms: []Permission{
+				{Name: camera, Required: true, Line: 3},
+				{Name: storage, Required: true, MaxSdk: 28, Line: 4},
+			},
+		},
+		{
+			name: "identical duplicates",
+			perms: []Permission{
+				{Name: camera, Required: true, Line: 3},
+				{Name: camera, Required: true, Line: 7},
+			},
+			want: []preflight.Severity{preflight.SeverityInfo},
+		},
+		{
+			name: "conflicting maxSdkVersion",
+			perms: []Permission{
+				{Name: storage, Required: true, MaxSdk: 28, Line: 3},
+				{Name: storage, Required: true, Line: 7},
+			},
+			want: []preflight.Severity{preflight.SeverityWarning},
+		},
+		{
+			name: "conflicting required",
+			perms: []Permission{
+				{Name: camera, Required: true, Line: 3},
+				{Name: camera, Required: false, Line: 7},
+			},
+			want: []preflight.Severity{preflight.SeverityWarning},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &AndroidManifest{filePath: "AndroidManifest.xml", Permissions: tt.perms}
+			findings := NewValidator(m).CheckDuplicatePermissions()
+			if len(findings) != len(tt.want) {
+				t.Fatalf("expected %d findings, got %d: %+v", len(tt.want), len(findings), findings)
+			}
+			for i, f := range findings {
+				if f.CheckID != RuleDuplicatePerm || f.Severity != tt.want[i] {
+					t.Errorf("expected %s at %s, got %s at %s", RuleDuplicatePerm, tt.want[i], f.CheckID, f.Severity)
+				}
+				if f.Location.Line != 7 {
+					t.Errorf("expected the second declaration at line 7, got line %d", f.Location.Line)
+				}
+				if !strings.Contains(f.Description, "3") || !strings.Contains(f.Description, "7") {
+					t.Errorf("description should list both lines: %s", f.Description)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckImplicitBroadcasts(t *testing.T) {
+	filter := func(actions ...string) []IntentFilter {
+		return []IntentFilter{{Actions: actions}}
+	}
+	tests := []struct {
+		name      string
+		targetSDK int
+		receivers []Receiver
+		want      int
+	}{
+		{
+			name:      "restricted implicit broadcast",
+			targetSDK: 34,
+			receivers: []Receiver{{Name: ".PowerReceiver", IntentFilters: filter("android.intent.action.ACTION_POWER_CONNECTED"), Line: 5}},
+			want:      1,
+		},
+		{
+			name:      "connectivity change",
+			targetSDK: 34,
+			receivers: []Receiver{{Name: ".NetReceiver", IntentFilters: filter("android.net.conn.CONNECTIVITY_CHANGE"), Line: 5}},
+			want:      1,
+		},
+		{
+			name:      "exempt broadcast",
+			targetSDK: 34,
+			receivers: []Receiver{{Name: ".BootReceiver", IntentFilters: filter("android.intent.action.BOOT_COMPLETED", "android.intent.action.MY_PACKAGE_REPLACED"), Line: 5}},
+		},
+		{
+			name:      "targets below API 26",
+			targetSDK: 25,
+			receivers: []Receiver{{Name: ".PowerReceiver", IntentFilters: filter("android.intent.action.ACTION_POWER_CONNECTED"), Line: 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &AndroidManifest{filePath: "AndroidManifest.xml", TargetSdkVersion: tt.targetSDK, Receivers: tt.receivers}
+			findings := NewValidator(m).CheckImplicitBroadcasts()
+			if len(findings) != tt.want {
+				t.Fatalf("expected %d findings, got %d", tt.want, len(findings))
+			}
+			for _, f := range findings {
+				if f.CheckID != RuleImplicitBroadcast || f.Severity != preflight.SeverityWarning || f.Location.Line != 5 {
+					t.Errorf("expected %s WARNING at line 5, got %s %s at line %d", RuleImplicitBroadcast, f.CheckID, f.Severity, f.Location.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckLargeScreenSupport(t *testing.T) {
+	tests := []struct {
+		name      string
+		appAttr   string
+		actAttr   string
+		wantLines []int
+	}{
+		{"application not resizeable", ` android:resizeableActivity="false"`, "", []int{2}},
+		{"activity not resizeable", "", ` android:resizeableActivity="false"`, []int{3}},
+		{"resizeable", ` android:resizeableActivity="true"`, "", nil},
+		{"attribute absent", "", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xml := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example">
+    <application` + tt.appAttr + `>
+        <activity android:name=".MainActivity"` + tt.actAttr + ` />
+    </application>
+</manifest>`
+			m, err := Parse([]byte(xml))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			var lines []int
+			for _, f := range NewValidator(m).CheckLargeScreenSupport() {
+				if f.CheckID != RuleLargeScreen || f.Severity != preflight.SeverityInfo {
+					t.Errorf("expected %s at INFO, got %s at %s", RuleLargeScreen, f.CheckID, f.Severity)
+				}
+				lines = append(lines, f.Location.Line)
+			}
+			if !slices.Equal(lines, tt.wantLines) {
+				t.Errorf("expected findings at lines %v, got %v", tt.wantLines, lines)
+			}
+		})
+	}
+}
+
+func TestCheckExportedComponents_Remediation(t *testing.T) {
+	launcher := IntentFilter{
+		Actions:    []string{"android.intent.action.MAIN"},
+		Categories: []string{"android.intent.category.LAUNCHER"},
+	}
+	m := &AndroidManifest{
+		filePath:   "AndroidManifest.xml",
+		Activities: []Activity{{Name: ".MainActivity", IntentFilters: []IntentFilter{launcher}, Line: 3}},
+		Services:   []Service{{Name: ".SyncService", IntentFilters: []IntentFilter{{Actions: []string{"com.example.SYNC"}}}, Line: 8}},
+		Receivers:  []Receiver{{Name: ".BootReceiver", IntentFilters: []IntentFilter{{Actions: []string{"android.intent.action.BOOT_COMPLETED"}}}, Line: 12}},
+	}
+	want := map[int]string{3: "true", 8: "false", 12: "true"}
+
+	findings := NewValidator(m).CheckExportedComponents()
+	if len(findings) != len(want) {
+		t.Fatalf("expected %d findings, got %d", len(want), len(findings))
+	}
+	for _, f := range findings {
+		r := f.Remediation
+		if r == nil {
+			t.Errorf("line %d: expected a structured remediation", f.Location.Line)
+			continue
+		}
+		if r.Type != preflight.RemediationChangeAttribute || r.Target != "android:exported" || r.Value != want[f.Location.Line] {
+			t.Errorf("line %d: got remediation %+v, want change-attribute android:exported=%q", f.Location.Line, *r, want[f.Location.Line])
+		}
+	}
+
+	m.Activities[0].Exported = boolPtr(true)
+	m.Services, m.Receivers = nil, nil
+	for _, f := range NewValidator(m).CheckExportedComponents() {
+		if f.Remediation != nil {
+			t.Errorf("advisory %s finding should have no remediation, got %+v", f.CheckID, *f.Remediation)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package manifest
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+	"github.com/kotaroyamazaki/playcheck/pkg/utils"
+)
+
+// gradleVersionCodeRe matches versionCode declarations in Groovy and Kotlin
+// DSL build files: `versionCode 12` and `versionCode = 12`.
+var gradleVersionCodeRe = regexp.MustCompile(`\bversionCode\s*(?:=\s*)?(\d+)`)
+
+// WithPreviousVersionCode sets the versionCode of the last uploaded build.
+// CheckVersionCode then requires the current versionCode to be greater.
+func WithPreviousVersionCode(code int) ValidatorOption {
+	return func(v *Validator) {
+		v.previousVersionCode = code
+	}
+}
+
+// CheckVersionCode warns when versionCode is unset and, when a previous
+// versionCode is configured, reports an error unless the current one is
+// strictly greater. The manifest value is preferred; otherwise the first
+// versionCode in the project's Gradle build files is used.
+func (v *Validator) CheckVersionCode() []preflight.Finding {
+	code, loc := v.resolveVersionCode()
+	if code <= 0 {
+		return []preflight.Finding{{
+			CheckID:     RuleVersionCode,
+			Title:       "Missing versionCode",
+			Description: "versionCode is not set or is 0. Play Store requires a positive versionCode that increases with every upload.",
+			Severity:    preflight.SeverityWarning,
+			Location:    loc,
+			Suggestion:  "Set versionCode in your build.gradle or android:versionCode in AndroidManifest.xml.",
+		}}
+	}
+
+	if v.previousVersionCode > 0 && code <= v.previousVersionCode {
+		return []preflight.Finding{{
+			CheckID:     RuleVersionCode,
+			Title:       fmt.Sprintf("versionCode %d is not greater than previous %d", code, v.previousVersionCode),
+			Description: fmt.Sprintf("versionCode is %d but the previous build used %d. Play Console rejects uploads whose versionCode is not strictly greater than every earlier one.", code, v.previousVersionCode),
+			Severity:    preflight.SeverityError,
+			Location:    loc,
+			Suggestion:  fmt.Sprintf("Increase versionCode to at least %d.", v.previousVersionCode+1),
+		}}
+	}
+	return nil
+}
+
+// resolveVersionCode returns the app's versionCode and where it is declared.
+func (v *Validator) resolveVersionCode() (int, preflight.Location) {
+	m := v.manifest
+	if m.VersionCode > 0 || v.projectDir == "" {
+		return m.VersionCode, preflight.Location{File: m.filePath}
+	}
+
+	gradleFiles, err := utils.FindGradleFiles(v.projectDir, v.walkOpts...)
+	if err != nil {
+		return 0, preflight.Location{File: m.filePath}
+	}
+	for _, gf := range gradleFiles {
+		data, err := utils.ReadFileWithLimit(gf)
+		if err != nil {
+			continue
+		}
+		code, line := parseGradleVersionCode(string(data))
+		if code <= 0 {
+			continue
+		}
+		file := gf
+		if rel, err := filepath.Rel(v.projectDir, gf); err == nil {
+			file = rel
+		}
+		return code, preflight.Location{File: file, Line: line}
+	}
+	return 0, preflight.Location{File: m.filePath}
+}
+
+// parseGradleVersionCode extracts the versionCode value and its 1-based line
+// from Gradle build file content.
+func parseGradleVersionCode(content string) (int, int) {
+	loc := gradleVersionCodeRe.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return 0, 0
+	}
+	code, _ := strconv.Atoi(content[loc[2]:loc[3]])
+	return code, strings.Count(content[:loc[0]], "\n") + 1
+}
@@ -3,6 +3,7 @@ package policies
 import (
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 )
@@ -20,7 +21,7 @@ var (
 // The result is cached after the first call.
 func Load() (*PolicyDatabase, error) {
 	defaultOnce.Do(func() {
-		defaultDB, loadErr = parse(policiesJSON)
+		defaultDB, loadErr = Parse(policiesJSON)
 	})
 	if loadErr != nil {
 		return nil, loadErr
@@ -28,8 +29,11 @@ func Load() (*PolicyDatabase, error) {
 	return defaultDB, nil
 }
 
-// parse decodes raw JSON into a PolicyDatabase and builds indexes.
-func parse(data []byte) (*PolicyDatabase, error) {
+// Parse decodes raw JSON into a PolicyDatabase and builds indexes. It is used
+// for the embedded database and for databases built in tests. Every rule
+// must have an ID, a known severity, and at least one detection pattern of a
+// known type; otherwise the error names each offending rule by its index.
+func Parse(data []byte) (*PolicyDatabase, error) {
 	var db PolicyDatabase
 	if err := json.Unmarshal(data, &db); err != nil {
 		return nil, fmt.Errorf("parsing policy database: %w", err)
@@ -37,6 +41,55 @@ func parse(data []byte) (*PolicyDatabase, error) {
 	if len(db.Rules) == 0 {
 		return nil, fmt.Errorf("policy database contains no rules")
 	}
+	if err := db.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy database: %w", err)
+	}
 	db.buildIndexes()
 	return &db, nil
 }
+
+// validSeverities are the severity strings a rule may declare.
+var validSeverities = map[string]bool{
+	SeverityCritical: true,
+	SeverityError:    true,
+	SeverityWarning:  true,
+	SeverityInfo:     true,
+}
+
+// validPatternTypes are the detection pattern types a rule may declare.
+var validPatternTypes = map[string]bool{
+	"manifest_permission": true,
+	"manifest_element":    true,
+	"manifest_attribute":  true,
+	"code_pattern":        true,
+	"file_check":          true,
+}
+
+// validate checks every rule against the database schema and returns the
+// problems found, joined.
+func (db *PolicyDatabase) validate() error {
+	var errs []error
+	for i, r := range db.Rules {
+		name := fmt.Sprintf("rule %d", i)
+		if r.ID == "" {
+			errs = append(errs, fmt.Errorf("%s: missing required field \"id\"", name))
+		} else {
+			name += " (" + r.ID + ")"
+		}
+		if !validSeverities[r.Severity] {
+			errs = append(errs, fmt.Errorf("%s: invalid severity %q, want one of CRITICAL, ERROR, WARNING, INFO", name, r.Severity))
+		}
+		if len(r.DetectionPatterns) == 0 {
+			errs = append(errs, fmt.Errorf("%s: missing required field \"detection_patterns\", want at least one pattern", name))
+		}
+		for j, p := range r.DetectionPatterns {
+			if !validPatternTypes[p.Type] {
+				errs = append(errs, fmt.Errorf("%s: detection pattern %d has invalid type %q", name, j, p.Type))
+			}
+			if p.Value == "" {
+				errs = append(errs, fmt.Errorf("%s: detection pattern %d is missing \"value\"", name, j))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
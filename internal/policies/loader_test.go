@@ -1,6 +1,7 @@
 package policies
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -26,11 +27,40 @@ func TestGetRule(t *testing.T) {
 	if r == nil {
 		t.Fatal("GetRule(DP001) returned nil")
 	}
-	if r.Name != "SMS Permission Usage" {
-		t.Errorf("expected SMS Permission Usage, got %s", r.Name)
+	if r.Name != "Dangerous Permission" {
+		t.Errorf("expected Dangerous Permission, got %s", r.Name)
 	}
-	if r.Severity != SeverityCritical {
-		t.Errorf("expected CRITICAL severity, got %s", r.Severity)
+	if r.Severity != SeverityWarning {
+		t.Errorf("expected WARNING severity, got %s", r.Severity)
+	}
+}
+
+// TestGetRule_MatchesScanners guards against the database describing a
+// different check than the scanner that reports the same ID.
+func TestGetRule_MatchesScanners(t *testing.T) {
+	db, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	tests := []struct {
+		id, name, link string
+	}{
+		{"MV001", "Component Missing android:exported", "https://developer.android.com/guide/topics/manifest/activity-element#exported"},
+		{"MC001", "Exported Component", "https://developer.android.com/privacy-and-security/risks/android-exported"},
+		{"DP014", "Broad Media Permission Where the Photo Picker Suffices", "https://support.google.com/googleplay/android-developer/answer/14115180"},
+	}
+	for _, tt := range tests {
+		r := db.GetRule(tt.id)
+		if r == nil {
+			t.Errorf("GetRule(%s) returned nil", tt.id)
+			continue
+		}
+		if r.Name != tt.name {
+			t.Errorf("%s: expected name %q, got %q", tt.id, tt.name, r.Name)
+		}
+		if r.PolicyLink != tt.link {
+			t.Errorf("%s: expected policy link %q, got %q", tt.id, tt.link, r.PolicyLink)
+		}
 	}
 }
 
@@ -185,19 +215,61 @@ func TestDetectionPatternTypes(t *testing.T) {
 }
 
 func TestParseInvalidJSON(t *testing.T) {
-	_, err := parse([]byte("not json"))
+	_, err := Parse([]byte("not json"))
 	if err == nil {
 		t.Error("expected error for invalid JSON")
 	}
 }
 
 func TestParseEmptyRules(t *testing.T) {
-	_, err := parse([]byte(`{"version":"1.0.0","rules":[]}`))
+	_, err := Parse([]byte(`{"version":"1.0.0","rules":[]}`))
 	if err == nil {
 		t.Error("expected error for empty rules")
 	}
 }
 
+func TestParseSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  []string
+	}{
+		{
+			name:  "missing patterns",
+			rules: `{"id": "CS001", "severity": "WARNING"}`,
+			want:  []string{`rule 0 (CS001): missing required field "detection_patterns"`},
+		},
+		{
+			name: "invalid severity",
+			rules: `{"id": "CS001", "severity": "WARNING", "detection_patterns": [{"type": "code_pattern", "value": "http://"}]},
+				{"id": "CS002", "severity": "HIGH", "detection_patterns": [{"type": "code_pattern", "value": "Log.d"}]}`,
+			want: []string{`rule 1 (CS002): invalid severity "HIGH"`},
+		},
+		{
+			name:  "missing id and bad pattern",
+			rules: `{"severity": "INFO", "detection_patterns": [{"type": "regex", "value": ""}]}`,
+			want: []string{
+				`rule 0: missing required field "id"`,
+				`rule 0: detection pattern 0 has invalid type "regex"`,
+				`rule 0: detection pattern 0 is missing "value"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(`{"version": "test", "rules": [` + tt.rules + `]}`))
+			if err == nil {
+				t.Fatal("expected a schema error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
 func TestLoadCaching(t *testing.T) {
 	db1, err := Load()
 	if err != nil {
@@ -211,3 +283,19 @@ func TestLoadCaching(t *testing.T) {
 		t.Error("expected same database object from cached Load()")
 	}
 }
+
+func TestRuleIsEnabled(t *testing.T) {
+	db, err := Parse([]byte(`{"version": "test", "rules": [
+		{"id": "A", "severity": "INFO", "detection_patterns": [{"type": "code_pattern", "value": "a"}]},
+		{"id": "B", "severity": "INFO", "enabled": true, "detection_patterns": [{"type": "code_pattern", "value": "b"}]},
+		{"id": "C", "severity": "INFO", "enabled": false, "detection_patterns": [{"type": "code_pattern", "value": "c"}]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]bool{"A": true, "B": true, "C": false} {
+		if got := db.GetRule(id).IsEnabled(); got != want {
+			t.Errorf("rule %s: IsEnabled() = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -18,6 +18,10 @@ const (
 	CategoryContentPolicy        = "content_policy"
 	CategoryMonetization         = "monetization"
 	CategorySecurity             = "security"
+	CategorySpecialPermissions   = "special_permissions"
+	CategoryFamilies             = "families"
+	CategoryStoreListing         = "store_listing"
+	CategoryCodeScanning         = "code_scanning"
 )
 
 // DetectionPattern describes how to detect a policy violation.
@@ -39,6 +43,15 @@ type Rule struct {
 	Remediation       string             `json:"remediation"`
 	PolicyLink        string             `json:"policy_link"`
 	Metadata          map[string]string  `json:"metadata,omitempty"`
+
+	// Enabled turns the rule off in the scanners when set to false. Omitted
+	// means enabled.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// IsEnabled reports whether scanners should report findings for the rule.
+func (r *Rule) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
 }
 
 // PolicyDatabase holds all compliance rules loaded from the embedded JSON.
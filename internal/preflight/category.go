@@ -0,0 +1,25 @@
+package preflight
+
+import "fmt"
+
+// AppCategory selects category-specific Play policies, such as the stricter
+// ads requirements for apps in the Designed for Families program.
+type AppCategory string
+
+const (
+	// CategoryDefault applies the policies that hold for every app.
+	CategoryDefault AppCategory = ""
+	// CategoryFamilies applies the Designed for Families requirements.
+	CategoryFamilies AppCategory = "families"
+)
+
+// ParseAppCategory converts a category name to an AppCategory. An empty
+// string selects CategoryDefault.
+func ParseAppCategory(s string) (AppCategory, error) {
+	switch c := AppCategory(s); c {
+	case CategoryDefault, CategoryFamilies:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unknown app category %q (valid: %s)", s, CategoryFamilies)
+	}
+}
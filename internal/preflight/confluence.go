@@ -0,0 +1,73 @@
+package preflight
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// RenderConfluence produces the report in Confluence storage format, the
+// XHTML markup Confluence pages are stored in, for pasting into a page with
+// the source editor or publishing through the REST API. The summary is a
+// warning macro when a finding is at or above FailOn and an info macro
+// otherwise, followed by a table of the findings.
+func (r *Report) RenderConfluence() string {
+	var b strings.Builder
+
+	macro, title := "info", "playcheck: PASS"
+	if r.HasFindingsAtOrAbove(r.FailOn) {
+		macro, title = "warning", "playcheck: FAIL"
+	}
+	fmt.Fprintf(&b, "<ac:structured-macro ac:name=%q>", macro)
+	fmt.Fprintf(&b, "<ac:parameter ac:name=\"title\">%s</ac:parameter>", html.EscapeString(title))
+	b.WriteString("<ac:rich-text-body>")
+	fmt.Fprintf(&b, "<p>Project: <code>%s</code></p>", html.EscapeString(r.ProjectPath))
+	fmt.Fprintf(&b, "<p>Critical: %d, Warning: %d, Info: %d</p>", r.CriticalCount, r.WarningCount, r.InfoCount)
+	b.WriteString("</ac:rich-text-body></ac:structured-macro>\n")
+
+	if len(r.Findings) == 0 {
+		b.WriteString("<p>No findings.</p>\n")
+		return b.String()
+	}
+
+	b.WriteString("<table><tbody>\n")
+	b.WriteString("<tr><th>Severity</th><th>Rule</th><th>Title</th><th>Location</th><th>Description</th><th>Suggestion</th></tr>\n")
+	for _, f := range r.Findings {
+		title := html.EscapeString(f.Title)
+		if f.PolicyLink != "" {
+			title = fmt.Sprintf("<a href=%q>%s</a>", html.EscapeString(f.PolicyLink), title)
+		}
+		loc := ""
+		if f.Location.File != "" {
+			loc = "<code>" + html.EscapeString(f.Location.String()) + "</code>"
+		}
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			confluenceStatus(f.Severity),
+			html.EscapeString(f.CheckID),
+			title,
+			loc,
+			confluenceText(f.Description),
+			confluenceText(f.Suggestion),
+		)
+	}
+	b.WriteString("</tbody></table>\n")
+	return b.String()
+}
+
+// confluenceStatus renders a severity as a colored status lozenge.
+func confluenceStatus(s Severity) string {
+	colour := "Blue"
+	switch s {
+	case SeverityCritical, SeverityError:
+		colour = "Red"
+	case SeverityWarning:
+		colour = "Yellow"
+	}
+	return fmt.Sprintf("<ac:structured-macro ac:name=\"status\"><ac:parameter ac:name=\"colour\">%s</ac:parameter><ac:parameter ac:name=\"title\">%s</ac:parameter></ac:structured-macro>",
+		colour, html.EscapeString(s.String()))
+}
+
+// confluenceText escapes s and keeps its line breaks.
+func confluenceText(s string) string {
+	return strings.ReplaceAll(html.EscapeString(s), "\n", "<br/>")
+}
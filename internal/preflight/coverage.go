@@ -0,0 +1,87 @@
+package preflight
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// RuleCoverage splits a checker's rules into those the project's files let it
+// evaluate and those that could not fire, e.g. SDK disclosure rules in a
+// project without Gradle build files.
+type RuleCoverage struct {
+	Applicable    []string             `json:"applicable"`
+	NotApplicable []NotApplicableRules `json:"not_applicable,omitempty"`
+}
+
+// NotApplicableRules groups rules that could not fire for the same reason.
+type NotApplicableRules struct {
+	Reason  string   `json:"reason"`
+	RuleIDs []string `json:"rule_ids"`
+}
+
+// NewRuleCoverage returns a coverage with every rule in ids applicable.
+func NewRuleCoverage(ids ...string) *RuleCoverage {
+	c := &RuleCoverage{Applicable: slices.Clone(ids)}
+	slices.Sort(c.Applicable)
+	c.Applicable = slices.Compact(c.Applicable)
+	return c
+}
+
+// Exclude marks the applicable rules among ids as not applicable for reason.
+// IDs that are not applicable already are ignored.
+func (c *RuleCoverage) Exclude(reason string, ids ...string) {
+	var moved []string
+	c.Applicable = slices.DeleteFunc(c.Applicable, func(id string) bool {
+		if slices.Contains(ids, id) {
+			moved = append(moved, id)
+			return true
+		}
+		return false
+	})
+	if len(moved) > 0 {
+		c.NotApplicable = append(c.NotApplicable, NotApplicableRules{Reason: reason, RuleIDs: moved})
+	}
+}
+
+// coverageByScanner returns the rule coverage reported by each scanner,
+// keyed like ScanResult.ByScanner.
+func (r *Report) coverageByScanner() map[string]*RuleCoverage {
+	cov := make(map[string]*RuleCoverage)
+	for id, cr := range r.ScanResult.ByScanner {
+		if cr != nil && cr.Coverage != nil {
+			cov[id] = cr.Coverage
+		}
+	}
+	return cov
+}
+
+// renderCoverage writes the rule coverage section of the terminal report.
+func (r *Report) renderCoverage(b *strings.Builder, dimColor *color.Color) {
+	cov := r.coverageByScanner()
+	if len(cov) == 0 {
+		return
+	}
+	ids := make([]string, 0, len(cov))
+	for id := range cov {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	b.WriteString("\nRule coverage:\n")
+	for _, id := range ids {
+		c := cov[id]
+		skipped := 0
+		for _, g := range c.NotApplicable {
+			skipped += len(g.RuleIDs)
+		}
+		fmt.Fprintf(b, "  %s: %d applicable, %d not applicable\n", id, len(c.Applicable), skipped)
+		for _, g := range c.NotApplicable {
+			dimColor.Fprintf(b, "    %s: %s", g.Reason, strings.Join(g.RuleIDs, ", "))
+			b.WriteString("\n")
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ReportDiff holds the findings that changed between two JSON reports.
+type ReportDiff struct {
+	OldPath   string
+	NewPath   string
+	Added     []JSONFinding
+	Resolved  []JSONFinding
+	Unchanged []JSONFinding
+}
+
+// findingKey identifies a finding across scans. Descriptions are left out
+// because they often embed values (such as SDK versions) that change between
+// runs without the underlying issue changing.
+func findingKey(f JSONFinding) string {
+	return f.CheckID + "\x00" + f.Location + "\x00" + f.Title
+}
+
+// DiffReports compares two JSON reports. Findings are matched by CheckID,
+// location, and title; a key that appears more often in newReport than in
+// oldReport counts as added, and vice versa as resolved.
+func DiffReports(oldReport, newReport JSONReport) *ReportDiff {
+	d := &ReportDiff{
+		OldPath: oldReport.ProjectPath,
+		NewPath: newReport.ProjectPath,
+	}
+
+	remaining := make(map[string]int, len(oldReport.Findings))
+	for _, f := range oldReport.Findings {
+		remaining[findingKey(f)]++
+	}
+	for _, f := range newReport.Findings {
+		key := findingKey(f)
+		if remaining[key] > 0 {
+			remaining[key]--
+			d.Unchanged = append(d.Unchanged, f)
+		} else {
+			d.Added = append(d.Added, f)
+		}
+	}
+	for _, f := range oldReport.Findings {
+		key := findingKey(f)
+		if remaining[key] > 0 {
+			remaining[key]--
+			d.Resolved = append(d.Resolved, f)
+		}
+	}
+
+	return d
+}
+
+// HasNewCritical returns true if any added finding is critical or error level.
+func (d *ReportDiff) HasNewCritical() bool {
+	for _, f := range d.Added {
+		if f.Severity == SeverityCritical.String() || f.Severity == SeverityError.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderTerminal produces colored, human-readable terminal output.
+func (d *ReportDiff) RenderTerminal() string {
+	var b strings.Builder
+
+	headerColor := color.New(color.FgCyan, color.Bold)
+	addedColor := color.New(color.FgRed, color.Bold)
+	resolvedColor := color.New(color.FgGreen, color.Bold)
+	dimColor := color.New(color.Faint)
+
+	b.WriteString("\n")
+	headerColor.Fprint(&b, "=== Play Store Compliance Diff ===")
+	b.WriteString("\n")
+	if d.OldPath == d.NewPath {
+		dimColor.Fprintf(&b, "Project: %s", d.NewPath)
+	} else {
+		dimColor.Fprintf(&b, "Projects: %s -> %s", d.OldPath, d.NewPath)
+	}
+	b.WriteString("\n\n")
+
+	sections := []struct {
+		label    string
+		prefix   string
+		c        *color.Color
+		findings []JSONFinding
+	}{
+		{"NEW", "+", addedColor, d.Added},
+		{"RESOLVED", "-", resolvedColor, d.Resolved},
+		{"UNCHANGED", " ", dimColor, d.Unchanged},
+	}
+	for _, s := range sections {
+		if len(s.findings) == 0 {
+			continue
+		}
+		s.c.Fprintf(&b, "%s (%d)", s.label, len(s.findings))
+		b.WriteString("\n")
+		for _, f := range s.findings {
+			s.c.Fprintf(&b, "%s [%s]", s.prefix, f.Severity)
+			fmt.Fprintf(&b, " %s: %s", f.CheckID, f.Title)
+			b.WriteString("\n")
+			if f.Location != "" {
+				dimColor.Fprintf(&b, "         %s", f.Location)
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(strings.Repeat("-", 50))
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "New: %d | Resolved: %d | Unchanged: %d\n", len(d.Added), len(d.Resolved), len(d.Unchanged))
+
+	b.WriteString("\n")
+	if d.HasNewCritical() {
+		addedColor.Fprint(&b, "RESULT: FAIL")
+		b.WriteString(" - New critical issues were introduced.\n")
+	} else {
+		resolvedColor.Fprint(&b, "RESULT: PASS")
+		b.WriteString(" - No new critical issues.\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderGitHub produces GitHub Actions workflow commands, one per finding,
+// so findings show up as annotations on the pull request diff.
+// See https://docs.github.com/actions/using-workflows/workflow-commands-for-github-actions.
+func (r *Report) RenderGitHub() string {
+	var b strings.Builder
+	for _, f := range r.Findings {
+		var props []string
+		if f.Location.File != "" {
+			props = append(props, "file="+escapeGitHubProperty(f.Location.File))
+			if f.Location.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", f.Location.Line))
+			}
+			if f.Location.Col > 0 {
+				props = append(props, fmt.Sprintf("col=%d", f.Location.Col))
+			}
+		}
+		props = append(props, "title="+escapeGitHubProperty(f.CheckID+": "+f.Title))
+
+		msg := f.Description
+		if f.Suggestion != "" {
+			msg += "\nSuggestion: " + f.Suggestion
+		}
+		fmt.Fprintf(&b, "::%s %s::%s\n", githubCommand(f.Severity), strings.Join(props, ","), escapeGitHubData(msg))
+	}
+	return b.String()
+}
+
+// githubCommand maps a severity to the workflow command name.
+func githubCommand(s Severity) string {
+	switch s {
+	case SeverityCritical, SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// escapeGitHubData escapes a workflow command message.
+func escapeGitHubData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	s = strings.ReplaceAll(s, "\n", "%0A")
+	return s
+}
+
+// escapeGitHubProperty escapes a workflow command property value, which
+// additionally must not contain the ':' and ',' delimiters.
+func escapeGitHubProperty(s string) string {
+	s = escapeGitHubData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	s = strings.ReplaceAll(s, ",", "%2C")
+	return s
+}
@@ -0,0 +1,96 @@
+package preflight
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
+)
+
+// NDJSONSummary is the final line of an NDJSON stream.
+type NDJSONSummary struct {
+	Timestamp   string      `json:"timestamp"`
+	ProjectPath string      `json:"project_path"`
+	Summary     JSONSummary `json:"summary"`
+}
+
+// NDJSONWriter streams findings as newline-delimited JSON, one JSONFinding
+// per line, followed by a single NDJSONSummary line. It is safe for
+// concurrent use.
+type NDJSONWriter struct {
+	mu          sync.Mutex
+	enc         *json.Encoder
+	minSeverity Severity
+	db          *policies.PolicyDatabase
+	label       string
+	seen        map[dedupKey]bool
+	err         error
+}
+
+// NewNDJSONWriter creates a writer that streams findings at or above
+// minSeverity to w.
+func NewNDJSONWriter(w io.Writer, minSeverity Severity) *NDJSONWriter {
+	db, _ := policies.Load()
+	return &NDJSONWriter{
+		enc:         json.NewEncoder(w),
+		minSeverity: minSeverity,
+		db:          db,
+		seen:        make(map[dedupKey]bool),
+	}
+}
+
+// SetLabel prefixes the locations of subsequent findings with label, matching
+// the locations MergeResults produces when scanning several modules.
+func (w *NDJSONWriter) SetLabel(label string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.label = label
+}
+
+// WriteFinding writes one finding line, skipping findings below the minimum
+// severity and duplicates of earlier lines. It has the signature expected by
+// Runner.OnFinding; the first write error is kept and returned by Err.
+func (w *NDJSONWriter) WriteFinding(f Finding) {
+	if f.Severity < w.minSeverity {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return
+	}
+	if w.label != "" {
+		f.Location.File = prefixLocation(w.label, f.Location.File)
+	}
+	// Skip duplicates the same way the aggregated result does.
+	key := dedupKeyOf(f)
+	if w.seen[key] {
+		return
+	}
+	w.seen[key] = true
+	w.err = w.enc.Encode(toJSONFinding(withPolicyLink(f, w.db)))
+}
+
+// WriteSummary writes the closing summary line for the report.
+func (w *NDJSONWriter) WriteSummary(r *Report) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.enc.Encode(NDJSONSummary{
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		ProjectPath: r.ProjectPath,
+		Summary:     r.jsonSummary(),
+	})
+	return w.err
+}
+
+// Err returns the first error encountered while writing.
+func (w *NDJSONWriter) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
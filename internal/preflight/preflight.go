@@ -1,7 +1,11 @@
 package preflight
 
 import (
+	"context"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -22,11 +26,44 @@ type ScanMetadata struct {
 	EndTime     time.Time
 	Duration    time.Duration
 	ScannerIDs  []string
+
+	// FilesScanned and BytesScanned sum the coverage reported by each
+	// scanner. A file read by several scanners is counted once per scanner.
+	FilesScanned int
+	BytesScanned int64
+
+	// SkippedRules is the sorted union of the rules each scanner skipped.
+	SkippedRules []string
+
+	// Unfinished lists the scanners that had not finished when the scan was
+	// canceled or timed out. Their findings are missing from the result.
+	Unfinished []string
+
+	// Acknowledged sums the findings each scanner suppressed because the
+	// user acknowledged them in the configuration.
+	Acknowledged int
 }
 
 // Runner orchestrates compliance checkers and aggregates results.
 type Runner struct {
-	checkers []Checker
+	checkers  []Checker
+	onFinding func(Finding)
+	overrides SeverityOverrides
+}
+
+// OnFinding registers fn to receive each checker's findings as soon as that
+// checker completes, in addition to the aggregated ScanResult. Calls are
+// serialized, so fn need not be safe for concurrent use. Findings are
+// streamed before deduplication and sorting.
+func (r *Runner) OnFinding(fn func(Finding)) {
+	r.onFinding = fn
+}
+
+// OverrideSeverities sets the severity overrides applied to each checker's
+// findings before they are streamed or aggregated. A checker whose findings
+// are raised to SeverityError or above no longer counts as passed.
+func (r *Runner) OverrideSeverities(o SeverityOverrides) {
+	r.overrides = o
 }
 
 // RegisterScanner adds a checker to the runner.
@@ -39,11 +76,22 @@ func (r *Runner) Checkers() []Checker {
 	return r.checkers
 }
 
-// Run executes all registered checkers against the project directory.
-// The onComplete callback is invoked after each checker finishes, which
-// is used by the CLI to advance the progress bar.
-// Checkers run concurrently for better performance.
-func (r *Runner) Run(projectDir string, onComplete func()) *ScanResult {
+// Run executes all registered checkers concurrently against the project
+// directory. The onComplete callback is invoked with each checker's result as
+// it finishes, which the CLI uses to advance the progress bar and show a
+// running finding count; since checkers finish concurrently, onComplete may
+// be called from several goroutines at once.
+func (r *Runner) Run(projectDir string, onComplete func(*CheckResult)) *ScanResult {
+	result, _ := r.RunContext(context.Background(), projectDir, onComplete)
+	return result
+}
+
+// RunContext is like Run but stops waiting for checkers once ctx is done. It
+// then returns the results of the checkers that finished, with the others
+// listed in ScanMetadata.Unfinished, together with ctx's error. Checkers
+// implementing ContextChecker are canceled through ctx; others keep running
+// in the background and their results are discarded.
+func (r *Runner) RunContext(ctx context.Context, projectDir string, onComplete func(*CheckResult)) (*ScanResult, error) {
 	startTime := time.Now()
 
 	result := &ScanResult{
@@ -58,15 +106,24 @@ func (r *Runner) Run(projectDir string, onComplete func()) *ScanResult {
 		result.ScanMeta.ScannerIDs = append(result.ScanMeta.ScannerIDs, c.ID())
 	}
 
+	// abandoned is set once RunContext stops waiting, after which late
+	// checkers must not touch result or call back.
 	var mu sync.Mutex
 	var wg sync.WaitGroup
+	abandoned := false
 
 	for _, c := range r.checkers {
 		wg.Add(1)
 		go func(checker Checker) {
 			defer wg.Done()
 
-			cr, err := checker.Run(projectDir)
+			var cr *CheckResult
+			var err error
+			if cc, ok := checker.(ContextChecker); ok {
+				cr, err = cc.RunContext(ctx, projectDir)
+			} else {
+				cr, err = checker.Run(projectDir)
+			}
 			if cr == nil {
 				cr = &CheckResult{
 					CheckID: checker.ID(),
@@ -75,63 +132,260 @@ func (r *Runner) Run(projectDir string, onComplete func()) *ScanResult {
 			if err != nil {
 				cr.Err = err
 			}
+			if rl, ok := checker.(RuleLister); ok {
+				setCategories(cr.Findings, rl.Rules())
+			}
+			if r.overrides.Apply(cr.Findings) {
+				cr.Passed = false
+			}
 
 			mu.Lock()
-			result.ByScanner[checker.ID()] = cr
-			result.Findings = append(result.Findings, cr.Findings...)
-			if cr.Passed {
-				result.TotalPassed++
-			} else {
-				result.TotalFailed++
+			if abandoned {
+				mu.Unlock()
+				return
+			}
+			result.add(checker.ID(), cr)
+			if r.onFinding != nil {
+				for _, f := range cr.Findings {
+					r.onFinding(f)
+				}
 			}
 			mu.Unlock()
 
 			if onComplete != nil {
-				onComplete()
+				onComplete(cr)
 			}
 		}(c)
 	}
 
-	wg.Wait()
-
-	// Deduplicate findings by CheckID + Location.
-	result.Findings = deduplicateFindings(result.Findings)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
 
-	// Sort findings: critical first, then by severity descending.
-	sort.Slice(result.Findings, func(i, j int) bool {
-		if result.Findings[i].Severity != result.Findings[j].Severity {
-			return result.Findings[i].Severity > result.Findings[j].Severity
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		mu.Lock()
+		abandoned = true
+		for _, id := range result.ScanMeta.ScannerIDs {
+			if _, ok := result.ByScanner[id]; !ok {
+				result.ScanMeta.Unfinished = append(result.ScanMeta.Unfinished, id)
+			}
 		}
-		if result.Findings[i].CheckID != result.Findings[j].CheckID {
-			return result.Findings[i].CheckID < result.Findings[j].CheckID
+		mu.Unlock()
+		// Every checker may have finished just as ctx was done.
+		if len(result.ScanMeta.Unfinished) > 0 {
+			err = ctx.Err()
 		}
-		return result.Findings[i].Location.String() < result.Findings[j].Location.String()
-	})
+	}
+
+	// Deduplicate findings with the same CheckID, Location, title, and
+	// description.
+	result.Findings = deduplicateFindings(result.Findings)
+
+	sortFindings(result.Findings)
 
 	result.ScanMeta.EndTime = time.Now()
 	result.ScanMeta.Duration = result.ScanMeta.EndTime.Sub(result.ScanMeta.StartTime)
 
+	return result, err
+}
+
+// NewScanResult aggregates checker results the way Runner.Run does,
+// deduplicating and sorting their findings. The scan times are left for the
+// caller to fill in.
+func NewScanResult(projectDir string, results []*CheckResult) *ScanResult {
+	result := &ScanResult{
+		ByScanner: make(map[string]*CheckResult, len(results)),
+		ScanMeta:  ScanMetadata{ProjectPath: projectDir},
+	}
+	for _, cr := range results {
+		result.ScanMeta.ScannerIDs = append(result.ScanMeta.ScannerIDs, cr.CheckID)
+		result.add(cr.CheckID, cr)
+	}
+	result.Findings = deduplicateFindings(result.Findings)
+	sortFindings(result.Findings)
 	return result
 }
 
-// deduplicateFindings removes duplicate findings based on CheckID and Location.
+// setCategories fills in the Category of findings that have none from the
+// rule with the same ID.
+func setCategories(findings []Finding, rules []RuleInfo) {
+	categories := make(map[string]string, len(rules))
+	for _, rule := range rules {
+		categories[rule.ID] = rule.Category
+	}
+	for i := range findings {
+		if findings[i].Category == "" {
+			findings[i].Category = categories[findings[i].CheckID]
+		}
+	}
+}
+
+// add records the result of the checker with the given ID.
+func (r *ScanResult) add(id string, cr *CheckResult) {
+	r.ByScanner[id] = cr
+	r.Findings = append(r.Findings, cr.Findings...)
+	r.ScanMeta.FilesScanned += cr.FilesScanned
+	r.ScanMeta.BytesScanned += cr.BytesScanned
+	r.ScanMeta.SkippedRules = mergeRuleIDs(r.ScanMeta.SkippedRules, cr.SkippedRules)
+	r.ScanMeta.Acknowledged += cr.Acknowledged
+	if cr.Passed {
+		r.TotalPassed++
+	} else {
+		r.TotalFailed++
+	}
+}
+
+// sortFindings orders findings critical first, then by CheckID and location.
+// Findings of one rule at the same location are ordered by their text, so
+// the order does not depend on which checker finished first.
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := &findings[i], &findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.CheckID != b.CheckID {
+			return a.CheckID < b.CheckID
+		}
+		if la, lb := a.Location.String(), b.Location.String(); la != lb {
+			return la < lb
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		if a.Description != b.Description {
+			return a.Description < b.Description
+		}
+		return a.Suggestion < b.Suggestion
+	})
+}
+
+// CountBySeverity returns the number of findings at each severity level.
+// Levels without findings are omitted.
+func (r *ScanResult) CountBySeverity() map[Severity]int {
+	counts := make(map[Severity]int)
+	for _, f := range r.Findings {
+		counts[f.Severity]++
+	}
+	return counts
+}
+
+// MergeResults combines the results of scanning several project directories
+// into a single ScanResult. Relative finding locations are prefixed with the
+// corresponding label (typically the module path) so files with the same
+// relative path in different modules stay distinguishable. labels and results
+// must have the same length.
+func MergeResults(labels []string, results []*ScanResult) *ScanResult {
+	merged := &ScanResult{
+		ByScanner: make(map[string]*CheckResult),
+	}
+	var paths []string
+	seenScanner := make(map[string]bool)
+
+	for i, r := range results {
+		label := labels[i]
+		paths = append(paths, r.ScanMeta.ProjectPath)
+
+		if merged.ScanMeta.StartTime.IsZero() || r.ScanMeta.StartTime.Before(merged.ScanMeta.StartTime) {
+			merged.ScanMeta.StartTime = r.ScanMeta.StartTime
+		}
+		if r.ScanMeta.EndTime.After(merged.ScanMeta.EndTime) {
+			merged.ScanMeta.EndTime = r.ScanMeta.EndTime
+		}
+		for _, id := range r.ScanMeta.ScannerIDs {
+			if !seenScanner[id] {
+				seenScanner[id] = true
+				merged.ScanMeta.ScannerIDs = append(merged.ScanMeta.ScannerIDs, id)
+			}
+		}
+		for id, cr := range r.ByScanner {
+			merged.ByScanner[label+":"+id] = cr
+		}
+		for _, id := range r.ScanMeta.Unfinished {
+			merged.ScanMeta.Unfinished = append(merged.ScanMeta.Unfinished, label+":"+id)
+		}
+
+		for _, f := range r.Findings {
+			f.Location.File = prefixLocation(label, f.Location.File)
+			merged.Findings = append(merged.Findings, f)
+		}
+		merged.TotalPassed += r.TotalPassed
+		merged.TotalFailed += r.TotalFailed
+		merged.ScanMeta.FilesScanned += r.ScanMeta.FilesScanned
+		merged.ScanMeta.BytesScanned += r.ScanMeta.BytesScanned
+		merged.ScanMeta.SkippedRules = mergeRuleIDs(merged.ScanMeta.SkippedRules, r.ScanMeta.SkippedRules)
+		merged.ScanMeta.Acknowledged += r.ScanMeta.Acknowledged
+	}
+
+	merged.ScanMeta.ProjectPath = strings.Join(paths, ", ")
+	merged.ScanMeta.Duration = merged.ScanMeta.EndTime.Sub(merged.ScanMeta.StartTime)
+	sortFindings(merged.Findings)
+	return merged
+}
+
+// prefixLocation joins a module label onto a relative finding path. Absolute
+// paths are already unambiguous and are returned unchanged.
+func prefixLocation(label, file string) string {
+	if file == "" {
+		return label
+	}
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(label, file)
+}
+
+// deduplicateFindings merges findings with the same CheckID, Location, title,
+// and description, keeping the first and recording the number of merged
+// findings in its Count. Findings of one rule at the same location that
+// differ in title or description are all kept.
 func deduplicateFindings(findings []Finding) []Finding {
 	if len(findings) == 0 {
 		return findings
 	}
-	type key struct {
-		checkID string
-		loc     string
-	}
-	seen := make(map[key]bool, len(findings))
+	seen := make(map[dedupKey]int, len(findings))
 	out := make([]Finding, 0, len(findings))
 	for _, f := range findings {
-		k := key{checkID: f.CheckID, loc: f.Location.String()}
-		if seen[k] {
+		k := dedupKeyOf(f)
+		if i, ok := seen[k]; ok {
+			out[i].Count = max(out[i].Count, 1) + max(f.Count, 1)
 			continue
 		}
-		seen[k] = true
+		seen[k] = len(out)
 		out = append(out, f)
 	}
 	return out
 }
+
+// dedupKey identifies duplicate findings: the same rule at the same
+// location with the same title and description.
+type dedupKey struct {
+	checkID     string
+	loc         string
+	title       string
+	description string
+}
+
+func dedupKeyOf(f Finding) dedupKey {
+	return dedupKey{
+		checkID:     f.CheckID,
+		loc:         f.Location.String(),
+		title:       f.Title,
+		description: f.Description,
+	}
+}
+
+// mergeRuleIDs returns the sorted union of two rule ID lists.
+func mergeRuleIDs(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	merged := slices.Concat(a, b)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
@@ -1,10 +1,19 @@
 package preflight
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
 )
 
 // mockScanner implements Checker for testing the Runner.
@@ -12,6 +21,9 @@ type mockScanner struct {
 	id       string
 	findings []Finding
 	err      error
+	files    int
+	bytes    int64
+	skipped  []string
 }
 
 func (m *mockScanner) ID() string          { return m.id }
@@ -23,12 +35,46 @@ func (m *mockScanner) Run(projectDir string) (*CheckResult, error) {
 		return nil, m.err
 	}
 	return &CheckResult{
-		CheckID:  m.id,
-		Passed:   len(m.findings) == 0,
-		Findings: m.findings,
+		CheckID:      m.id,
+		Passed:       len(m.findings) == 0,
+		Findings:     m.findings,
+		FilesScanned: m.files,
+		BytesScanned: m.bytes,
+		SkippedRules: m.skipped,
 	}, nil
 }
 
+// slowScanner blocks in Run until release is closed, ignoring cancellation.
+type slowScanner struct {
+	mockScanner
+	release chan struct{}
+}
+
+func (s *slowScanner) Run(projectDir string) (*CheckResult, error) {
+	<-s.release
+	return s.mockScanner.Run(projectDir)
+}
+
+// listingScanner implements RuleLister.
+type listingScanner struct {
+	mockScanner
+	rules []RuleInfo
+}
+
+func (s *listingScanner) Rules() []RuleInfo { return s.rules }
+
+// cancelableScanner implements ContextChecker and returns once ctx is done.
+type cancelableScanner struct {
+	mockScanner
+	canceled chan struct{}
+}
+
+func (s *cancelableScanner) RunContext(ctx context.Context, projectDir string) (*CheckResult, error) {
+	<-ctx.Done()
+	close(s.canceled)
+	return nil, ctx.Err()
+}
+
 func TestRunner_NoScanners(t *testing.T) {
 	r := &Runner{}
 	result := r.Run("/tmp", nil)
@@ -100,7 +146,7 @@ func TestRunner_ProgressCallback(t *testing.T) {
 	r.RegisterScanner(&mockScanner{id: "s3"})
 
 	callCount := &atomic.Int32{}
-	r.Run("/tmp", func() {
+	r.Run("/tmp", func(*CheckResult) {
 		callCount.Add(1)
 	})
 	if callCount.Load() != 3 {
@@ -108,6 +154,34 @@ func TestRunner_ProgressCallback(t *testing.T) {
 	}
 }
 
+func TestRunner_ProgressCallbackReceivesResults(t *testing.T) {
+	r := &Runner{}
+	r.RegisterScanner(&mockScanner{id: "s1", findings: []Finding{{CheckID: "A1"}, {CheckID: "A2"}}})
+	r.RegisterScanner(&mockScanner{id: "s2", findings: []Finding{{CheckID: "B1"}}})
+	r.RegisterScanner(&mockScanner{id: "s3", err: fmt.Errorf("scanner failed")})
+
+	var mu sync.Mutex
+	got := make(map[string]*CheckResult)
+	r.Run("/tmp", func(cr *CheckResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		got[cr.CheckID] = cr
+	})
+
+	if len(got) != 3 {
+		t.Fatalf("expected a result for each of 3 scanners, got %d", len(got))
+	}
+	if n := len(got["s1"].Findings); n != 2 {
+		t.Errorf("expected 2 findings for s1, got %d", n)
+	}
+	if n := len(got["s2"].Findings); n != 1 {
+		t.Errorf("expected 1 finding for s2, got %d", n)
+	}
+	if got["s3"].Err == nil {
+		t.Error("expected the error of s3 in its result")
+	}
+}
+
 func TestRunner_ScannerError(t *testing.T) {
 	r := &Runner{}
 	r.RegisterScanner(&mockScanner{
@@ -125,6 +199,63 @@ func TestRunner_ScannerError(t *testing.T) {
 	}
 }
 
+func TestRunner_RunContext_Timeout(t *testing.T) {
+	slow := &slowScanner{mockScanner: mockScanner{id: "slow"}, release: make(chan struct{})}
+	defer close(slow.release)
+	cancelable := &cancelableScanner{mockScanner: mockScanner{id: "cancelable"}, canceled: make(chan struct{})}
+
+	r := &Runner{}
+	r.RegisterScanner(&mockScanner{
+		id:       "fast",
+		findings: []Finding{{CheckID: "T001", Title: "Fast finding", Severity: SeverityWarning}},
+	})
+	r.RegisterScanner(slow)
+	r.RegisterScanner(cancelable)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result, err := r.RunContext(ctx, "/tmp", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RunContext waited %s for the slow scanner", elapsed)
+	}
+
+	if len(result.Findings) != 1 || result.Findings[0].CheckID != "T001" {
+		t.Errorf("expected the fast scanner's finding, got %+v", result.Findings)
+	}
+	if _, ok := result.ByScanner["fast"]; !ok {
+		t.Error("expected the fast scanner's result")
+	}
+	// The context-aware scanner returns as soon as it is canceled, so it may
+	// or may not be reported in time; the slow one never is.
+	unfinished := result.ScanMeta.Unfinished
+	if !slices.Contains(unfinished, "slow") || slices.Contains(unfinished, "fast") {
+		t.Errorf("expected slow but not fast among unfinished scanners, got %v", unfinished)
+	}
+	select {
+	case <-cancelable.canceled:
+	case <-time.After(5 * time.Second):
+		t.Error("expected the context-aware scanner to be canceled")
+	}
+}
+
+func TestRunner_RunContext_Completes(t *testing.T) {
+	r := &Runner{}
+	r.RegisterScanner(&mockScanner{id: "fast"})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	result, err := r.RunContext(ctx, "/tmp", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.ScanMeta.Unfinished) != 0 {
+		t.Errorf("expected no unfinished scanners, got %v", result.ScanMeta.Unfinished)
+	}
+}
+
 func TestRunner_Deduplication(t *testing.T) {
 	r := &Runner{}
 	r.RegisterScanner(&mockScanner{
@@ -152,6 +283,43 @@ func TestRunner_Deduplication(t *testing.T) {
 	}
 }
 
+func TestRunner_SetsCategoryFromRules(t *testing.T) {
+	r := &Runner{}
+	// DP001 is in the policy database under dangerous_permissions; the
+	// category the scanner lists for its rule wins.
+	r.RegisterScanner(&listingScanner{
+		mockScanner: mockScanner{
+			id: "lister",
+			findings: []Finding{
+				{CheckID: "DP001", Title: "Listed", Severity: SeverityWarning},
+				{CheckID: "OTHER", Title: "Not listed", Severity: SeverityWarning},
+			},
+		},
+		rules: []RuleInfo{{ID: "DP001", Category: "security"}},
+	})
+	var streamed []Finding
+	r.OnFinding(func(f Finding) { streamed = append(streamed, f) })
+
+	result := r.Run("/tmp", nil)
+	categories := make(map[string]string)
+	for _, f := range result.Findings {
+		categories[f.CheckID] = f.Category
+	}
+	if categories["DP001"] != "security" || categories["OTHER"] != "" {
+		t.Errorf("unexpected categories %v", categories)
+	}
+	for _, f := range streamed {
+		if f.CheckID == "DP001" && f.Category != "security" {
+			t.Errorf("expected streamed DP001 to carry its category, got %q", f.Category)
+		}
+	}
+
+	jr := NewReport(result, SeverityInfo).ToJSON()
+	if jr.ByCategory["security"].Count != 1 || jr.ByCategory[CategoryUncategorized].Count != 1 {
+		t.Errorf("expected DP001 under security and OTHER uncategorized, got %v", jr.ByCategory)
+	}
+}
+
 func TestRunner_Metadata(t *testing.T) {
 	r := &Runner{}
 	r.RegisterScanner(&mockScanner{id: "m1"})
@@ -169,6 +337,47 @@ func TestRunner_Metadata(t *testing.T) {
 	}
 }
 
+func TestRunner_Coverage(t *testing.T) {
+	r := &Runner{}
+	r.RegisterScanner(&mockScanner{id: "m1", files: 3, bytes: 1500})
+	r.RegisterScanner(&mockScanner{id: "m2", files: 2, bytes: 600})
+	r.RegisterScanner(&mockScanner{id: "m3"})
+
+	result := r.Run("/some/path", nil)
+	if result.ScanMeta.FilesScanned != 5 || result.ScanMeta.BytesScanned != 2100 {
+		t.Errorf("expected 5 files / 2100 bytes, got %d / %d", result.ScanMeta.FilesScanned, result.ScanMeta.BytesScanned)
+	}
+
+	report := NewReport(result, SeverityInfo)
+	summary := report.ToJSON().Summary
+	if summary.FilesScanned != 5 || summary.BytesScanned != 2100 {
+		t.Errorf("expected JSON summary 5 files / 2100 bytes, got %d / %d", summary.FilesScanned, summary.BytesScanned)
+	}
+	if out := report.RenderTerminal(); !strings.Contains(out, "Scanned: 5 files (2.1 KiB)") {
+		t.Errorf("expected coverage in terminal footer, got:\n%s", out)
+	}
+}
+
+func TestRunner_SkippedRules(t *testing.T) {
+	r := &Runner{}
+	r.RegisterScanner(&mockScanner{id: "m1", skipped: []string{"CS010", "CS002"}})
+	r.RegisterScanner(&mockScanner{id: "m2", skipped: []string{"CS002"}})
+	r.RegisterScanner(&mockScanner{id: "m3"})
+
+	result := r.Run("/some/path", nil)
+	if got := strings.Join(result.ScanMeta.SkippedRules, ","); got != "CS002,CS010" {
+		t.Errorf("expected skipped rules CS002,CS010, got %s", got)
+	}
+
+	report := NewReport(result, SeverityInfo)
+	if got := report.ToJSON().Summary.SkippedRules; len(got) != 2 {
+		t.Errorf("expected 2 skipped rules in JSON summary, got %v", got)
+	}
+	if out := report.RenderTerminal(); !strings.Contains(out, "Skipped rules (matching time budget exceeded): CS002, CS010") {
+		t.Errorf("expected skipped rules in terminal footer, got:\n%s", out)
+	}
+}
+
 func TestRunner_Checkers(t *testing.T) {
 	r := &Runner{}
 	r.RegisterScanner(&mockScanner{id: "c1"})
@@ -206,6 +415,45 @@ func TestDeduplicateFindings_NoDuplicates(t *testing.T) {
 	}
 }
 
+func TestDeduplicateFindings_Identical(t *testing.T) {
+	f := Finding{CheckID: "A", Title: "T", Description: "D", Location: Location{File: "a.java", Line: 1}}
+	result := deduplicateFindings([]Finding{f, f, f})
+	if len(result) != 1 {
+		t.Fatalf("expected 1, got %d", len(result))
+	}
+	if result[0].Count != 3 {
+		t.Errorf("expected Count 3, got %d", result[0].Count)
+	}
+
+	report := NewReport(&ScanResult{Findings: result}, SeverityInfo)
+	if got := report.ToJSON().Findings[0].Count; got != 3 {
+		t.Errorf("expected JSON count 3, got %d", got)
+	}
+	if out := report.RenderTerminal(); !strings.Contains(out, "(reported 3 times)") {
+		t.Errorf("expected terminal output to show the count, got:\n%s", out)
+	}
+}
+
+func TestDeduplicateFindings_DifferentTitles(t *testing.T) {
+	loc := Location{File: "a.java", Line: 1}
+	findings := []Finding{
+		{CheckID: "A", Title: "First", Description: "D", Location: loc},
+		{CheckID: "A", Title: "Second", Description: "D", Location: loc},
+	}
+	result := deduplicateFindings(findings)
+	if len(result) != 2 {
+		t.Fatalf("expected 2, got %d", len(result))
+	}
+	for _, f := range result {
+		if f.Count != 0 {
+			t.Errorf("%s: expected Count 0 for an unmerged finding, got %d", f.Title, f.Count)
+		}
+	}
+	if got := NewReport(&ScanResult{Findings: result}, SeverityInfo).ToJSON().Findings[0].Count; got != 0 {
+		t.Errorf("expected JSON count omitted, got %d", got)
+	}
+}
+
 func TestReport_NewReport(t *testing.T) {
 	sr := &ScanResult{
 		Findings: []Finding{
@@ -273,6 +521,37 @@ func TestReport_HasCritical_OnlyWarnings(t *testing.T) {
 	}
 }
 
+func TestReport_StatusLine(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{
+			{CheckID: "C1", Severity: SeverityCritical},
+			{CheckID: "C2", Severity: SeverityCritical},
+			{CheckID: "W1", Severity: SeverityWarning},
+			{CheckID: "W2", Severity: SeverityWarning},
+			{CheckID: "I1", Severity: SeverityInfo},
+		},
+		ScanMeta: ScanMetadata{ProjectPath: "app/"},
+	}
+	if got, want := NewReport(sr, SeverityInfo).StatusLine(), "playcheck: FAIL (2 critical, 2 warning, 1 info) in app/"; got != want {
+		t.Errorf("StatusLine() = %q, want %q", got, want)
+	}
+
+	sr.Findings = sr.Findings[2:]
+	if got, want := NewReport(sr, SeverityInfo).StatusLine(), "playcheck: PASS (2 warning, 1 info) in app/"; got != want {
+		t.Errorf("StatusLine() = %q, want %q", got, want)
+	}
+	strict := NewReport(sr, SeverityInfo)
+	strict.FailOn = SeverityWarning
+	if got, want := strict.StatusLine(), "playcheck: FAIL (2 warning, 1 info) in app/"; got != want {
+		t.Errorf("StatusLine() with FailOn warning = %q, want %q", got, want)
+	}
+
+	sr.Findings = nil
+	if got, want := NewReport(sr, SeverityInfo).StatusLine(), "playcheck: PASS (no findings) in app/"; got != want {
+		t.Errorf("StatusLine() = %q, want %q", got, want)
+	}
+}
+
 func TestReport_ToJSON(t *testing.T) {
 	sr := &ScanResult{
 		Findings: []Finding{
@@ -296,6 +575,23 @@ func TestReport_ToJSON(t *testing.T) {
 	}
 }
 
+func TestReport_Context(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{
+			{CheckID: "C1", Severity: SeverityError, Title: "Test", Location: Location{File: "a.kt", Line: 2}, Context: []string{"1: before()", "3: after()"}},
+		},
+		ScanMeta: ScanMetadata{ProjectPath: "/test"},
+	}
+	report := NewReport(sr, SeverityInfo)
+
+	if got := report.ToJSON().Findings[0].Context; len(got) != 2 || got[1] != "3: after()" {
+		t.Errorf("unexpected JSON context %q", got)
+	}
+	if output := report.RenderTerminal(); !strings.Contains(output, "           1: before()\n") {
+		t.Errorf("expected indented context line in terminal output, got:\n%s", output)
+	}
+}
+
 func TestReport_RenderTerminal(t *testing.T) {
 	sr := &ScanResult{
 		Findings: []Finding{
@@ -334,3 +630,613 @@ func TestReport_RenderTerminal_AllPassed(t *testing.T) {
 	}
 }
 
+func TestReport_PolicyLinkAttached(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{
+			{CheckID: "DP001", Severity: SeverityWarning, Title: "Dangerous permission"},
+			{CheckID: "NOPE999", Severity: SeverityWarning, Title: "Unknown rule"},
+			{CheckID: "SMS", Severity: SeverityWarning, Title: "SMS permission", PolicyLink: "https://example.com/sms"},
+		},
+		ScanMeta: ScanMetadata{ProjectPath: "/test"},
+	}
+	report := NewReport(sr, SeverityInfo)
+
+	links := make(map[string]string)
+	for _, f := range report.Findings {
+		links[f.CheckID] = f.PolicyLink
+	}
+	if links["DP001"] != "https://support.google.com/googleplay/android-developer/answer/9888170" {
+		t.Errorf("expected DP001 policy link, got %q", links["DP001"])
+	}
+	if links["NOPE999"] != "" {
+		t.Errorf("expected empty policy link for unknown rule, got %q", links["NOPE999"])
+	}
+	if links["SMS"] != "https://example.com/sms" {
+		t.Errorf("expected the scanner's policy link to be kept, got %q", links["SMS"])
+	}
+
+	jsonReport := report.ToJSON()
+	if jsonReport.Findings[0].PolicyLink == "" {
+		t.Error("expected policy link in JSON finding")
+	}
+	if !strings.Contains(report.RenderTerminal(), "Policy: https://support.google.com") {
+		t.Error("expected policy link in terminal output")
+	}
+}
+
+func TestReport_RenderTerminal_RuleLine(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{
+			{CheckID: "DP001", Severity: SeverityWarning, Title: "Dangerous permission"},
+			{CheckID: "CUSTOM1", Severity: SeverityWarning, Title: "Not in the policy database"},
+		},
+		ScanMeta: ScanMetadata{ProjectPath: "/test"},
+	}
+	out := NewReport(sr, SeverityInfo).RenderTerminal()
+
+	if !strings.Contains(out, "Rule: DP001 | Policy: https://support.google.com/googleplay/android-developer/answer/9888170\n") {
+		t.Errorf("expected rule and policy line for DP001, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Rule: CUSTOM1\n") {
+		t.Errorf("expected rule line for CUSTOM1, got:\n%s", out)
+	}
+}
+
+func TestReport_RenderTerminal_FailOn(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{{CheckID: "W1", Severity: SeverityWarning, Title: "Warning Issue"}},
+		ScanMeta: ScanMetadata{ProjectPath: "/test"},
+	}
+	tests := []struct {
+		failOn Severity
+		want   string
+	}{
+		{SeverityWarning, "RESULT: FAIL - Findings at WARNING or above must be resolved before submission.\n"},
+		{SeverityError, "RESULT: PASS - No findings at ERROR or above.\n"},
+	}
+	for _, tt := range tests {
+		report := NewReport(sr, SeverityInfo)
+		report.FailOn = tt.failOn
+		if out := report.RenderTerminal(); !strings.Contains(out, tt.want) {
+			t.Errorf("FailOn %s: expected %q, got:\n%s", tt.failOn, tt.want, out)
+		}
+	}
+}
+
+func TestReport_ComplianceScore_Clean(t *testing.T) {
+	sr := &ScanResult{
+		TotalPassed: 3,
+		ScanMeta:    ScanMetadata{ProjectPath: "/test"},
+	}
+	report := NewReport(sr, SeverityInfo)
+	if score := report.ComplianceScore(); score != 100 {
+		t.Errorf("expected score 100 for clean scan, got %d", score)
+	}
+	if report.ToJSON().Summary.Score != 100 {
+		t.Errorf("expected JSON score 100, got %d", report.ToJSON().Summary.Score)
+	}
+}
+
+func TestReport_ComplianceScore_HeavilyViolating(t *testing.T) {
+	var findings []Finding
+	for i := 0; i < 5; i++ {
+		findings = append(findings,
+			Finding{CheckID: fmt.Sprintf("C%d", i), Severity: SeverityCritical},
+			Finding{CheckID: fmt.Sprintf("W%d", i), Severity: SeverityWarning},
+		)
+	}
+	sr := &ScanResult{
+		Findings:    findings,
+		TotalFailed: 3,
+		ScanMeta:    ScanMetadata{ProjectPath: "/test"},
+	}
+	report := NewReport(sr, SeverityCritical)
+
+	// penalty = 5*25 + 5*5 = 150; 100 - 150/3 = 50
+	if score := report.ComplianceScore(); score != 50 {
+		t.Errorf("expected score 50, got %d", score)
+	}
+
+	report.ScoreWeights = ScoreWeights{Critical: 100, Warning: 5, Error: 15, Info: 1}
+	if score := report.ComplianceScore(); score != 0 {
+		t.Errorf("expected score clamped to 0 with heavy weights, got %d", score)
+	}
+}
+
+func TestMergeResults(t *testing.T) {
+	a := &ScanResult{
+		Findings: []Finding{
+			{CheckID: "W1", Severity: SeverityWarning, Location: Location{File: "src/Main.java", Line: 3}},
+		},
+		TotalPassed: 1,
+		TotalFailed: 1,
+		ByScanner:   map[string]*CheckResult{"code-scan": {CheckID: "code-scan"}},
+		ScanMeta:    ScanMetadata{ProjectPath: "/repo/app", ScannerIDs: []string{"code-scan"}},
+	}
+	b := &ScanResult{
+		Findings: []Finding{
+			{CheckID: "W1", Severity: SeverityWarning, Location: Location{File: "src/Main.java", Line: 3}},
+			{CheckID: "C1", Severity: SeverityCritical, Location: Location{File: "/abs/AndroidManifest.xml"}},
+		},
+		TotalFailed: 2,
+		ByScanner:   map[string]*CheckResult{"code-scan": {CheckID: "code-scan"}},
+		ScanMeta:    ScanMetadata{ProjectPath: "/repo/lib", ScannerIDs: []string{"code-scan"}},
+	}
+
+	merged := MergeResults([]string{"app", "lib"}, []*ScanResult{a, b})
+
+	if len(merged.Findings) != 3 {
+		t.Fatalf("expected 3 findings, got %d", len(merged.Findings))
+	}
+	if merged.Findings[0].CheckID != "C1" {
+		t.Errorf("expected critical finding first, got %s", merged.Findings[0].CheckID)
+	}
+	if merged.Findings[0].Location.File != "/abs/AndroidManifest.xml" {
+		t.Errorf("expected absolute path unchanged, got %s", merged.Findings[0].Location.File)
+	}
+	files := map[string]bool{}
+	for _, f := range merged.Findings {
+		files[f.Location.File] = true
+	}
+	if !files[filepath.Join("app", "src", "Main.java")] || !files[filepath.Join("lib", "src", "Main.java")] {
+		t.Errorf("expected module-prefixed locations, got %v", files)
+	}
+	if merged.TotalPassed != 1 || merged.TotalFailed != 3 {
+		t.Errorf("expected 1 passed / 3 failed, got %d / %d", merged.TotalPassed, merged.TotalFailed)
+	}
+	if len(merged.ByScanner) != 2 {
+		t.Errorf("expected 2 per-module scanner results, got %d", len(merged.ByScanner))
+	}
+	if len(merged.ScanMeta.ScannerIDs) != 1 {
+		t.Errorf("expected 1 distinct scanner ID, got %d", len(merged.ScanMeta.ScannerIDs))
+	}
+}
+
+func TestNewScanResult(t *testing.T) {
+	dup := Finding{CheckID: "W1", Severity: SeverityWarning, Title: "t", Location: Location{File: "AndroidManifest.xml"}}
+	results := []*CheckResult{
+		{CheckID: "manifest", Findings: []Finding{dup}, FilesScanned: 1},
+		{CheckID: "code-scan", Passed: true, Findings: []Finding{dup, {CheckID: "C1", Severity: SeverityCritical}}, FilesScanned: 2},
+	}
+
+	result := NewScanResult("/repo", results)
+
+	if len(result.Findings) != 2 || result.Findings[0].CheckID != "C1" {
+		t.Errorf("expected 2 deduplicated findings, critical first, got %+v", result.Findings)
+	}
+	if result.TotalPassed != 1 || result.TotalFailed != 1 {
+		t.Errorf("expected 1 passed / 1 failed, got %d / %d", result.TotalPassed, result.TotalFailed)
+	}
+	if len(result.ByScanner) != 2 || result.ScanMeta.FilesScanned != 3 || result.ScanMeta.ProjectPath != "/repo" {
+		t.Errorf("unexpected aggregation: %+v", result.ScanMeta)
+	}
+}
+
+func TestReport_ToJSON_ByCategory(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{
+			{CheckID: "DP001", Severity: SeverityCritical, Title: "Dangerous permission: READ_SMS", Category: policies.CategoryDangerousPermissions},
+			{CheckID: "DP003", Severity: SeverityWarning, Title: "Camera permission", Category: policies.CategoryDangerousPermissions},
+			{CheckID: "CUSTOM1", Severity: SeverityInfo, Title: "No category"},
+		},
+		ScanMeta: ScanMetadata{ProjectPath: "/test"},
+	}
+	jr := NewReport(sr, SeverityInfo).ToJSON()
+
+	perms := jr.ByCategory[policies.CategoryDangerousPermissions]
+	if perms.Count != 2 || len(perms.Findings) != 2 || perms.Findings[0].CheckID != "DP001" {
+		t.Errorf("expected DP001 and DP003 under %s, got %+v", policies.CategoryDangerousPermissions, perms)
+	}
+	other := jr.ByCategory[CategoryUncategorized]
+	if other.Count != 1 || other.Findings[0].CheckID != "CUSTOM1" {
+		t.Errorf("expected CUSTOM1 under %s, got %+v", CategoryUncategorized, other)
+	}
+	if len(jr.ByCategory) != 2 {
+		t.Errorf("expected 2 categories, got %v", jr.ByCategory)
+	}
+}
+
+func TestReport_ToJSON_SchemaVersion(t *testing.T) {
+	for _, summaryOnly := range []bool{false, true} {
+		report := NewReport(&ScanResult{ScanMeta: ScanMetadata{ProjectPath: "/test"}}, SeverityInfo)
+		report.SummaryOnly = summaryOnly
+		data, err := json.Marshal(report.ToJSON())
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		var got map[string]json.RawMessage
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if want := `"` + JSONSchemaVersion + `"`; string(got["schema_version"]) != want {
+			t.Errorf("summaryOnly=%v: expected schema_version %s, got %s", summaryOnly, want, got["schema_version"])
+		}
+	}
+}
+
+func TestReport_ToJSON_SummaryOnly(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{
+			{CheckID: "DP001", Severity: SeverityCritical, Title: "Dangerous permission: READ_SMS"},
+			{CheckID: "CS001", Severity: SeverityWarning, Title: "HTTP URL"},
+		},
+		TotalFailed: 1,
+		ScanMeta:    ScanMetadata{ProjectPath: "/test"},
+	}
+	report := NewReport(sr, SeverityInfo)
+	report.SummaryOnly = true
+	data, err := json.Marshal(report.ToJSON())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if _, ok := got["findings"]; ok {
+		t.Errorf("expected no findings array, got %s", got["findings"])
+	}
+	var summary JSONSummary
+	if err := json.Unmarshal(got["summary"], &summary); err != nil {
+		t.Fatalf("expected a summary: %v", err)
+	}
+	if summary.CriticalCount != 1 || summary.WarningCount != 1 || summary.Failed != 1 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if string(got["project_path"]) != `"/test"` {
+		t.Errorf("expected project_path /test, got %s", got["project_path"])
+	}
+	if strings.Contains(string(got["by_category"]), "check_id") {
+		t.Errorf("expected category counts without findings, got %s", got["by_category"])
+	}
+
+	// Full reports keep the array even when no finding passes the filter.
+	data, _ = json.Marshal(NewReport(&ScanResult{}, SeverityCritical).ToJSON())
+	if !strings.Contains(string(data), `"findings":[]`) {
+		t.Errorf("expected an empty findings array, got %s", data)
+	}
+}
+
+func TestReport_RenderGitHub(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{
+			{
+				CheckID:     "CS001",
+				Severity:    SeverityError,
+				Title:       "Unencrypted HTTP URL detected",
+				Description: "Uses 100% cleartext\n  Code: String a, b;",
+				Location:    Location{File: "app/Main.java", Line: 12},
+			},
+			{CheckID: "W1", Severity: SeverityWarning, Title: "Warn"},
+			{CheckID: "I1", Severity: SeverityInfo, Title: "Info, with comma"},
+		},
+		ScanMeta: ScanMetadata{ProjectPath: "/test"},
+	}
+	out := NewReport(sr, SeverityInfo).RenderGitHub()
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 workflow commands, got %d:\n%s", len(lines), out)
+	}
+
+	want := "::error file=app/Main.java,line=12,title=CS001%3A Unencrypted HTTP URL detected::Uses 100%25 cleartext%0A  Code: String a, b;"
+	if lines[0] != want {
+		t.Errorf("unexpected error command:\n got: %s\nwant: %s", lines[0], want)
+	}
+	if !strings.HasPrefix(lines[1], "::warning title=W1%3A Warn::") {
+		t.Errorf("unexpected warning command: %s", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "::notice title=I1%3A Info%2C with comma::") {
+		t.Errorf("unexpected notice command: %s", lines[2])
+	}
+}
+
+func TestReport_RenderConfluence(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{
+			{
+				CheckID:     "CS001",
+				Severity:    SeverityCritical,
+				Title:       "Unencrypted HTTP URL detected",
+				Description: "Uses <http> & friends\n  Code: if (a < b)",
+				Location:    Location{File: "app/Main.java", Line: 12},
+			},
+			{CheckID: "I1", Severity: SeverityInfo, Title: "Info"},
+		},
+		ScanMeta: ScanMetadata{ProjectPath: "/test"},
+	}
+	out := NewReport(sr, SeverityInfo).RenderConfluence()
+	for _, want := range []string{
+		`<ac:structured-macro ac:name="warning">`,
+		`<ac:parameter ac:name="title">playcheck: FAIL</ac:parameter>`,
+		`<ac:rich-text-body>`,
+		`<table><tbody>`,
+		`<ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">Red</ac:parameter>`,
+		`<code>app/Main.java:12</code>`,
+		`Uses &lt;http&gt; &amp; friends<br/>  Code: if (a &lt; b)`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<http>") {
+		t.Errorf("description was not escaped:\n%s", out)
+	}
+
+	sr.Findings = sr.Findings[1:]
+	out = NewReport(sr, SeverityInfo).RenderConfluence()
+	if !strings.HasPrefix(out, `<ac:structured-macro ac:name="info">`) {
+		t.Errorf("expected info macro for a passing scan, got:\n%s", out)
+	}
+	report := NewReport(sr, SeverityInfo)
+	report.FailOn = SeverityInfo
+	if out := report.RenderConfluence(); !strings.Contains(out, "playcheck: FAIL") {
+		t.Errorf("expected FAIL with FailOn info, got:\n%s", out)
+	}
+}
+
+func TestDiffReports(t *testing.T) {
+	oldReport := JSONReport{
+		ProjectPath: "/app",
+		Findings: []JSONFinding{
+			{CheckID: "CS001", Severity: "ERROR", Title: "HTTP URL", Location: "Api.kt:10"},
+			{CheckID: "DP001", Severity: "CRITICAL", Title: "SMS permission", Location: "AndroidManifest.xml:4"},
+		},
+	}
+	newReport := JSONReport{
+		ProjectPath: "/app",
+		Findings: []JSONFinding{
+			{CheckID: "CS001", Severity: "ERROR", Title: "HTTP URL", Location: "Api.kt:10", Description: "reworded"},
+			{CheckID: "CS011", Severity: "ERROR", Title: "Weak crypto", Location: "Crypto.kt:3"},
+		},
+	}
+
+	d := DiffReports(oldReport, newReport)
+
+	if len(d.Added) != 1 || d.Added[0].CheckID != "CS011" {
+		t.Errorf("expected CS011 added, got %v", d.Added)
+	}
+	if len(d.Resolved) != 1 || d.Resolved[0].CheckID != "DP001" {
+		t.Errorf("expected DP001 resolved, got %v", d.Resolved)
+	}
+	if len(d.Unchanged) != 1 || d.Unchanged[0].CheckID != "CS001" {
+		t.Errorf("expected CS001 unchanged, got %v", d.Unchanged)
+	}
+	if !d.HasNewCritical() {
+		t.Error("expected HasNewCritical for an added ERROR finding")
+	}
+
+	out := d.RenderTerminal()
+	for _, want := range []string{"NEW (1)", "RESOLVED (1)", "UNCHANGED (1)", "RESULT: FAIL"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q", want)
+		}
+	}
+}
+
+func TestDiffReports_OnlyResolved(t *testing.T) {
+	oldReport := JSONReport{Findings: []JSONFinding{
+		{CheckID: "CS001", Severity: "ERROR", Title: "HTTP URL", Location: "Api.kt:10"},
+		{CheckID: "CS001", Severity: "ERROR", Title: "HTTP URL", Location: "Api.kt:10"},
+	}}
+	newReport := JSONReport{Findings: []JSONFinding{
+		{CheckID: "CS001", Severity: "ERROR", Title: "HTTP URL", Location: "Api.kt:10"},
+	}}
+
+	d := DiffReports(oldReport, newReport)
+	if len(d.Resolved) != 1 || len(d.Unchanged) != 1 || len(d.Added) != 0 {
+		t.Errorf("expected 1 resolved and 1 unchanged duplicate, got added=%d resolved=%d unchanged=%d",
+			len(d.Added), len(d.Resolved), len(d.Unchanged))
+	}
+	if d.HasNewCritical() {
+		t.Error("did not expect HasNewCritical when findings were only resolved")
+	}
+}
+
+func TestRunner_OnFinding(t *testing.T) {
+	r := &Runner{}
+	r.RegisterScanner(&mockScanner{id: "a", findings: []Finding{{CheckID: "A1"}, {CheckID: "A2"}}})
+	r.RegisterScanner(&mockScanner{id: "b", findings: []Finding{{CheckID: "B1"}}})
+
+	var streamed []string
+	r.OnFinding(func(f Finding) {
+		streamed = append(streamed, f.CheckID)
+	})
+	result := r.Run("/tmp", nil)
+
+	if len(streamed) != 3 {
+		t.Errorf("expected 3 streamed findings, got %v", streamed)
+	}
+	if len(result.Findings) != 3 {
+		t.Errorf("expected findings to still be aggregated, got %d", len(result.Findings))
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	r := &Runner{}
+	r.RegisterScanner(&mockScanner{id: "a", findings: []Finding{
+		{CheckID: "DP001", Title: "SMS", Severity: SeverityCritical, Location: Location{File: "AndroidManifest.xml", Line: 3}},
+		{CheckID: "DP001", Title: "CALL_LOG", Severity: SeverityCritical, Location: Location{File: "AndroidManifest.xml", Line: 3}},
+		{CheckID: "X", Title: "Info", Severity: SeverityInfo},
+	}})
+
+	var buf strings.Builder
+	w := NewNDJSONWriter(&buf, SeverityWarning)
+	w.SetLabel("app")
+	r.OnFinding(w.WriteFinding)
+	result := r.Run("/project", nil)
+	if err := w.WriteSummary(NewReport(result, SeverityWarning)); err != nil {
+		t.Fatalf("WriteSummary() error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	// Findings of one rule at the same location with different titles are
+	// all kept, as in the aggregated result.
+	if len(lines) != 3 {
+		t.Fatalf("expected 2 finding lines and 1 summary line, got %d:\n%s", len(lines), buf.String())
+	}
+	var f JSONFinding
+	if err := json.Unmarshal([]byte(lines[0]), &f); err != nil {
+		t.Fatalf("finding line is not valid JSON: %v", err)
+	}
+	if f.Location != filepath.Join("app", "AndroidManifest.xml")+":3" {
+		t.Errorf("expected labeled location, got %s", f.Location)
+	}
+	if f.PolicyLink == "" {
+		t.Error("expected policy link on streamed finding")
+	}
+	var s NDJSONSummary
+	if err := json.Unmarshal([]byte(lines[2]), &s); err != nil {
+		t.Fatalf("summary line is not valid JSON: %v", err)
+	}
+	if s.Summary.CriticalCount != 2 {
+		t.Errorf("expected critical count 2 in summary, got %d", s.Summary.CriticalCount)
+	}
+}
+
+func TestParseAppCategory(t *testing.T) {
+	if c, err := ParseAppCategory(""); err != nil || c != CategoryDefault {
+		t.Errorf("expected default category, got %q, %v", c, err)
+	}
+	if c, err := ParseAppCategory("families"); err != nil || c != CategoryFamilies {
+		t.Errorf("expected families category, got %q, %v", c, err)
+	}
+	if _, err := ParseAppCategory("games"); err == nil {
+		t.Error("expected error for unknown category")
+	}
+}
+
+func TestSeverityOverrides_Apply(t *testing.T) {
+	findings := []Finding{
+		{CheckID: "DP001", Title: "Dangerous permission: BODY_SENSORS", Severity: SeverityWarning},
+		{CheckID: "DP001", Title: "Dangerous permission: CAMERA", Severity: SeverityWarning},
+		{CheckID: "CS016", Title: "Sensitive data logged", Severity: SeverityWarning},
+		{CheckID: "CS001", Title: "Cleartext HTTP URL", Severity: SeverityInfo},
+	}
+	o := SeverityOverrides{
+		"DP001:BODY_SENSORS": SeverityCritical,
+		"DP001":              SeverityInfo,
+		"CS001":              SeverityWarning,
+	}
+	if !o.Apply(findings) {
+		t.Error("expected Apply to report a finding raised to error or above")
+	}
+	// Overrides only raise severities.
+	want := []Severity{SeverityCritical, SeverityWarning, SeverityWarning, SeverityWarning}
+	for i, f := range findings {
+		if f.Severity != want[i] {
+			t.Errorf("%s: got severity %s, want %s", f.Title, f.Severity, want[i])
+		}
+	}
+
+	if PresetNone.Overrides().Apply(findings) {
+		t.Error("expected no overrides without a preset")
+	}
+}
+
+func TestParsePreset(t *testing.T) {
+	for _, name := range []string{"", "game", "finance", "health"} {
+		if p, err := ParsePreset(name); err != nil || string(p) != name {
+			t.Errorf("ParsePreset(%q) = %q, %v", name, p, err)
+		}
+	}
+	if _, err := ParsePreset("retail"); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+}
+
+func TestRuleCoverage_Exclude(t *testing.T) {
+	c := NewRuleCoverage("B1", "A1", "C1", "A1")
+	c.Exclude("no gradle files", "C1", "Z9")
+	c.Exclude("no sources", "C1", "B1")
+
+	if want := []string{"A1"}; strings.Join(c.Applicable, ",") != strings.Join(want, ",") {
+		t.Errorf("got applicable %v, want %v", c.Applicable, want)
+	}
+	if len(c.NotApplicable) != 2 ||
+		c.NotApplicable[0].Reason != "no gradle files" || strings.Join(c.NotApplicable[0].RuleIDs, ",") != "C1" ||
+		c.NotApplicable[1].Reason != "no sources" || strings.Join(c.NotApplicable[1].RuleIDs, ",") != "B1" {
+		t.Errorf("unexpected not-applicable groups %+v", c.NotApplicable)
+	}
+}
+
+func TestReport_ShowCoverage(t *testing.T) {
+	cov := NewRuleCoverage("SDK001", "PDS001")
+	cov.Exclude("no Gradle build files found", "SDK001")
+	sr := &ScanResult{
+		ByScanner: map[string]*CheckResult{"DATA_SAFETY": {CheckID: "DATA_SAFETY", Passed: true, Coverage: cov}},
+		ScanMeta:  ScanMetadata{ProjectPath: "/test"},
+	}
+
+	report := NewReport(sr, SeverityInfo)
+	if out := report.RenderTerminal(); strings.Contains(out, "Rule coverage") {
+		t.Error("expected no coverage section unless requested")
+	}
+	if report.ToJSON().Coverage != nil {
+		t.Error("expected no JSON coverage unless requested")
+	}
+
+	report.ShowCoverage = true
+	out := report.RenderTerminal()
+	if !strings.Contains(out, "DATA_SAFETY: 1 applicable, 1 not applicable") || !strings.Contains(out, "no Gradle build files found: SDK001") {
+		t.Errorf("expected coverage section, got:\n%s", out)
+	}
+	if got := report.ToJSON().Coverage["DATA_SAFETY"]; got == nil || len(got.NotApplicable) != 1 {
+		t.Errorf("unexpected JSON coverage %+v", got)
+	}
+}
+
+func TestReport_AcknowledgedTally(t *testing.T) {
+	sr := &ScanResult{ScanMeta: ScanMetadata{ProjectPath: "/test", Acknowledged: 2}}
+	report := NewReport(sr, SeverityInfo)
+	if got := report.ToJSON().Summary.Acknowledged; got != 2 {
+		t.Errorf("expected acknowledged 2 in JSON summary, got %d", got)
+	}
+	if out := report.RenderTerminal(); !strings.Contains(out, "Acknowledged: 2") {
+		t.Errorf("expected acknowledged tally in terminal summary, got:\n%s", out)
+	}
+}
+
+func TestReport_HasFindingsAtOrAbove_IgnoresDisplayFilter(t *testing.T) {
+	sr := &ScanResult{Findings: []Finding{{CheckID: "CS001", Severity: SeverityWarning}}}
+	report := NewReport(sr, SeverityCritical)
+	if len(report.Findings) != 0 {
+		t.Fatalf("expected the warning to be filtered from display, got %d findings", len(report.Findings))
+	}
+	if !report.HasFindingsAtOrAbove(SeverityWarning) {
+		t.Error("expected the filtered warning to count toward a warning threshold")
+	}
+	if report.HasFindingsAtOrAbove(SeverityError) {
+		t.Error("expected no findings at error or above")
+	}
+}
+
+func TestReport_ToJSON_Remediation(t *testing.T) {
+	sr := &ScanResult{
+		Findings: []Finding{
+			{CheckID: "MV001", Severity: SeverityError, Title: "Activity missing android:exported", Remediation: &Remediation{Type: RemediationChangeAttribute, Target: "android:exported", Value: "false"}},
+			{CheckID: "MC001", Severity: SeverityInfo, Title: "Exported activity"},
+		},
+		ScanMeta: ScanMetadata{ProjectPath: "/test"},
+	}
+	data, err := json.Marshal(NewReport(sr, SeverityInfo).ToJSON())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got struct {
+		Findings []map[string]json.RawMessage `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(got.Findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(got.Findings))
+	}
+	want := `{"type":"change-attribute","target":"android:exported","value":"false"}`
+	if r := string(got.Findings[0]["remediation"]); r != want {
+		t.Errorf("expected remediation %s, got %s", want, r)
+	}
+	if r, ok := got.Findings[1]["remediation"]; ok {
+		t.Errorf("expected no remediation for an advisory finding, got %s", r)
+	}
+}
@@ -0,0 +1,113 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset emphasizes the rules that matter most for a type of app by raising
+// their severity, e.g. health data disclosures for health apps.
+type Preset string
+
+const (
+	// PresetNone applies no severity overrides.
+	PresetNone Preset = ""
+	// PresetGame emphasizes ads, ad consent, and in-app purchase rules.
+	PresetGame Preset = "game"
+	// PresetFinance emphasizes secure storage and transport of financial data.
+	PresetFinance Preset = "finance"
+	// PresetHealth emphasizes body sensor and health data collection.
+	PresetHealth Preset = "health"
+)
+
+// presetNames lists the presets accepted by ParsePreset.
+var presetNames = []Preset{PresetGame, PresetFinance, PresetHealth}
+
+// ParsePreset converts a preset name to a Preset. An empty string selects
+// PresetNone.
+func ParsePreset(s string) (Preset, error) {
+	p := Preset(s)
+	if p == PresetNone {
+		return p, nil
+	}
+	for _, name := range presetNames {
+		if p == name {
+			return p, nil
+		}
+	}
+	names := make([]string, len(presetNames))
+	for i, name := range presetNames {
+		names[i] = string(name)
+	}
+	return "", fmt.Errorf("unknown preset %q (valid: %s)", s, strings.Join(names, ", "))
+}
+
+// SeverityOverrides maps a rule selector to the minimum severity its findings
+// are reported at. A selector is either a rule ID such as "CS016", or a rule ID
+// and a keyword joined by a colon such as "DP001:BODY_SENSORS", which only
+// matches findings whose title or description contains the keyword. Keyword
+// selectors take precedence over plain rule IDs.
+type SeverityOverrides map[string]Severity
+
+// presetOverrides holds the severity overrides of each preset.
+var presetOverrides = map[Preset]SeverityOverrides{
+	PresetGame: {
+		"PDS003": SeverityCritical, // data collection without consent
+		"CS004":  SeverityError,    // AdMob SDK usage
+		"CS005":  SeverityError,    // advertising ID usage
+		"MP001":  SeverityWarning,  // subscription disclosures
+	},
+	PresetFinance: {
+		"MV004": SeverityCritical, // cleartext traffic enabled
+		"CS001": SeverityCritical, // cleartext HTTP URL
+		"CS011": SeverityCritical, // weak cryptography
+		"CS016": SeverityError,    // sensitive data in logcat
+		"CS025": SeverityError,    // sensitive data in plain SharedPreferences
+	},
+	PresetHealth: {
+		"DP001:BODY_SENSORS":  SeverityCritical,
+		"PDS002:BODY_SENSORS": SeverityCritical,
+		"PDS002:Health data":  SeverityCritical,
+		"CS016":               SeverityError, // sensitive data in logcat
+		"CS025":               SeverityError, // sensitive data in plain SharedPreferences
+	},
+}
+
+// Overrides returns the severity overrides of the preset. PresetNone has
+// none.
+func (p Preset) Overrides() SeverityOverrides {
+	return presetOverrides[p]
+}
+
+// Apply raises each finding matched by a selector to the selector's severity.
+// Findings already at or above it are left unchanged. It reports whether any
+// finding was raised to SeverityError or above.
+func (o SeverityOverrides) Apply(findings []Finding) (failed bool) {
+	if len(o) == 0 {
+		return false
+	}
+	for i := range findings {
+		f := &findings[i]
+		sev, ok := o.match(f)
+		if !ok || sev <= f.Severity {
+			continue
+		}
+		f.Severity = sev
+		if sev >= SeverityError {
+			failed = true
+		}
+	}
+	return failed
+}
+
+// match returns the override for f, preferring keyword selectors.
+func (o SeverityOverrides) match(f *Finding) (Severity, bool) {
+	for sel, sev := range o {
+		id, keyword, ok := strings.Cut(sel, ":")
+		if ok && id == f.CheckID && (strings.Contains(f.Title, keyword) || strings.Contains(f.Description, keyword)) {
+			return sev, true
+		}
+	}
+	sev, ok := o[f.CheckID]
+	return sev, ok
+}
@@ -1,11 +1,13 @@
 package preflight
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
 )
 
 // Report holds the analyzed scan results and provides rendering methods.
@@ -17,50 +19,120 @@ type Report struct {
 	WarningCount  int
 	InfoCount     int
 	Findings      []Finding
+
+	// ScoreWeights sets the per-severity penalties used by
+	// ComplianceScore. NewReport sets it to DefaultScoreWeights.
+	ScoreWeights ScoreWeights
+
+	// ShowCoverage adds the rule coverage reported by each scanner to the
+	// terminal and JSON output.
+	ShowCoverage bool
+
+	// SummaryOnly omits the findings from the JSON output, keeping the
+	// summary, metadata, and per-category counts.
+	SummaryOnly bool
+
+	// FailOn is the lowest severity that fails the scan in RenderTerminal,
+	// StatusLine, and RenderConfluence. NewReport sets it to SeverityError.
+	FailOn Severity
 }
 
+// JSONSchemaVersion is the version of the JSON report format, as
+// "major.minor". The minor version is bumped for additive changes such as new
+// fields, and the major version for changes that break existing consumers,
+// such as renamed or removed fields.
+const JSONSchemaVersion = "1.2"
+
 // JSONReport is the JSON-serializable representation of a scan report.
 type JSONReport struct {
-	Timestamp   string        `json:"timestamp"`
-	ProjectPath string        `json:"project_path"`
-	Summary     JSONSummary   `json:"summary"`
-	Findings    []JSONFinding `json:"findings"`
+	SchemaVersion string        `json:"schema_version"`
+	Timestamp     string        `json:"timestamp"`
+	ProjectPath   string        `json:"project_path"`
+	Summary       JSONSummary   `json:"summary"`
+	Findings      []JSONFinding `json:"findings"`
+
+	// ByCategory groups the findings by the category their scanner lists for
+	// their rule. Findings without a category are grouped under
+	// CategoryUncategorized.
+	ByCategory map[string]JSONCategory `json:"by_category"`
+
+	// Coverage maps scanner IDs to their rule coverage when requested.
+	Coverage map[string]*RuleCoverage `json:"coverage,omitempty"`
+
+	summaryOnly bool
+}
+
+// MarshalJSON leaves out the findings array of summary-only reports. Other
+// reports always include it, even when empty.
+func (jr JSONReport) MarshalJSON() ([]byte, error) {
+	type report JSONReport
+	if !jr.summaryOnly {
+		return json.Marshal(report(jr))
+	}
+	return json.Marshal(struct {
+		report
+		Findings []JSONFinding `json:"findings,omitempty"`
+	}{report: report(jr)})
+}
+
+// CategoryUncategorized groups findings without a category in
+// JSONReport.ByCategory.
+const CategoryUncategorized = "uncategorized"
+
+// JSONCategory holds the findings of one policy category.
+type JSONCategory struct {
+	Count    int           `json:"count"`
+	Findings []JSONFinding `json:"findings,omitempty"`
 }
 
 // JSONSummary holds aggregate counts for JSON output.
 type JSONSummary struct {
-	TotalChecks   int    `json:"total_checks"`
-	Passed        int    `json:"passed"`
-	Failed        int    `json:"failed"`
-	CriticalCount int    `json:"critical"`
-	WarningCount  int    `json:"warning"`
-	InfoCount     int    `json:"info"`
-	Duration      string `json:"duration"`
+	TotalChecks   int      `json:"total_checks"`
+	Passed        int      `json:"passed"`
+	Failed        int      `json:"failed"`
+	CriticalCount int      `json:"critical"`
+	WarningCount  int      `json:"warning"`
+	InfoCount     int      `json:"info"`
+	Duration      string   `json:"duration"`
+	Score         int      `json:"compliance_score"`
+	FilesScanned  int      `json:"files_scanned"`
+	BytesScanned  int64    `json:"bytes_scanned"`
+	SkippedRules  []string `json:"skipped_rules,omitempty"`
+	Acknowledged  int      `json:"acknowledged,omitempty"`
 }
 
 // JSONFinding is a single finding in JSON format.
 type JSONFinding struct {
-	CheckID     string `json:"check_id"`
-	Severity    string `json:"severity"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Location    string `json:"location,omitempty"`
-	Suggestion  string `json:"suggestion,omitempty"`
+	CheckID     string   `json:"check_id"`
+	Severity    string   `json:"severity"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Location    string   `json:"location,omitempty"`
+	Suggestion  string   `json:"suggestion,omitempty"`
+	PolicyLink  string   `json:"policy_link,omitempty"`
+	Context     []string `json:"context,omitempty"`
+	Count       int      `json:"count,omitempty"`
+
+	Remediation *Remediation `json:"remediation,omitempty"`
 }
 
 // NewReport creates a Report from a ScanResult, filtering findings by minimum severity.
 func NewReport(result *ScanResult, minSeverity Severity) *Report {
 	r := &Report{
-		ProjectPath: result.ScanMeta.ProjectPath,
-		ScanResult:  result,
-		MinSeverity: minSeverity,
+		ProjectPath:  result.ScanMeta.ProjectPath,
+		ScanResult:   result,
+		MinSeverity:  minSeverity,
+		FailOn:       SeverityError,
+		ScoreWeights: DefaultScoreWeights,
 	}
 
+	db, _ := policies.Load()
+
 	for _, f := range result.Findings {
 		if f.Severity < minSeverity {
 			continue
 		}
-		r.Findings = append(r.Findings, f)
+		r.Findings = append(r.Findings, withPolicyLink(f, db))
 		switch f.Severity {
 		case SeverityCritical, SeverityError:
 			r.CriticalCount++
@@ -74,45 +146,120 @@ func NewReport(result *ScanResult, minSeverity Severity) *Report {
 	return r
 }
 
+// withPolicyLink fills in the finding's policy link from the policy database
+// when the scanner did not set one. Findings whose CheckID has no matching
+// rule are returned unchanged.
+func withPolicyLink(f Finding, db *policies.PolicyDatabase) Finding {
+	if f.PolicyLink != "" || db == nil {
+		return f
+	}
+	if rule := db.GetRule(f.CheckID); rule != nil {
+		f.PolicyLink = rule.PolicyLink
+	}
+	return f
+}
+
 // HasCritical returns true if any critical-level findings exist (unfiltered).
 func (r *Report) HasCritical() bool {
+	return r.HasFindingsAtOrAbove(SeverityError)
+}
+
+// HasFindingsAtOrAbove reports whether any finding is at or above threshold.
+// Like HasCritical, it looks at every finding of the scan, including those
+// below MinSeverity, so the display filter never changes the outcome.
+func (r *Report) HasFindingsAtOrAbove(threshold Severity) bool {
 	for _, f := range r.ScanResult.Findings {
-		if f.Severity == SeverityCritical || f.Severity == SeverityError {
+		if f.Severity >= threshold {
 			return true
 		}
 	}
 	return false
 }
 
-// ToJSON returns a JSON-serializable report structure.
+// ToJSON returns a JSON-serializable report structure. With SummaryOnly,
+// Findings and the findings of each category are nil.
 func (r *Report) ToJSON() JSONReport {
 	findings := make([]JSONFinding, 0, len(r.Findings))
 	for _, f := range r.Findings {
-		findings = append(findings, JSONFinding{
-			CheckID:     f.CheckID,
-			Severity:    f.Severity.String(),
-			Title:       f.Title,
-			Description: f.Description,
-			Location:    f.Location.String(),
-			Suggestion:  f.Suggestion,
-		})
+		findings = append(findings, toJSONFinding(f))
 	}
 
-	totalChecks := r.ScanResult.TotalPassed + r.ScanResult.TotalFailed
+	jr := JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+		ProjectPath:   r.ProjectPath,
+		Summary:       r.jsonSummary(),
+		Findings:      findings,
+		ByCategory:    groupByCategory(r.Findings),
+	}
+	if r.ShowCoverage {
+		jr.Coverage = r.coverageByScanner()
+	}
+	if r.SummaryOnly {
+		jr.summaryOnly = true
+		jr.Findings = nil
+		for category, g := range jr.ByCategory {
+			g.Findings = nil
+			jr.ByCategory[category] = g
+		}
+	}
+	return jr
+}
 
-	return JSONReport{
-		Timestamp:   time.Now().UTC().Format(time.RFC3339),
-		ProjectPath: r.ProjectPath,
-		Summary: JSONSummary{
-			TotalChecks:   totalChecks,
-			Passed:        r.ScanResult.TotalPassed,
-			Failed:        r.ScanResult.TotalFailed,
-			CriticalCount: r.CriticalCount,
-			WarningCount:  r.WarningCount,
-			InfoCount:     r.InfoCount,
-			Duration:      r.ScanResult.ScanMeta.Duration.String(),
-		},
-		Findings: findings,
+// groupByCategory groups findings by their Category.
+func groupByCategory(findings []Finding) map[string]JSONCategory {
+	groups := make(map[string]JSONCategory)
+	for _, f := range findings {
+		category := f.Category
+		if category == "" {
+			category = CategoryUncategorized
+		}
+		g := groups[category]
+		g.Count++
+		g.Findings = append(g.Findings, toJSONFinding(f))
+		groups[category] = g
+	}
+	return groups
+}
+
+func toJSONFinding(f Finding) JSONFinding {
+	return JSONFinding{
+		CheckID:     f.CheckID,
+		Severity:    f.Severity.String(),
+		Title:       f.Title,
+		Description: f.Description,
+		Location:    f.Location.String(),
+		Suggestion:  f.Suggestion,
+		PolicyLink:  f.PolicyLink,
+		Context:     f.Context,
+		Count:       reportedCount(f),
+		Remediation: f.Remediation,
+	}
+}
+
+// reportedCount returns the finding's Count if it merged duplicates, and 0
+// otherwise so single findings omit the field.
+func reportedCount(f Finding) int {
+	if f.Count > 1 {
+		return f.Count
+	}
+	return 0
+}
+
+func (r *Report) jsonSummary() JSONSummary {
+	return JSONSummary{
+		TotalChecks:   r.ScanResult.TotalPassed + r.ScanResult.TotalFailed,
+		Passed:        r.ScanResult.TotalPassed,
+		Failed:        r.ScanResult.TotalFailed,
+		CriticalCount: r.CriticalCount,
+		WarningCount:  r.WarningCount,
+		InfoCount:     r.InfoCount,
+		Duration:      r.ScanResult.ScanMeta.Duration.String(),
+		Score:         r.ComplianceScore(),
+		FilesScanned:  r.ScanResult.ScanMeta.FilesScanned,
+		BytesScanned:  r.ScanResult.ScanMeta.BytesScanned,
+		SkippedRules:  r.ScanResult.ScanMeta.SkippedRules,
+		Acknowledged:  r.ScanResult.ScanMeta.Acknowledged,
 	}
 }
 
@@ -201,15 +348,27 @@ func (r *Report) RenderTerminal() string {
 	b.WriteString(" | Info: ")
 	fmt.Fprintf(&b, "%d", r.InfoCount)
 	b.WriteString("\n")
+	fmt.Fprintf(&b, "Compliance score: %d/100\n", r.ComplianceScore())
+	if meta := r.ScanResult.ScanMeta; meta.FilesScanned > 0 {
+		dimColor.Fprintf(&b, "Scanned: %d files (%s)\n", meta.FilesScanned, formatBytes(meta.BytesScanned))
+	}
+	if n := r.ScanResult.ScanMeta.Acknowledged; n > 0 {
+		dimColor.Fprintf(&b, "Acknowledged: %d SDK disclosure reminders (acknowledged_sdks in config)\n", n)
+	}
+	if skipped := r.ScanResult.ScanMeta.SkippedRules; len(skipped) > 0 {
+		warningColor.Fprintf(&b, "Skipped rules (matching time budget exceeded): %s\n", strings.Join(skipped, ", "))
+	}
+	if r.ShowCoverage {
+		r.renderCoverage(&b, dimColor)
+	}
 
-	if r.CriticalCount > 0 {
-		b.WriteString("\n")
+	b.WriteString("\n")
+	if r.HasFindingsAtOrAbove(r.FailOn) {
 		criticalColor.Fprint(&b, "RESULT: FAIL")
-		b.WriteString(" - Critical issues must be resolved before submission.\n")
+		fmt.Fprintf(&b, " - Findings at %s or above must be resolved before submission.\n", r.FailOn)
 	} else {
-		b.WriteString("\n")
 		passedColor.Fprint(&b, "RESULT: PASS")
-		b.WriteString(" - No critical issues found.\n")
+		fmt.Fprintf(&b, " - No findings at %s or above.\n", r.FailOn)
 	}
 
 	return b.String()
@@ -218,6 +377,9 @@ func (r *Report) RenderTerminal() string {
 func renderFinding(b *strings.Builder, f Finding, severityColor *color.Color, dimColor *color.Color) {
 	severityColor.Fprintf(b, "  [%s]", f.Severity)
 	fmt.Fprintf(b, " %s", f.Title)
+	if f.Count > 1 {
+		dimColor.Fprintf(b, " (reported %d times)", f.Count)
+	}
 	b.WriteString("\n")
 	if f.Location.File != "" {
 		dimColor.Fprintf(b, "         %s", f.Location)
@@ -227,8 +389,37 @@ func renderFinding(b *strings.Builder, f Finding, severityColor *color.Color, di
 		fmt.Fprintf(b, "         %s", f.Description)
 		b.WriteString("\n")
 	}
+	for _, line := range f.Context {
+		dimColor.Fprintf(b, "           %s", line)
+		b.WriteString("\n")
+	}
 	if f.Suggestion != "" {
 		dimColor.Fprintf(b, "         Suggestion: %s", f.Suggestion)
 		b.WriteString("\n")
 	}
+	var ref []string
+	if f.CheckID != "" {
+		ref = append(ref, "Rule: "+f.CheckID)
+	}
+	if f.PolicyLink != "" {
+		ref = append(ref, "Policy: "+f.PolicyLink)
+	}
+	if len(ref) > 0 {
+		dimColor.Fprintf(b, "         %s", strings.Join(ref, " | "))
+		b.WriteString("\n")
+	}
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "12.3 KiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
@@ -0,0 +1,13 @@
+package preflight
+
+// RuleInfo describes a check a scanner can report. Title and Severity are the
+// defaults; individual findings may refine the title or adjust the severity
+// to the context they were found in.
+type RuleInfo struct {
+	ID          string
+	Title       string
+	Description string
+	Severity    Severity
+	Category    string // category findings of the rule are grouped under; matches the policy entry, if any
+	Scanner     string // ID of the Checker that reports the rule
+}
@@ -0,0 +1,60 @@
+package preflight
+
+// ScoreWeights sets how many points a single finding of each severity costs
+// when computing the compliance score.
+type ScoreWeights struct {
+	Critical float64 `json:"critical"`
+	Error    float64 `json:"error"`
+	Warning  float64 `json:"warning"`
+	Info     float64 `json:"info"`
+}
+
+// DefaultScoreWeights are used when no weights are configured.
+var DefaultScoreWeights = ScoreWeights{
+	Critical: 25,
+	Error:    15,
+	Warning:  5,
+	Info:     1,
+}
+
+// weight returns the penalty for a single finding of the given severity.
+func (w ScoreWeights) weight(s Severity) float64 {
+	switch s {
+	case SeverityCritical:
+		return w.Critical
+	case SeverityError:
+		return w.Error
+	case SeverityWarning:
+		return w.Warning
+	default:
+		return w.Info
+	}
+}
+
+// ComplianceScore returns a 0-100 score summarizing the scan, where 100 means
+// no findings. The score is computed as:
+//
+//	penalty = sum(weight(f.Severity) for every finding)
+//	score   = 100 - penalty / checksRun
+//
+// clamped to [0, 100] and rounded to the nearest integer. Dividing by the
+// number of checks run keeps the score comparable as scanners are added.
+// All findings count regardless of the display severity filter, so changing
+// --severity never changes the score.
+func (r *Report) ComplianceScore() int {
+	checks := r.ScanResult.TotalPassed + r.ScanResult.TotalFailed
+	if checks == 0 {
+		checks = 1
+	}
+
+	var penalty float64
+	for _, f := range r.ScanResult.Findings {
+		penalty += r.ScoreWeights.weight(f.Severity)
+	}
+
+	score := 100 - penalty/float64(checks)
+	if score < 0 {
+		score = 0
+	}
+	return int(score + 0.5)
+}
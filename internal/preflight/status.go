@@ -0,0 +1,45 @@
+package preflight
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StatusLine returns a single uncolored line summarizing the report, e.g.
+// "playcheck: FAIL (2 critical, 5 warning, 3 info) in app/", for chat bots
+// and CI status messages. The verb is FAIL when a finding is at or above
+// FailOn and PASS otherwise; the counts cover the findings shown in the report.
+func (r *Report) StatusLine() string {
+	counts := make(map[Severity]int)
+	for _, f := range r.Findings {
+		counts[f.Severity]++
+	}
+	var parts []string
+	for _, sev := range []Severity{SeverityCritical, SeverityError, SeverityWarning, SeverityInfo} {
+		if n := counts[sev]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, strings.ToLower(sev.String())))
+		}
+	}
+	summary := "no findings"
+	if len(parts) > 0 {
+		summary = strings.Join(parts, ", ")
+	}
+
+	verb := "PASS"
+	if r.HasFindingsAtOrAbove(r.FailOn) {
+		verb = "FAIL"
+	}
+	return fmt.Sprintf("playcheck: %s (%s) in %s", verb, summary, r.ProjectPath)
+}
+
+// CheckIDs returns the distinct CheckIDs of the findings shown in the report,
+// sorted, for scripts that only need to know which rules fired.
+func (r *Report) CheckIDs() []string {
+	ids := make([]string, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		ids = append(ids, f.CheckID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
@@ -1,6 +1,10 @@
 package preflight
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
 // Severity represents the importance level of a finding.
 type Severity int
@@ -27,6 +31,17 @@ func (s Severity) String() string {
 	}
 }
 
+// ParseSeverity parses a severity name as produced by Severity.String,
+// ignoring case.
+func ParseSeverity(s string) (Severity, error) {
+	for _, sev := range []Severity{SeverityInfo, SeverityWarning, SeverityError, SeverityCritical} {
+		if strings.EqualFold(s, sev.String()) {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q", s)
+}
+
 // Location identifies where a finding was detected.
 type Location struct {
 	File string
@@ -52,6 +67,41 @@ type Finding struct {
 	Severity    Severity
 	Location    Location
 	Suggestion  string
+	PolicyLink  string // authoritative Play policy URL, if known
+	Category    string // category the reporting checker lists for CheckID; empty if unknown
+
+	// Context holds the source lines around Location.Line as "<line>: <code>",
+	// excluding the matched line itself. Empty unless requested.
+	Context []string
+
+	// Count is the number of identical findings merged into this one when
+	// the scan results were deduplicated. Zero and one both mean the
+	// finding was reported once.
+	Count int
+
+	// Remediation is the machine-readable form of Suggestion for rules with
+	// a deterministic fix. It is nil for advisory findings.
+	Remediation *Remediation
+}
+
+// RemediationType is the kind of change a Remediation makes.
+type RemediationType string
+
+const (
+	// RemediationAddPermission adds <uses-permission android:name=Value>
+	// to the manifest named by Target.
+	RemediationAddPermission RemediationType = "add-permission"
+	// RemediationChangeAttribute sets the attribute named by Target to
+	// Value on the element at the finding's location, adding it if absent.
+	RemediationChangeAttribute RemediationType = "change-attribute"
+)
+
+// Remediation describes the fix for a finding in a form tools can apply
+// without parsing Suggestion.
+type Remediation struct {
+	Type   RemediationType `json:"type"`
+	Target string          `json:"target"`
+	Value  string          `json:"value"`
 }
 
 func (f Finding) String() string {
@@ -64,6 +114,24 @@ type CheckResult struct {
 	Passed   bool
 	Findings []Finding
 	Err      error
+
+	// FilesScanned and BytesScanned optionally report how much of the
+	// project the check read. The runner sums them into ScanMetadata.
+	FilesScanned int
+	BytesScanned int64
+
+	// SkippedRules lists rules the check stopped evaluating part-way, e.g.
+	// because they exceeded their matching time budget on some file.
+	SkippedRules []string
+
+	// Coverage optionally reports which of the check's rules could fire
+	// given the files found in the project.
+	Coverage *RuleCoverage
+
+	// Acknowledged counts findings the check did not report because the
+	// user acknowledged them in the configuration, e.g. SDKs already
+	// declared in the Data Safety form.
+	Acknowledged int
 }
 
 // Checker is the interface that all compliance checks must implement.
@@ -73,3 +141,18 @@ type Checker interface {
 	Description() string
 	Run(projectDir string) (*CheckResult, error)
 }
+
+// RuleLister is implemented by checkers that can list the rules they report.
+// The runner sets the Category of each finding from the matching rule.
+type RuleLister interface {
+	Checker
+	Rules() []RuleInfo
+}
+
+// ContextChecker is implemented by checkers that can stop early. The runner
+// calls RunContext instead of Run when a checker implements it, and the
+// checker should return promptly once ctx is done.
+type ContextChecker interface {
+	Checker
+	RunContext(ctx context.Context, projectDir string) (*CheckResult, error)
+}
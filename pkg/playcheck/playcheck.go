@@ -0,0 +1,219 @@
+// Package playcheck is the library entry point for embedding playcheck in
+// other Go tools. Scan runs the same scanners as the CLI and returns the raw
+// result, so callers can build their own reports.
+package playcheck
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kotaroyamazaki/playcheck/internal/codescan"
+	"github.com/kotaroyamazaki/playcheck/internal/datasafety"
+	"github.com/kotaroyamazaki/playcheck/internal/manifest"
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// Result types, re-exported so callers outside this module can name them.
+type (
+	ScanResult   = preflight.ScanResult
+	ScanMetadata = preflight.ScanMetadata
+	CheckResult  = preflight.CheckResult
+	Finding      = preflight.Finding
+	Location     = preflight.Location
+	Severity     = preflight.Severity
+	AppCategory  = preflight.AppCategory
+	Preset       = preflight.Preset
+)
+
+// Severity levels, from least to most severe.
+const (
+	SeverityInfo     = preflight.SeverityInfo
+	SeverityWarning  = preflight.SeverityWarning
+	SeverityError    = preflight.SeverityError
+	SeverityCritical = preflight.SeverityCritical
+)
+
+// App categories accepted by Options.AppCategory.
+const (
+	CategoryDefault  = preflight.CategoryDefault
+	CategoryFamilies = preflight.CategoryFamilies
+)
+
+// Presets accepted by Options.Preset.
+const (
+	PresetNone    = preflight.PresetNone
+	PresetGame    = preflight.PresetGame
+	PresetFinance = preflight.PresetFinance
+	PresetHealth  = preflight.PresetHealth
+)
+
+// Scanner IDs accepted by Options.Scanners.
+const (
+	ScannerManifest   = "manifest"
+	ScannerCode       = "code-scan"
+	ScannerDataSafety = "DATA_SAFETY"
+)
+
+// Options configures a Scan. The zero value runs every scanner.
+type Options struct {
+	// Scanners limits the scan to the scanners with these IDs. Empty runs all;
+	// an unknown ID makes Scan return an error.
+	Scanners []string
+
+	// PreviousVersionCode is the versionCode of the last uploaded build. When
+	// set, a versionCode that is not greater is reported as an error.
+	PreviousVersionCode int
+
+	// MinSDKFloor is the lowest minSdkVersion that is not reported. Zero
+	// uses the default of 21.
+	MinSDKFloor int
+
+	// AppCategory applies category-specific policies, e.g. CategoryFamilies
+	// escalates ads SDK findings and requires certified ads SDKs.
+	AppCategory AppCategory
+
+	// ContextLines is the number of source lines before and after each code
+	// scan match to capture in Finding.Context.
+	ContextLines int
+
+	// Preset raises the severity of the rules that matter most for a type of
+	// app, e.g. PresetHealth escalates BODY_SENSORS findings to critical.
+	Preset Preset
+
+	// EndpointAllowlist lists domains, including their subdomains, that are
+	// not reported as development endpoints.
+	EndpointAllowlist []string
+
+	// StoreCriticalStrings lists the string resource names every locale must
+	// translate. Nil checks app_name only.
+	StoreCriticalStrings []string
+
+	// AcknowledgedSDKs lists SDKs already declared in the Data Safety form,
+	// e.g. "Firebase Analytics". Their SDK007 disclosure reminders are
+	// counted in ScanMetadata.Acknowledged instead of reported.
+	AcknowledgedSDKs []string
+
+	// FollowSymlinks makes the scanners follow symlinked files and
+	// directories within the project. Each file is scanned once, under the
+	// path it has without going through a link when there is one.
+	FollowSymlinks bool
+
+	// OnScannerDone is called with each scanner's result as it finishes.
+	// Scanners run in parallel, so it may be called concurrently.
+	OnScannerDone func(*CheckResult)
+
+	// OnFinding receives each scanner's findings as soon as it finishes,
+	// before deduplication. Calls are serialized.
+	OnFinding func(Finding)
+}
+
+// ScannerIDs returns the IDs of the scanners Scan runs by default.
+func ScannerIDs() []string {
+	return []string{ScannerManifest, ScannerCode, ScannerDataSafety}
+}
+
+// ValidateScannerIDs returns an error naming the valid IDs if any of ids is
+// not a known scanner ID.
+func ValidateScannerIDs(ids []string) error {
+	valid := ScannerIDs()
+	for _, id := range ids {
+		if !slices.Contains(valid, id) {
+			return fmt.Errorf("unknown scanner %q (valid: %s)", id, strings.Join(valid, ", "))
+		}
+	}
+	return nil
+}
+
+// ScannersFor returns the IDs of the scanners Scan runs for path with opts:
+// those selected by opts.Scanners, or every default scanner, and only the
+// manifest scanner when path is an App Bundle.
+func ScannersFor(path string, opts Options) []string {
+	info, err := os.Stat(path)
+	bundle := err == nil && !info.IsDir() && manifest.IsBundlePath(path)
+	var ids []string
+	for _, c := range newRunner(opts, bundle).Checkers() {
+		ids = append(ids, c.ID())
+	}
+	return ids
+}
+
+// newRunner returns a runner with the scanners selected by opts.Scanners
+// registered, or every default scanner when it is empty. App Bundles contain
+// no sources, so only the manifest scanner runs for them. Scanners are
+// created per call so concurrent scans share no state.
+func newRunner(opts Options, bundle bool) *preflight.Runner {
+	want := make(map[string]bool, len(opts.Scanners))
+	for _, id := range opts.Scanners {
+		want[id] = true
+	}
+	manifestOpts := []manifest.ValidatorOption{manifest.WithPreviousVersionCode(opts.PreviousVersionCode), manifest.WithMinSDKFloor(opts.MinSDKFloor)}
+	codeOpts := []codescan.Option{codescan.WithAppCategory(opts.AppCategory), codescan.WithContextLines(opts.ContextLines), codescan.WithEndpointAllowlist(opts.EndpointAllowlist...), codescan.WithPreset(opts.Preset)}
+	dataSafetyOpts := []datasafety.Option{datasafety.WithAppCategory(opts.AppCategory), datasafety.WithStoreCriticalStrings(opts.StoreCriticalStrings...), datasafety.WithAcknowledgedSDKs(opts.AcknowledgedSDKs...)}
+	if opts.FollowSymlinks {
+		manifestOpts = append(manifestOpts, manifest.WithFollowSymlinks())
+		codeOpts = append(codeOpts, codescan.WithFollowSymlinks())
+		dataSafetyOpts = append(dataSafetyOpts, datasafety.WithFollowSymlinks())
+	}
+	return preflight.NewDefaultRunner(func(r *preflight.Runner) {
+		for _, c := range []preflight.Checker{
+			manifest.NewScanner(manifestOpts...),
+			codescan.NewScanner(codeOpts...),
+			datasafety.NewChecker(dataSafetyOpts...),
+		} {
+			if (len(want) == 0 || want[c.ID()]) && (!bundle || c.ID() == ScannerManifest) {
+				r.RegisterScanner(c)
+			}
+		}
+	})
+}
+
+// Scan checks the Android project at path and returns the aggregated result.
+// path may also name an Android App Bundle (.aab), in which case the module
+// manifests inside it are validated. Scan returns an error only if path is
+// not an accessible directory or bundle; problems found by individual
+// scanners are reported in the result.
+//
+// Scan keeps no state between calls and is safe to call concurrently,
+// including for different paths.
+func Scan(path string, opts Options) (*ScanResult, error) {
+	return ScanContext(context.Background(), path, opts)
+}
+
+// ScanContext is like Scan but stops waiting for scanners once ctx is done,
+// e.g. when its deadline passes. It then returns the partial result, listing
+// the scanners that had not finished in ScanMetadata.Unfinished, together
+// with ctx's error.
+func ScanContext(ctx context.Context, path string, opts Options) (*ScanResult, error) {
+	if err := ValidateScannerIDs(opts.Scanners); err != nil {
+		return nil, err
+	}
+	if _, err := preflight.ParseAppCategory(string(opts.AppCategory)); err != nil {
+		return nil, err
+	}
+	if _, err := preflight.ParsePreset(string(opts.Preset)); err != nil {
+		return nil, err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project path: %w", err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access project path: %w", err)
+	}
+	bundle := !info.IsDir() && manifest.IsBundlePath(absPath)
+	if !info.IsDir() && !bundle {
+		return nil, fmt.Errorf("project path is not a directory or .aab bundle: %s", absPath)
+	}
+
+	runner := newRunner(opts, bundle)
+	runner.OverrideSeverities(opts.Preset.Overrides())
+	if opts.OnFinding != nil {
+		runner.OnFinding(opts.OnFinding)
+	}
+	return runner.RunContext(ctx, absPath, opts.OnScannerDone)
+}
@@ -0,0 +1,264 @@
+package playcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+func sampleApp(name string) string {
+	return filepath.Join("..", "..", "testdata", "sample-apps", name)
+}
+
+func TestScan_ViolatingApp(t *testing.T) {
+	result, err := Scan(sampleApp("violating-app"), Options{})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(result.Findings) == 0 {
+		t.Fatal("expected findings for violating app")
+	}
+	if len(result.ScanMeta.ScannerIDs) != len(ScannerIDs()) {
+		t.Errorf("expected %d scanners, got %d", len(ScannerIDs()), len(result.ScanMeta.ScannerIDs))
+	}
+
+	counts := result.CountBySeverity()
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total != len(result.Findings) {
+		t.Errorf("severity counts sum to %d, want %d", total, len(result.Findings))
+	}
+	if counts[SeverityCritical] == 0 {
+		t.Error("expected critical findings for violating app")
+	}
+}
+
+func TestScan_Deterministic(t *testing.T) {
+	reportJSON := func() []byte {
+		t.Helper()
+		result, err := Scan(sampleApp("violating-app"), Options{ContextLines: 2})
+		if err != nil {
+			t.Fatalf("Scan returned error: %v", err)
+		}
+		jr := preflight.NewReport(result, SeverityInfo).ToJSON()
+		jr.Timestamp, jr.Summary.Duration = "", ""
+		data, err := json.Marshal(jr)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		return data
+	}
+
+	first := reportJSON()
+	for i := 0; i < 5; i++ {
+		if next := reportJSON(); !bytes.Equal(first, next) {
+			t.Fatalf("run %d produced different JSON:\n%s\nwant:\n%s", i+2, next, first)
+		}
+	}
+}
+
+func TestScan_Options(t *testing.T) {
+	var mu sync.Mutex
+	done, streamed := 0, 0
+	result, err := Scan(sampleApp("violating-app"), Options{
+		Scanners:      []string{ScannerManifest},
+		OnScannerDone: func(*CheckResult) { done++ },
+		OnFinding: func(Finding) {
+			mu.Lock()
+			streamed++
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if got := result.ScanMeta.ScannerIDs; len(got) != 1 || got[0] != ScannerManifest {
+		t.Errorf("expected only the manifest scanner, got %v", got)
+	}
+	if done != 1 {
+		t.Errorf("expected 1 OnScannerDone call, got %d", done)
+	}
+	if streamed == 0 {
+		t.Error("expected OnFinding to receive findings")
+	}
+}
+
+func TestScan_Concurrent(t *testing.T) {
+	apps := []string{"violating-app", "clean-app", "violating-app", "clean-app"}
+	results := make([]*ScanResult, len(apps))
+	var wg sync.WaitGroup
+	for i, app := range apps {
+		wg.Add(1)
+		go func(i int, app string) {
+			defer wg.Done()
+			r, err := Scan(sampleApp(app), Options{})
+			if err != nil {
+				t.Errorf("Scan(%s) returned error: %v", app, err)
+				return
+			}
+			results[i] = r
+		}(i, app)
+	}
+	wg.Wait()
+
+	for i := 2; i < len(results); i++ {
+		if results[i] == nil || results[i-2] == nil {
+			continue
+		}
+		if len(results[i].Findings) != len(results[i-2].Findings) {
+			t.Errorf("%s: concurrent scans disagree: %d vs %d findings",
+				apps[i], len(results[i].Findings), len(results[i-2].Findings))
+		}
+	}
+}
+
+func TestScannersFor(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		opts Options
+		want []string
+	}{
+		{"project", sampleApp("clean-app"), Options{}, ScannerIDs()},
+		{"selected", sampleApp("clean-app"), Options{Scanners: []string{ScannerCode}}, []string{ScannerCode}},
+		{"bundle", sampleApp("bundle-app.aab"), Options{}, []string{ScannerManifest}},
+		{"bundle without manifest scanner", sampleApp("bundle-app.aab"), Options{Scanners: []string{ScannerCode}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScannersFor(tt.path, tt.opts); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScan_InvalidPath(t *testing.T) {
+	if _, err := Scan(filepath.Join(t.TempDir(), "missing"), Options{}); err == nil {
+		t.Error("expected error for nonexistent path")
+	}
+}
+
+func TestScan_HealthPreset(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.health">
+    <uses-sdk android:minSdkVersion="26" android:targetSdkVersion="35" />
+    <uses-permission android:name="android.permission.BODY_SENSORS" />
+    <uses-permission android:name="android.permission.RECORD_AUDIO" />
+    <application android:icon="@mipmap/ic_launcher" />
+</manifest>`
+	if err := os.WriteFile(filepath.Join(dir, "AndroidManifest.xml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	severities := func(preset Preset) map[string]Severity {
+		t.Helper()
+		result, err := Scan(dir, Options{Scanners: []string{ScannerManifest}, Preset: preset})
+		if err != nil {
+			t.Fatalf("Scan returned error: %v", err)
+		}
+		got := make(map[string]Severity)
+		for _, f := range result.Findings {
+			if f.CheckID == "DP001" {
+				got[strings.TrimPrefix(f.Title, "Dangerous permission: ")] = f.Severity
+			}
+		}
+		return got
+	}
+
+	if got := severities(PresetNone)["BODY_SENSORS"]; got != SeverityWarning {
+		t.Errorf("without a preset: BODY_SENSORS severity = %s, want %s", got, SeverityWarning)
+	}
+	health := severities(PresetHealth)
+	if got := health["BODY_SENSORS"]; got != SeverityCritical {
+		t.Errorf("health preset: BODY_SENSORS severity = %s, want %s", got, SeverityCritical)
+	}
+	if got := health["RECORD_AUDIO"]; got != SeverityWarning {
+		t.Errorf("health preset: RECORD_AUDIO severity = %s, want unchanged %s", got, SeverityWarning)
+	}
+}
+
+func TestPresetOverrides_ScannedRules(t *testing.T) {
+	catalog, err := Rules()
+	if err != nil {
+		t.Fatalf("Rules returned error: %v", err)
+	}
+	scanned := make(map[string]bool)
+	for _, r := range catalog.Rules {
+		if r.Scanner != "" {
+			scanned[r.ID] = true
+		}
+	}
+	for _, preset := range []Preset{PresetGame, PresetFinance, PresetHealth} {
+		for sel := range preset.Overrides() {
+			id, _, _ := strings.Cut(sel, ":")
+			if !scanned[id] {
+				t.Errorf("%s preset overrides %s, which no scanner reports", preset, id)
+			}
+		}
+	}
+}
+
+func TestScan_UnknownPreset(t *testing.T) {
+	if _, err := Scan(sampleApp("clean-app"), Options{Preset: "retail"}); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+}
+
+func TestScan_UnknownScanner(t *testing.T) {
+	if _, err := Scan(sampleApp("clean-app"), Options{Scanners: []string{"lint"}}); err == nil {
+		t.Error("expected error for unknown scanner ID")
+	}
+}
+
+func TestScan_FollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"AndroidManifest.xml": `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.app" />`,
+		"build/shared/Ids.kt": "val id = telephonyManager.getImei()\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// build/ is skipped, so the sources are only reachable through the link.
+	if err := os.Symlink(filepath.Join(dir, "build", "shared"), filepath.Join(dir, "shared")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	cs018Files := func(follow bool) []string {
+		t.Helper()
+		result, err := Scan(dir, Options{Scanners: []string{ScannerCode}, FollowSymlinks: follow})
+		if err != nil {
+			t.Fatalf("Scan returned error: %v", err)
+		}
+		var got []string
+		for _, f := range result.Findings {
+			if f.CheckID == "CS018" {
+				got = append(got, f.Location.File)
+			}
+		}
+		return got
+	}
+
+	if got := cs018Files(false); len(got) != 0 {
+		t.Errorf("without FollowSymlinks: CS018 reported in %v, want none", got)
+	}
+	if got := cs018Files(true); len(got) != 1 || got[0] != filepath.Join("shared", "Ids.kt") {
+		t.Errorf("with FollowSymlinks: CS018 reported in %v, want [shared/Ids.kt]", got)
+	}
+}
@@ -0,0 +1,85 @@
+package playcheck
+
+import (
+	"sort"
+
+	"github.com/kotaroyamazaki/playcheck/internal/codescan"
+	"github.com/kotaroyamazaki/playcheck/internal/datasafety"
+	"github.com/kotaroyamazaki/playcheck/internal/manifest"
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// CatalogSchemaVersion is the version of the Catalog JSON layout. It is bumped
+// whenever a field is removed or changes meaning.
+const CatalogSchemaVersion = 1
+
+// Catalog lists every rule playcheck knows about.
+type Catalog struct {
+	SchemaVersion int    `json:"schema_version"`
+	PolicyVersion string `json:"policy_version"`
+	Rules         []Rule `json:"rules"`
+}
+
+// Rule is one entry of the Catalog. Rules reported by a scanner carry the
+// scanner's title and default severity; rules only present in the policy
+// database carry the database's name and severity and no Scanner.
+type Rule struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Severity    string `json:"severity"`
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description,omitempty"`
+	PolicyLink  string `json:"policy_link,omitempty"`
+	Scanner     string `json:"scanner,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// Rules returns the catalog merged from the policy database and the rules
+// the manifest, code, and data safety scanners report, ordered by ID.
+func Rules() (*Catalog, error) {
+	db, err := policies.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*Rule)
+	for _, r := range db.AllRules() {
+		byID[r.ID] = &Rule{
+			ID:          r.ID,
+			Title:       r.Name,
+			Severity:    r.Severity,
+			Category:    r.Category,
+			Description: r.Description,
+			PolicyLink:  r.PolicyLink,
+			Enabled:     r.IsEnabled(),
+		}
+	}
+	var scanned []preflight.RuleInfo
+	scanned = append(scanned, manifest.Rules()...)
+	scanned = append(scanned, codescan.Rules()...)
+	scanned = append(scanned, datasafety.Rules()...)
+	for _, info := range scanned {
+		r, ok := byID[info.ID]
+		if !ok {
+			r = &Rule{ID: info.ID, Enabled: true}
+			byID[info.ID] = r
+		}
+		r.Title = info.Title
+		r.Severity = info.Severity.String()
+		r.Scanner = info.Scanner
+		if info.Category != "" {
+			r.Category = info.Category
+		}
+		if info.Description != "" {
+			r.Description = info.Description
+		}
+	}
+
+	catalog := &Catalog{SchemaVersion: CatalogSchemaVersion, PolicyVersion: db.Version}
+	for _, r := range byID {
+		catalog.Rules = append(catalog.Rules, *r)
+	}
+	sort.Slice(catalog.Rules, func(i, j int) bool { return catalog.Rules[i].ID < catalog.Rules[j].ID })
+	return catalog, nil
+}
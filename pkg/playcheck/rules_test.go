@@ -0,0 +1,73 @@
+package playcheck
+
+import (
+	"testing"
+
+	"github.com/kotaroyamazaki/playcheck/internal/codescan"
+	"github.com/kotaroyamazaki/playcheck/internal/datasafety"
+	"github.com/kotaroyamazaki/playcheck/internal/manifest"
+	"github.com/kotaroyamazaki/playcheck/internal/policies"
+	"github.com/kotaroyamazaki/playcheck/internal/preflight"
+)
+
+// TestRules_UniqueIDs fails when two scanner rules share an ID, or when a
+// scanner rule and its policy database entry disagree on the category, since
+// Rules merges both into a single catalog entry.
+func TestRules_UniqueIDs(t *testing.T) {
+	db, err := policies.Load()
+	if err != nil {
+		t.Fatalf("policies.Load() error: %v", err)
+	}
+
+	var scanned []preflight.RuleInfo
+	scanned = append(scanned, manifest.Rules()...)
+	scanned = append(scanned, codescan.Rules()...)
+	scanned = append(scanned, datasafety.Rules()...)
+
+	seen := make(map[string]string)
+	for _, info := range scanned {
+		if scanner, ok := seen[info.ID]; ok {
+			t.Errorf("%s is reported by both %s and %s", info.ID, scanner, info.Scanner)
+		}
+		seen[info.ID] = info.Scanner
+
+		if info.Category == "" {
+			t.Errorf("%s (%s) has no category", info.ID, info.Scanner)
+		}
+		if r := db.GetRule(info.ID); r != nil && r.Category != info.Category {
+			t.Errorf("%s: %s reports category %q, policy database has %q (%s)", info.ID, info.Scanner, info.Category, r.Category, r.Name)
+		}
+	}
+
+	catalog, err := Rules()
+	if err != nil {
+		t.Fatalf("Rules() error: %v", err)
+	}
+	for i := 1; i < len(catalog.Rules); i++ {
+		if catalog.Rules[i].ID == catalog.Rules[i-1].ID {
+			t.Errorf("catalog lists %s twice", catalog.Rules[i].ID)
+		}
+	}
+}
+
+// TestRules_PolicySeverities fails when the embedded policy database disagrees
+// with a scanner's default severity. The scanners treat a differing database
+// severity as an override, so a stale entry would change findings silently.
+func TestRules_PolicySeverities(t *testing.T) {
+	db, err := policies.Load()
+	if err != nil {
+		t.Fatalf("policies.Load() error: %v", err)
+	}
+	for _, rules := range [][]preflight.RuleInfo{manifest.Rules(), codescan.Rules(), datasafety.Rules()} {
+		for _, info := range rules {
+			r := db.GetRule(info.ID)
+			if r == nil {
+				t.Errorf("%s (%s) has no policy database entry", info.ID, info.Scanner)
+				continue
+			}
+			if r.Severity != info.Severity.String() {
+				t.Errorf("%s: %s defaults to %s, policy database has %s", info.ID, info.Scanner, info.Severity, r.Severity)
+			}
+		}
+	}
+}
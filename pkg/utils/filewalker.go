@@ -26,9 +26,10 @@ var DefaultSkipDirs = map[string]bool{
 type WalkOption func(*walkConfig)
 
 type walkConfig struct {
-	extensions []string
-	skipDirs   map[string]bool
-	filenames  []string
+	extensions     []string
+	skipDirs       map[string]bool
+	filenames      []string
+	followSymlinks bool
 }
 
 // WithExtensions limits the walk to files matching the given extensions (e.g., ".xml", ".kt").
@@ -54,6 +55,18 @@ func WithFilenames(names ...string) WalkOption {
 	}
 }
 
+// WithFollowSymlinks follows symlinked files and directories instead of
+// skipping them, e.g. shared modules linked into a monorepo app tree. Links
+// whose target resolves outside the root are still skipped, and each
+// directory is walked at most once through links, so link cycles terminate.
+// A file reachable through several paths is returned once, under its path
+// without links when the walk reaches it that way.
+func WithFollowSymlinks() WalkOption {
+	return func(c *walkConfig) {
+		c.followSymlinks = true
+	}
+}
+
 // WalkFiles traverses the project directory and returns file paths matching the given options.
 func WalkFiles(root string, opts ...WalkOption) ([]string, error) {
 	cfg := &walkConfig{
@@ -91,51 +104,116 @@ func WalkFiles(root string, opts ...WalkOption) ([]string, error) {
 	}
 	absRoot, _ = filepath.Abs(absRoot)
 
-	var files []string
-
-	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil // skip entries with errors
+	matches := func(name string) bool {
+		if len(nameSet) > 0 && nameSet[name] {
+			return true
 		}
-
-		if d.IsDir() {
-			if cfg.skipDirs[d.Name()] {
-				return filepath.SkipDir
-			}
-			return nil
+		if len(extSet) > 0 {
+			return extSet[strings.ToLower(filepath.Ext(name))]
 		}
+		// No filters means collect all files.
+		return len(nameSet) == 0
+	}
 
-		// Skip symlinks to prevent path traversal outside the project root.
-		if d.Type()&os.ModeSymlink != 0 {
-			return nil
-		}
+	// found maps the resolved path of each collected file to its index in
+	// files and whether it was reached without following a link.
+	type foundFile struct {
+		index  int
+		direct bool
+	}
+	found := make(map[string]foundFile)
 
-		if len(nameSet) > 0 && nameSet[d.Name()] {
-			files = append(files, path)
-			return nil
+	var files []string
+	collect := func(path, name, resolved string, direct bool) {
+		if !matches(name) {
+			return
 		}
-
-		if len(extSet) > 0 {
-			ext := strings.ToLower(filepath.Ext(d.Name()))
-			if extSet[ext] {
-				files = append(files, path)
+		if cfg.followSymlinks {
+			if f, ok := found[resolved]; ok {
+				if direct && !f.direct {
+					files[f.index] = path
+					found[resolved] = foundFile{index: f.index, direct: true}
+				}
+				return
 			}
-			return nil
+			found[resolved] = foundFile{index: len(files), direct: direct}
 		}
+		files = append(files, path)
+	}
 
-		// No filters means collect all files.
-		if len(nameSet) == 0 && len(extSet) == 0 {
-			files = append(files, path)
-		}
+	// visited holds the resolved directories already walked when following
+	// symlinks.
+	visited := make(map[string]bool)
+
+	// walk traverses the resolved directory dir, reporting paths under
+	// display, the path the directory was reached through. direct is false
+	// when that path goes through a link.
+	var walk func(dir, display string, direct bool) error
+	walk = func(dir, display string, direct bool) error {
+		return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return nil // skip entries with errors
+			}
+			shown := display
+			if path != dir {
+				rel, _ := filepath.Rel(dir, path)
+				shown = filepath.Join(display, rel)
+			}
 
-		return nil
-	})
+			if d.IsDir() {
+				if path != dir && cfg.skipDirs[d.Name()] {
+					return filepath.SkipDir
+				}
+				if cfg.followSymlinks {
+					// Directories reached through a link are walked once;
+					// the walk without links always continues so files
+					// keep their real paths.
+					if !direct && visited[path] {
+						return filepath.SkipDir
+					}
+					visited[path] = true
+				}
+				return nil
+			}
 
-	_ = absRoot // used for documentation of intent; symlinks are skipped above
+			// Skip symlinks unless asked to follow them, and never follow
+			// one out of the project root.
+			if d.Type()&os.ModeSymlink != 0 {
+				if !cfg.followSymlinks {
+					return nil
+				}
+				target, err := filepath.EvalSymlinks(path)
+				if err != nil || !within(absRoot, target) {
+					return nil
+				}
+				info, err := os.Stat(target)
+				switch {
+				case err != nil:
+				case info.IsDir():
+					if !cfg.skipDirs[d.Name()] && !visited[target] {
+						return walk(target, shown, false)
+					}
+				case info.Mode().IsRegular():
+					collect(shown, d.Name(), target, false)
+				}
+				return nil
+			}
 
+			collect(shown, d.Name(), path, direct)
+			return nil
+		})
+	}
+	err = walk(absRoot, root, true)
 	return files, err
 }
 
+// within reports whether path is root or inside it. Both must be absolute
+// and resolved.
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // ReadFileWithLimit reads a file up to MaxFileSize bytes. Returns an error if
 // the file exceeds the limit, preventing memory exhaustion from oversized files.
 func ReadFileWithLimit(path string) ([]byte, error) {
@@ -149,12 +227,28 @@ func ReadFileWithLimit(path string) ([]byte, error) {
 	return os.ReadFile(path)
 }
 
+// Coverage returns the number and total size of the given files that are
+// within MaxFileSize, i.e. the files a scanner can actually read.
+func Coverage(paths []string) (files int, bytes int64) {
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil || info.Size() > MaxFileSize {
+			continue
+		}
+		files++
+		bytes += info.Size()
+	}
+	return files, bytes
+}
+
 // FindAndroidManifests locates all AndroidManifest.xml files in the project.
-func FindAndroidManifests(root string) ([]string, error) {
-	return WalkFiles(root, WithFilenames("AndroidManifest.xml"))
+// opts may add walk options such as WithFollowSymlinks.
+func FindAndroidManifests(root string, opts ...WalkOption) ([]string, error) {
+	return WalkFiles(root, append([]WalkOption{WithFilenames("AndroidManifest.xml")}, opts...)...)
 }
 
 // FindGradleFiles locates all build.gradle and build.gradle.kts files in the project.
-func FindGradleFiles(root string) ([]string, error) {
-	return WalkFiles(root, WithFilenames("build.gradle", "build.gradle.kts"))
+// opts may add walk options such as WithFollowSymlinks.
+func FindGradleFiles(root string, opts ...WalkOption) ([]string, error) {
+	return WalkFiles(root, append([]WalkOption{WithFilenames("build.gradle", "build.gradle.kts")}, opts...)...)
 }
@@ -3,6 +3,7 @@ package utils
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"testing"
 )
@@ -243,3 +244,75 @@ func TestWalkFiles_FilenamesTakePrecedence(t *testing.T) {
 		t.Errorf("expected 1 file by filename, got %d", len(files))
 	}
 }
+
+func TestWalkFiles_FollowSymlinks(t *testing.T) {
+	dir := setupWalkDir(t, map[string]string{
+		"app/src/Main.kt":    "class Main",
+		"shared/src/Util.kt": "object Util",
+		"build/gen/Gen.kt":   "object Gen",
+	})
+	outside := setupWalkDir(t, map[string]string{
+		"Secret.kt": "object Secret",
+	})
+	// A linked module and file inside the root, a link into a skipped
+	// directory, a link escaping the root, and a cycle back to the root.
+	links := map[string]string{
+		"app/shared":    filepath.Join(dir, "shared"),
+		"app/gen":       filepath.Join(dir, "build/gen"),
+		"app/Linked.kt": filepath.Join(dir, "shared/src/Util.kt"),
+		"app/outside":   outside,
+		"shared/loop":   dir,
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	rel := func(files []string) []string {
+		var out []string
+		for _, f := range files {
+			r, err := filepath.Rel(dir, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, filepath.ToSlash(r))
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	files, err := WalkFiles(dir, WithExtensions(".kt"))
+	if err != nil {
+		t.Fatalf("WalkFiles error: %v", err)
+	}
+	if got, want := rel(files), []string{"app/src/Main.kt", "shared/src/Util.kt"}; !slices.Equal(got, want) {
+		t.Errorf("default walk: expected %v, got %v", want, got)
+	}
+
+	files, err = WalkFiles(dir, WithExtensions(".kt"), WithFollowSymlinks())
+	if err != nil {
+		t.Fatalf("WalkFiles error: %v", err)
+	}
+	// Files reachable without links keep that path; Gen.kt is only
+	// reachable through a link.
+	want := []string{"app/gen/Gen.kt", "app/src/Main.kt", "shared/src/Util.kt"}
+	if got := rel(files); !slices.Equal(got, want) {
+		t.Errorf("following symlinks: expected %v, got %v", want, got)
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	dir := setupWalkDir(t, map[string]string{
+		"a.kt":  "12345",
+		"b.xml": "123",
+	})
+	files, bytes := Coverage([]string{
+		filepath.Join(dir, "a.kt"),
+		filepath.Join(dir, "b.xml"),
+		filepath.Join(dir, "missing.kt"),
+	})
+	if files != 2 || bytes != 8 {
+		t.Errorf("expected 2 files / 8 bytes, got %d / %d", files, bytes)
+	}
+}